@@ -11,6 +11,7 @@ import (
 
 	"github.com/drone/drone-runtime/engine/docker"
 	"github.com/drone/drone/cmd/drone-agent/config"
+	"github.com/drone/drone/core"
 	"github.com/drone/drone/operator/manager/rpc"
 	"github.com/drone/drone/operator/runner"
 	"github.com/drone/drone/plugin/registry"
@@ -59,6 +60,7 @@ func main() {
 		config.RPC.Proto+"://"+config.RPC.Host,
 		config.RPC.Secret,
 	)
+	manager.SetAgent(config.RPC.Agent)
 	if config.RPC.Debug {
 		manager.SetDebug(true)
 	}
@@ -89,6 +91,10 @@ func main() {
 		Machine:    config.Runner.Machine,
 		Labels:     config.Runner.Labels,
 		Environ:    config.Runner.Environ,
+		LogLimits: core.LogLimits{
+			Step:  int64(config.Logs.StepLimit),
+			Stage: int64(config.Logs.StageLimit),
+		},
 		Limits: runner.Limits{
 			MemSwapLimit: int64(config.Runner.Limits.MemSwapLimit),
 			MemLimit:     int64(config.Runner.Limits.MemLimit),
@@ -35,6 +35,7 @@ type (
 	Config struct {
 		Docker     Docker
 		Logging    Logging
+		Logs       Logs
 		Registries Registries
 		Runner     Runner
 		RPC        RPC
@@ -56,6 +57,12 @@ type (
 		Text   bool `envconfig:"DRONE_LOGS_TEXT"`
 	}
 
+	// Logs provides the build log configuration.
+	Logs struct {
+		StepLimit  Bytes `envconfig:"DRONE_LOGS_STEP_LIMIT"`
+		StageLimit Bytes `envconfig:"DRONE_LOGS_STAGE_LIMIT"`
+	}
+
 	// Registries provides the registry configuration.
 	Registries struct {
 		Endpoint   string `envconfig:"DRONE_REGISTRY_ENDPOINT"`
@@ -74,6 +81,7 @@ type (
 	RPC struct {
 		Server string `envconfig:"DRONE_RPC_SERVER"`
 		Secret string `envconfig:"DRONE_RPC_SECRET"`
+		Agent  string `envconfig:"DRONE_RPC_AGENT"`
 		Debug  bool   `envconfig:"DRONE_RPC_DEBUG"`
 		Host   string `envconfig:"DRONE_RPC_HOST"`
 		Proto  string `envconfig:"DRONE_RPC_PROTO"`
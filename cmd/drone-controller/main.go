@@ -15,6 +15,7 @@ import (
 	"github.com/drone/drone-runtime/engine/docker"
 	"github.com/drone/drone-runtime/engine/kube"
 	"github.com/drone/drone/cmd/drone-controller/config"
+	"github.com/drone/drone/core"
 	"github.com/drone/drone/operator/manager/rpc"
 	"github.com/drone/drone/operator/runner"
 	"github.com/drone/drone/plugin/registry"
@@ -63,6 +64,7 @@ func main() {
 		config.RPC.Proto+"://"+config.RPC.Host,
 		config.RPC.Secret,
 	)
+	manager.SetAgent(config.RPC.Agent)
 	if config.RPC.Debug {
 		manager.SetDebug(true)
 	}
@@ -103,6 +105,10 @@ func main() {
 		Machine:    config.Runner.Machine,
 		Labels:     config.Runner.Labels,
 		Environ:    config.Runner.Environ,
+		LogLimits: core.LogLimits{
+			Step:  int64(config.Logs.StepLimit),
+			Stage: int64(config.Logs.StageLimit),
+		},
 		Limits: runner.Limits{
 			MemSwapLimit: int64(config.Runner.Limits.MemSwapLimit),
 			MemLimit:     int64(config.Runner.Limits.MemLimit),
@@ -118,6 +124,13 @@ func main() {
 		logrus.WithError(err).
 			Fatalln("cannot parse stage ID")
 	}
+	// the stage is accepted before it is executed, which
+	// assigns the stage to this machine and issues the stage
+	// token required by the server to execute the stage.
+	if err := manager.Accept(ctx, id, config.Runner.Machine); err != nil {
+		logrus.WithError(err).
+			Fatalln("cannot accept stage")
+	}
 	if err := r.Run(ctx, id); err != nil {
 		logrus.WithError(err).
 			Warnln("program terminated")
@@ -0,0 +1,104 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/drone/drone/cmd/drone-server/config"
+	"github.com/drone/drone/cmd/drone-server/migrate"
+	"github.com/drone/drone/store/step"
+
+	"github.com/sirupsen/logrus"
+)
+
+// migrateLogs copies the step logs between log stores. The
+// source and target stores are named (database, file, s3, gcs
+// or azure) and configured from the environment.
+//
+//	drone-server migrate-logs -source=database -target=s3
+func migrateLogs(ctx context.Context, config config.Config, args []string) error {
+	var (
+		source  string
+		target  string
+		options struct {
+			checkpoint  string
+			concurrency int
+			batch       int
+			dryrun      bool
+			delete      bool
+		}
+	)
+
+	flags := flag.NewFlagSet("migrate-logs", flag.ExitOnError)
+	flags.StringVar(&source, "source", "database", "Source log store (database, file, s3, gcs, azure)")
+	flags.StringVar(&target, "target", logStoreDriver(config), "Target log store (database, file, s3, gcs, azure)")
+	flags.StringVar(&options.checkpoint, "checkpoint", "", "Path to the checkpoint file used to resume the migration")
+	flags.IntVar(&options.concurrency, "concurrency", 4, "Number of logs copied in parallel")
+	flags.IntVar(&options.batch, "batch", 100, "Number of steps migrated between checkpoints")
+	flags.BoolVar(&options.dryrun, "dry-run", false, "Report the logs that would be migrated without copying")
+	flags.BoolVar(&options.delete, "delete", false, "Delete logs from the source after verifying the checksum")
+	flags.Parse(args)
+
+	if source == target {
+		return errors.New("source and target log stores must differ")
+	}
+
+	db, err := provideDatabase(config)
+	if err != nil {
+		return err
+	}
+	from, err := newLogStore(db, config, source)
+	if err != nil {
+		return err
+	}
+	to, err := newLogStore(db, config, target)
+	if err != nil {
+		return err
+	}
+
+	migrator := migrate.New(step.New(db), from, to)
+	migrator.Checkpoint = options.checkpoint
+	migrator.Concurrency = options.concurrency
+	migrator.DryRun = options.dryrun
+	migrator.Delete = options.delete
+	if options.batch > 0 {
+		migrator.BatchSize = options.batch
+	}
+
+	logrus.WithFields(
+		logrus.Fields{
+			"source":  source,
+			"target":  target,
+			"dry-run": options.dryrun,
+			"delete":  options.delete,
+		},
+	).Infoln("main: migrating logs")
+
+	res, err := migrator.Migrate(ctx)
+	if res != nil {
+		logrus.WithFields(
+			logrus.Fields{
+				"copied":  res.Copied,
+				"skipped": res.Skipped,
+				"deleted": res.Deleted,
+				"last":    res.Last,
+			},
+		).Infoln("main: log migration complete")
+	}
+	return err
+}
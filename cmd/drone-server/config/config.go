@@ -46,16 +46,23 @@ type (
 	Config struct {
 		License string `envconfig:"DRONE_LICENSE"`
 
-		Authn    Authentication
-		Agent    Agent
-		Cron     Cron
-		Cloning  Cloning
-		Database Database
-		Datadog  Datadog
-		Docker   Docker
-		HTTP     HTTP
-		Jsonnet  Jsonnet
-		Logging  Logging
+		Authn       Authentication
+		Agent       Agent
+		Audit       Audit
+		Cron        Cron
+		Cloning     Cloning
+		Content     Content
+		Convert     Convert
+		Database    Database
+		Deprovision Deprovision
+		Datadog     Datadog
+		Docker      Docker
+		HTTP        HTTP
+		Jsonnet     Jsonnet
+		Logging     Logging
+		Logs        Logs
+		OIDC        OIDC
+		Policy      Policy
 		// Prometheus Prometheus
 		Proxy        Proxy
 		Registration Registration
@@ -66,10 +73,14 @@ type (
 		Kube         Kubernetes
 		RPC          RPC
 		S3           S3
+		GCS          GCS
+		Azure        Azure
 		Secrets      Secrets
 		Server       Server
 		Session      Session
+		Starlark     Starlark
 		Status       Status
+		Template     Template
 		Users        Users
 		Webhook      Webhook
 		Yaml         Yaml
@@ -98,6 +109,14 @@ type (
 		Interval time.Duration `envconfig:"DRONE_CRON_INTERVAL" default:"30m"`
 	}
 
+	// Deprovision provides the account deprovisioning
+	// configuration.
+	Deprovision struct {
+		Enabled  bool          `envconfig:"DRONE_DEPROVISION_ENABLED"`
+		Interval time.Duration `envconfig:"DRONE_DEPROVISION_INTERVAL" default:"24h"`
+		Owner    string        `envconfig:"DRONE_DEPROVISION_REPO_OWNER"`
+	}
+
 	// Database provides the database configuration.
 	Database struct {
 		Driver     string `envconfig:"DRONE_DATABASE_DRIVER"     default:"sqlite3"`
@@ -117,11 +136,41 @@ type (
 		Token    string `envconfig:"DRONE_DATADOG_TOKEN"`
 	}
 
+	// Content configures the file content cache.
+	Content struct {
+		CacheSize        int           `envconfig:"DRONE_CONTENT_CACHE_SIZE" default:"1000"`
+		CacheNotFoundTTL time.Duration `envconfig:"DRONE_CONTENT_CACHE_NOT_FOUND_TTL" default:"1m"`
+		CacheRefTTL      time.Duration `envconfig:"DRONE_CONTENT_CACHE_REF_TTL" default:"5m"`
+	}
+
+	// Convert configures the conversion plugin
+	Convert struct {
+		Endpoint   string `envconfig:"DRONE_CONVERT_PLUGIN_ENDPOINT"`
+		Secret     string `envconfig:"DRONE_CONVERT_PLUGIN_SECRET"`
+		SkipVerify bool   `envconfig:"DRONE_CONVERT_PLUGIN_SKIP_VERIFY"`
+	}
+
 	// Jsonnet configures the jsonnet plugin
 	Jsonnet struct {
 		Enabled bool `envconfig:"DRONE_JSONNET_ENABLED"`
 	}
 
+	// Policy configures the pipeline policy plugin
+	Policy struct {
+		Path string `envconfig:"DRONE_POLICY_PATH"`
+	}
+
+	// Starlark configures the starlark plugin
+	Starlark struct {
+		Enabled bool `envconfig:"DRONE_STARLARK_ENABLED"`
+	}
+
+	// Template configures the configuration template plugin
+	Template struct {
+		Repos []string `envconfig:"DRONE_TEMPLATE_REPOS"`
+		Ref   string   `envconfig:"DRONE_TEMPLATE_REF" default:"refs/heads/master"`
+	}
+
 	// Kubernetes provides kubernetes configuration
 	Kubernetes struct {
 		Enabled            bool   `envconfig:"DRONE_KUBERNETES_ENABLED"`
@@ -162,6 +211,13 @@ type (
 		Text   bool `envconfig:"DRONE_LOGS_TEXT"`
 	}
 
+	// Logs provides the build log configuration.
+	Logs struct {
+		Path       string `envconfig:"DRONE_LOGS_PATH"`
+		StepLimit  Bytes  `envconfig:"DRONE_LOGS_STEP_LIMIT"`
+		StageLimit Bytes  `envconfig:"DRONE_LOGS_STAGE_LIMIT"`
+	}
+
 	// Repository provides the repository configuration.
 	Repository struct {
 		Filter []string `envconfig:"DRONE_REPOSITORY_FILTER"`
@@ -183,11 +239,14 @@ type (
 
 	// RPC provides the rpc configuration.
 	RPC struct {
-		Server string `envconfig:"DRONE_RPC_SERVER"`
-		Secret string `envconfig:"DRONE_RPC_SECRET"`
-		Debug  bool   `envconfig:"DRONE_RPC_DEBUG"`
-		Host   string `envconfig:"DRONE_RPC_HOST"`
-		Proto  string `envconfig:"DRONE_RPC_PROTO"`
+		Server     string            `envconfig:"DRONE_RPC_SERVER"`
+		Secret     string            `envconfig:"DRONE_RPC_SECRET"`
+		Debug      bool              `envconfig:"DRONE_RPC_DEBUG"`
+		Host       string            `envconfig:"DRONE_RPC_HOST"`
+		Proto      string            `envconfig:"DRONE_RPC_PROTO"`
+		Agents     map[string]string `envconfig:"DRONE_RPC_AGENTS"`
+		SigningKey string            `envconfig:"DRONE_RPC_SIGNING_KEY"`
+		TokenTTL   time.Duration     `envconfig:"DRONE_RPC_TOKEN_TTL" default:"1h"`
 		// Hosts  map[string]string `envconfig:"DRONE_RPC_EXTRA_HOSTS"`
 	}
 
@@ -245,6 +304,11 @@ type (
 		Closed bool `envconfig:"DRONE_REGISTRATION_CLOSED"`
 	}
 
+	// Audit provides the audit log configuration.
+	Audit struct {
+		Webhook bool `envconfig:"DRONE_AUDIT_WEBHOOK"`
+	}
+
 	// Authentication Controller configuration
 	Authentication struct {
 		Endpoint   string `envconfig:"DRONE_AUTHENTICATION_ENDPOINT"`
@@ -252,6 +316,19 @@ type (
 		SkipVerify bool   `envconfig:"DRONE_AUTHENTICATION_SKIP_VERIFY"`
 	}
 
+	// OIDC provides the OpenID Connect login configuration.
+	OIDC struct {
+		Issuer       string   `envconfig:"DRONE_OIDC_ISSUER"`
+		ClientID     string   `envconfig:"DRONE_OIDC_CLIENT_ID"`
+		ClientSecret string   `envconfig:"DRONE_OIDC_CLIENT_SECRET"`
+		Scopes       []string `envconfig:"DRONE_OIDC_SCOPES" default:"openid,profile,email,groups"`
+		LoginClaim   string   `envconfig:"DRONE_OIDC_LOGIN_CLAIM" default:"preferred_username"`
+		GroupsClaim  string   `envconfig:"DRONE_OIDC_GROUPS_CLAIM" default:"groups"`
+		AdminGroups  []string `envconfig:"DRONE_OIDC_ADMIN_GROUPS"`
+		LinkLogin    bool     `envconfig:"DRONE_OIDC_LINK_LOGIN"`
+		SkipVerify   bool     `envconfig:"DRONE_OIDC_SKIP_VERIFY"`
+	}
+
 	// Session provides the session configuration.
 	Session struct {
 		Timeout time.Duration `envconfig:"DRONE_COOKIE_TIMEOUT" default:"720h"`
@@ -350,6 +427,23 @@ type (
 		PathStyle bool   `envconfig:"DRONE_S3_PATH_STYLE"`
 	}
 
+	// GCS provides the Google Cloud Storage configuration.
+	GCS struct {
+		Bucket      string `envconfig:"DRONE_GCS_BUCKET"`
+		Prefix      string `envconfig:"DRONE_GCS_PREFIX"`
+		Endpoint    string `envconfig:"DRONE_GCS_ENDPOINT"`
+		Credentials string `envconfig:"DRONE_GCS_CREDENTIALS"`
+	}
+
+	// Azure provides the Azure Blob Storage configuration.
+	Azure struct {
+		Account   string `envconfig:"DRONE_AZURE_STORAGE_ACCOUNT"`
+		Key       string `envconfig:"DRONE_AZURE_STORAGE_KEY"`
+		Container string `envconfig:"DRONE_AZURE_STORAGE_CONTAINER"`
+		Prefix    string `envconfig:"DRONE_AZURE_STORAGE_PREFIX"`
+		Endpoint  string `envconfig:"DRONE_AZURE_STORAGE_ENDPOINT"`
+	}
+
 	// HTTP provides http configuration.
 	HTTP struct {
 		AllowedHosts          []string          `envconfig:"DRONE_HTTP_ALLOWED_HOSTS"`
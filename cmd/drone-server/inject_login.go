@@ -16,6 +16,7 @@ package main
 
 import (
 	"github.com/drone/drone/cmd/drone-server/config"
+	"github.com/drone/drone/handler/web/oidc"
 	"github.com/drone/go-login/login"
 	"github.com/drone/go-login/login/bitbucket"
 	"github.com/drone/go-login/login/github"
@@ -31,6 +32,7 @@ import (
 // wire set for loading the authenticator.
 var loginSet = wire.NewSet(
 	provideLogin,
+	provideOIDC,
 	provideRefresher,
 )
 
@@ -55,6 +57,26 @@ func provideLogin(config config.Config) login.Middleware {
 	return nil
 }
 
+// provideOIDC is a Wire provider function that returns an
+// OpenID Connect identity provider, or nil if not configured.
+func provideOIDC(config config.Config) *oidc.Provider {
+	if config.OIDC.Issuer == "" {
+		return nil
+	}
+	return oidc.New(oidc.Config{
+		Issuer:       config.OIDC.Issuer,
+		ClientID:     config.OIDC.ClientID,
+		ClientSecret: config.OIDC.ClientSecret,
+		RedirectURL:  config.Server.Addr + "/login/oidc",
+		Scopes:       config.OIDC.Scopes,
+		LoginClaim:   config.OIDC.LoginClaim,
+		GroupsClaim:  config.OIDC.GroupsClaim,
+		AdminGroups:  config.OIDC.AdminGroups,
+		LinkLogin:    config.OIDC.LinkLogin,
+		SkipVerify:   config.OIDC.SkipVerify,
+	})
+}
+
 // provideBitbucketLogin is a Wire provider function that
 // returns a Bitbucket Cloud autenticator based on the
 // environment configuration.
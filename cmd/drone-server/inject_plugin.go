@@ -19,6 +19,8 @@ import (
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/plugin/admission"
 	"github.com/drone/drone/plugin/config"
+	"github.com/drone/drone/plugin/converter"
+	"github.com/drone/drone/plugin/policy"
 	"github.com/drone/drone/plugin/registry"
 	"github.com/drone/drone/plugin/secret"
 	"github.com/drone/drone/plugin/webhook"
@@ -31,6 +33,8 @@ import (
 var pluginSet = wire.NewSet(
 	provideAdmissionPlugin,
 	provideConfigPlugin,
+	provideConvertPlugin,
+	providePolicyPlugin,
 	provideRegistryPlugin,
 	provideSecretPlugin,
 	provideWebhookPlugin,
@@ -57,11 +61,33 @@ func provideConfigPlugin(client *scm.Client, contents core.FileService, conf spe
 			conf.Yaml.Secret,
 			conf.Yaml.SkipVerify,
 		),
-		config.Jsonnet(contents, conf.Jsonnet.Enabled),
+		config.Template(contents, conf.Template.Repos, conf.Template.Ref),
 		config.Repository(contents),
 	)
 }
 
+// provideConvertPlugin is a Wire provider function that returns
+// a yaml conversion plugin based on the environment
+// configuration.
+func provideConvertPlugin(contents core.FileService, conf spec.Config) core.ConvertService {
+	return converter.Combine(
+		converter.Jsonnet(contents, conf.Jsonnet.Enabled),
+		converter.Starlark(contents, conf.Starlark.Enabled),
+		converter.Remote(
+			conf.Convert.Endpoint,
+			conf.Convert.Secret,
+			conf.Convert.SkipVerify,
+		),
+	)
+}
+
+// providePolicyPlugin is a Wire provider function that returns
+// a pipeline policy plugin based on the environment
+// configuration.
+func providePolicyPlugin(conf spec.Config) (core.PolicyService, error) {
+	return policy.Expression(conf.Policy.Path)
+}
+
 // provideRegistryPlugin is a Wire provider function that
 // returns a registry plugin based on the environment
 // configuration.
@@ -66,6 +66,10 @@ func provideRunner(
 		Machine:    config.Runner.Machine,
 		Labels:     config.Runner.Labels,
 		Environ:    config.Runner.Environ,
+		LogLimits: core.LogLimits{
+			Step:  int64(config.Logs.StepLimit),
+			Stage: int64(config.Logs.StageLimit),
+		},
 		Limits: runner.Limits{
 			MemSwapLimit: int64(config.Runner.Limits.MemSwapLimit),
 			MemLimit:     int64(config.Runner.Limits.MemLimit),
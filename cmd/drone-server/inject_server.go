@@ -15,9 +15,11 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/drone/drone/cmd/drone-server/config"
+	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api"
 	"github.com/drone/drone/handler/web"
 	"github.com/drone/drone/metric"
@@ -34,6 +36,7 @@ import (
 var serverSet = wire.NewSet(
 	manager.New,
 	metric.NewServer,
+	provideLogLimits,
 	api.New,
 	web.New,
 	provideRouter,
@@ -55,8 +58,27 @@ func provideRouter(api api.Server, web web.Server, rpc http.Handler, metrics *me
 
 // provideRPC is a Wire provider function that returns an rpc
 // handler that exposes the build manager to a remote agent.
-func provideRPC(m manager.BuildManager, config config.Config) http.Handler {
-	return rpc.NewServer(m, config.RPC.Secret)
+func provideRPC(m manager.BuildManager, steps core.StepStore, config config.Config) (http.Handler, error) {
+	// stage tokens are signed with a key derived from the
+	// shared secret. If agents only use per-agent credentials
+	// the signing key must be provided explicitly.
+	if len(config.RPC.Agents) != 0 && config.RPC.Secret == "" && config.RPC.SigningKey == "" {
+		return nil, errors.New("main: DRONE_RPC_SIGNING_KEY is required when DRONE_RPC_SECRET is not set")
+	}
+	server := rpc.NewServer(m, steps, config.RPC.Secret)
+	server.SetAgents(config.RPC.Agents)
+	server.SetSigningKey(config.RPC.SigningKey)
+	server.SetTokenTTL(config.RPC.TokenTTL)
+	return server, nil
+}
+
+// provideLogLimits is a Wire provider function that returns
+// the build log size limits configured from the environment.
+func provideLogLimits(config config.Config) core.LogLimits {
+	return core.LogLimits{
+		Step:  int64(config.Logs.StepLimit),
+		Stage: int64(config.Logs.StageLimit),
+	}
 }
 
 // provideServer is a Wire provider function that returns an
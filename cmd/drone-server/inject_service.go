@@ -18,16 +18,22 @@ import (
 	"github.com/drone/drone/cmd/drone-server/config"
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/livelog"
+	"github.com/drone/drone/metric"
 	"github.com/drone/drone/metric/sink"
+	"github.com/drone/drone/plugin/admission"
 	"github.com/drone/drone/pubsub"
+	"github.com/drone/drone/service/approval"
+	"github.com/drone/drone/service/audit"
 	"github.com/drone/drone/service/commit"
 	"github.com/drone/drone/service/content"
 	"github.com/drone/drone/service/content/cache"
+	"github.com/drone/drone/service/deprovision"
 	"github.com/drone/drone/service/hook"
 	"github.com/drone/drone/service/hook/parser"
 	"github.com/drone/drone/service/netrc"
 	"github.com/drone/drone/service/org"
 	"github.com/drone/drone/service/repo"
+	"github.com/drone/drone/service/role"
 	"github.com/drone/drone/service/status"
 	"github.com/drone/drone/service/syncer"
 	"github.com/drone/drone/service/token"
@@ -43,10 +49,12 @@ import (
 
 // wire set for loading the services.
 var serviceSet = wire.NewSet(
+	approval.New,
 	commit.New,
 	cron.New,
 	livelog.New,
 	orgs.New,
+	role.New,
 	parser.New,
 	pubsub.New,
 	repo.New,
@@ -54,7 +62,9 @@ var serviceSet = wire.NewSet(
 	trigger.New,
 	user.New,
 
+	provideAuditService,
 	provideContentService,
+	provideDeprovisioner,
 	provideDatadog,
 	provideHookService,
 	provideNetrcService,
@@ -64,12 +74,25 @@ var serviceSet = wire.NewSet(
 	provideSystem,
 )
 
+// provideAuditService is a Wire provider function that returns
+// an audit service that optionally forwards audit events to the
+// webhook sender, based on the environment configuration.
+func provideAuditService(store core.AuditStore, sender core.WebhookSender, config config.Config) core.AuditService {
+	return audit.New(store, sender, config.Audit.Webhook)
+}
+
 // provideContentService is a Wire provider function that
-// returns a contents service wrapped with a simple LRU cache.
-func provideContentService(client *scm.Client, renewer core.Renewer) core.FileService {
-	return cache.Contents(
+// returns a contents service wrapped with an LRU cache, with
+// cache metrics enabled.
+func provideContentService(client *scm.Client, renewer core.Renewer, config config.Config) core.FileService {
+	files := cache.Contents(
 		contents.New(client, renewer),
+		config.Content.CacheSize,
+		config.Content.CacheNotFoundTTL,
+		config.Content.CacheRefTTL,
 	)
+	metric.ContentCache(files)
+	return files
 }
 
 // provideHookService is a Wire provider function that returns a
@@ -92,8 +115,8 @@ func provideNetrcService(client *scm.Client, renewer core.Renewer, config config
 
 // provideSession is a Wire provider function that returns a
 // user session based on the environment configuration.
-func provideSession(store core.UserStore, config config.Config) core.Session {
-	return session.New(store, session.NewConfig(
+func provideSession(store core.UserStore, tokens core.TokenStore, sessions core.SessionStore, config config.Config) core.Session {
+	return session.New(store, tokens, sessions, session.NewConfig(
 		config.Session.Secret,
 		config.Session.Timeout),
 	)
@@ -171,3 +194,35 @@ func provideDatadog(
 		},
 	)
 }
+
+// provideDeprovisioner is a Wire provider function that returns
+// a reconciler that deactivates user accounts revoked in the
+// source control management system, or that are no longer
+// members of an approved organization.
+func provideDeprovisioner(
+	users core.UserStore,
+	userz core.UserService,
+	renewer core.Renewer,
+	orgs core.OrganizationService,
+	repos core.RepositoryStore,
+	sessions core.SessionStore,
+	tokens core.TokenStore,
+	audits core.AuditService,
+	config config.Config,
+) *deprovision.Reconciler {
+	var membership core.AdmissionService
+	if len(config.Users.Filter) != 0 {
+		membership = admission.Membership(orgs, config.Users.Filter)
+	}
+	return deprovision.New(
+		users,
+		userz,
+		renewer,
+		membership,
+		repos,
+		sessions,
+		tokens,
+		audits,
+		config.Deprovision.Owner,
+	)
+}
@@ -15,20 +15,30 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/drone/drone/cmd/drone-server/config"
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/metric"
+	"github.com/drone/drone/store/approvals"
+	"github.com/drone/drone/store/audit"
 	"github.com/drone/drone/store/batch"
 	"github.com/drone/drone/store/build"
+	"github.com/drone/drone/store/configs"
 	"github.com/drone/drone/store/cron"
+	"github.com/drone/drone/store/identities"
+	"github.com/drone/drone/store/logins"
 	"github.com/drone/drone/store/logs"
 	"github.com/drone/drone/store/perm"
 	"github.com/drone/drone/store/repos"
+	"github.com/drone/drone/store/roles"
 	"github.com/drone/drone/store/secret"
+	"github.com/drone/drone/store/sessions"
 	"github.com/drone/drone/store/shared/db"
 	"github.com/drone/drone/store/shared/encrypt"
 	"github.com/drone/drone/store/stage"
 	"github.com/drone/drone/store/step"
+	"github.com/drone/drone/store/tokens"
 	"github.com/drone/drone/store/user"
 
 	"github.com/google/wire"
@@ -43,11 +53,19 @@ var storeSet = wire.NewSet(
 	provideRepoStore,
 	provideStageStore,
 	provideUserStore,
+	approvals.New,
+	audit.New,
 	batch.New,
+	configs.New,
 	cron.New,
+	identities.New,
+	logins.New,
 	perm.New,
 	secret.New,
+	sessions.New,
 	step.New,
+	tokens.New,
+	roles.New,
 )
 
 // provideDatabase is a Wire provider function that provides a
@@ -78,16 +96,60 @@ func provideBuildStore(db *db.DB) core.BuildStore {
 
 // provideLogStore is a Wire provider function that provides a
 // log datastore, configured from the environment.
-func provideLogStore(db *db.DB, config config.Config) core.LogStore {
-	if config.S3.Bucket == "" {
-		return logs.New(db)
+func provideLogStore(db *db.DB, config config.Config) (core.LogStore, error) {
+	return newLogStore(db, config, logStoreDriver(config))
+}
+
+// newLogStore returns the named log datastore, configured from
+// the environment.
+func newLogStore(db *db.DB, config config.Config, driver string) (core.LogStore, error) {
+	switch driver {
+	case "s3":
+		return logs.NewS3Env(
+			config.S3.Bucket,
+			config.S3.Prefix,
+			config.S3.Endpoint,
+			config.S3.PathStyle,
+		), nil
+	case "gcs":
+		return logs.NewGCSEnv(
+			config.GCS.Bucket,
+			config.GCS.Prefix,
+			config.GCS.Endpoint,
+			config.GCS.Credentials,
+		)
+	case "azure":
+		return logs.NewAzureEnv(
+			config.Azure.Account,
+			config.Azure.Key,
+			config.Azure.Container,
+			config.Azure.Prefix,
+			config.Azure.Endpoint,
+		)
+	case "file":
+		return logs.NewFile(config.Logs.Path), nil
+	case "database":
+		return logs.New(db), nil
+	default:
+		return nil, fmt.Errorf("unknown log store: %s", driver)
+	}
+}
+
+// helper function returns the name of the log datastore
+// configured in the environment.
+func logStoreDriver(config config.Config) string {
+	switch {
+	case config.S3.Bucket != "":
+		return "s3"
+	case config.GCS.Bucket != "":
+		return "gcs"
+	case config.Azure.Container != "":
+		return "azure"
+	case config.Logs.Path != "":
+		return "file"
+	default:
+		return "database"
 	}
-	return logs.NewS3Env(
-		config.S3.Bucket,
-		config.S3.Prefix,
-		config.S3.Endpoint,
-		config.S3.PathStyle,
-	)
 }
 
 // provideStageStore is a Wire provider function that provides a
@@ -25,6 +25,7 @@ import (
 	"github.com/drone/drone/metric/sink"
 	"github.com/drone/drone/operator/runner"
 	"github.com/drone/drone/server"
+	"github.com/drone/drone/service/deprovision"
 	"github.com/drone/drone/trigger/cron"
 	"github.com/drone/signal"
 
@@ -60,6 +61,15 @@ func main() {
 		fmt.Println(config.String())
 	}
 
+	// the migrate-logs command copies the build logs between
+	// log stores and exits, without starting the server.
+	if flag.Arg(0) == "migrate-logs" {
+		if err := migrateLogs(ctx, config, flag.Args()[1:]); err != nil {
+			logrus.WithError(err).Fatalln("main: cannot migrate logs")
+		}
+		return
+	}
+
 	app, err := InitializeApplication(config)
 	if err != nil {
 		logger := logrus.WithError(err)
@@ -114,6 +124,18 @@ func main() {
 		return app.cron.Start(ctx, config.Cron.Interval)
 	})
 
+	// launches the account deprovisioning reconciler in a
+	// goroutine. If the reconciler is disabled, the goroutine
+	// exits immediately without error.
+	g.Go(func() (err error) {
+		if !config.Deprovision.Enabled {
+			return nil
+		}
+		logrus.WithField("interval", config.Deprovision.Interval.String()).
+			Infoln("starting the account deprovisioning reconciler")
+		return app.deprovision.Start(ctx, config.Deprovision.Interval)
+	})
+
 	// launches the build runner in a goroutine. If the local
 	// runner is disabled (because nomad or kubernetes is enabled)
 	// then the goroutine exits immediately without error.
@@ -153,25 +175,28 @@ func initLogging(c config.Config) {
 
 // application is the main struct for the Drone server.
 type application struct {
-	cron   *cron.Scheduler
-	sink   *sink.Datadog
-	runner *runner.Runner
-	server *server.Server
-	users  core.UserStore
+	cron        *cron.Scheduler
+	deprovision *deprovision.Reconciler
+	sink        *sink.Datadog
+	runner      *runner.Runner
+	server      *server.Server
+	users       core.UserStore
 }
 
 // newApplication creates a new application struct.
 func newApplication(
 	cron *cron.Scheduler,
+	deprovision *deprovision.Reconciler,
 	sink *sink.Datadog,
 	runner *runner.Runner,
 	server *server.Server,
 	users core.UserStore) application {
 	return application{
-		users:  users,
-		cron:   cron,
-		sink:   sink,
-		server: server,
-		runner: runner,
+		users:       users,
+		cron:        cron,
+		deprovision: deprovision,
+		sink:        sink,
+		server:      server,
+		runner:      runner,
 	}
 }
@@ -0,0 +1,255 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/logs"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// New returns a new log migrator that copies step logs from
+// the source store to the target store.
+func New(steps core.StepStore, source, target core.LogStore) *Migrator {
+	return &Migrator{
+		steps:       steps,
+		source:      source,
+		target:      target,
+		Concurrency: 4,
+		BatchSize:   100,
+	}
+}
+
+// Migrator migrates step logs between log stores.
+type Migrator struct {
+	steps  core.StepStore
+	source core.LogStore
+	target core.LogStore
+
+	// Checkpoint is the optional path to a file that records
+	// the last migrated step, used to resume the migration.
+	Checkpoint string
+
+	// Concurrency is the number of logs copied in parallel.
+	Concurrency int
+
+	// BatchSize is the number of steps fetched from the
+	// database, and migrated, between checkpoints.
+	BatchSize int
+
+	// DryRun reports the logs that would be migrated without
+	// writing to the target or deleting from the source.
+	DryRun bool
+
+	// Delete removes the logs from the source store after the
+	// copy is verified using a checksum.
+	Delete bool
+}
+
+// Result provides the migration summary.
+type Result struct {
+	Copied  int64 `json:"copied"`
+	Skipped int64 `json:"skipped"`
+	Deleted int64 `json:"deleted"`
+	Last    int64 `json:"last"`
+}
+
+// Migrate copies the step logs from the source store to the
+// target store. If a checkpoint file is configured, the
+// migration resumes from the last step recorded in the file,
+// and the file is updated after each batch completes.
+func (m *Migrator) Migrate(ctx context.Context) (*Result, error) {
+	last, err := m.readCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Last: last}
+	for {
+		steps, err := m.steps.ListAfter(ctx, last, m.BatchSize)
+		if err != nil {
+			return res, err
+		}
+		if len(steps) == 0 {
+			return res, nil
+		}
+
+		if err := m.batch(ctx, steps, res); err != nil {
+			return res, err
+		}
+
+		last = steps[len(steps)-1].ID
+		res.Last = last
+		if err := m.writeCheckpoint(last); err != nil {
+			return res, err
+		}
+
+		logrus.WithFields(
+			logrus.Fields{
+				"step":    last,
+				"copied":  res.Copied,
+				"skipped": res.Skipped,
+				"deleted": res.Deleted,
+			},
+		).Infoln("migrate: batch complete")
+	}
+}
+
+// batch migrates the batch of steps concurrently.
+func (m *Migrator) batch(ctx context.Context, steps []*core.Step, res *Result) error {
+	var mu sync.Mutex
+	var g errgroup.Group
+	limit := m.Concurrency
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	for _, step := range steps {
+		step := step
+		sem <- struct{}{}
+		g.Go(func() error {
+			defer func() { <-sem }()
+			copied, deleted, err := m.migrate(ctx, step.ID)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				return err
+			case copied:
+				res.Copied++
+			default:
+				res.Skipped++
+			}
+			if deleted {
+				res.Deleted++
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// migrate copies the logs for a single step. It returns false
+// if the step has no logs in the source store.
+func (m *Migrator) migrate(ctx context.Context, step int64) (copied, deleted bool, err error) {
+	log := logrus.WithField("step", step)
+
+	// steps without logs in the source store are skipped. Any
+	// other error fails the batch, so that the checkpoint is not
+	// advanced past logs that were never copied.
+	rc, err := m.source.Find(ctx, step)
+	if logs.IsNotFound(err) {
+		log.WithError(err).Debugln("migrate: cannot find logs, skipping")
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("migrate: cannot find logs for step %d: %s", step, err)
+	}
+	data, err := ioutil.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return false, false, fmt.Errorf("migrate: cannot read logs for step %d: %s", step, err)
+	}
+
+	if m.DryRun {
+		log.WithField("size", len(data)).Infoln("migrate: dry run, logs not copied")
+		return true, false, nil
+	}
+
+	// the logs may already exist in the target store if a
+	// previous migration was interrupted mid-batch, in which
+	// case the existing logs are overwritten.
+	if err := m.target.Create(ctx, step, bytes.NewReader(data)); err != nil {
+		if err := m.target.Update(ctx, step, bytes.NewReader(data)); err != nil {
+			return false, false, fmt.Errorf("migrate: cannot write logs for step %d: %s", step, err)
+		}
+	}
+
+	if !m.Delete {
+		return true, false, nil
+	}
+
+	if err := m.verify(ctx, step, data); err != nil {
+		log.WithError(err).Warnln("migrate: checksum mismatch, logs not deleted from source")
+		return true, false, nil
+	}
+	if err := m.source.Delete(ctx, step); err != nil {
+		return true, false, fmt.Errorf("migrate: cannot delete logs for step %d: %s", step, err)
+	}
+	return true, true, nil
+}
+
+// verify compares the checksum of the logs in the target store
+// to the checksum of the logs copied from the source store.
+func (m *Migrator) verify(ctx context.Context, step int64, data []byte) error {
+	rc, err := m.target.Find(ctx, step)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return err
+	}
+	want := sha256.Sum256(data)
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		return fmt.Errorf("want checksum %x, got %x", want, got)
+	}
+	return nil
+}
+
+// readCheckpoint returns the last migrated step recorded in
+// the checkpoint file, or zero if the file does not exist.
+func (m *Migrator) readCheckpoint() (int64, error) {
+	if m.Checkpoint == "" {
+		return 0, nil
+	}
+	data, err := ioutil.ReadFile(m.Checkpoint)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// writeCheckpoint records the last migrated step to the
+// checkpoint file. The checkpoint is not updated in dry run
+// mode, since no logs were migrated.
+func (m *Migrator) writeCheckpoint(step int64) error {
+	if m.Checkpoint == "" || m.DryRun {
+		return nil
+	}
+	tmp := filepath.Join(filepath.Dir(m.Checkpoint), "."+filepath.Base(m.Checkpoint))
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatInt(step, 10)), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.Checkpoint)
+}
@@ -0,0 +1,251 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/store/logs"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+)
+
+var noContext = context.TODO()
+
+func init() {
+	logrus.SetOutput(ioutil.Discard)
+}
+
+func TestMigrate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	source := logs.NewFile(filepath.Join(dir, "source"))
+	target := logs.NewFile(filepath.Join(dir, "target"))
+	source.Create(noContext, 1, bytes.NewBufferString("hello"))
+	source.Create(noContext, 3, bytes.NewBufferString("world"))
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(0), 2).Return([]*core.Step{{ID: 1}, {ID: 2}}, nil)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(2), 2).Return([]*core.Step{{ID: 3}}, nil)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(3), 2).Return(nil, nil)
+
+	migrator := New(steps, source, target)
+	migrator.BatchSize = 2
+	migrator.Checkpoint = filepath.Join(dir, "checkpoint")
+	res, err := migrator.Migrate(noContext)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if got, want := res.Copied, int64(2); got != want {
+		t.Errorf("Want %d copied, got %d", want, got)
+	}
+	if got, want := res.Skipped, int64(1); got != want {
+		t.Errorf("Want %d skipped, got %d", want, got)
+	}
+	if got, want := res.Deleted, int64(0); got != want {
+		t.Errorf("Want %d deleted, got %d", want, got)
+	}
+	if got, want := readLogs(t, target, 3), "world"; got != want {
+		t.Errorf("Want logs %q, got %q", want, got)
+	}
+	if got, want := readLogs(t, source, 3), "world"; got != want {
+		t.Errorf("Want source logs retained")
+	}
+	if data, _ := ioutil.ReadFile(migrator.Checkpoint); string(data) != "3" {
+		t.Errorf("Want checkpoint 3, got %q", data)
+	}
+}
+
+func TestMigrate_Resume(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	checkpoint := filepath.Join(dir, "checkpoint")
+	ioutil.WriteFile(checkpoint, []byte("42\n"), 0600)
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(42), 100).Return(nil, nil)
+
+	migrator := New(steps, nil, nil)
+	migrator.Checkpoint = checkpoint
+	res, err := migrator.Migrate(noContext)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := res.Last, int64(42); got != want {
+		t.Errorf("Want last step %d, got %d", want, got)
+	}
+}
+
+func TestMigrate_DryRun(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	source := logs.NewFile(filepath.Join(dir, "source"))
+	target := logs.NewFile(filepath.Join(dir, "target"))
+	source.Create(noContext, 1, bytes.NewBufferString("hello"))
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(0), 100).Return([]*core.Step{{ID: 1}}, nil)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(1), 100).Return(nil, nil)
+
+	migrator := New(steps, source, target)
+	migrator.DryRun = true
+	migrator.Delete = true
+	migrator.Checkpoint = filepath.Join(dir, "checkpoint")
+	res, err := migrator.Migrate(noContext)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := res.Copied, int64(1); got != want {
+		t.Errorf("Want %d copied, got %d", want, got)
+	}
+	if _, err := target.Find(noContext, 1); err == nil {
+		t.Errorf("Want logs not copied in dry run mode")
+	}
+	if _, err := source.Find(noContext, 1); err != nil {
+		t.Errorf("Want logs not deleted in dry run mode")
+	}
+	if _, err := os.Stat(migrator.Checkpoint); err == nil {
+		t.Errorf("Want checkpoint not written in dry run mode")
+	}
+}
+
+func TestMigrate_Delete(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	source := logs.NewFile(filepath.Join(dir, "source"))
+	target := logs.NewFile(filepath.Join(dir, "target"))
+	source.Create(noContext, 1, bytes.NewBufferString("hello"))
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(0), 100).Return([]*core.Step{{ID: 1}}, nil)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(1), 100).Return(nil, nil)
+
+	migrator := New(steps, source, target)
+	migrator.Delete = true
+	res, err := migrator.Migrate(noContext)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := res.Deleted, int64(1); got != want {
+		t.Errorf("Want %d deleted, got %d", want, got)
+	}
+	if _, err := source.Find(noContext, 1); err == nil {
+		t.Errorf("Want logs deleted from source")
+	}
+	if got, want := readLogs(t, target, 1), "hello"; got != want {
+		t.Errorf("Want logs %q, got %q", want, got)
+	}
+}
+
+func TestMigrate_ChecksumMismatch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	source := logs.NewFile(filepath.Join(dir, "source"))
+	source.Create(noContext, 1, bytes.NewBufferString("hello"))
+
+	target := mock.NewMockLogStore(controller)
+	target.EXPECT().Create(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	target.EXPECT().Find(gomock.Any(), int64(1)).Return(ioutil.NopCloser(bytes.NewBufferString("hell")), nil)
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(0), 100).Return([]*core.Step{{ID: 1}}, nil)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(1), 100).Return(nil, nil)
+
+	migrator := New(steps, source, target)
+	migrator.Delete = true
+	res, err := migrator.Migrate(noContext)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := res.Deleted, int64(0); got != want {
+		t.Errorf("Want %d deleted, got %d", want, got)
+	}
+	if _, err := source.Find(noContext, 1); err != nil {
+		t.Errorf("Want logs retained in source on checksum mismatch")
+	}
+}
+
+func TestMigrate_SourceError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	source := mock.NewMockLogStore(controller)
+	source.EXPECT().Find(gomock.Any(), int64(1)).Return(nil, errors.New("connection reset"))
+
+	target := mock.NewMockLogStore(controller)
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().ListAfter(gomock.Any(), int64(0), 100).Return([]*core.Step{{ID: 1}}, nil)
+
+	migrator := New(steps, source, target)
+	migrator.Checkpoint = filepath.Join(dir, "checkpoint")
+	res, err := migrator.Migrate(noContext)
+	if err == nil {
+		t.Errorf("Want error when the source store fails")
+	}
+	if got, want := res.Last, int64(0); got != want {
+		t.Errorf("Want last step %d, got %d", want, got)
+	}
+	if _, err := os.Stat(migrator.Checkpoint); !os.IsNotExist(err) {
+		t.Errorf("Want checkpoint not advanced")
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "drone-migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func readLogs(t *testing.T, store core.LogStore, step int64) string {
+	r, err := store.Find(noContext, step)
+	if err != nil {
+		t.Error(err)
+		return ""
+	}
+	defer r.Close()
+	data, _ := ioutil.ReadAll(r)
+	return string(data)
+}
@@ -13,18 +13,28 @@ import (
 	"github.com/drone/drone/metric"
 	"github.com/drone/drone/operator/manager"
 	"github.com/drone/drone/pubsub"
+	"github.com/drone/drone/service/approval"
 	"github.com/drone/drone/service/commit"
 	"github.com/drone/drone/service/hook/parser"
 	"github.com/drone/drone/service/license"
 	"github.com/drone/drone/service/org"
 	"github.com/drone/drone/service/repo"
+	"github.com/drone/drone/service/role"
 	"github.com/drone/drone/service/token"
 	"github.com/drone/drone/service/user"
+	"github.com/drone/drone/store/approvals"
+	"github.com/drone/drone/store/audit"
 	"github.com/drone/drone/store/batch"
+	"github.com/drone/drone/store/configs"
 	"github.com/drone/drone/store/cron"
+	"github.com/drone/drone/store/identities"
+	"github.com/drone/drone/store/logins"
 	"github.com/drone/drone/store/perm"
+	"github.com/drone/drone/store/roles"
 	"github.com/drone/drone/store/secret"
+	"github.com/drone/drone/store/sessions"
 	"github.com/drone/drone/store/step"
+	"github.com/drone/drone/store/tokens"
 	"github.com/drone/drone/trigger"
 	cron2 "github.com/drone/drone/trigger/cron"
 )
@@ -49,20 +59,29 @@ func InitializeApplication(config2 config.Config) (application, error) {
 	commitService := commit.New(client, renewer)
 	cronStore := cron.New(db)
 	repositoryStore := provideRepoStore(db)
-	fileService := provideContentService(client, renewer)
+	fileService := provideContentService(client, renewer, config2)
 	configService := provideConfigPlugin(client, fileService, config2)
+	convertService := provideConvertPlugin(fileService, config2)
+	policyService, err := providePolicyPlugin(config2)
+	if err != nil {
+		return application{}, err
+	}
 	statusService := provideStatusService(client, renewer, config2)
 	buildStore := provideBuildStore(db)
+	configStore := configs.New(db)
 	stageStore := provideStageStore(db)
 	scheduler := provideScheduler(stageStore, config2)
 	webhookSender := provideWebhookPlugin(config2)
-	triggerer := trigger.New(configService, commitService, statusService, buildStore, scheduler, repositoryStore, userStore, webhookSender)
+	triggerer := trigger.New(configService, convertService, policyService, commitService, statusService, buildStore, scheduler, repositoryStore, userStore, webhookSender)
 	cronScheduler := cron2.New(commitService, cronStore, repositoryStore, userStore, triggerer)
 	system := provideSystem(config2)
 	coreLicense := provideLicense(client, config2)
 	datadog := provideDatadog(userStore, repositoryStore, buildStore, system, coreLicense, config2)
 	corePubsub := pubsub.New()
-	logStore := provideLogStore(db, config2)
+	logStore, err := provideLogStore(db, config2)
+	if err != nil {
+		return application{}, err
+	}
 	logStream := livelog.New()
 	netrcService := provideNetrcService(client, renewer, config2)
 	encrypter, err := provideEncrypter(config2)
@@ -71,7 +90,8 @@ func InitializeApplication(config2 config.Config) (application, error) {
 	}
 	secretStore := secret.New(db, encrypter)
 	stepStore := step.New(db)
-	buildManager := manager.New(buildStore, configService, corePubsub, logStore, logStream, netrcService, repositoryStore, scheduler, secretStore, statusService, stageStore, stepStore, system, userStore, webhookSender)
+	logLimits := provideLogLimits(config2)
+	buildManager := manager.New(buildStore, configService, configStore, convertService, corePubsub, logLimits, logStore, logStream, netrcService, repositoryStore, scheduler, secretStore, statusService, stageStore, stepStore, system, userStore, webhookSender)
 	secretService := provideSecretPlugin(config2)
 	registryService := provideRegistryPlugin(config2)
 	runner := provideRunner(buildManager, secretService, registryService, config2)
@@ -79,21 +99,36 @@ func InitializeApplication(config2 config.Config) (application, error) {
 	licenseService := license.NewService(userStore, repositoryStore, buildStore, coreLicense)
 	permStore := perm.New(db)
 	repositoryService := repo.New(client, renewer)
-	session := provideSession(userStore, config2)
+	tokenStore := tokens.New(db)
+	roleStore := roles.New(db)
+	organizationService := orgs.New(client, renewer)
+	roleService := role.New(roleStore, organizationService)
+	sessionStore := sessions.New(db)
+	loginStore := logins.New(db)
+	session := provideSession(userStore, tokenStore, sessionStore, config2)
 	batcher := batch.New(db)
 	syncer := provideSyncer(repositoryService, repositoryStore, userStore, batcher, config2)
-	server := api.New(buildStore, cronStore, corePubsub, hookService, logStore, coreLicense, licenseService, permStore, repositoryStore, repositoryService, scheduler, secretStore, stageStore, stepStore, statusService, session, logStream, syncer, system, triggerer, userStore, webhookSender)
-	organizationService := orgs.New(client, renewer)
+	approvalStore := approvals.New(db)
+	approvalService := approval.New(approvalStore, roleService)
+	auditStore := audit.New(db)
+	auditService := provideAuditService(auditStore, webhookSender, config2)
+	server := api.New(approvalStore, approvalService, auditStore, auditService, buildStore, commitService, configService, configStore, convertService, cronStore, corePubsub, hookService, loginStore, logStore, coreLicense, licenseService, organizationService, permStore, policyService, repositoryStore, repositoryService, roleStore, roleService, scheduler, secretStore, stageStore, stepStore, statusService, session, sessionStore, logStream, syncer, system, tokenStore, triggerer, userStore, webhookSender)
 	userService := user.New(client)
 	admissionService := provideAdmissionPlugin(client, organizationService, userService, config2)
 	hookParser := parser.New(client)
 	middleware := provideLogin(config2)
+	provider := provideOIDC(config2)
 	options := provideServerOptions(config2)
-	webServer := web.New(admissionService, buildStore, client, hookParser, coreLicense, licenseService, middleware, repositoryStore, session, syncer, triggerer, userStore, userService, webhookSender, options, system)
-	handler := provideRPC(buildManager, config2)
+	identityStore := identities.New(db)
+	webServer := web.New(admissionService, buildStore, client, fileService, hookParser, identityStore, coreLicense, licenseService, middleware, provider, repositoryStore, session, loginStore, syncer, triggerer, userStore, userService, webhookSender, options, system)
+	handler, err := provideRPC(buildManager, stepStore, config2)
+	if err != nil {
+		return application{}, err
+	}
 	metricServer := metric.NewServer(session)
 	mux := provideRouter(server, webServer, handler, metricServer)
 	serverServer := provideServer(mux, config2)
-	mainApplication := newApplication(cronScheduler, datadog, runner, serverServer, userStore)
+	reconciler := provideDeprovisioner(userStore, userService, renewer, organizationService, repositoryStore, sessionStore, tokenStore, auditService, config2)
+	mainApplication := newApplication(cronScheduler, reconciler, datadog, runner, serverServer, userStore)
 	return mainApplication, nil
 }
@@ -0,0 +1,176 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrApprovalSelf is returned when the build author is not
+	// permitted to approve the build.
+	ErrApprovalSelf = errors.New("Cannot approve your own build")
+
+	// ErrApprovalGroup is returned when the user is not a member
+	// of a required approver group.
+	ErrApprovalGroup = errors.New("Not a member of an approver group")
+
+	// ErrApprovalExists is returned when the user has already
+	// approved or declined the stage.
+	ErrApprovalExists = errors.New("Stage already reviewed by user")
+
+	errApprovalRuleInvalid = errors.New("Invalid Approval Rule")
+)
+
+type (
+	// Approval represents a user approval, or decline, of a
+	// blocked pipeline stage.
+	Approval struct {
+		ID       int64  `json:"id"`
+		StageID  int64  `json:"stage_id"`
+		Approver string `json:"approver"`
+		Approved bool   `json:"approved"`
+		Comment  string `json:"comment,omitempty"`
+		Created  int64  `json:"created"`
+	}
+
+	// ApprovalRule defines the approvals required to unblock
+	// a pipeline stage. Rules are evaluated in order, and the
+	// first rule matching the repository and build applies.
+	ApprovalRule struct {
+		ID int64 `json:"id"`
+
+		// Repo is a glob pattern matching the repository slug.
+		// If empty, the rule matches all repositories.
+		Repo string `json:"repo,omitempty"`
+
+		// Target is a glob pattern matching the deployment
+		// target. If empty, the rule matches all builds.
+		Target string `json:"target,omitempty"`
+
+		// Protected restricts the rule to protected repositories.
+		Protected bool `json:"protected"`
+
+		// Approvals is the number of approvals required to
+		// unblock the stage.
+		Approvals int `json:"approvals"`
+
+		// AllowSelf permits the build author to approve the
+		// build.
+		AllowSelf bool `json:"allow_self"`
+
+		// Groups restricts the approvers to users granted one
+		// of the named roles.
+		Groups []string `json:"groups,omitempty"`
+
+		Created int64 `json:"created"`
+		Updated int64 `json:"updated"`
+	}
+
+	// ApprovalStore persists stage approvals and approval rules
+	// to storage.
+	ApprovalStore interface {
+		// List returns a list of approvals for the stage.
+		List(context.Context, int64) ([]*Approval, error)
+
+		// Create persists a new approval to the datastore.
+		Create(context.Context, *Approval) error
+
+		// ListRules returns the ordered list of approval rules
+		// from the datastore.
+		ListRules(context.Context) ([]*ApprovalRule, error)
+
+		// FindRule returns an approval rule from the datastore.
+		FindRule(context.Context, int64) (*ApprovalRule, error)
+
+		// CreateRule persists a new approval rule to the
+		// datastore.
+		CreateRule(context.Context, *ApprovalRule) error
+
+		// UpdateRule persists an updated approval rule to the
+		// datastore.
+		UpdateRule(context.Context, *ApprovalRule) error
+
+		// DeleteRule deletes an approval rule from the datastore.
+		DeleteRule(context.Context, *ApprovalRule) error
+	}
+
+	// ApprovalService resolves the approval requirements for
+	// blocked pipeline stages.
+	ApprovalService interface {
+		// Rule returns the approval rule that applies to the
+		// build.
+		Rule(context.Context, *Repository, *Build) (*ApprovalRule, error)
+
+		// Authorize returns an error if the rule does not
+		// permit the user to approve the build.
+		Authorize(context.Context, *User, *Repository, *Build, *ApprovalRule) error
+	}
+)
+
+// DefaultApprovalRule returns the approval rule that applies
+// when no rule matches the build. A single approval is required,
+// and the build author may approve the build.
+func DefaultApprovalRule() *ApprovalRule {
+	return &ApprovalRule{Approvals: 1, AllowSelf: true}
+}
+
+// Validate validates the required fields and formats.
+func (r *ApprovalRule) Validate() error {
+	if r.Approvals < 1 {
+		return errApprovalRuleInvalid
+	}
+	for _, pattern := range []string{r.Repo, r.Target} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errApprovalRuleInvalid
+		}
+	}
+	return nil
+}
+
+// Match returns true if the rule applies to the repository
+// and build.
+func (r *ApprovalRule) Match(repo *Repository, build *Build) bool {
+	if r.Protected && !repo.Protected {
+		return false
+	}
+	if r.Repo != "" {
+		if ok, _ := path.Match(r.Repo, repo.Slug); !ok {
+			return false
+		}
+	}
+	if r.Target != "" {
+		if ok, _ := path.Match(r.Target, build.Deploy); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Approved returns true if the approvals meet the number of
+// approvals required by the rule. Each approver is counted
+// once.
+func (r *ApprovalRule) Approved(approvals []*Approval) bool {
+	approvers := map[string]struct{}{}
+	for _, approval := range approvals {
+		if approval.Approved {
+			approvers[strings.ToLower(approval.Approver)] = struct{}{}
+		}
+	}
+	return len(approvers) >= r.Approvals
+}
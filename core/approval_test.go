@@ -0,0 +1,64 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package core
+
+import "testing"
+
+func TestApprovalRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule *ApprovalRule
+		err  error
+	}{
+		{rule: &ApprovalRule{Approvals: 1}, err: nil},
+		{rule: &ApprovalRule{Approvals: 2, Repo: "octocat/*", Target: "prod*"}, err: nil},
+		{rule: &ApprovalRule{Approvals: 0}, err: errApprovalRuleInvalid},
+		{rule: &ApprovalRule{Approvals: 1, Repo: "octocat/["}, err: errApprovalRuleInvalid},
+	}
+	for i, test := range tests {
+		if got, want := test.rule.Validate(), test.err; got != want {
+			t.Errorf("Want error %v at index %d, got %v", want, i, got)
+		}
+	}
+}
+
+func TestApprovalRuleMatch(t *testing.T) {
+	repo := &Repository{Slug: "octocat/hello-world", Protected: true}
+	tests := []struct {
+		rule  *ApprovalRule
+		repo  *Repository
+		build *Build
+		match bool
+	}{
+		{rule: &ApprovalRule{}, repo: repo, build: &Build{}, match: true},
+		{rule: &ApprovalRule{Repo: "octocat/*"}, repo: repo, build: &Build{}, match: true},
+		{rule: &ApprovalRule{Repo: "spaceghost/*"}, repo: repo, build: &Build{}, match: false},
+		{rule: &ApprovalRule{Target: "production"}, repo: repo, build: &Build{Deploy: "production"}, match: true},
+		{rule: &ApprovalRule{Target: "production"}, repo: repo, build: &Build{Deploy: "staging"}, match: false},
+		{rule: &ApprovalRule{Target: "production"}, repo: repo, build: &Build{}, match: false},
+		{rule: &ApprovalRule{Protected: true}, repo: repo, build: &Build{}, match: true},
+		{rule: &ApprovalRule{Protected: true}, repo: &Repository{}, build: &Build{}, match: false},
+	}
+	for i, test := range tests {
+		if got, want := test.rule.Match(test.repo, test.build), test.match; got != want {
+			t.Errorf("Want match %v at index %d, got %v", want, i, got)
+		}
+	}
+}
+
+func TestApprovalRuleApproved(t *testing.T) {
+	rule := &ApprovalRule{Approvals: 2}
+	approvals := []*Approval{
+		{Approver: "octocat", Approved: true},
+		{Approver: "Octocat", Approved: true},
+		{Approver: "spaceghost", Approved: false},
+	}
+	if rule.Approved(approvals) {
+		t.Errorf("Want approvals counted once per approver")
+	}
+	approvals = append(approvals, &Approval{Approver: "janecitizen", Approved: true})
+	if !rule.Approved(approvals) {
+		t.Errorf("Want rule approved")
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Audit actions.
+const (
+	AuditActionBuildCancel  = "build:cancel"
+	AuditActionBuildPromote = "build:promote"
+	AuditActionStageApprove = "stage:approve"
+	AuditActionStageDecline = "stage:decline"
+	AuditActionQueuePause   = "queue:pause"
+	AuditActionQueueResume  = "queue:resume"
+	AuditActionRepoUpdate   = "repo:update"
+	AuditActionRepoChown    = "repo:chown"
+	AuditActionRepoOrphan   = "repo:orphan"
+	AuditActionUserCreate   = "user:create"
+	AuditActionUserUpdate   = "user:update"
+	AuditActionUserDelete   = "user:delete"
+	AuditActionUserLogout   = "user:logout"
+	AuditActionUserDisable  = "user:disable"
+)
+
+type (
+	// AuditEvent records an administrative or build action
+	// performed by a user.
+	AuditEvent struct {
+		ID     int64  `json:"id"`
+		Actor  string `json:"actor"`
+		Action string `json:"action"`
+		Target string `json:"target"`
+
+		// Before and After contain the fields of the target
+		// resource changed by the action, encoded as json.
+		Before json.RawMessage `json:"before,omitempty"`
+		After  json.RawMessage `json:"after,omitempty"`
+
+		IP      string `json:"ip"`
+		Created int64  `json:"created"`
+	}
+
+	// AuditParams defines audit event query parameters.
+	AuditParams struct {
+		Actor  string
+		Action string
+		Target string
+
+		// Since and Until restrict the events to a time
+		// range, in seconds since the epoch. Zero values
+		// are ignored.
+		Since int64
+		Until int64
+
+		Page int
+		Size int
+	}
+
+	// AuditStore persists audit events to storage.
+	AuditStore interface {
+		// List returns a list of audit events from the
+		// datastore, ordered by most recent.
+		List(context.Context, AuditParams) ([]*AuditEvent, error)
+
+		// Create persists a new audit event to the datastore.
+		Create(context.Context, *AuditEvent) error
+	}
+
+	// AuditService records audit events.
+	AuditService interface {
+		// Record records the audit event.
+		Record(context.Context, *AuditEvent) error
+	}
+)
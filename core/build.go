@@ -80,6 +80,11 @@ type BuildStore interface {
 	// Create persists a build to the datastore.
 	Create(context.Context, *Build, []*Stage) error
 
+	// CreateWithConfig persists a build, and the resolved
+	// configuration file used to plan the build, to the
+	// datastore in a single transaction.
+	CreateWithConfig(context.Context, *Build, []*Stage, *Config) error
+
 	// Update updates a build in the datastore.
 	Update(context.Context, *Build) error
 
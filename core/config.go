@@ -37,4 +37,15 @@ type (
 	ConfigService interface {
 		Find(context.Context, *ConfigArgs) (*Config, error)
 	}
+
+	// ConfigStore persists the resolved pipeline configuration
+	// file of each build, so that the build executes the same
+	// configuration that was used to plan the build.
+	ConfigStore interface {
+		// Find returns the configuration file for the build.
+		Find(ctx context.Context, build int64) (*Config, error)
+
+		// Create persists the configuration file for the build.
+		Create(ctx context.Context, build int64, config *Config) error
+	}
 )
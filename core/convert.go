@@ -0,0 +1,36 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "context"
+
+type (
+	// ConvertArgs represents a request to the pipeline
+	// conversion service.
+	ConvertArgs struct {
+		User   *User       `json:"-"`
+		Repo   *Repository `json:"repo,omitempty"`
+		Build  *Build      `json:"build,omitempty"`
+		Config *Config     `json:"config,omitempty"`
+	}
+
+	// ConvertService converts the pipeline configuration
+	// file, for example from a non-native format such as
+	// jsonnet to yaml, or by rewriting the yaml to enforce
+	// organization-wide pipeline steps.
+	ConvertService interface {
+		Convert(context.Context, *ConvertArgs) (*Config, error)
+	}
+)
@@ -35,6 +35,10 @@ type (
 	// FileService provides access to contents of files in
 	// the remote source code management service (e.g. GitHub).
 	FileService interface {
+		// Find returns the file contents by path.
 		Find(ctx context.Context, user *User, repo, commit, ref, path string) (*File, error)
+
+		// List returns the paths of the files in the directory.
+		List(ctx context.Context, user *User, repo, commit, ref, dir string) ([]string, error)
 	}
 )
@@ -0,0 +1,40 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "context"
+
+type (
+	// Identity represents an account with an external identity
+	// provider that is linked to a user. The identity is keyed
+	// by the issuer and subject, which are stable for the life
+	// of the account, unlike the username.
+	Identity struct {
+		UserID  int64  `json:"user_id"`
+		Issuer  string `json:"issuer"`
+		Subject string `json:"subject"`
+		Created int64  `json:"created"`
+	}
+
+	// IdentityStore persists external identities.
+	IdentityStore interface {
+		// Find returns the identity by issuer and subject.
+		Find(ctx context.Context, issuer, subject string) (*Identity, error)
+
+		// Create persists a new identity. A user may be linked
+		// to a single identity per issuer.
+		Create(ctx context.Context, identity *Identity) error
+	}
+)
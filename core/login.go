@@ -0,0 +1,50 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "context"
+
+type (
+	// LoginEvent records a user login attempt.
+	LoginEvent struct {
+		ID     int64  `json:"id"`
+		UserID int64  `json:"user_id,omitempty"`
+		Login  string `json:"login"`
+
+		// Provider identifies the login flow used to
+		// authenticate the user, for example scm or oidc.
+		Provider string `json:"provider"`
+
+		// Success is true if the login attempt succeeded.
+		// If false, Reason describes why the attempt failed,
+		// including admission denials.
+		Success bool   `json:"success"`
+		Reason  string `json:"reason,omitempty"`
+
+		IP      string `json:"ip"`
+		Agent   string `json:"user_agent"`
+		Created int64  `json:"created"`
+	}
+
+	// LoginStore persists login events to storage.
+	LoginStore interface {
+		// List returns a list of login events for the login
+		// from the datastore, ordered by most recent.
+		List(ctx context.Context, login string, limit int) ([]*LoginEvent, error)
+
+		// Create persists a new login event to the datastore.
+		Create(context.Context, *LoginEvent) error
+	}
+)
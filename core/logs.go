@@ -26,6 +26,34 @@ type Line struct {
 	Timestamp int64  `json:"time"`
 }
 
+// LogLimits defines the maximum log size, in bytes, for a
+// single step and for all steps in a stage. A zero value
+// indicates no limit.
+type LogLimits struct {
+	Step  int64
+	Stage int64
+}
+
+// Limit returns the effective log limit for a step, given the
+// number of bytes already consumed by other steps in the same
+// stage. A zero value indicates no limit.
+func (l LogLimits) Limit(used int64) int64 {
+	limit := l.Step
+	if l.Stage > 0 {
+		// if the stage budget is exhausted the step is
+		// limited to a single byte, which results in the
+		// truncation marker being the only line stored.
+		remain := l.Stage - used
+		if remain < 1 {
+			remain = 1
+		}
+		if limit == 0 || remain < limit {
+			limit = remain
+		}
+	}
+	return limit
+}
+
 // LogStore persists build output to storage.
 type LogStore interface {
 	// Find returns a log stream from the datastore.
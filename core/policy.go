@@ -0,0 +1,48 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "context"
+
+// Policy enforcement actions.
+const (
+	PolicyError = "error"
+	PolicyBlock = "block"
+)
+
+type (
+	// PolicyArgs represents a request to evaluate the
+	// organization policies against a pipeline configuration.
+	PolicyArgs struct {
+		Repo   *Repository
+		Build  *Build
+		Config *Config
+	}
+
+	// PolicyViolation represents a pipeline configuration
+	// that violates an organization policy.
+	PolicyViolation struct {
+		Policy  string
+		Message string
+		Action  string
+	}
+
+	// PolicyService evaluates the pipeline configuration
+	// against the organization policies, and returns the
+	// policy violations.
+	PolicyService interface {
+		Evaluate(context.Context, *PolicyArgs) ([]*PolicyViolation, error)
+	}
+)
@@ -0,0 +1,181 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/gosimple/slug"
+)
+
+// Role permissions. Permissions are colon-separated and may
+// be narrowed with a suffix, for example deploy:approve:prod
+// grants approval of deployments to the prod environment. A
+// role permission grants all narrower permissions, and may
+// include glob patterns.
+const (
+	PermissionRepoRead             = "repo:read"
+	PermissionRepoWrite            = "repo:write"
+	PermissionRepoAdmin            = "repo:admin"
+	PermissionSecretManage         = "secret:manage"
+	PermissionQueuePause           = "queue:pause"
+	PermissionDeployApprove        = "deploy:approve"
+	PermissionServiceAccountManage = "serviceaccount:manage"
+)
+
+var (
+	errRoleNameInvalid       = errors.New("Invalid Role Name")
+	errRolePermissionInvalid = errors.New("Invalid Role Permission")
+	errBindingSubjectInvalid = errors.New("Invalid Role Binding Subject")
+)
+
+type (
+	// Role represents a named set of permissions that can be
+	// granted to users and organizations.
+	Role struct {
+		ID          int64    `json:"id"`
+		Name        string   `json:"name"`
+		Description string   `json:"description,omitempty"`
+		Permissions []string `json:"permissions"`
+		Created     int64    `json:"created"`
+		Updated     int64    `json:"updated"`
+	}
+
+	// RoleBinding grants a role to a user or organization,
+	// optionally restricted to the repositories in a namespace.
+	RoleBinding struct {
+		ID     int64 `json:"id"`
+		RoleID int64 `json:"role_id"`
+
+		// User is the login of the user granted the role.
+		User string `json:"user,omitempty"`
+
+		// Org is the name of the source control organization
+		// whose members are granted the role.
+		Org string `json:"org,omitempty"`
+
+		// Namespace restricts the role to repositories in the
+		// namespace. If empty, the role is granted globally.
+		Namespace string `json:"namespace,omitempty"`
+
+		Created int64 `json:"created"`
+
+		// Role is the bound role. It is only populated when
+		// bindings are listed with their roles.
+		Role *Role `json:"role,omitempty"`
+	}
+
+	// RoleStore persists roles and role bindings to storage.
+	RoleStore interface {
+		// List returns a list of roles from the datastore.
+		List(context.Context) ([]*Role, error)
+
+		// Find returns a role from the datastore.
+		Find(context.Context, int64) (*Role, error)
+
+		// FindName returns a role from the datastore by name.
+		FindName(context.Context, string) (*Role, error)
+
+		// Create persists a new role to the datastore.
+		Create(context.Context, *Role) error
+
+		// Update persists an updated role to the datastore.
+		Update(context.Context, *Role) error
+
+		// Delete deletes a role, and its bindings, from the
+		// datastore.
+		Delete(context.Context, *Role) error
+
+		// ListBindings returns a list of all role bindings
+		// from the datastore.
+		ListBindings(context.Context) ([]*RoleBinding, error)
+
+		// ListBindingRoles returns a list of all role bindings,
+		// including the bound role, from the datastore.
+		ListBindingRoles(context.Context) ([]*RoleBinding, error)
+
+		// FindBinding returns a role binding from the datastore.
+		FindBinding(context.Context, int64) (*RoleBinding, error)
+
+		// CreateBinding persists a new role binding to the
+		// datastore.
+		CreateBinding(context.Context, *RoleBinding) error
+
+		// DeleteBinding deletes a role binding from the
+		// datastore.
+		DeleteBinding(context.Context, *RoleBinding) error
+	}
+
+	// RoleService resolves the permissions granted to a user
+	// through role bindings.
+	RoleService interface {
+		// List returns the roles granted to the user in the
+		// namespace, including globally granted roles.
+		List(ctx context.Context, user *User, namespace string) ([]*Role, error)
+
+		// Permits returns true if the user is granted the
+		// permission in the namespace.
+		Permits(ctx context.Context, user *User, namespace, permission string) (bool, error)
+	}
+)
+
+// Validate validates the required fields and formats.
+func (r *Role) Validate() error {
+	switch {
+	case r.Name == "":
+		return errRoleNameInvalid
+	case r.Name != slug.Make(r.Name):
+		return errRoleNameInvalid
+	case len(r.Permissions) == 0:
+		return errRolePermissionInvalid
+	}
+	for _, perm := range r.Permissions {
+		if perm == "" {
+			return errRolePermissionInvalid
+		}
+		if _, err := path.Match(perm, ""); err != nil {
+			return errRolePermissionInvalid
+		}
+	}
+	return nil
+}
+
+// Permits returns true if the role grants the permission.
+func (r *Role) Permits(permission string) bool {
+	for _, perm := range r.Permissions {
+		if perm == permission || strings.HasPrefix(permission, perm+":") {
+			return true
+		}
+		if ok, _ := path.Match(perm, permission); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the required fields and formats.
+func (b *RoleBinding) Validate() error {
+	switch {
+	case b.User == "" && b.Org == "":
+		return errBindingSubjectInvalid
+	case b.User != "" && b.Org != "":
+		return errBindingSubjectInvalid
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package core
+
+import "testing"
+
+func TestRoleValidate(t *testing.T) {
+	tests := []struct {
+		role *Role
+		err  error
+	}{
+		{
+			role: &Role{Name: "release-managers", Permissions: []string{"deploy:approve:prod"}},
+			err:  nil,
+		},
+		{
+			role: &Role{Name: "Release Managers", Permissions: []string{"deploy:approve"}},
+			err:  errRoleNameInvalid,
+		},
+		{
+			role: &Role{Name: "release-managers"},
+			err:  errRolePermissionInvalid,
+		},
+		{
+			role: &Role{Name: "release-managers", Permissions: []string{"deploy:[prod"}},
+			err:  errRolePermissionInvalid,
+		},
+	}
+	for i, test := range tests {
+		if got, want := test.role.Validate(), test.err; got != want {
+			t.Errorf("Want error %v, got %v at index %d", want, got, i)
+		}
+	}
+}
+
+func TestRolePermits(t *testing.T) {
+	role := &Role{Permissions: []string{"deploy:approve:prod", "secret:*", "repo:read"}}
+	tests := []struct {
+		permission string
+		permits    bool
+	}{
+		{"deploy:approve:prod", true},
+		{"deploy:approve:production", false},
+		{"deploy:approve", false},
+		{"secret:manage", true},
+		{"repo:read", true},
+		{"repo:write", false},
+		{"queue:pause", false},
+	}
+	for _, test := range tests {
+		if got := role.Permits(test.permission); got != test.permits {
+			t.Errorf("Want permits %v for %s", test.permits, test.permission)
+		}
+	}
+
+	// a permission grants all narrower permissions.
+	role = &Role{Permissions: []string{"deploy:approve"}}
+	if !role.Permits("deploy:approve:prod") {
+		t.Errorf("Want deploy:approve to grant deploy:approve:prod")
+	}
+}
+
+func TestRoleBindingValidate(t *testing.T) {
+	if err := (&RoleBinding{User: "octocat"}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := (&RoleBinding{Org: "github", Namespace: "github"}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := (&RoleBinding{}).Validate(); err != errBindingSubjectInvalid {
+		t.Errorf("Want error without a subject")
+	}
+	if err := (&RoleBinding{User: "octocat", Org: "github"}).Validate(); err != errBindingSubjectInvalid {
+		t.Errorf("Want error with multiple subjects")
+	}
+}
@@ -14,20 +14,87 @@
 
 package core
 
-import "net/http"
-
-// Session provides session management for
-// authenticated users.
-type Session interface {
-	// Create creates a new user session and writes the
-	// session to the http.Response.
-	Create(http.ResponseWriter, *User) error
-
-	// Delete deletes the user session from the http.Response.
-	Delete(http.ResponseWriter) error
-
-	// Get returns the session from the http.Request. If no
-	// session exists a nil user is returned. Returning an
-	// error is optional, for debugging purposes only.
-	Get(*http.Request) (*User, error)
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type (
+	// Session provides session management for
+	// authenticated users.
+	Session interface {
+		// Create creates a new user session and writes the
+		// session to the http.Response.
+		Create(http.ResponseWriter, *http.Request, *User) error
+
+		// Delete deletes the user session from the http.Response
+		// and revokes the session.
+		Delete(http.ResponseWriter, *http.Request) error
+
+		// Revoke evicts the cached sessions of the user, so
+		// that sessions deleted from the datastore are rejected
+		// immediately. Sessions cached by other server instances
+		// are rejected when the cache entry expires.
+		Revoke(user int64)
+
+		// Upgrade replaces a session cookie created before
+		// server-side sessions were enabled with a revocable
+		// session for the authenticated user. It is a no-op if
+		// the request does not include a legacy session cookie.
+		Upgrade(http.ResponseWriter, *http.Request, *User) error
+
+		// Get returns the session from the http.Request. If no
+		// session exists a nil user is returned. Returning an
+		// error is optional, for debugging purposes only.
+		Get(*http.Request) (*User, error)
+	}
+
+	// UserSession represents a server-side record of a user
+	// session. The session key is included in the session
+	// cookie, and is persisted as a hash.
+	UserSession struct {
+		ID       int64  `json:"id"`
+		UserID   int64  `json:"user_id"`
+		Hash     string `json:"-"`
+		Device   string `json:"device"`
+		IP       string `json:"ip"`
+		Expires  int64  `json:"expires"`
+		LastSeen int64  `json:"last_seen"`
+		Created  int64  `json:"created"`
+	}
+
+	// SessionStore persists user sessions to storage.
+	SessionStore interface {
+		// List returns a list of sessions for the user.
+		List(context.Context, int64) ([]*UserSession, error)
+
+		// Find returns a session from the datastore.
+		Find(context.Context, int64) (*UserSession, error)
+
+		// FindHash returns a session from the datastore by hash.
+		FindHash(context.Context, string) (*UserSession, error)
+
+		// Create persists a new session to the datastore.
+		Create(context.Context, *UserSession) error
+
+		// Update persists an updated session to the datastore.
+		Update(context.Context, *UserSession) error
+
+		// Delete deletes a session from the datastore.
+		Delete(context.Context, *UserSession) error
+
+		// DeleteUser deletes all sessions for the user from
+		// the datastore.
+		DeleteUser(context.Context, int64) error
+
+		// DeleteExpired deletes all sessions that expired
+		// before the given time from the datastore.
+		DeleteExpired(context.Context, int64) error
+	}
+)
+
+// IsExpired returns true if the session is expired.
+func (s *UserSession) IsExpired() bool {
+	return s.Expires < time.Now().Unix()
 }
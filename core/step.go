@@ -19,17 +19,18 @@ import "context"
 type (
 	// Step represents an individual step in the stage.
 	Step struct {
-		ID        int64  `json:"id"`
-		StageID   int64  `json:"step_id"`
-		Number    int    `json:"number"`
-		Name      string `json:"name"`
-		Status    string `json:"status"`
-		Error     string `json:"error,omitempty"`
-		ErrIgnore bool   `json:"errignore,omitempty"`
-		ExitCode  int    `json:"exit_code"`
-		Started   int64  `json:"started,omitempty"`
-		Stopped   int64  `json:"stopped,omitempty"`
-		Version   int64  `json:"version"`
+		ID           int64  `json:"id"`
+		StageID      int64  `json:"step_id"`
+		Number       int    `json:"number"`
+		Name         string `json:"name"`
+		Status       string `json:"status"`
+		Error        string `json:"error,omitempty"`
+		ErrIgnore    bool   `json:"errignore,omitempty"`
+		ExitCode     int    `json:"exit_code"`
+		Started      int64  `json:"started,omitempty"`
+		Stopped      int64  `json:"stopped,omitempty"`
+		Version      int64  `json:"version"`
+		LogTruncated bool   `json:"log_truncated,omitempty"`
 	}
 
 	// StepStore persists build step information to storage.
@@ -43,6 +44,11 @@ type (
 		// FindNumber returns a stage from the datastore by number.
 		FindNumber(context.Context, int64, int) (*Step, error)
 
+		// ListAfter returns a list of steps from the datastore
+		// with an identifier greater than the given identifier,
+		// ordered by identifier.
+		ListAfter(context.Context, int64, int) ([]*Step, error)
+
 		// Create persists a new stage to the datastore.
 		Create(context.Context, *Step) error
 
@@ -0,0 +1,104 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+)
+
+// Token scopes.
+const (
+	TokenScopeRead  = "read"
+	TokenScopeWrite = "write"
+)
+
+var (
+	errTokenNameInvalid  = errors.New("Invalid Token Name")
+	errTokenScopeInvalid = errors.New("Invalid Token Scope")
+	errTokenRepoInvalid  = errors.New("Invalid Token Repository")
+)
+
+type (
+	// Token represents a personal access token. The token
+	// value is only returned when the token is created, and
+	// is persisted as a hash.
+	Token struct {
+		ID       int64  `json:"id"`
+		UserID   int64  `json:"user_id"`
+		Name     string `json:"name"`
+		Hash     string `json:"-"`
+		Scope    string `json:"scope"`
+		Repo     string `json:"repo,omitempty"`
+		Expires  int64  `json:"expires,omitempty"`
+		LastUsed int64  `json:"last_used,omitempty"`
+		Created  int64  `json:"created"`
+	}
+
+	// TokenStore persists personal access tokens to storage.
+	TokenStore interface {
+		// List returns a list of tokens for the user.
+		List(context.Context, int64) ([]*Token, error)
+
+		// Find returns a token from the datastore.
+		Find(context.Context, int64) (*Token, error)
+
+		// FindHash returns a token from the datastore by hash.
+		FindHash(context.Context, string) (*Token, error)
+
+		// Create persists a new token to the datastore.
+		Create(context.Context, *Token) error
+
+		// Update persists an updated token to the datastore.
+		Update(context.Context, *Token) error
+
+		// Delete deletes a token from the datastore.
+		Delete(context.Context, *Token) error
+	}
+)
+
+// Validate validates the required fields and formats.
+func (t *Token) Validate() error {
+	switch {
+	case t.Name == "":
+		return errTokenNameInvalid
+	case t.Name != slug.Make(t.Name):
+		return errTokenNameInvalid
+	case t.Scope != TokenScopeRead && t.Scope != TokenScopeWrite:
+		return errTokenScopeInvalid
+	case t.Repo != "" && strings.Count(t.Repo, "/") != 1:
+		return errTokenRepoInvalid
+	default:
+		return nil
+	}
+}
+
+// IsExpired returns true if the token is expired.
+func (t *Token) IsExpired() bool {
+	return t.Expires != 0 && t.Expires < time.Now().Unix()
+}
+
+// HashToken returns the hash of the token value, used to
+// persist and lookup the token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
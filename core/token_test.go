@@ -0,0 +1,78 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenValidate(t *testing.T) {
+	tests := []struct {
+		token *Token
+		error error
+	}{
+		{
+			token: &Token{Name: "deploy-script", Scope: TokenScopeWrite},
+			error: nil,
+		},
+		{
+			token: &Token{Name: "dashboard", Scope: TokenScopeRead, Repo: "octocat/hello-world"},
+			error: nil,
+		},
+		{
+			token: &Token{Name: "", Scope: TokenScopeRead},
+			error: errTokenNameInvalid,
+		},
+		{
+			token: &Token{Name: "Deploy Script", Scope: TokenScopeRead},
+			error: errTokenNameInvalid,
+		},
+		{
+			token: &Token{Name: "dashboard", Scope: "admin"},
+			error: errTokenScopeInvalid,
+		},
+		{
+			token: &Token{Name: "dashboard", Scope: TokenScopeRead, Repo: "hello-world"},
+			error: errTokenRepoInvalid,
+		},
+	}
+	for i, test := range tests {
+		got, want := test.token.Validate(), test.error
+		if got != want {
+			t.Errorf("Want error %v, got %v at index %d", want, got, i)
+		}
+	}
+}
+
+func TestTokenExpired(t *testing.T) {
+	tests := []struct {
+		expires int64
+		expired bool
+	}{
+		{0, false},
+		{time.Now().Add(time.Hour).Unix(), false},
+		{time.Now().Add(-time.Hour).Unix(), true},
+	}
+	for i, test := range tests {
+		token := &Token{Expires: test.expires}
+		if got, want := token.IsExpired(), test.expired; got != want {
+			t.Errorf("Want expired %v, got %v at index %d", want, got, i)
+		}
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	a, b := HashToken("correct-horse-battery-staple"), HashToken("correct-horse-battery-staple")
+	if a != b {
+		t.Errorf("Want deterministic token hash")
+	}
+	if a == "correct-horse-battery-staple" {
+		t.Errorf("Want token hashed")
+	}
+	if len(a) != 64 {
+		t.Errorf("Want sha256 hex digest, got %d characters", len(a))
+	}
+}
@@ -45,6 +45,7 @@ type (
 		Refresh   string `json:"-"`
 		Expiry    int64  `json:"-"`
 		Hash      string `json:"-"`
+		Namespace string `json:"namespace,omitempty"`
 	}
 
 	// UserStore defines operations for working with users.
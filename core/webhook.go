@@ -23,6 +23,7 @@ const (
 	WebhookEventBuild = "build"
 	WebhookEventRepo  = "repo"
 	WebhookEventUser  = "user"
+	WebhookEventAudit = "audit"
 )
 
 // Webhook action types.
@@ -49,6 +50,7 @@ type (
 		User   *User       `json:"user,omitempty"`
 		Repo   *Repository `json:"repo,omitempty"`
 		Build  *Build      `json:"build,omitempty"`
+		Audit  *AuditEvent `json:"audit,omitempty"`
 	}
 
 	// WebhookSender sends the webhook payload.
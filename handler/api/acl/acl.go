@@ -17,10 +17,13 @@ package acl
 import (
 	"net/http"
 
+	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api/errors"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/handler/api/request"
 	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
 )
 
 // AuthorizeUser returns an http.Handler middleware that authorizes only
@@ -57,3 +60,41 @@ func AuthorizeAdmin(next http.Handler) http.Handler {
 		}
 	})
 }
+
+// AuthorizeOrgAdmin returns an http.Handler middleware that authorizes
+// system administrators, and administrators of the organization in the
+// request path, to proceed to the next handler in the chain.
+func AuthorizeOrgAdmin(orgs core.OrganizationService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := request.UserFrom(r.Context())
+			if !ok {
+				render.Unauthorized(w, errors.ErrUnauthorized)
+				logger.FromRequest(r).
+					Debugln("api: authentication required")
+				return
+			}
+			if user.Admin {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			namespace := chi.URLParam(r, "namespace")
+			log := logger.FromRequest(r).WithField("namespace", namespace)
+
+			_, admin, err := orgs.Membership(r.Context(), user, namespace)
+			if err != nil {
+				log.WithError(err).
+					Warnln("api: cannot find organization membership")
+			}
+			if !admin {
+				render.Forbidden(w, errors.ErrForbidden)
+				log.Debugln("api: organization administrative access required")
+				return
+			}
+
+			log.Debugln("api: access granted to organization administrator")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
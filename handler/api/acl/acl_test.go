@@ -5,6 +5,7 @@
 package acl
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -12,7 +13,10 @@ import (
 
 	"github.com/drone/drone/handler/api/request"
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
 
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
 )
 
@@ -127,3 +131,82 @@ func TestAuthorizeAdminForbidden(t *testing.T) {
 		t.Errorf("Want status code %d, got %d", want, got)
 	}
 }
+
+func TestAuthorizeOrgAdmin(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	orgs := mock.NewMockOrganizationService(controller)
+	orgs.EXPECT().Membership(gomock.Any(), mockUser, "github").Return(true, true, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "github")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	AuthorizeOrgAdmin(orgs)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	).ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusTeapot; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+}
+
+func TestAuthorizeOrgAdmin_Member(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	orgs := mock.NewMockOrganizationService(controller)
+	orgs.EXPECT().Membership(gomock.Any(), mockUser, "github").Return(true, false, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "github")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	AuthorizeOrgAdmin(orgs)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("Must not invoke next handler in middleware chain")
+		}),
+	).ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusForbidden; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that system administrators are authorized
+// without requesting the organization membership.
+func TestAuthorizeOrgAdmin_SystemAdmin(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	orgs := mock.NewMockOrganizationService(controller)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), &core.User{ID: 1, Login: "octocat", Admin: true}),
+	)
+
+	AuthorizeOrgAdmin(orgs)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	).ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusTeapot; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+}
@@ -15,7 +15,9 @@
 package acl
 
 import (
+	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/drone/drone/core"
@@ -29,11 +31,14 @@ import (
 )
 
 // InjectRepository returns an http.Handler middleware that injects
-// the repository and repository permissions into the context.
+// the repository and repository permissions into the context. The
+// permissions synchronized with the remote system are merged with
+// the permissions granted through role bindings.
 func InjectRepository(
 	repoz core.RepositoryService,
 	repos core.RepositoryStore,
 	perms core.PermStore,
+	roles core.RoleService,
 ) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -79,17 +84,39 @@ func InjectRepository(
 				return
 			}
 
+			// service accounts are owned by an organization, and
+			// are granted write access to the organization
+			// repositories only. The permissions are not synced
+			// with the remote system, and are not extended by
+			// role bindings.
+			if user.Namespace != "" {
+				if strings.EqualFold(user.Namespace, repo.Namespace) {
+					ctx = request.WithPerm(ctx, &core.Perm{
+						UserID:  user.ID,
+						RepoUID: repo.UID,
+						Read:    true,
+						Write:   true,
+					})
+				}
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			// else get the cached permissions from the database
 			// for the user and repository.
 			perm, err := perms.Find(ctx, repo.UID, user.ID)
 			if err != nil {
 				// if the permissions are not found we forward
 				// the request to the next handler in the chain
-				// with no permissions in the context.
+				// with no permissions in the context, unless
+				// permissions are granted through a role.
 				//
 				// It is the responsibility to downstream
 				// middleware and handlers to decide if the
 				// request should be rejected.
+				if perm := grantRoles(ctx, roles, user, repo, nil); perm != nil {
+					ctx = request.WithPerm(ctx, perm)
+				}
 				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
@@ -129,8 +156,51 @@ func InjectRepository(
 				}
 			}
 
+			if granted := grantRoles(ctx, roles, user, repo, perm); granted != nil {
+				perm = granted
+			}
+
 			ctx = request.WithPerm(ctx, perm)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// helper function returns the repository permissions granted
+// to the user through role bindings, merged with the source
+// control permissions. It returns nil if the roles grant no
+// additional permissions. The merged permissions are never
+// cached, since role bindings take effect immediately.
+func grantRoles(ctx context.Context, roles core.RoleService, user *core.User, repo *core.Repository, perm *core.Perm) *core.Perm {
+	if roles == nil {
+		return nil
+	}
+	list, err := roles.List(ctx, user, repo.Namespace)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).
+			Warnln("api: cannot list user roles")
+		return nil
+	}
+	out := &core.Perm{UserID: user.ID, RepoUID: repo.UID}
+	if perm != nil {
+		*out = *perm
+	}
+	granted := false
+	for _, role := range list {
+		if !out.Read && role.Permits(core.PermissionRepoRead) {
+			out.Read, granted = true, true
+		}
+		if !out.Write && role.Permits(core.PermissionRepoWrite) {
+			out.Write, granted = true, true
+		}
+		if !out.Admin && role.Permits(core.PermissionRepoAdmin) {
+			out.Admin, granted = true, true
+		}
+	}
+	if !granted {
+		return nil
+	}
+	// write and admin permissions imply read permissions.
+	out.Read = true
+	return out
+}
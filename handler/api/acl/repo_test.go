@@ -44,7 +44,7 @@ func TestInjectRepository_RepoNotFound_Guest(t *testing.T) {
 		t.Fail()
 	})
 
-	InjectRepository(nil, repos, nil)(next).ServeHTTP(w, r)
+	InjectRepository(nil, repos, nil, nil)(next).ServeHTTP(w, r)
 	if got, want := w.Code, http.StatusUnauthorized; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -76,7 +76,7 @@ func TestInjectRepository_RepoNotFound_User(t *testing.T) {
 		t.Fail()
 	})
 
-	InjectRepository(nil, repos, nil)(next).ServeHTTP(w, r)
+	InjectRepository(nil, repos, nil, nil)(next).ServeHTTP(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -109,7 +109,7 @@ func TestInjectRepository_RepoFound_Guest(t *testing.T) {
 		invoked = true
 	})
 
-	InjectRepository(nil, repos, nil)(next).ServeHTTP(w, r)
+	InjectRepository(nil, repos, nil, nil)(next).ServeHTTP(w, r)
 	if !invoked {
 		t.Errorf("Expect middleware invoked")
 	}
@@ -153,7 +153,7 @@ func TestInjectRepository_PermsFound(t *testing.T) {
 		}
 	})
 
-	InjectRepository(nil, repos, perms)(next).ServeHTTP(w, r)
+	InjectRepository(nil, repos, perms, nil)(next).ServeHTTP(w, r)
 	if !invoked {
 		t.Errorf("Expect middleware invoked")
 	}
@@ -198,8 +198,85 @@ func TestInjectRepository_PermsNotFound(t *testing.T) {
 		}
 	})
 
-	InjectRepository(nil, repos, perms)(next).ServeHTTP(w, r)
+	InjectRepository(nil, repos, perms, nil)(next).ServeHTTP(w, r)
 	if !invoked {
 		t.Errorf("Expect middleware invoked")
 	}
 }
+
+// this unit test ensures that a service account is granted
+// write access to repositories in its namespace, without
+// looking up the synchronized permissions.
+func TestInjectRepository_ServiceAccount(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Machine: true, Namespace: "octocat"}
+	mockRepo := &core.Repository{UID: "1", Namespace: "octocat"}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockRepo, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(
+			request.WithUser(r.Context(), mockUser),
+			chi.RouteCtxKey, c),
+	)
+
+	invoked := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		invoked = true
+		perm, ok := request.PermFrom(r.Context())
+		if !ok {
+			t.Errorf("Expect perm from context")
+			return
+		}
+		if !perm.Read || !perm.Write || perm.Admin {
+			t.Errorf("Expect read and write permissions only")
+		}
+	})
+
+	InjectRepository(nil, repos, nil, nil)(next).ServeHTTP(w, r)
+	if !invoked {
+		t.Errorf("Expect middleware invoked")
+	}
+}
+
+// this unit test ensures that a service account is granted
+// no permissions to repositories outside its namespace.
+func TestInjectRepository_ServiceAccount_OtherNamespace(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Machine: true, Namespace: "spaceghost"}
+	mockRepo := &core.Repository{UID: "1", Namespace: "octocat"}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockRepo, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(
+			request.WithUser(r.Context(), mockUser),
+			chi.RouteCtxKey, c),
+	)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := request.PermFrom(r.Context()); ok {
+			t.Errorf("Expect nil perm from context")
+		}
+	})
+
+	InjectRepository(nil, repos, nil, nil)(next).ServeHTTP(w, r)
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// CheckPermission returns an http.Handler middleware that authorizes
+// users granted the permission through a role binding. If a repository
+// exists in the context, or the request path includes a namespace, the
+// role binding may be restricted to the namespace. Requests that are
+// not authorized by a role are forwarded to the fallback middleware.
+func CheckPermission(
+	roles core.RoleService,
+	permission string,
+	fallback func(http.Handler) http.Handler,
+) func(http.Handler) http.Handler {
+	return checkPermission(roles, func(*http.Request) string {
+		return permission
+	}, fallback)
+}
+
+// CheckApprovePermission returns an http.Handler middleware that
+// authorizes users granted permission to approve deployments to the
+// build target environment through a role binding. Requests that are
+// not authorized by a role are forwarded to the fallback middleware.
+func CheckApprovePermission(
+	roles core.RoleService,
+	builds core.BuildStore,
+	fallback func(http.Handler) http.Handler,
+) func(http.Handler) http.Handler {
+	return checkPermission(roles, func(r *http.Request) string {
+		repo, ok := request.RepoFrom(r.Context())
+		if !ok {
+			return ""
+		}
+		number, err := strconv.ParseInt(chi.URLParam(r, "number"), 10, 64)
+		if err != nil {
+			return ""
+		}
+		build, err := builds.FindNumber(r.Context(), repo.ID, number)
+		if err != nil || build.Deploy == "" {
+			return ""
+		}
+		return core.PermissionDeployApprove + ":" + build.Deploy
+	}, fallback)
+}
+
+func checkPermission(
+	roles core.RoleService,
+	permission func(*http.Request) string,
+	fallback func(http.Handler) http.Handler,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		deny := fallback(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			user, ok := request.UserFrom(ctx)
+			if !ok || roles == nil {
+				deny.ServeHTTP(w, r)
+				return
+			}
+
+			var namespace string
+			if repo, ok := request.RepoFrom(ctx); ok {
+				namespace = repo.Namespace
+			} else if rctx, ok := ctx.Value(chi.RouteCtxKey).(*chi.Context); ok {
+				namespace = rctx.URLParam("namespace")
+			}
+
+			perm := permission(r)
+			if perm == "" {
+				deny.ServeHTTP(w, r)
+				return
+			}
+
+			log := logger.FromRequest(r).
+				WithField("permission", perm).
+				WithField("namespace", namespace)
+
+			permits, err := roles.Permits(ctx, user, namespace, perm)
+			if err != nil {
+				log.WithError(err).Warnln("api: cannot check role permissions")
+			}
+			if !permits {
+				deny.ServeHTTP(w, r)
+				return
+			}
+
+			log.Debugln("api: access granted by role")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package acl
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+// this unit test ensures that the repository permissions
+// granted through a role binding are injected into the
+// context when the user has no source control permissions.
+func TestInjectRepository_RolePerms(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Login: "octocat"}
+	mockRepo := &core.Repository{UID: "1", Namespace: "octocat"}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockRepo, nil)
+
+	perms := mock.NewMockPermStore(controller)
+	perms.EXPECT().Find(gomock.Any(), mockRepo.UID, mockUser.ID).Return(nil, sql.ErrNoRows)
+
+	roles := mock.NewMockRoleService(controller)
+	roles.EXPECT().List(gomock.Any(), mockUser, "octocat").Return([]*core.Role{
+		{Permissions: []string{core.PermissionRepoWrite}},
+	}, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(
+			request.WithUser(r.Context(), mockUser),
+			chi.RouteCtxKey, c),
+	)
+
+	invoked := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		invoked = true
+		perm, ok := request.PermFrom(r.Context())
+		if !ok {
+			t.Errorf("Expect perm from context")
+			return
+		}
+		if !perm.Read || !perm.Write || perm.Admin {
+			t.Errorf("Expect read and write permissions granted by role, got %+v", perm)
+		}
+	})
+
+	InjectRepository(nil, repos, perms, roles)(next).ServeHTTP(w, r)
+	if !invoked {
+		t.Errorf("Expect middleware invoked")
+	}
+}
+
+func TestCheckPermission(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Login: "octocat"}
+
+	roles := mock.NewMockRoleService(controller)
+	roles.EXPECT().Permits(gomock.Any(), mockUser, "", core.PermissionQueuePause).Return(true, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/api/queue", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	invoked := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		invoked = true
+	})
+
+	CheckPermission(roles, core.PermissionQueuePause, AuthorizeAdmin)(next).ServeHTTP(w, r)
+	if !invoked {
+		t.Errorf("Expect access granted by role")
+	}
+}
+
+// this unit test ensures that requests not authorized by a
+// role are forwarded to the fallback middleware.
+func TestCheckPermission_Fallback(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Login: "octocat"}
+
+	roles := mock.NewMockRoleService(controller)
+	roles.EXPECT().Permits(gomock.Any(), mockUser, "", core.PermissionQueuePause).Return(false, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/api/queue", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expect access denied")
+	})
+
+	CheckPermission(roles, core.PermissionQueuePause, AuthorizeAdmin)(next).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusForbidden; got != want {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestCheckApprovePermission(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Login: "octocat"}
+	mockRepo := &core.Repository{ID: 1, Namespace: "octocat", Visibility: core.VisibilityPrivate}
+	mockBuild := &core.Build{Number: 2, Deploy: "prod"}
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	roles := mock.NewMockRoleService(controller)
+	roles.EXPECT().Permits(gomock.Any(), mockUser, "octocat", "deploy:approve:prod").Return(true, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("number", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(
+			request.WithRepo(
+				request.WithUser(r.Context(), mockUser),
+				mockRepo,
+			),
+			chi.RouteCtxKey, c),
+	)
+
+	invoked := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		invoked = true
+	})
+
+	CheckApprovePermission(roles, builds, CheckAdminAccess())(next).ServeHTTP(w, r)
+	if !invoked {
+		t.Errorf("Expect access granted by role")
+	}
+}
+
+// this unit test ensures that builds that do not target a
+// deployment environment are approved by repository admins.
+func TestCheckApprovePermission_NotDeployment(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Login: "octocat"}
+	mockRepo := &core.Repository{ID: 1, Namespace: "octocat", Visibility: core.VisibilityPrivate}
+	mockBuild := &core.Build{Number: 2}
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	roles := mock.NewMockRoleService(controller)
+
+	c := new(chi.Context)
+	c.URLParams.Add("number", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(
+			request.WithPerm(
+				request.WithRepo(
+					request.WithUser(r.Context(), mockUser),
+					mockRepo,
+				),
+				&core.Perm{Read: true, Write: true},
+			),
+			chi.RouteCtxKey, c),
+	)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expect access denied")
+	})
+
+	CheckApprovePermission(roles, builds, CheckAdminAccess())(next).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; got != want {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
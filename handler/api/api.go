@@ -19,6 +19,8 @@ import (
 
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api/acl"
+	"github.com/drone/drone/handler/api/approvals"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/auth"
 	"github.com/drone/drone/handler/api/badge"
 	globalbuilds "github.com/drone/drone/handler/api/builds"
@@ -34,8 +36,12 @@ import (
 	"github.com/drone/drone/handler/api/repos/encrypt"
 	"github.com/drone/drone/handler/api/repos/secrets"
 	"github.com/drone/drone/handler/api/repos/sign"
+	"github.com/drone/drone/handler/api/roles"
+	"github.com/drone/drone/handler/api/serviceaccounts"
 	"github.com/drone/drone/handler/api/system"
 	"github.com/drone/drone/handler/api/user"
+	"github.com/drone/drone/handler/api/user/sessions"
+	"github.com/drone/drone/handler/api/user/tokens"
 	"github.com/drone/drone/handler/api/users"
 	"github.com/drone/drone/logger"
 
@@ -54,49 +60,79 @@ var corsOpts = cors.Options{
 }
 
 func New(
+	approvals core.ApprovalStore,
+	approvalz core.ApprovalService,
+	audits core.AuditStore,
+	auditz core.AuditService,
 	builds core.BuildStore,
+	commits core.CommitService,
+	config core.ConfigService,
+	configs core.ConfigStore,
+	convert core.ConvertService,
 	cron core.CronStore,
 	events core.Pubsub,
 	hooks core.HookService,
+	logins core.LoginStore,
 	logs core.LogStore,
 	license *core.License,
 	licenses core.LicenseService,
+	orgs core.OrganizationService,
 	perms core.PermStore,
+	policy core.PolicyService,
 	repos core.RepositoryStore,
 	repoz core.RepositoryService,
+	roles core.RoleStore,
+	rolez core.RoleService,
 	scheduler core.Scheduler,
 	secrets core.SecretStore,
 	stages core.StageStore,
 	steps core.StepStore,
 	status core.StatusService,
 	session core.Session,
+	sessions core.SessionStore,
 	stream core.LogStream,
 	syncer core.Syncer,
 	system *core.System,
+	tokens core.TokenStore,
 	triggerer core.Triggerer,
 	users core.UserStore,
 	webhook core.WebhookSender,
 ) Server {
 	return Server{
+		Approvals: approvals,
+		Approvalz: approvalz,
+		Audits:    audits,
+		Auditz:    auditz,
 		Builds:    builds,
+		Commits:   commits,
+		Config:    config,
+		Configs:   configs,
+		Convert:   convert,
 		Cron:      cron,
 		Events:    events,
 		Hooks:     hooks,
+		Logins:    logins,
 		Logs:      logs,
 		License:   license,
 		Licenses:  licenses,
+		Orgs:      orgs,
 		Perms:     perms,
+		Policy:    policy,
 		Repos:     repos,
 		Repoz:     repoz,
+		Roles:     roles,
+		Rolez:     rolez,
 		Scheduler: scheduler,
 		Secrets:   secrets,
 		Stages:    stages,
 		Steps:     steps,
 		Status:    status,
 		Session:   session,
+		Sessions:  sessions,
 		Stream:    stream,
 		Syncer:    syncer,
 		System:    system,
+		Tokens:    tokens,
 		Triggerer: triggerer,
 		Users:     users,
 		Webhook:   webhook,
@@ -105,25 +141,40 @@ func New(
 
 // Server is a http.Handler which exposes drone functionality over HTTP.
 type Server struct {
+	Approvals core.ApprovalStore
+	Approvalz core.ApprovalService
+	Audits    core.AuditStore
+	Auditz    core.AuditService
 	Builds    core.BuildStore
+	Commits   core.CommitService
+	Config    core.ConfigService
+	Configs   core.ConfigStore
+	Convert   core.ConvertService
 	Cron      core.CronStore
 	Events    core.Pubsub
 	Hooks     core.HookService
+	Logins    core.LoginStore
 	Logs      core.LogStore
 	License   *core.License
 	Licenses  core.LicenseService
+	Orgs      core.OrganizationService
 	Perms     core.PermStore
+	Policy    core.PolicyService
 	Repos     core.RepositoryStore
 	Repoz     core.RepositoryService
+	Roles     core.RoleStore
+	Rolez     core.RoleService
 	Scheduler core.Scheduler
 	Secrets   core.SecretStore
 	Stages    core.StageStore
 	Steps     core.StepStore
 	Status    core.StatusService
 	Session   core.Session
+	Sessions  core.SessionStore
 	Stream    core.LogStream
 	Syncer    core.Syncer
 	System    *core.System
+	Tokens    core.TokenStore
 	Triggerer core.Triggerer
 	Users     core.UserStore
 	Webhook   core.WebhookSender
@@ -141,13 +192,13 @@ func (s Server) Handler() http.Handler {
 	r.Use(cors.Handler)
 
 	r.Route("/repos/{owner}/{name}", func(r chi.Router) {
-		r.Use(acl.InjectRepository(s.Repoz, s.Repos, s.Perms))
+		r.Use(acl.InjectRepository(s.Repoz, s.Repos, s.Perms, s.Rolez))
 		r.Use(acl.CheckReadAccess())
 
 		r.Get("/", repos.HandleFind())
 		r.With(
 			acl.CheckAdminAccess(),
-		).Patch("/", repos.HandleUpdate(s.Repos))
+		).Patch("/", repos.HandleUpdate(s.Repos, s.Auditz))
 		r.With(
 			acl.CheckAdminAccess(),
 		).Post("/", repos.HandleEnable(s.Hooks, s.Repos, s.Webhook))
@@ -165,31 +216,41 @@ func (s Server) Handler() http.Handler {
 			r.Get("/", builds.HandleList(s.Repos, s.Builds))
 			r.Get("/latest", builds.HandleLast(s.Repos, s.Builds, s.Stages))
 			r.Get("/{number}", builds.HandleFind(s.Repos, s.Builds, s.Stages))
+			r.With(
+				acl.CheckWriteAccess(),
+			).Get("/{number}/config", builds.HandleConfig(s.Repos, s.Builds, s.Configs))
+			r.Get("/{number}/logs", logs.HandleArchive(s.Repos, s.Builds, s.Stages, s.Logs))
 			r.Get("/{number}/logs/{stage}/{step}", logs.HandleFind(s.Repos, s.Builds, s.Stages, s.Steps, s.Logs))
 
+			r.With(
+				acl.CheckWriteAccess(),
+			).Post("/", builds.HandleCreate(s.Users, s.Repos, s.Commits, s.Triggerer))
+
 			r.With(
 				acl.CheckWriteAccess(),
 			).Post("/{number}", builds.HandleRetry(s.Repos, s.Builds, s.Triggerer))
 
 			r.With(
 				acl.CheckWriteAccess(),
-			).Delete("/{number}", builds.HandleCancel(s.Users, s.Repos, s.Builds, s.Stages, s.Steps, s.Status, s.Scheduler, s.Webhook))
+			).Delete("/{number}", builds.HandleCancel(s.Users, s.Repos, s.Builds, s.Stages, s.Steps, s.Status, s.Scheduler, s.Webhook, s.Auditz))
 
 			r.With(
 				acl.CheckAdminAccess(),
-			).Post("/{number}/promote", builds.HandlePromote(s.Repos, s.Builds, s.Triggerer))
+			).Post("/{number}/promote", builds.HandlePromote(s.Repos, s.Builds, s.Triggerer, s.Auditz))
 
 			// r.With(
 			// 	acl.CheckAdminAccess(),
 			// ).Post("/{number}/rollback", builds.HandleRollback(s.Repos, s.Builds, s.Triggerer))
 
 			r.With(
-				acl.CheckAdminAccess(),
-			).Post("/{number}/decline/{stage}", stages.HandleDecline(s.Repos, s.Builds, s.Stages))
+				acl.CheckApprovePermission(s.Rolez, s.Builds, acl.CheckAdminAccess()),
+			).Post("/{number}/decline/{stage}", stages.HandleDecline(s.Repos, s.Builds, s.Stages, s.Approvals, s.Approvalz, s.Auditz))
 
 			r.With(
-				acl.CheckAdminAccess(),
-			).Post("/{number}/approve/{stage}", stages.HandleApprove(s.Repos, s.Builds, s.Stages, s.Scheduler))
+				acl.CheckApprovePermission(s.Rolez, s.Builds, acl.CheckAdminAccess()),
+			).Post("/{number}/approve/{stage}", stages.HandleApprove(s.Repos, s.Builds, s.Stages, s.Approvals, s.Approvalz, s.Scheduler, s.Auditz))
+
+			r.Get("/{number}/approvals/{stage}", stages.HandleApprovals(s.Repos, s.Builds, s.Stages, s.Approvals))
 
 			r.With(
 				acl.CheckAdminAccess(),
@@ -202,7 +263,7 @@ func (s Server) Handler() http.Handler {
 		})
 
 		r.Route("/secrets", func(r chi.Router) {
-			r.Use(acl.CheckWriteAccess())
+			r.Use(acl.CheckPermission(s.Rolez, core.PermissionSecretManage, acl.CheckWriteAccess()))
 			r.Get("/", secrets.HandleList(s.Repos, s.Secrets))
 			r.Post("/", secrets.HandleCreate(s.Repos, s.Secrets))
 			r.Get("/{secret}", secrets.HandleFind(s.Repos, s.Secrets))
@@ -210,6 +271,11 @@ func (s Server) Handler() http.Handler {
 			r.Delete("/{secret}", secrets.HandleDelete(s.Repos, s.Secrets))
 		})
 
+		r.Route("/lint", func(r chi.Router) {
+			r.Use(acl.CheckWriteAccess())
+			r.Post("/", repos.HandleLint(s.Users, s.Repos, s.Commits, s.Config, s.Convert, s.Policy))
+		})
+
 		r.Route("/sign", func(r chi.Router) {
 			r.Use(acl.CheckWriteAccess())
 			r.Post("/", sign.HandleSign(s.Repos))
@@ -242,16 +308,18 @@ func (s Server) Handler() http.Handler {
 	r.Route("/badges/{owner}/{name}", func(r chi.Router) {
 		r.Get("/status.svg", badge.Handler(s.Repos, s.Builds))
 		r.With(
-			acl.InjectRepository(s.Repoz, s.Repos, s.Perms),
+			acl.InjectRepository(s.Repoz, s.Repos, s.Perms, s.Rolez),
 			acl.CheckReadAccess(),
 		).Get("/cc.xml", ccmenu.Handler(s.Repos, s.Builds, s.System.Link))
 	})
 
 	r.Route("/queue", func(r chi.Router) {
-		r.Use(acl.AuthorizeAdmin)
-		r.Get("/", queue.HandleItems(s.Stages))
-		r.Post("/", queue.HandleResume(s.Scheduler))
-		r.Delete("/", queue.HandlePause(s.Scheduler))
+		r.With(acl.AuthorizeAdmin).Get("/", queue.HandleItems(s.Stages))
+		r.Group(func(r chi.Router) {
+			r.Use(acl.CheckPermission(s.Rolez, core.PermissionQueuePause, acl.AuthorizeAdmin))
+			r.Post("/", queue.HandleResume(s.Scheduler, s.Auditz))
+			r.Delete("/", queue.HandlePause(s.Scheduler, s.Auditz))
+		})
 	})
 
 	r.Route("/user", func(r chi.Router) {
@@ -259,32 +327,74 @@ func (s Server) Handler() http.Handler {
 		r.Get("/", user.HandleFind())
 		r.Patch("/", user.HandleUpdate(s.Users))
 		r.Post("/token", user.HandleToken(s.Users))
+		r.Get("/tokens", tokens.HandleList(s.Tokens))
+		r.Post("/tokens", tokens.HandleCreate(s.Tokens))
+		r.Delete("/tokens/{token}", tokens.HandleDelete(s.Tokens))
+		r.Get("/sessions", sessions.HandleList(s.Sessions))
+		r.Delete("/sessions/{session}", sessions.HandleDelete(s.Sessions, s.Session))
 		r.Get("/repos", user.HandleRepos(s.Repos))
 		r.Post("/repos", user.HandleSync(s.Syncer, s.Repos))
 
 		// TODO(bradrydzewski) finalize the name for this endpoint.
 		r.Get("/builds", user.HandleRecent(s.Repos))
 		r.Get("/builds/recent", user.HandleRecent(s.Repos))
+		r.Get("/approvals", user.HandleApprovals(s.Repos, s.Builds, s.Stages, s.Perms, s.Approvals, s.Approvalz, s.Rolez))
 	})
 
 	r.Route("/users", func(r chi.Router) {
 		r.Use(acl.AuthorizeAdmin)
 		r.Get("/", users.HandleList(s.Users))
-		r.Post("/", users.HandleCreate(s.Users, s.Webhook))
+		r.Post("/", users.HandleCreate(s.Users, s.Webhook, s.Auditz))
 		r.Get("/{user}", users.HandleFind(s.Users))
-		r.Patch("/{user}", users.HandleUpdate(s.Users))
-		r.Delete("/{user}", users.HandleDelete(s.Users, s.Webhook))
+		r.Patch("/{user}", users.HandleUpdate(s.Users, s.Auditz))
+		r.Delete("/{user}", users.HandleDelete(s.Users, s.Webhook, s.Auditz))
+		r.Get("/{user}/sessions", users.HandleListSessions(s.Users, s.Sessions))
+		r.Delete("/{user}/sessions", users.HandleDeleteSessions(s.Users, s.Sessions, s.Session, s.Auditz))
+		r.Get("/{user}/activity", users.HandleActivity(s.Users, s.Logins, s.Audits))
+	})
+
+	r.Route("/orgs/{namespace}/serviceaccounts", func(r chi.Router) {
+		r.Use(acl.CheckPermission(s.Rolez, core.PermissionServiceAccountManage, acl.AuthorizeOrgAdmin(s.Orgs)))
+		r.Get("/", serviceaccounts.HandleList(s.Users))
+		r.Post("/", serviceaccounts.HandleCreate(s.Users, s.Auditz))
+		r.Delete("/{name}", serviceaccounts.HandleDelete(s.Users, s.Auditz))
+	})
+
+	r.Route("/roles", func(r chi.Router) {
+		r.Use(acl.AuthorizeAdmin)
+		r.Get("/", roles.HandleList(s.Roles))
+		r.Post("/", roles.HandleCreate(s.Roles))
+		r.Get("/{role}", roles.HandleFind(s.Roles))
+		r.Patch("/{role}", roles.HandleUpdate(s.Roles))
+		r.Delete("/{role}", roles.HandleDelete(s.Roles))
+		r.Get("/{role}/bindings", roles.HandleListBindings(s.Roles))
+		r.Post("/{role}/bindings", roles.HandleCreateBinding(s.Roles))
+		r.Delete("/{role}/bindings/{binding}", roles.HandleDeleteBinding(s.Roles))
+	})
+
+	r.Route("/approvals/rules", func(r chi.Router) {
+		r.Use(acl.AuthorizeAdmin)
+		r.Get("/", approvals.HandleList(s.Approvals))
+		r.Post("/", approvals.HandleCreate(s.Approvals))
+		r.Get("/{rule}", approvals.HandleFind(s.Approvals))
+		r.Patch("/{rule}", approvals.HandleUpdate(s.Approvals))
+		r.Delete("/{rule}", approvals.HandleDelete(s.Approvals))
+	})
+
+	r.Route("/audit", func(r chi.Router) {
+		r.Use(acl.AuthorizeAdmin)
+		r.Get("/", audit.HandleList(s.Audits))
 	})
 
 	r.Route("/stream", func(r chi.Router) {
 		r.Get("/", events.HandleGlobal(s.Repos, s.Events))
 
 		r.Route("/{owner}/{name}", func(r chi.Router) {
-			r.Use(acl.InjectRepository(s.Repoz, s.Repos, s.Perms))
+			r.Use(acl.InjectRepository(s.Repoz, s.Repos, s.Perms, s.Rolez))
 			r.Use(acl.CheckReadAccess())
 
 			r.Get("/", events.HandleEvents(s.Repos, s.Events))
-			r.Get("/{number}/{stage}/{step}", events.HandleLogStream(s.Repos, s.Builds, s.Stages, s.Steps, s.Stream))
+			r.Get("/{number}/{stage}/{step}", events.HandleLogStream(s.Repos, s.Builds, s.Stages, s.Steps, s.Stream, s.Logs))
 		})
 	})
 
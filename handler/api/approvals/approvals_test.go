@@ -0,0 +1,145 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package approvals
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var mockRule = &core.ApprovalRule{
+	ID:        1,
+	Target:    "production",
+	Approvals: 2,
+	Groups:    []string{"release-managers"},
+}
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().ListRules(gomock.Any()).Return([]*core.ApprovalRule{mockRule}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+
+	HandleList(approvals).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*core.ApprovalRule{}, []*core.ApprovalRule{mockRule}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Error(diff)
+	}
+}
+
+func TestHandleCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().CreateRule(gomock.Any(), gomock.Any()).Return(nil)
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&ruleInput{
+		Target:    "production",
+		Approvals: 2,
+		Groups:    []string{"release-managers"},
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+
+	HandleCreate(approvals).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := new(core.ApprovalRule)
+	json.NewDecoder(w.Body).Decode(got)
+	if got.Target != "production" || got.Approvals != 2 || got.Created == 0 {
+		t.Errorf("Unexpected approval rule %+v", got)
+	}
+}
+
+func TestHandleCreate_Invalid(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&ruleInput{Target: "production"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+
+	HandleCreate(nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleUpdate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	rule := *mockRule
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().FindRule(gomock.Any(), rule.ID).Return(&rule, nil)
+	approvals.EXPECT().UpdateRule(gomock.Any(), &rule).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("rule", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PATCH", "/", bytes.NewBufferString(`{"approvals":3,"allow_self":true}`))
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleUpdate(approvals).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if rule.Approvals != 3 || !rule.AllowSelf || rule.Target != "production" {
+		t.Errorf("Unexpected approval rule %+v", rule)
+	}
+}
+
+func TestHandleDelete_NotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().FindRule(gomock.Any(), int64(1)).Return(nil, sql.ErrNoRows)
+
+	c := new(chi.Context)
+	c.URLParams.Add("rule", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(approvals).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approvals
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+)
+
+type ruleInput struct {
+	Repo      string   `json:"repo"`
+	Target    string   `json:"target"`
+	Protected bool     `json:"protected"`
+	Approvals int      `json:"approvals"`
+	AllowSelf bool     `json:"allow_self"`
+	Groups    []string `json:"groups"`
+}
+
+// HandleCreate returns an http.HandlerFunc that processes http
+// requests to create an approval rule.
+func HandleCreate(approvals core.ApprovalStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in := new(ruleInput)
+		err := json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot unmarshal request body")
+			return
+		}
+
+		rule := &core.ApprovalRule{
+			Repo:      in.Repo,
+			Target:    in.Target,
+			Protected: in.Protected,
+			Approvals: in.Approvals,
+			AllowSelf: in.AllowSelf,
+			Groups:    in.Groups,
+			Created:   time.Now().Unix(),
+			Updated:   time.Now().Unix(),
+		}
+		err = rule.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = approvals.CreateRule(r.Context(), rule)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot create approval rule")
+			return
+		}
+		render.JSON(w, rule, 200)
+	}
+}
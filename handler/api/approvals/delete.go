@@ -0,0 +1,53 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approvals
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleDelete returns an http.HandlerFunc that processes http
+// requests to delete an approval rule.
+func HandleDelete(approvals core.ApprovalStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "rule"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		rule, err := approvals.FindRule(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot find approval rule")
+			return
+		}
+		err = approvals.DeleteRule(r.Context(), rule)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot delete approval rule")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
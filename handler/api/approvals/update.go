@@ -0,0 +1,100 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approvals
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+type ruleUpdate struct {
+	Repo      *string  `json:"repo"`
+	Target    *string  `json:"target"`
+	Protected *bool    `json:"protected"`
+	Approvals *int     `json:"approvals"`
+	AllowSelf *bool    `json:"allow_self"`
+	Groups    []string `json:"groups"`
+}
+
+// HandleUpdate returns an http.HandlerFunc that processes http
+// requests to update an approval rule.
+func HandleUpdate(approvals core.ApprovalStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in := new(ruleUpdate)
+		err := json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot unmarshal request body")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "rule"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		rule, err := approvals.FindRule(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot find approval rule")
+			return
+		}
+
+		if in.Repo != nil {
+			rule.Repo = *in.Repo
+		}
+		if in.Target != nil {
+			rule.Target = *in.Target
+		}
+		if in.Protected != nil {
+			rule.Protected = *in.Protected
+		}
+		if in.Approvals != nil {
+			rule.Approvals = *in.Approvals
+		}
+		if in.AllowSelf != nil {
+			rule.AllowSelf = *in.AllowSelf
+		}
+		if in.Groups != nil {
+			rule.Groups = in.Groups
+		}
+		rule.Updated = time.Now().Unix()
+
+		err = rule.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = approvals.UpdateRule(r.Context(), rule)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot update approval rule")
+			return
+		}
+		render.JSON(w, rule, 200)
+	}
+}
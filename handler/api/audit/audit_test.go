@@ -0,0 +1,109 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecord(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	var got *core.AuditEvent
+	audits := mock.NewMockAuditService(controller)
+	audits.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event *core.AuditEvent) error {
+		got = event
+		return nil
+	})
+
+	before := &core.Repository{Slug: "octocat/hello-world", Trusted: false, Timeout: 60}
+	after := &core.Repository{Slug: "octocat/hello-world", Trusted: true, Timeout: 60}
+
+	r := httptest.NewRequest("PATCH", "/", nil)
+	r.RemoteAddr = "10.0.0.1:4321"
+	r.Header.Set("X-Forwarded-For", "192.168.1.1")
+	r = r.WithContext(
+		request.WithUser(r.Context(), &core.User{Login: "octocat"}),
+	)
+
+	Record(r, audits, core.AuditActionRepoUpdate, after.Slug, before, after)
+	if got == nil {
+		t.Fatalf("Want audit event recorded")
+	}
+	if got, want := got.Actor, "octocat"; got != want {
+		t.Errorf("Want actor %q, got %q", want, got)
+	}
+	if got, want := got.IP, "10.0.0.1"; got != want {
+		t.Errorf("Want ip %q, got %q", want, got)
+	}
+	if got, want := string(got.Before), `{"trusted":false}`; got != want {
+		t.Errorf("Want before %s, got %s", want, got)
+	}
+	if got, want := string(got.After), `{"trusted":true}`; got != want {
+		t.Errorf("Want after %s, got %s", want, got)
+	}
+}
+
+// this test verifies that recording is a no-op when the
+// audit service is not configured.
+func TestRecord_Disabled(t *testing.T) {
+	r := httptest.NewRequest("POST", "/", nil)
+	Record(r, nil, core.AuditActionQueuePause, "queue", nil, nil)
+}
+
+func TestDiff_Nil(t *testing.T) {
+	var user *core.User
+	before, after := diff(user, &core.User{Login: "octocat"})
+	if before != nil {
+		t.Errorf("Want nil before, got %s", before)
+	}
+	if len(after) == 0 {
+		t.Errorf("Want after encoded")
+	}
+}
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockEvents := []*core.AuditEvent{
+		{ID: 1, Actor: "octocat", Action: core.AuditActionBuildCancel, Target: "octocat/hello-world#1"},
+	}
+	params := core.AuditParams{
+		Actor: "octocat",
+		Since: 1500000000,
+		Page:  2,
+		Size:  25,
+	}
+
+	audits := mock.NewMockAuditStore(controller)
+	audits.EXPECT().List(gomock.Any(), params).Return(mockEvents, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?actor=octocat&since=1500000000&page=2&per_page=500", nil)
+
+	HandleList(audits).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*core.AuditEvent{}, mockEvents
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+)
+
+// HandleList returns an http.HandlerFunc that writes a json-encoded
+// list of audit events to the response body. The events can be
+// filtered by actor, action, target and time range.
+func HandleList(audits core.AuditStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.FormValue("page"))
+		size, _ := strconv.Atoi(r.FormValue("per_page"))
+		if size < 1 || size > 100 {
+			size = 25
+		}
+		since, _ := strconv.ParseInt(r.FormValue("since"), 10, 64)
+		until, _ := strconv.ParseInt(r.FormValue("until"), 10, 64)
+
+		list, err := audits.List(r.Context(), core.AuditParams{
+			Actor:  r.FormValue("actor"),
+			Action: r.FormValue("action"),
+			Target: r.FormValue("target"),
+			Since:  since,
+			Until:  until,
+			Page:   page,
+			Size:   size,
+		})
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot list audit events")
+			return
+		}
+		render.JSON(w, list, 200)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+)
+
+// Record records an audit event for the action performed on the
+// target by the authenticated user. The before and after values
+// are the target resource before and after the action, and only
+// the changed fields are recorded. Either value may be nil. The
+// event is not recorded if the audit service is nil, and errors
+// are logged but otherwise ignored, since the action has already
+// been performed.
+func Record(r *http.Request, audits core.AuditService, action, target string, before, after interface{}) {
+	if audits == nil {
+		return
+	}
+	event := &core.AuditEvent{
+		Action:  action,
+		Target:  target,
+		IP:      request.RemoteAddr(r),
+		Created: time.Now().Unix(),
+	}
+	if user, ok := request.UserFrom(r.Context()); ok {
+		event.Actor = user.Login
+	}
+	event.Before, event.After = diff(before, after)
+
+	err := audits.Record(r.Context(), event)
+	if err != nil {
+		logger.FromRequest(r).WithError(err).
+			WithField("action", action).
+			Warnln("api: cannot record audit event")
+	}
+}
+
+// BuildTarget returns the audit target for the build.
+func BuildTarget(repo *core.Repository, build *core.Build) string {
+	return fmt.Sprintf("%s#%d", repo.Slug, build.Number)
+}
+
+// StageTarget returns the audit target for the build stage.
+func StageTarget(repo *core.Repository, build *core.Build, stage *core.Stage) string {
+	return fmt.Sprintf("%s#%d.%d", repo.Slug, build.Number, stage.Number)
+}
+
+// helper function returns the json-encoded fields that differ
+// between the before and after values.
+func diff(before, after interface{}) (json.RawMessage, json.RawMessage) {
+	a, b := toMap(before), toMap(after)
+	if a == nil || b == nil {
+		return encode(a), encode(b)
+	}
+	for k, v := range a {
+		if reflect.DeepEqual(v, b[k]) {
+			delete(a, k)
+			delete(b, k)
+		}
+	}
+	return encode(a), encode(b)
+}
+
+// helper function converts the value to a map using its json
+// encoding, which omits fields that should not be exposed.
+func toMap(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	out := map[string]interface{}{}
+	json.Unmarshal(raw, &out)
+	return out
+}
+
+func encode(m map[string]interface{}) json.RawMessage {
+	if len(m) == 0 {
+		return nil
+	}
+	raw, _ := json.Marshal(m)
+	return raw
+}
@@ -47,6 +47,13 @@ func HandleAuthentication(session core.Session) func(http.Handler) http.Handler
 				log = log.WithField("user.admin", user.Admin)
 			}
 			log = log.WithField("user.login", user.Login)
+
+			// sessions created before server-side sessions were
+			// enabled are replaced with a revocable session.
+			if err := session.Upgrade(w, r, user); err != nil {
+				log.WithError(err).Warnln("api: cannot upgrade session")
+			}
+
 			ctx = logger.WithContext(ctx, log)
 			next.ServeHTTP(w, r.WithContext(
 				request.WithUser(ctx, user),
@@ -37,6 +37,7 @@ func TestAuth(t *testing.T) {
 
 	session := mock.NewMockSession(controller)
 	session.EXPECT().Get(gomock.Any()).Return(mockUser, nil)
+	session.EXPECT().Upgrade(gomock.Any(), gomock.Any(), mockUser).Return(nil)
 
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/?access_token=VA.197XXbZablx0RPQ8", nil)
@@ -29,13 +29,17 @@ import (
 )
 
 // HandleLogStream creates an http.HandlerFunc that streams builds logs
-// to the http.Response in an event stream format.
+// to the http.Response in an event stream format. Each event includes
+// the line number as the event identifier, which allows the client to
+// resume the stream using the Last-Event-ID header or the since query
+// parameter.
 func HandleLogStream(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
 	stages core.StageStore,
 	steps core.StepStore,
 	stream core.LogStream,
+	logs core.LogStore,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -77,6 +81,11 @@ func HandleLogStream(
 			render.NotFound(w, err)
 			return
 		}
+		since, err := parseSince(r)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
 
 		h := w.Header()
 		h.Set("Content-Type", "text/event-stream")
@@ -98,7 +107,14 @@ func HandleLogStream(
 		enc := json.NewEncoder(w)
 		linec, errc := stream.Tail(ctx, step.ID)
 		if errc == nil {
+			// the live stream is closed once the step completes,
+			// in which case the remaining lines are replayed from
+			// the persisted logs.
+			for _, line := range findLines(ctx, logs, step.ID, since, -1) {
+				writeLine(w, enc, line)
+			}
 			io.WriteString(w, "event: error\ndata: eof\n\n")
+			f.Flush()
 			return
 		}
 
@@ -114,9 +130,19 @@ func HandleLogStream(
 			case <-time.After(pingInterval):
 				io.WriteString(w, ": ping\n\n")
 			case line := <-linec:
-				io.WriteString(w, "data: ")
-				enc.Encode(line)
-				io.WriteString(w, "\n\n")
+				if line.Number <= since {
+					continue
+				}
+				// if the requested lines are no longer available
+				// in the in-memory buffer, attempt to backfill the
+				// missing lines from the persisted logs.
+				if line.Number > since+1 {
+					for _, prev := range findLines(ctx, logs, step.ID, since, line.Number) {
+						writeLine(w, enc, prev)
+					}
+				}
+				writeLine(w, enc, line)
+				since = line.Number
 				f.Flush()
 			}
 		}
@@ -125,3 +151,54 @@ func HandleLogStream(
 		f.Flush()
 	}
 }
+
+// parseSince returns the number of the last line received by
+// the client, or -1 if the stream should start from the first
+// line. The Last-Event-ID header takes precedence over the since
+// query parameter.
+func parseSince(r *http.Request) (int, error) {
+	since := r.Header.Get("Last-Event-ID")
+	if since == "" {
+		since = r.FormValue("since")
+	}
+	if since == "" {
+		return -1, nil
+	}
+	return strconv.Atoi(since)
+}
+
+// findLines returns the persisted log lines numbered after
+// the since line and before the until line. If until is
+// negative all remaining lines are returned.
+func findLines(ctx context.Context, logs core.LogStore, step int64, since, until int) []*core.Line {
+	rc, err := logs.Find(ctx, step)
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	var all []*core.Line
+	if err := json.NewDecoder(rc).Decode(&all); err != nil {
+		return nil
+	}
+	var lines []*core.Line
+	for _, line := range all {
+		if line.Number <= since {
+			continue
+		}
+		if until >= 0 && line.Number >= until {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// writeLine writes the log line to the event stream using
+// the line number as the event identifier.
+func writeLine(w io.Writer, enc *json.Encoder, line *core.Line) {
+	io.WriteString(w, "id: ")
+	io.WriteString(w, strconv.Itoa(line.Number))
+	io.WriteString(w, "\ndata: ")
+	enc.Encode(line)
+	io.WriteString(w, "\n\n")
+}
@@ -5,11 +5,154 @@
 package events
 
 import (
+	"bytes"
+	"context"
 	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
 
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/livelog"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
 )
 
 func init() {
 	logrus.SetOutput(ioutil.Discard)
 }
+
+var (
+	mockRepo  = &core.Repository{ID: 1, Namespace: "octocat", Name: "hello-world"}
+	mockBuild = &core.Build{ID: 2, RepoID: 1, Number: 3}
+	mockStage = &core.Stage{ID: 4, BuildID: 2, Number: 5}
+	mockStep  = &core.Step{ID: 6, StageID: 4, Number: 7}
+)
+
+// this test verifies that the persisted logs are replayed,
+// starting after the Last-Event-ID, when the live stream is
+// no longer available.
+func TestLogStream_Completed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos, builds, stages, steps := mockLogStores(controller)
+
+	stream := mock.NewMockLogStream(controller)
+	stream.EXPECT().Tail(gomock.Any(), mockStep.ID).Return(nil, nil)
+
+	logs := mock.NewMockLogStore(controller)
+	logs.EXPECT().Find(gomock.Any(), mockStep.ID).Return(
+		ioutil.NopCloser(bytes.NewBufferString(`[{"pos":0,"out":"a"},{"pos":1,"out":"b"},{"pos":2,"out":"c"}]`)), nil,
+	)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Last-Event-ID", "0")
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, mockLogContext()),
+	)
+
+	HandleLogStream(repos, builds, stages, steps, stream, logs)(w, r)
+
+	want := ": ping\n\n" +
+		"id: 1\ndata: {\"pos\":1,\"out\":\"b\",\"time\":0}\n\n\n" +
+		"id: 2\ndata: {\"pos\":2,\"out\":\"c\",\"time\":0}\n\n\n" +
+		"event: error\ndata: eof\n\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("Want event stream %q, got %q", want, got)
+	}
+}
+
+// this test verifies that lines that have fallen out of the
+// in-memory buffer are backfilled from the persisted logs, and
+// that lines already received by the client are skipped.
+func TestLogStream_Resume(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos, builds, stages, steps := mockLogStores(controller)
+
+	stream := livelog.New()
+	stream.Create(noContext, mockStep.ID)
+	stream.Write(noContext, mockStep.ID, &core.Line{Number: 3, Message: "d"})
+	stream.Write(noContext, mockStep.ID, &core.Line{Number: 4, Message: "e"})
+
+	logs := mock.NewMockLogStore(controller)
+	logs.EXPECT().Find(gomock.Any(), mockStep.ID).Return(
+		ioutil.NopCloser(bytes.NewBufferString(`[{"pos":0,"out":"a"},{"pos":1,"out":"b"},{"pos":2,"out":"c"}]`)), nil,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?since=1", nil)
+	r = r.WithContext(
+		context.WithValue(ctx, chi.RouteCtxKey, mockLogContext()),
+	)
+
+	HandleLogStream(repos, builds, stages, steps, stream, logs)(w, r)
+
+	want := ": ping\n\n" +
+		"id: 2\ndata: {\"pos\":2,\"out\":\"c\",\"time\":0}\n\n\n" +
+		"id: 3\ndata: {\"pos\":3,\"out\":\"d\",\"time\":0}\n\n\n" +
+		"id: 4\ndata: {\"pos\":4,\"out\":\"e\",\"time\":0}\n\n\n" +
+		"event: error\ndata: eof\n\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("Want event stream %q, got %q", want, got)
+	}
+}
+
+func TestLogStream_InvalidSince(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos, builds, stages, steps := mockLogStores(controller)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?since=foo", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, mockLogContext()),
+	)
+
+	HandleLogStream(repos, builds, stages, steps, nil, nil)(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+var noContext = context.Background()
+
+func mockLogContext() *chi.Context {
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("number", "3")
+	c.URLParams.Add("stage", "5")
+	c.URLParams.Add("step", "7")
+	return c
+}
+
+func mockLogStores(controller *gomock.Controller) (
+	core.RepositoryStore,
+	core.BuildStore,
+	core.StageStore,
+	core.StepStore,
+) {
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockBuild.ID, mockStage.Number).Return(mockStage, nil)
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().FindNumber(gomock.Any(), mockStage.ID, mockStep.Number).Return(mockStep, nil)
+	return repos, builds, stages, steps
+}
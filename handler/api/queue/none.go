@@ -31,10 +31,10 @@ func HandleItems(store core.StageStore) http.HandlerFunc {
 	return notImplemented
 }
 
-func HandlePause(core.Scheduler) http.HandlerFunc {
+func HandlePause(core.Scheduler, core.AuditService) http.HandlerFunc {
 	return notImplemented
 }
 
-func HandleResume(core.Scheduler) http.HandlerFunc {
+func HandleResume(core.Scheduler, core.AuditService) http.HandlerFunc {
 	return notImplemented
 }
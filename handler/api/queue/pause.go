@@ -10,13 +10,14 @@ import (
 	"net/http"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/logger"
 )
 
 // HandlePause returns an http.HandlerFunc that processes
 // an http.Request to pause the scheduler.
-func HandlePause(scheduler core.Scheduler) http.HandlerFunc {
+func HandlePause(scheduler core.Scheduler, audits core.AuditService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		err := scheduler.Pause(ctx)
@@ -26,6 +27,7 @@ func HandlePause(scheduler core.Scheduler) http.HandlerFunc {
 				Errorln("api: cannot pause scheduler")
 			return
 		}
+		audit.Record(r, audits, core.AuditActionQueuePause, "queue", nil, nil)
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
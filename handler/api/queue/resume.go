@@ -10,13 +10,14 @@ import (
 	"net/http"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/logger"
 )
 
 // HandleResume returns an http.HandlerFunc that processes
 // an http.Request to pause the scheduler.
-func HandleResume(scheduler core.Scheduler) http.HandlerFunc {
+func HandleResume(scheduler core.Scheduler, audits core.AuditService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		err := scheduler.Resume(ctx)
@@ -26,6 +27,7 @@ func HandleResume(scheduler core.Scheduler) http.HandlerFunc {
 				Errorln("api: cannot resume scheduler")
 			return
 		}
+		audit.Record(r, audits, core.AuditActionQueueResume, "queue", nil, nil)
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
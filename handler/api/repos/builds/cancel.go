@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/logger"
 
@@ -39,6 +40,7 @@ func HandleCancel(
 	status core.StatusService,
 	scheduler core.Scheduler,
 	webhooks core.WebhookSender,
+	audits core.AuditService,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -86,6 +88,7 @@ func HandleCancel(
 			return
 		}
 
+		before := *build
 		build.Status = core.StatusKilled
 		build.Finished = time.Now().Unix()
 		if build.Started == 0 {
@@ -104,6 +107,8 @@ func HandleCancel(
 			return
 		}
 
+		audit.Record(r, audits, core.AuditActionBuildCancel, audit.BuildTarget(repo, build), &before, build)
+
 		err = scheduler.Cancel(r.Context(), build.ID)
 		if err != nil {
 			logger.FromRequest(r).
@@ -71,7 +71,7 @@ func TestCancel(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleCancel(users, repos, builds, stages, steps, statusService, scheduler, webhook)(w, r)
+	HandleCancel(users, repos, builds, stages, steps, statusService, scheduler, webhook, nil)(w, r)
 	if got, want := w.Code, 200; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -0,0 +1,65 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builds
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleConfig returns an http.HandlerFunc that writes the
+// json-encoded configuration file that was resolved when the
+// build was created to the response body. The resolved
+// configuration may include private templates and the output
+// of remote conversion, and the handler is therefore
+// restricted to users with write access to the repository.
+func HandleConfig(
+	repos core.RepositoryStore,
+	builds core.BuildStore,
+	configs core.ConfigStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+		)
+		number, err := strconv.ParseInt(chi.URLParam(r, "number"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		build, err := builds.FindNumber(r.Context(), repo.ID, number)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		config, err := configs.Find(r.Context(), build.ID)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		render.JSON(w, config, 200)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package builds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/trigger"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var mockCommit = &core.Commit{
+	Sha:     "cce10d5c4760d1d6ede99db850ab7e77efe15579",
+	Ref:     "refs/heads/master",
+	Message: "updated README.md",
+	Link:    "https://github.com/octocat/hello-world/commit/cce10d5c4760d1d6ede99db850ab7e77efe15579",
+	Author: &core.Committer{
+		Name:   "The Octocat",
+		Email:  "octocat@github.com",
+		Login:  "octocat",
+		Avatar: "https://github.com/octocat.png",
+	},
+}
+
+func TestCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	checkHook := func(_ context.Context, _ *core.Repository, hook *core.Hook) error {
+		if got, want := hook.Trigger, mockUser.Login; got != want {
+			t.Errorf("Want Trigger By %s, got %s", want, got)
+		}
+		if got, want := hook.Event, core.EventPush; got != want {
+			t.Errorf("Want Build Event %s, got %s", want, got)
+		}
+		if got, want := hook.After, mockCommit.Sha; got != want {
+			t.Errorf("Want Build After %s, got %s", want, got)
+		}
+		if got, want := hook.Ref, "refs/heads/develop"; got != want {
+			t.Errorf("Want Build Ref %s, got %s", want, got)
+		}
+		if got, want := hook.Target, "develop"; got != want {
+			t.Errorf("Want Build Target %s, got %s", want, got)
+		}
+		if diff := cmp.Diff(hook.Params, map[string]string{"environment": "production"}); diff != "" {
+			t.Error(diff)
+		}
+		return nil
+	}
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Find(gomock.Any(), mockRepo.UserID).Return(mockUser, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), gomock.Any(), mockRepo.Name).Return(mockRepo, nil)
+
+	commits := mock.NewMockCommitService(controller)
+	commits.EXPECT().FindRef(gomock.Any(), mockUser, mockRepo.Slug, "develop").Return(mockCommit, nil)
+
+	triggerer := mock.NewMockTriggerer(controller)
+	triggerer.EXPECT().Trigger(gomock.Any(), mockRepo, gomock.Any()).Return(mockBuild, nil).Do(checkHook)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/?branch=develop&environment=production", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(users, repos, commits, triggerer)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(core.Build), mockBuild
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
+
+func TestCreate_Commit(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Find(gomock.Any(), mockRepo.UserID).Return(mockUser, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), gomock.Any(), mockRepo.Name).Return(mockRepo, nil)
+
+	commits := mock.NewMockCommitService(controller)
+	commits.EXPECT().Find(gomock.Any(), mockUser, mockRepo.Slug, mockCommit.Sha).Return(mockCommit, nil)
+
+	triggerer := mock.NewMockTriggerer(controller)
+	triggerer.EXPECT().Trigger(gomock.Any(), mockRepo, gomock.Any()).Return(mockBuild, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/?commit="+mockCommit.Sha, nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(users, repos, commits, triggerer)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestCreate_CommitNotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Find(gomock.Any(), mockRepo.UserID).Return(mockUser, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), gomock.Any(), mockRepo.Name).Return(mockRepo, nil)
+
+	commits := mock.NewMockCommitService(controller)
+	commits.EXPECT().FindRef(gomock.Any(), mockUser, mockRepo.Slug, mockRepo.Branch).Return(nil, errors.ErrNotFound)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(users, repos, commits, nil)(w, r)
+	if got, want := w.Code, 404; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestCreate_InvalidInput(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Find(gomock.Any(), mockRepo.UserID).Return(mockUser, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), gomock.Any(), mockRepo.Name).Return(mockRepo, nil)
+
+	commits := mock.NewMockCommitService(controller)
+	commits.EXPECT().FindRef(gomock.Any(), mockUser, mockRepo.Slug, mockRepo.Branch).Return(mockCommit, nil)
+
+	triggerer := mock.NewMockTriggerer(controller)
+	triggerer.EXPECT().Trigger(gomock.Any(), mockRepo, gomock.Any()).Return(nil, &trigger.InputError{Message: "Missing required input version"})
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(users, repos, commits, triggerer)(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(errors.Error), &errors.Error{Message: "Missing required input version"}
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Errorf(diff)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// unsafeName matches characters that are not permitted in
+// archive file names.
+var unsafeName = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// HandleArchive returns an http.HandlerFunc that streams a zip
+// or tar.gz archive of the plain text logs for every step in
+// the build to the response body.
+func HandleArchive(
+	repos core.RepositoryStore,
+	builds core.BuildStore,
+	stages core.StageStore,
+	logs core.LogStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+			format    = r.FormValue("format")
+		)
+		switch format {
+		case "":
+			format = "zip"
+		case "zip", "tar.gz", "tgz":
+		default:
+			render.BadRequestf(w, "Unsupported archive format %q", format)
+			return
+		}
+		number, err := strconv.ParseInt(chi.URLParam(r, "number"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		build, err := builds.FindNumber(r.Context(), repo.ID, number)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		stages, err := stages.ListSteps(r.Context(), build.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+
+		ext := "zip"
+		if format != "zip" {
+			ext = "tar.gz"
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf(
+			"attachment; filename=\"%s-%s-%d-logs.%s\"",
+			repo.Namespace,
+			repo.Name,
+			build.Number,
+			ext,
+		))
+
+		var arch archiver
+		if format == "zip" {
+			w.Header().Set("Content-Type", "application/zip")
+			arch = newZipArchiver(w)
+		} else {
+			w.Header().Set("Content-Type", "application/gzip")
+			arch = newTarArchiver(w)
+		}
+
+		opts := parseOptions(r)
+		for _, stage := range stages {
+			for _, step := range stage.Steps {
+				buf, err := renderStep(r.Context(), logs, step, opts)
+				if err != nil {
+					// the response headers are already written, so the
+					// step is skipped instead of failing the archive.
+					logger.FromRequest(r).
+						WithError(err).
+						WithField("step.id", step.ID).
+						Debugln("api: cannot archive step logs")
+					continue
+				}
+				path := fmt.Sprintf("%d-%s/%d-%s.log",
+					stage.Number,
+					archiveName(stage.Name),
+					step.Number,
+					archiveName(step.Name),
+				)
+				if err := arch.add(path, buf); err != nil {
+					logger.FromRequest(r).
+						WithError(err).
+						Warnln("api: cannot write log archive")
+					return
+				}
+			}
+		}
+		arch.close()
+	}
+}
+
+// archiveName returns the stage or step name with every
+// character other than letters, digits, dots, dashes and
+// underscores replaced, to prevent names defined in the
+// configuration file from writing outside of the archive
+// directory when the archive is extracted.
+func archiveName(name string) string {
+	return unsafeName.ReplaceAllString(name, "_")
+}
+
+// renderStep returns the step logs in plain text format.
+func renderStep(ctx context.Context, logs core.LogStore, step *core.Step, opts options) ([]byte, error) {
+	rc, err := logs.Find(ctx, step.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	lines, err := decodeLines(rc)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	err = writeText(buf, lines, opts)
+	return buf.Bytes(), err
+}
+
+// archiver writes files to an archive.
+type archiver interface {
+	add(path string, data []byte) error
+	close() error
+}
+
+type zipArchiver struct {
+	zw *zip.Writer
+}
+
+func newZipArchiver(w io.Writer) archiver {
+	return &zipArchiver{zw: zip.NewWriter(w)}
+}
+
+func (a *zipArchiver) add(path string, data []byte) error {
+	f, err := a.zw.CreateHeader(&zip.FileHeader{
+		Name:     path,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func (a *zipArchiver) close() error {
+	return a.zw.Close()
+}
+
+type tarArchiver struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarArchiver(w io.Writer) archiver {
+	gz := gzip.NewWriter(w)
+	return &tarArchiver{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (a *tarArchiver) add(path string, data []byte) error {
+	err := a.tw.WriteHeader(&tar.Header{
+		Name:    path,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = a.tw.Write(data)
+	return err
+}
+
+func (a *tarArchiver) close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
@@ -0,0 +1,152 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package logs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+func TestArchive_Zip(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?strip=true", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, mockContext()),
+	)
+
+	HandleArchive(mockArchiveStores(controller))(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := w.Header().Get("Content-Type"), "application/zip"; got != want {
+		t.Errorf("Want content type %q, got %q", want, got)
+	}
+
+	body := w.Body.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(zr.File), 1; got != want {
+		t.Errorf("Want %d archived files, got %d", want, got)
+		return
+	}
+	if got, want := zr.File[0].Name, "5-default/7-build.log"; got != want {
+		t.Errorf("Want archived file %q, got %q", want, got)
+	}
+	rc, _ := zr.File[0].Open()
+	data, _ := ioutil.ReadAll(rc)
+	if got, want := string(data), "go build\ngo test\n"; got != want {
+		t.Errorf("Want archived logs %q, got %q", want, got)
+	}
+}
+
+func TestArchive_TarGz(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?format=tar.gz", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, mockContext()),
+	)
+
+	HandleArchive(mockArchiveStores(controller))(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	tr := tar.NewReader(gz)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := hdr.Name, "5-default/7-build.log"; got != want {
+		t.Errorf("Want archived file %q, got %q", want, got)
+	}
+	data, _ := ioutil.ReadAll(tr)
+	if got, want := string(data), "\x1b[32mgo build\x1b[0m\ngo test\n"; got != want {
+		t.Errorf("Want archived logs %q, got %q", want, got)
+	}
+}
+
+func TestArchive_InvalidFormat(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?format=rar", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, mockContext()),
+	)
+
+	HandleArchive(nil, nil, nil, nil)(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func mockArchiveStores(controller *gomock.Controller) (
+	core.RepositoryStore,
+	core.BuildStore,
+	core.StageStore,
+	core.LogStore,
+) {
+	stage := *mockStage
+	stage.Steps = []*core.Step{mockStep}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().ListSteps(gomock.Any(), mockBuild.ID).Return([]*core.Stage{&stage}, nil)
+
+	logs := mock.NewMockLogStore(controller)
+	logs.EXPECT().Find(gomock.Any(), mockStep.ID).Return(
+		ioutil.NopCloser(bytes.NewBufferString(mockLogs)), nil,
+	)
+	return repos, builds, stages, logs
+}
+
+func TestArchiveName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"build", "build"},
+		{"go-1.12_test", "go-1.12_test"},
+		{"../../etc/passwd", ".._.._etc_passwd"},
+		{`..\..\evil`, ".._.._evil"},
+		{"/abs", "_abs"},
+		{"hello world", "hello_world"},
+	}
+	for _, test := range tests {
+		if got := archiveName(test.name); got != test.want {
+			t.Errorf("Want archive name %q for %q, got %q", test.want, test.name, got)
+		}
+	}
+}
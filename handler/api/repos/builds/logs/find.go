@@ -15,6 +15,7 @@
 package logs
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -26,7 +27,9 @@ import (
 )
 
 // HandleFind returns an http.HandlerFunc that writes the
-// json-encoded logs to the response body.
+// json-encoded logs to the response body. The logs are written
+// in plain text format if requested with the format query
+// parameter or the Accept header.
 func HandleFind(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
@@ -79,12 +82,43 @@ func HandleFind(
 			render.NotFound(w, err)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")
-		io.Copy(w, rc)
-		rc.Close()
+		defer rc.Close()
 
-		// TODO: logs are stored in jsonl format and therefore
-		// need to be converted to valid json.
-		// ELSE: JSON.parse('['+x.split('\n').join(',')+']')
+		if r.FormValue("download") == "true" {
+			w.Header().Set("Content-Disposition", fmt.Sprintf(
+				"attachment; filename=%q",
+				filename(repo, build, stage, step, wantText(r)),
+			))
+		}
+
+		if !wantText(r) {
+			w.Header().Set("Content-Type", "application/json")
+			io.Copy(w, rc)
+			return
+		}
+
+		lines, err := decodeLines(rc)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writeText(w, lines, parseOptions(r))
+	}
+}
+
+// filename returns the name of the downloaded log file.
+func filename(repo *core.Repository, build *core.Build, stage *core.Stage, step *core.Step, text bool) string {
+	ext := "json"
+	if text {
+		ext = "log"
 	}
+	return fmt.Sprintf("%s-%s-%d-%d-%d.%s",
+		repo.Namespace,
+		repo.Name,
+		build.Number,
+		stage.Number,
+		step.Number,
+		ext,
+	)
 }
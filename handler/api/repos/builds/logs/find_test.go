@@ -3,3 +3,168 @@
 // that can be found in the LICENSE file.
 
 package logs
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+var (
+	mockRepo = &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+	}
+
+	mockBuild = &core.Build{
+		ID:     2,
+		RepoID: 1,
+		Number: 3,
+	}
+
+	mockStage = &core.Stage{
+		ID:      4,
+		BuildID: 2,
+		Number:  5,
+		Name:    "default",
+	}
+
+	mockStep = &core.Step{
+		ID:      6,
+		StageID: 4,
+		Number:  7,
+		Name:    "build",
+	}
+
+	mockLogs = `[{"pos":0,"out":"\u001b[32mgo build\u001b[0m\n","time":0},{"pos":1,"out":"go test\n","time":65}]`
+)
+
+func TestFind(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, mockContext()),
+	)
+
+	HandleFind(mockStores(controller))(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := w.Header().Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("Want content type %q, got %q", want, got)
+	}
+	if got, want := w.Body.String(), mockLogs; got != want {
+		t.Errorf("Want json logs %q, got %q", want, got)
+	}
+}
+
+func TestFind_Text(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?format=text&timestamps=true&strip=true&download=true", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, mockContext()),
+	)
+
+	HandleFind(mockStores(controller))(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := w.Header().Get("Content-Type"), "text/plain; charset=utf-8"; got != want {
+		t.Errorf("Want content type %q, got %q", want, got)
+	}
+	if got, want := w.Header().Get("Content-Disposition"), `attachment; filename="octocat-hello-world-3-5-7.log"`; got != want {
+		t.Errorf("Want content disposition %q, got %q", want, got)
+	}
+	want := "[00:00:00] go build\n[00:01:05] go test\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("Want text logs %q, got %q", want, got)
+	}
+}
+
+func TestFind_TextAccept(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/plain")
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, mockContext()),
+	)
+
+	HandleFind(mockStores(controller))(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	want := "\x1b[32mgo build\x1b[0m\ngo test\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("Want text logs %q, got %q", want, got)
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		before, after string
+	}{
+		{"\x1b[1;32mok\x1b[0m", "ok"},
+		{"\x1b[2K\x1b[1Gprogress", "progress"},
+		{"\x1b]0;title\x07hello", "hello"},
+		{"plain text", "plain text"},
+	}
+	for _, test := range tests {
+		if got, want := stripANSI(test.before), test.after; got != want {
+			t.Errorf("Want stripped text %q, got %q", want, got)
+		}
+	}
+}
+
+func mockContext() *chi.Context {
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("number", "3")
+	c.URLParams.Add("stage", "5")
+	c.URLParams.Add("step", "7")
+	return c
+}
+
+func mockStores(controller *gomock.Controller) (
+	core.RepositoryStore,
+	core.BuildStore,
+	core.StageStore,
+	core.StepStore,
+	core.LogStore,
+) {
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), mockRepo.Namespace, mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockBuild.ID, mockStage.Number).Return(mockStage, nil)
+
+	steps := mock.NewMockStepStore(controller)
+	steps.EXPECT().FindNumber(gomock.Any(), mockStage.ID, mockStep.Number).Return(mockStep, nil)
+
+	logs := mock.NewMockLogStore(controller)
+	logs.EXPECT().Find(gomock.Any(), mockStep.ID).Return(
+		ioutil.NopCloser(bytes.NewBufferString(mockLogs)), nil,
+	)
+	return repos, builds, stages, steps, logs
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/drone/drone/core"
+)
+
+// regular expression used to match ansi escape sequences,
+// including color codes, cursor movement and terminal titles.
+var ansi = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// options configures how logs are rendered in plain text.
+type options struct {
+	timestamps bool
+	strip      bool
+}
+
+// parseOptions returns the plain text rendering options
+// from the http.Request query parameters.
+func parseOptions(r *http.Request) options {
+	return options{
+		timestamps: r.FormValue("timestamps") == "true",
+		strip:      r.FormValue("strip") == "true",
+	}
+}
+
+// wantText returns true if the client requested the logs
+// in plain text format, either with the format query
+// parameter or with the Accept header.
+func wantText(r *http.Request) bool {
+	switch r.FormValue("format") {
+	case "text", "txt", "raw":
+		return true
+	case "json":
+		return false
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "text/plain") &&
+		!strings.Contains(accept, "application/json")
+}
+
+// decodeLines decodes the json-encoded log lines.
+func decodeLines(r io.Reader) ([]*core.Line, error) {
+	var lines []*core.Line
+	err := json.NewDecoder(r).Decode(&lines)
+	if err == io.EOF {
+		err = nil
+	}
+	return lines, err
+}
+
+// writeText writes the log lines to w in plain text format.
+func writeText(w io.Writer, lines []*core.Line, opts options) error {
+	for _, line := range lines {
+		if line == nil {
+			continue
+		}
+		out := line.Message
+		if opts.strip {
+			out = stripANSI(out)
+		}
+		if !strings.HasSuffix(out, "\n") {
+			out = out + "\n"
+		}
+		if opts.timestamps {
+			out = fmt.Sprintf("[%s] %s", formatElapsed(line.Timestamp), out)
+		}
+		if _, err := io.WriteString(w, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stripANSI removes ansi escape sequences from the string.
+func stripANSI(s string) string {
+	return ansi.ReplaceAllString(s, "")
+}
+
+// formatElapsed formats the line timestamp, measured in
+// seconds elapsed since the step started, as hh:mm:ss.
+func formatElapsed(secs int64) string {
+	if secs < 0 {
+		secs = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs%3600/60, secs%60)
+}
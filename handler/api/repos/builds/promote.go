@@ -11,8 +11,10 @@ import (
 	"strconv"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/trigger"
 
 	"github.com/go-chi/chi"
 )
@@ -23,6 +25,7 @@ func HandlePromote(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
 	triggerer core.Triggerer,
+	audits core.AuditService,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -88,9 +91,12 @@ func HandlePromote(
 		}
 
 		result, err := triggerer.Trigger(r.Context(), repo, hook)
-		if err != nil {
+		if _, ok := err.(*trigger.InputError); ok {
+			render.BadRequest(w, err)
+		} else if err != nil {
 			render.InternalError(w, err)
 		} else {
+			audit.Record(r, audits, core.AuditActionBuildPromote, audit.BuildTarget(repo, prev), nil, result)
 			render.JSON(w, result, 200)
 		}
 	}
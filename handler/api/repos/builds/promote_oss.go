@@ -32,6 +32,7 @@ func HandlePromote(
 	core.RepositoryStore,
 	core.BuildStore,
 	core.Triggerer,
+	core.AuditService,
 ) http.HandlerFunc {
 	return notImplemented
 }
@@ -95,7 +95,7 @@ func TestPromote(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(repos, builds, triggerer)(w, r)
+	HandlePromote(repos, builds, triggerer, nil)(w, r)
 	if got, want := w.Code, 200; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -122,7 +122,7 @@ func TestPromote_InvalidBuildNumber(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(nil, nil, nil)(w, r)
+	HandlePromote(nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -154,7 +154,7 @@ func TestPromote_RepoNotFound(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(repos, nil, nil)(w, r)
+	HandlePromote(repos, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -187,7 +187,7 @@ func TestPromote_BuildNotFound(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(repos, builds, nil)(w, r)
+	HandlePromote(repos, builds, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -220,7 +220,7 @@ func TestPromote_MissingTargetError(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(repos, builds, nil)(w, r)
+	HandlePromote(repos, builds, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -256,7 +256,7 @@ func TestPromote_TriggerError(t *testing.T) {
 		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
 	)
 
-	HandlePromote(repos, builds, triggerer)(w, r)
+	HandlePromote(repos, builds, triggerer, nil)(w, r)
 	if got, want := w.Code, 500; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -21,6 +21,7 @@ import (
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/trigger"
 
 	"github.com/go-chi/chi"
 )
@@ -95,7 +96,9 @@ func HandleRetry(
 		}
 
 		result, err := triggerer.Trigger(r.Context(), repo, hook)
-		if err != nil {
+		if _, ok := err.(*trigger.InputError); ok {
+			render.BadRequest(w, err)
+		} else if err != nil {
 			render.InternalError(w, err)
 		} else {
 			render.JSON(w, result, 200)
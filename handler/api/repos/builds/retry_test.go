@@ -14,6 +14,7 @@ import (
 	"github.com/drone/drone/handler/api/request"
 	"github.com/drone/drone/mock"
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/trigger"
 
 	"github.com/go-chi/chi"
 	"github.com/golang/mock/gomock"
@@ -229,3 +230,33 @@ func TestRetry_TriggerError(t *testing.T) {
 		t.Errorf(diff)
 	}
 }
+
+func TestRetry_InvalidInput(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), gomock.Any(), mockRepo.Name).Return(mockRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockRepo.ID, mockBuild.Number).Return(mockBuild, nil)
+
+	triggerer := mock.NewMockTriggerer(controller)
+	triggerer.EXPECT().Trigger(gomock.Any(), mockRepo, gomock.Any()).Return(nil, &trigger.InputError{Message: "Unknown input foo"})
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("number", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/?foo=bar", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleRetry(repos, builds, triggerer)(w, r)
+	if got, want := w.Code, 400; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleApprovals returns an http.HandlerFunc that writes a
+// json-encoded list of approvals recorded for the stage.
+func HandleApprovals(
+	repos core.RepositoryStore,
+	builds core.BuildStore,
+	stages core.StageStore,
+	approvals core.ApprovalStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "owner")
+			name      = chi.URLParam(r, "name")
+		)
+		buildNumber, err := strconv.ParseInt(chi.URLParam(r, "number"), 10, 64)
+		if err != nil {
+			render.BadRequestf(w, "Invalid build number")
+			return
+		}
+		stageNumber, err := strconv.Atoi(chi.URLParam(r, "stage"))
+		if err != nil {
+			render.BadRequestf(w, "Invalid stage number")
+			return
+		}
+		repo, err := repos.FindName(r.Context(), namespace, name)
+		if err != nil {
+			render.NotFoundf(w, "Repository not found")
+			return
+		}
+		build, err := builds.FindNumber(r.Context(), repo.ID, buildNumber)
+		if err != nil {
+			render.NotFoundf(w, "Build not found")
+			return
+		}
+		stage, err := stages.FindNumber(r.Context(), build.ID, stageNumber)
+		if err != nil {
+			render.NotFoundf(w, "Stage not found")
+			return
+		}
+		list, err := approvals.List(r.Context(), stage.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			return
+		}
+		render.JSON(w, list, 200)
+	}
+}
@@ -20,7 +20,9 @@ import (
 	"strconv"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/store/shared/db"
 
 	"github.com/go-chi/chi"
 )
@@ -29,11 +31,16 @@ var noContext = context.Background()
 
 // HandleApprove returns an http.HandlerFunc that processes http
 // requests to approve a blocked build that is pending review.
+// If an approval service is configured, the stage is scheduled
+// once the approvals required by the approval rule are met.
 func HandleApprove(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
 	stages core.StageStore,
+	approvals core.ApprovalStore,
+	approvalz core.ApprovalService,
 	sched core.Scheduler,
+	audits core.AuditService,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -69,12 +76,31 @@ func HandleApprove(
 			render.BadRequestf(w, "Cannot approve a Pipeline with Status %q", stage.Status)
 			return
 		}
+		if approvalz != nil {
+			approval, list, rule, ok := review(w, r, approvals, approvalz, repo, build, stage, true)
+			if !ok {
+				return
+			}
+			if !rule.Approved(list) {
+				audit.Record(r, audits, core.AuditActionStageApprove, audit.StageTarget(repo, build, stage), nil, approval)
+				render.JSON(w, list, http.StatusAccepted)
+				return
+			}
+		}
+		before := *stage
 		stage.Status = core.StatusPending
 		err = stages.Update(r.Context(), stage)
+		if err == db.ErrOptimisticLock {
+			// a concurrent approval reached the quorum first
+			// and the stage is already scheduled.
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
 		if err != nil {
 			render.InternalErrorf(w, "There was a problem approving the Pipeline")
 			return
 		}
+		audit.Record(r, audits, core.AuditActionStageApprove, audit.StageTarget(repo, build, stage), &before, stage)
 		err = sched.Schedule(noContext, stage)
 		if err != nil {
 			render.InternalErrorf(w, "There was a problem scheduling the Pipeline")
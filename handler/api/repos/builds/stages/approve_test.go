@@ -74,7 +74,7 @@ func TestApprove(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, stages, sched)(w, r)
+	HandleApprove(repos, builds, stages, nil, nil, sched, nil)(w, r)
 	if got, want := w.Code, 204; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -125,7 +125,7 @@ func TestApprove_InvalidStatus(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, stages, nil)(w, r)
+	HandleApprove(repos, builds, stages, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -153,7 +153,7 @@ func TestApprove_InvalidBuildNumber(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(nil, nil, nil, nil)(w, r)
+	HandleApprove(nil, nil, nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -181,7 +181,7 @@ func TestApprove_InvalidStageNumber(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(nil, nil, nil, nil)(w, r)
+	HandleApprove(nil, nil, nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -231,7 +231,7 @@ func TestApprove_StageNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, stages, nil)(w, r)
+	HandleApprove(repos, builds, stages, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -273,7 +273,7 @@ func TestApprove_BuildNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, nil, nil)(w, r)
+	HandleApprove(repos, builds, nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -312,7 +312,7 @@ func TestApprove_RepoNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, nil, nil, nil)(w, r)
+	HandleApprove(repos, nil, nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -370,7 +370,7 @@ func TestApprove_CannotSaveStage(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, stages, nil)(w, r)
+	HandleApprove(repos, builds, stages, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 500; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -431,7 +431,7 @@ func TestApprove_CannotEnqueue(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleApprove(repos, builds, stages, sched)(w, r)
+	HandleApprove(repos, builds, stages, nil, nil, sched, nil)(w, r)
 	if got, want := w.Code, 500; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
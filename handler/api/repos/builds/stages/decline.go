@@ -20,6 +20,7 @@ import (
 	"strconv"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/render"
 
 	"github.com/go-chi/chi"
@@ -31,6 +32,9 @@ func HandleDecline(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
 	stages core.StageStore,
+	approvals core.ApprovalStore,
+	approvalz core.ApprovalService,
+	audits core.AuditService,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -67,6 +71,12 @@ func HandleDecline(
 			render.BadRequest(w, err)
 			return
 		}
+		if approvalz != nil {
+			if _, _, _, ok := review(w, r, approvals, approvalz, repo, build, stage, false); !ok {
+				return
+			}
+		}
+		before := *stage
 		stage.Status = core.StatusDeclined
 		err = stages.Update(r.Context(), stage)
 		if err != nil {
@@ -79,6 +89,7 @@ func HandleDecline(
 			render.InternalError(w, err)
 			return
 		}
+		audit.Record(r, audits, core.AuditActionStageDecline, audit.StageTarget(repo, build, stage), &before, stage)
 
 		// TODO delete any pending stages from the build queue
 		// TODO update any pending stages to skipped in the database
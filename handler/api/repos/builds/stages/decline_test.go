@@ -36,7 +36,7 @@ func TestDecline_InvalidBuildNumber(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleDecline(nil, nil, nil)(w, r)
+	HandleDecline(nil, nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -64,7 +64,7 @@ func TestDecline_InvalidStageNumber(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleDecline(nil, nil, nil)(w, r)
+	HandleDecline(nil, nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -103,7 +103,7 @@ func TestDecline_RepoNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleDecline(repos, nil, nil)(w, r)
+	HandleDecline(repos, nil, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -145,7 +145,7 @@ func TestDecline_BuildNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleDecline(repos, builds, nil)(w, r)
+	HandleDecline(repos, builds, nil, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -195,7 +195,7 @@ func TestDecline_StageNotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleDecline(repos, builds, stages)(w, r)
+	HandleDecline(repos, builds, stages, nil, nil, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -250,7 +250,7 @@ func TestDecline_InvalidStatus(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleDecline(repos, builds, stages)(w, r)
+	HandleDecline(repos, builds, stages, nil, nil, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -0,0 +1,138 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+)
+
+type reviewInput struct {
+	Comment string `json:"comment"`
+}
+
+// review records the user approval, or decline, of the
+// blocked stage and returns the recorded approval, the current
+// list of stage approvals and the approval rule that applies
+// to the build.
+// If the review cannot be recorded an error is written to the
+// response and ok is false.
+func review(
+	w http.ResponseWriter,
+	r *http.Request,
+	approvals core.ApprovalStore,
+	approvalz core.ApprovalService,
+	repo *core.Repository,
+	build *core.Build,
+	stage *core.Stage,
+	approved bool,
+) (approval *core.Approval, list []*core.Approval, rule *core.ApprovalRule, ok bool) {
+	in := new(reviewInput)
+	err := json.NewDecoder(r.Body).Decode(in)
+	if err != nil && err != io.EOF {
+		render.BadRequest(w, err)
+		return nil, nil, nil, false
+	}
+
+	user, _ := request.UserFrom(r.Context())
+	if user == nil {
+		render.Unauthorized(w, errors.ErrUnauthorized)
+		return nil, nil, nil, false
+	}
+
+	rule, err = approvalz.Rule(r.Context(), repo, build)
+	if err != nil {
+		render.InternalError(w, err)
+		logger.FromRequest(r).WithError(err).
+			Warnln("api: cannot resolve approval rule")
+		return nil, nil, nil, false
+	}
+
+	// the build author may decline their own build, however
+	// approver group restrictions still apply.
+	authz := *rule
+	if !approved {
+		authz.AllowSelf = true
+	}
+	err = approvalz.Authorize(r.Context(), user, repo, build, &authz)
+	if err == core.ErrApprovalSelf || err == core.ErrApprovalGroup {
+		render.Forbidden(w, err)
+		return nil, nil, nil, false
+	} else if err != nil {
+		render.InternalError(w, err)
+		return nil, nil, nil, false
+	}
+
+	list, err = approvals.List(r.Context(), stage.ID)
+	if err != nil {
+		render.InternalError(w, err)
+		return nil, nil, nil, false
+	}
+	if hasReviewed(list, user) {
+		render.ErrorCode(w, core.ErrApprovalExists, http.StatusConflict)
+		return nil, nil, nil, false
+	}
+
+	approval = &core.Approval{
+		StageID:  stage.ID,
+		Approver: user.Login,
+		Approved: approved,
+		Comment:  in.Comment,
+	}
+	err = approvals.Create(r.Context(), approval)
+	if err != nil {
+		// a concurrent request from the same user may have
+		// recorded the review first, in which case the insert
+		// violates the unique (stage, approver) constraint.
+		if list, _ = approvals.List(r.Context(), stage.ID); hasReviewed(list, user) {
+			render.ErrorCode(w, core.ErrApprovalExists, http.StatusConflict)
+			return nil, nil, nil, false
+		}
+		render.InternalError(w, err)
+		logger.FromRequest(r).WithError(err).
+			Warnln("api: cannot record stage approval")
+		return nil, nil, nil, false
+	}
+
+	// the approvals are re-read after the insert so that the
+	// quorum includes reviews recorded by concurrent requests.
+	list, err = approvals.List(r.Context(), stage.ID)
+	if err != nil {
+		render.InternalError(w, err)
+		logger.FromRequest(r).WithError(err).
+			Warnln("api: cannot list stage approvals")
+		return nil, nil, nil, false
+	}
+	return approval, list, rule, true
+}
+
+// helper function returns true if the user has already
+// reviewed the stage.
+func hasReviewed(list []*core.Approval, user *core.User) bool {
+	for _, approval := range list {
+		if strings.EqualFold(approval.Approver, user.Login) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,405 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package stages
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	mockReviewRepo = &core.Repository{
+		ID:        1,
+		Namespace: "octocat",
+		Name:      "hello-world",
+	}
+	mockReviewBuild = &core.Build{
+		ID:     111,
+		Number: 1,
+		Author: "spaceghost",
+		Status: core.StatusBlocked,
+	}
+	mockReviewRule = &core.ApprovalRule{
+		Approvals: 2,
+	}
+)
+
+func newReviewStage() *core.Stage {
+	return &core.Stage{
+		ID:     222,
+		Number: 2,
+		Status: core.StatusBlocked,
+	}
+}
+
+func newReviewRequest(user *core.User, body string) (*httptest.ResponseRecorder, *http.Request) {
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+	c.URLParams.Add("number", "1")
+	c.URLParams.Add("stage", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), user), chi.RouteCtxKey, c),
+	)
+	return w, r
+}
+
+// this test verifies that the stage remains blocked, and the
+// approval is recorded, when the approval quorum is not met.
+func TestApprove_Pending(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{Login: "octocat"}
+	mockStage := newReviewStage()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockReviewRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockReviewRepo.ID, mockReviewBuild.Number).Return(mockReviewBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockReviewBuild.ID, mockStage.Number).Return(mockStage, nil)
+
+	approvalz := mock.NewMockApprovalService(controller)
+	approvalz.EXPECT().Rule(gomock.Any(), mockReviewRepo, mockReviewBuild).Return(mockReviewRule, nil)
+	approvalz.EXPECT().Authorize(gomock.Any(), mockUser, mockReviewRepo, mockReviewBuild, gomock.Any()).Return(nil)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return(nil, nil)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return([]*core.Approval{
+		{StageID: 222, Approver: "octocat", Approved: true, Comment: "lgtm"},
+	}, nil)
+
+	w, r := newReviewRequest(mockUser, `{"comment":"lgtm"}`)
+	HandleApprove(repos, builds, stages, approvals, approvalz, nil, nil)(w, r)
+	if got, want := w.Code, 202; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*core.Approval{}, []*core.Approval{
+		{StageID: 222, Approver: "octocat", Approved: true, Comment: "lgtm"},
+	}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Error(diff)
+	}
+	if mockStage.Status != core.StatusBlocked {
+		t.Errorf("Want stage status Blocked, got %s", mockStage.Status)
+	}
+}
+
+// this test verifies that the stage is scheduled once the
+// approval quorum is met.
+func TestApprove_Quorum(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{Login: "octocat"}
+	mockStage := newReviewStage()
+	mockApprovals := []*core.Approval{
+		{StageID: 222, Approver: "janecitizen", Approved: true},
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockReviewRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockReviewRepo.ID, mockReviewBuild.Number).Return(mockReviewBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockReviewBuild.ID, mockStage.Number).Return(mockStage, nil)
+	stages.EXPECT().Update(gomock.Any(), mockStage).Return(nil)
+
+	approvalz := mock.NewMockApprovalService(controller)
+	approvalz.EXPECT().Rule(gomock.Any(), mockReviewRepo, mockReviewBuild).Return(mockReviewRule, nil)
+	approvalz.EXPECT().Authorize(gomock.Any(), mockUser, mockReviewRepo, mockReviewBuild, gomock.Any()).Return(nil)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return(mockApprovals, nil)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return(append(mockApprovals,
+		&core.Approval{StageID: 222, Approver: "octocat", Approved: true},
+	), nil)
+
+	sched := mock.NewMockScheduler(controller)
+	sched.EXPECT().Schedule(gomock.Any(), mockStage).Return(nil)
+
+	w, r := newReviewRequest(mockUser, "")
+	HandleApprove(repos, builds, stages, approvals, approvalz, sched, nil)(w, r)
+	if got, want := w.Code, 204; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if mockStage.Status != core.StatusPending {
+		t.Errorf("Want stage status Pending, got %s", mockStage.Status)
+	}
+}
+
+// this test verifies that the quorum is evaluated against the
+// approvals re-read after the insert, so that an approval
+// recorded by a concurrent request is counted.
+func TestApprove_QuorumConcurrent(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{Login: "octocat"}
+	mockStage := newReviewStage()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockReviewRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockReviewRepo.ID, mockReviewBuild.Number).Return(mockReviewBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockReviewBuild.ID, mockStage.Number).Return(mockStage, nil)
+	stages.EXPECT().Update(gomock.Any(), mockStage).Return(nil)
+
+	approvalz := mock.NewMockApprovalService(controller)
+	approvalz.EXPECT().Rule(gomock.Any(), mockReviewRepo, mockReviewBuild).Return(mockReviewRule, nil)
+	approvalz.EXPECT().Authorize(gomock.Any(), mockUser, mockReviewRepo, mockReviewBuild, gomock.Any()).Return(nil)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return(nil, nil)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return([]*core.Approval{
+		{StageID: 222, Approver: "janecitizen", Approved: true},
+		{StageID: 222, Approver: "octocat", Approved: true},
+	}, nil)
+
+	sched := mock.NewMockScheduler(controller)
+	sched.EXPECT().Schedule(gomock.Any(), mockStage).Return(nil)
+
+	w, r := newReviewRequest(mockUser, "")
+	HandleApprove(repos, builds, stages, approvals, approvalz, sched, nil)(w, r)
+	if got, want := w.Code, 204; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that the stage is not scheduled twice if
+// a concurrent approval already moved the stage to pending.
+func TestApprove_AlreadyScheduled(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{Login: "octocat"}
+	mockStage := newReviewStage()
+	mockApprovals := []*core.Approval{
+		{StageID: 222, Approver: "janecitizen", Approved: true},
+		{StageID: 222, Approver: "octocat", Approved: true},
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockReviewRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockReviewRepo.ID, mockReviewBuild.Number).Return(mockReviewBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockReviewBuild.ID, mockStage.Number).Return(mockStage, nil)
+	stages.EXPECT().Update(gomock.Any(), mockStage).Return(db.ErrOptimisticLock)
+
+	approvalz := mock.NewMockApprovalService(controller)
+	approvalz.EXPECT().Rule(gomock.Any(), mockReviewRepo, mockReviewBuild).Return(mockReviewRule, nil)
+	approvalz.EXPECT().Authorize(gomock.Any(), mockUser, mockReviewRepo, mockReviewBuild, gomock.Any()).Return(nil)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return(mockApprovals[:1], nil)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return(mockApprovals, nil)
+
+	w, r := newReviewRequest(mockUser, "")
+	HandleApprove(repos, builds, stages, approvals, approvalz, nil, nil)(w, r)
+	if got, want := w.Code, 204; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that a 403 forbidden error is returned
+// if the approval rule does not permit the user to approve.
+func TestApprove_Forbidden(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{Login: "spaceghost"}
+	mockStage := newReviewStage()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockReviewRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockReviewRepo.ID, mockReviewBuild.Number).Return(mockReviewBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockReviewBuild.ID, mockStage.Number).Return(mockStage, nil)
+
+	approvalz := mock.NewMockApprovalService(controller)
+	approvalz.EXPECT().Rule(gomock.Any(), mockReviewRepo, mockReviewBuild).Return(mockReviewRule, nil)
+	approvalz.EXPECT().Authorize(gomock.Any(), mockUser, mockReviewRepo, mockReviewBuild, gomock.Any()).Return(core.ErrApprovalSelf)
+
+	w, r := newReviewRequest(mockUser, "")
+	HandleApprove(repos, builds, stages, nil, approvalz, nil, nil)(w, r)
+	if got, want := w.Code, 403; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(errors.Error), &errors.Error{Message: core.ErrApprovalSelf.Error()}
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Error(diff)
+	}
+}
+
+// this test verifies that a 409 conflict error is returned
+// if the user has already reviewed the stage.
+func TestApprove_Exists(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{Login: "octocat"}
+	mockStage := newReviewStage()
+	mockApprovals := []*core.Approval{
+		{StageID: 222, Approver: "Octocat", Approved: true},
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockReviewRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockReviewRepo.ID, mockReviewBuild.Number).Return(mockReviewBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockReviewBuild.ID, mockStage.Number).Return(mockStage, nil)
+
+	approvalz := mock.NewMockApprovalService(controller)
+	approvalz.EXPECT().Rule(gomock.Any(), mockReviewRepo, mockReviewBuild).Return(mockReviewRule, nil)
+	approvalz.EXPECT().Authorize(gomock.Any(), mockUser, mockReviewRepo, mockReviewBuild, gomock.Any()).Return(nil)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return(mockApprovals, nil)
+
+	w, r := newReviewRequest(mockUser, "")
+	HandleApprove(repos, builds, stages, approvals, approvalz, nil, nil)(w, r)
+	if got, want := w.Code, 409; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that the build author is permitted to
+// decline their own build, and the decline is recorded.
+func TestDecline_Review(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{Login: "spaceghost"}
+	mockStage := newReviewStage()
+	mockBuild := *mockReviewBuild
+
+	checkRule := func(_ context.Context, _ *core.User, _ *core.Repository, _ *core.Build, rule *core.ApprovalRule) {
+		if !rule.AllowSelf {
+			t.Errorf("Want build author permitted to decline")
+		}
+	}
+	checkApproval := func(_ context.Context, approval *core.Approval) {
+		if approval.Approved {
+			t.Errorf("Want decline recorded")
+		}
+		if got, want := approval.Comment, "not yet"; got != want {
+			t.Errorf("Want comment %q, got %q", want, got)
+		}
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockReviewRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockReviewRepo.ID, mockBuild.Number).Return(&mockBuild, nil)
+	builds.EXPECT().Update(gomock.Any(), &mockBuild).Return(nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockBuild.ID, mockStage.Number).Return(mockStage, nil)
+	stages.EXPECT().Update(gomock.Any(), mockStage).Return(nil)
+
+	approvalz := mock.NewMockApprovalService(controller)
+	approvalz.EXPECT().Rule(gomock.Any(), mockReviewRepo, &mockBuild).Return(mockReviewRule, nil)
+	approvalz.EXPECT().Authorize(gomock.Any(), mockUser, mockReviewRepo, &mockBuild, gomock.Any()).Return(nil).Do(checkRule)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return(nil, nil)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Do(checkApproval)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return([]*core.Approval{
+		{StageID: 222, Approver: "spaceghost", Comment: "not yet"},
+	}, nil)
+
+	w, r := newReviewRequest(mockUser, `{"comment":"not yet"}`)
+	HandleDecline(repos, builds, stages, approvals, approvalz, nil)(w, r)
+	if got, want := w.Code, 204; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if mockStage.Status != core.StatusDeclined {
+		t.Errorf("Want stage status Declined, got %s", mockStage.Status)
+	}
+	if mockReviewRule.AllowSelf {
+		t.Errorf("Want approval rule unchanged")
+	}
+}
+
+// this test verifies that a 409 conflict error is returned
+// if a concurrent request from the same user recorded the
+// review first and the insert fails.
+func TestApprove_ExistsConcurrent(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{Login: "octocat"}
+	mockStage := newReviewStage()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(mockReviewRepo, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().FindNumber(gomock.Any(), mockReviewRepo.ID, mockReviewBuild.Number).Return(mockReviewBuild, nil)
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().FindNumber(gomock.Any(), mockReviewBuild.ID, mockStage.Number).Return(mockStage, nil)
+
+	approvalz := mock.NewMockApprovalService(controller)
+	approvalz.EXPECT().Rule(gomock.Any(), mockReviewRepo, mockReviewBuild).Return(mockReviewRule, nil)
+	approvalz.EXPECT().Authorize(gomock.Any(), mockUser, mockReviewRepo, mockReviewBuild, gomock.Any()).Return(nil)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return(nil, nil)
+	approvals.EXPECT().Create(gomock.Any(), gomock.Any()).Return(sql.ErrTxDone)
+	approvals.EXPECT().List(gomock.Any(), mockStage.ID).Return([]*core.Approval{
+		{StageID: 222, Approver: "octocat", Approved: true},
+	}, nil)
+
+	w, r := newReviewRequest(mockUser, "")
+	HandleApprove(repos, builds, stages, approvals, approvalz, nil, nil)(w, r)
+	if got, want := w.Code, 409; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repos
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+	"github.com/drone/drone/trigger"
+
+	"github.com/drone/go-scm/scm"
+	"github.com/go-chi/chi"
+)
+
+type lintRequest struct {
+	Data   string `json:"data"`
+	Branch string `json:"branch"`
+	Event  string `json:"event"`
+	Ref    string `json:"ref"`
+	Commit string `json:"commit"`
+	Target string `json:"target"`
+}
+
+// HandleLint returns an http.HandlerFunc that processes http
+// requests to lint a pipeline configuration file and report
+// the pipelines that would execute for a hypothetical branch
+// and event. If the configuration file is not provided, it
+// is fetched from the repository at the branch or commit.
+// The configuration file is converted and evaluated using
+// the same conversion and policy services as the triggerer.
+func HandleLint(
+	users core.UserStore,
+	repos core.RepositoryStore,
+	commits core.CommitService,
+	config core.ConfigService,
+	convert core.ConvertService,
+	policy core.PolicyService,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			owner = chi.URLParam(r, "owner")
+			name  = chi.URLParam(r, "name")
+		)
+
+		in := new(lintRequest)
+		err := json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		repo, err := repos.FindName(r.Context(), owner, name)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("namespace", owner).
+				WithField("name", name).
+				Debugln("api: repository not found")
+			return
+		}
+
+		if in.Branch == "" {
+			in.Branch = repo.Branch
+		}
+		if in.Event == "" {
+			in.Event = core.EventPush
+		}
+		if in.Ref == "" {
+			in.Ref = "refs/heads/" + in.Branch
+		}
+
+		hook := &core.Hook{
+			Trigger:    core.TriggerHook,
+			Event:      in.Event,
+			Ref:        in.Ref,
+			Source:     in.Branch,
+			Target:     in.Branch,
+			After:      in.Commit,
+			Deployment: in.Target,
+		}
+		if strings.HasPrefix(in.Ref, "refs/tags/") {
+			hook.Source = ""
+			hook.Target = ""
+		}
+
+		user, err := users.Find(r.Context(), repo.UserID)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).
+				WithError(err).
+				WithField("namespace", owner).
+				WithField("name", name).
+				Warnln("api: cannot find repository owner")
+			return
+		}
+
+		if hook.After == "" {
+			commit, err := commits.FindRef(r.Context(), user, repo.Slug, scm.TrimRef(in.Ref))
+			if err != nil {
+				render.NotFound(w, err)
+				logger.FromRequest(r).
+					WithError(err).
+					WithField("namespace", owner).
+					WithField("name", name).
+					WithField("ref", in.Ref).
+					Debugln("api: cannot find commit")
+				return
+			}
+			hook.After = commit.Sha
+		}
+
+		build := &core.Build{
+			RepoID:  repo.ID,
+			Trigger: hook.Trigger,
+			Event:   hook.Event,
+			After:   hook.After,
+			Ref:     hook.Ref,
+			Source:  hook.Source,
+			Target:  hook.Target,
+			Deploy:  hook.Deployment,
+			Created: time.Now().Unix(),
+			Updated: time.Now().Unix(),
+		}
+
+		raw := &core.Config{Data: in.Data}
+		if in.Data == "" {
+			raw, err = config.Find(r.Context(), &core.ConfigArgs{
+				User:  user,
+				Repo:  repo,
+				Build: build,
+			})
+			if err != nil {
+				render.NotFound(w, err)
+				logger.FromRequest(r).
+					WithError(err).
+					WithField("namespace", owner).
+					WithField("name", name).
+					Debugln("api: cannot find configuration")
+				return
+			}
+		}
+
+		raw, err = convert.Convert(r.Context(), &core.ConvertArgs{
+			User:   user,
+			Repo:   repo,
+			Build:  build,
+			Config: raw,
+		})
+		if err != nil {
+			render.JSON(w, &trigger.Report{
+				Pipelines: []*trigger.ReportResult{},
+				Skipped:   []*trigger.ReportResult{},
+				Errors: []*trigger.ReportError{
+					{Message: err.Error()},
+				},
+			}, 200)
+			return
+		}
+
+		render.JSON(w, trigger.Lint(r.Context(), policy, repo, hook, raw.Data), 200)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package repos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/trigger"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+// convertPassthrough returns the configuration unmodified.
+func convertPassthrough(_ context.Context, args *core.ConvertArgs) (*core.Config, error) {
+	return args.Config, nil
+}
+
+func TestLint(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := &core.User{ID: 1, Login: "octocat"}
+	repo := &core.Repository{
+		ID:     1,
+		UserID: 1,
+		Slug:   "octocat/hello-world",
+		Branch: "master",
+		Config: ".drone.yml",
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(repo, nil)
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Find(gomock.Any(), repo.UserID).Return(user, nil)
+
+	commits := mock.NewMockCommitService(controller)
+	commits.EXPECT().FindRef(gomock.Any(), user, repo.Slug, "develop").Return(&core.Commit{Sha: "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d"}, nil)
+
+	convert := mock.NewMockConvertService(controller)
+	convert.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
+
+	policy := mock.NewMockPolicyService(controller)
+	policy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&lintRequest{
+		Data:   "kind: pipeline\nname: test\ntrigger:\n  event: [ tag ]\nsteps:\n- name: test\n  image: golang\n",
+		Branch: "develop",
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleLint(users, repos, commits, nil, convert, policy)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(trigger.Report), &trigger.Report{
+		Verified:  true,
+		Pipelines: []*trigger.ReportResult{},
+		Skipped: []*trigger.ReportResult{
+			{Name: "test", Line: 1, Reason: "does not match event"},
+		},
+		Errors: []*trigger.ReportError{},
+	}
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) > 0 {
+		t.Error(diff)
+	}
+}
+
+func TestLint_FetchConfig(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := &core.User{ID: 1, Login: "octocat"}
+	repo := &core.Repository{
+		ID:     1,
+		UserID: 1,
+		Slug:   "octocat/hello-world",
+		Branch: "master",
+		Config: ".drone.yml",
+	}
+
+	checkArgs := func(_ context.Context, args *core.ConfigArgs) {
+		if got, want := args.Build.After, "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d"; got != want {
+			t.Errorf("Want config fetched at commit %s, got %s", want, got)
+		}
+		if got, want := args.Build.Ref, "refs/heads/master"; got != want {
+			t.Errorf("Want config fetched at ref %s, got %s", want, got)
+		}
+	}
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(repo, nil)
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Find(gomock.Any(), repo.UserID).Return(user, nil)
+
+	commits := mock.NewMockCommitService(controller)
+	commits.EXPECT().FindRef(gomock.Any(), user, repo.Slug, "master").Return(&core.Commit{Sha: "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d"}, nil)
+
+	config := mock.NewMockConfigService(controller)
+	config.EXPECT().Find(gomock.Any(), gomock.Any()).Do(checkArgs).Return(&core.Config{Data: "kind: pipeline\nname: default\n"}, nil)
+
+	convert := mock.NewMockConvertService(controller)
+	convert.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
+
+	policy := mock.NewMockPolicyService(controller)
+	policy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", bytes.NewBufferString("{}"))
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleLint(users, repos, commits, config, convert, policy)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got := new(trigger.Report)
+	json.NewDecoder(w.Body).Decode(got)
+	if len(got.Pipelines) != 1 || got.Pipelines[0].Name != "default" {
+		t.Errorf("Want default pipeline matched, got %v", got.Pipelines)
+	}
+}
+
+func TestLint_RepoNotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().FindName(gomock.Any(), "octocat", "hello-world").Return(nil, errors.ErrNotFound)
+
+	c := new(chi.Context)
+	c.URLParams.Add("owner", "octocat")
+	c.URLParams.Add("name", "hello-world")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", bytes.NewBufferString("{}"))
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleLint(nil, repos, nil, nil, nil, nil)(w, r)
+	if got, want := w.Code, 404; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"net/http"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/handler/api/request"
 	"github.com/drone/drone/logger"
@@ -41,7 +42,7 @@ type (
 
 // HandleUpdate returns an http.HandlerFunc that processes http
 // requests to update the repository details.
-func HandleUpdate(repos core.RepositoryStore) http.HandlerFunc {
+func HandleUpdate(repos core.RepositoryStore, audits core.AuditService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
 			owner = chi.URLParam(r, "owner")
@@ -60,6 +61,8 @@ func HandleUpdate(repos core.RepositoryStore) http.HandlerFunc {
 			return
 		}
 
+		before := *repo
+
 		in := new(repositoryInput)
 		err = json.NewDecoder(r.Body).Decode(in)
 		if err != nil {
@@ -122,6 +125,7 @@ func HandleUpdate(repos core.RepositoryStore) http.HandlerFunc {
 			return
 		}
 
+		audit.Record(r, audits, core.AuditActionRepoUpdate, repo.Slug, &before, repo)
 		render.JSON(w, repo, 200)
 	}
 }
@@ -66,7 +66,7 @@ func TestUpdate(t *testing.T) {
 		context.WithValue(r.Context(), chi.RouteCtxKey, c),
 	)
 
-	HandleUpdate(repos)(w, r)
+	HandleUpdate(repos, nil)(w, r)
 	if got, want := w.Code, 200; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -110,7 +110,7 @@ func TestUpdate_RepoNotFound(t *testing.T) {
 		context.WithValue(r.Context(), chi.RouteCtxKey, c),
 	)
 
-	HandleUpdate(repos)(w, r)
+	HandleUpdate(repos, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -156,7 +156,7 @@ func TestUpdate_InvalidInput(t *testing.T) {
 		context.WithValue(r.Context(), chi.RouteCtxKey, c),
 	)
 
-	HandleUpdate(repos)(w, r)
+	HandleUpdate(repos, nil)(w, r)
 	if got, want := w.Code, 400; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -209,7 +209,7 @@ func TestUpdate_UpdateFailed(t *testing.T) {
 		context.WithValue(r.Context(), chi.RouteCtxKey, c),
 	)
 
-	HandleUpdate(repos)(w, r)
+	HandleUpdate(repos, nil)(w, r)
 	if got, want := w.Code, 500; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -0,0 +1,45 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package request
+
+import (
+	"net"
+	"net/http"
+)
+
+// RemoteAddr returns the network address of the client. The
+// X-Forwarded-For and X-Real-IP headers are not trusted, since
+// they can be set by the client, and the address is the peer
+// address of the connection.
+func RemoteAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// maxUserAgent is the maximum length of the user agent, which
+// is persisted with sessions and login events.
+const maxUserAgent = 500
+
+// UserAgent returns the client user agent, truncated to the
+// maximum length.
+func UserAgent(r *http.Request) string {
+	if agent := r.UserAgent(); len(agent) > maxUserAgent {
+		return agent[:maxUserAgent]
+	}
+	return r.UserAgent()
+}
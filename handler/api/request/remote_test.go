@@ -0,0 +1,44 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package request
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRemoteAddr(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "172.16.0.1:4321"
+	if got, want := RemoteAddr(r), "172.16.0.1"; got != want {
+		t.Errorf("Want address %q, got %q", want, got)
+	}
+
+	// the forwarding headers can be set by the client and
+	// must be ignored.
+	r.Header.Set("X-Forwarded-For", "10.1.1.1, 172.16.0.1")
+	r.Header.Set("X-Real-IP", "10.1.1.1")
+	if got, want := RemoteAddr(r), "172.16.0.1"; got != want {
+		t.Errorf("Want address %q, got %q", want, got)
+	}
+
+	r.RemoteAddr = "172.16.0.1"
+	if got, want := RemoteAddr(r), "172.16.0.1"; got != want {
+		t.Errorf("Want address %q, got %q", want, got)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("User-Agent", "Mozilla/5.0")
+	if got, want := UserAgent(r), "Mozilla/5.0"; got != want {
+		t.Errorf("Want user agent %q, got %q", want, got)
+	}
+	r.Header.Set("User-Agent", strings.Repeat("a", 600))
+	if got, want := len(UserAgent(r)), 500; got != want {
+		t.Errorf("Want user agent truncated to %d, got %d", want, got)
+	}
+}
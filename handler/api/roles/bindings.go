@@ -0,0 +1,144 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roles
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+type bindingInput struct {
+	User      string `json:"user"`
+	Org       string `json:"org"`
+	Namespace string `json:"namespace"`
+}
+
+// HandleListBindings returns an http.HandlerFunc that writes a
+// json-encoded list of the role bindings to the response body.
+func HandleListBindings(roles core.RoleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role, err := roles.FindName(r.Context(), chi.URLParam(r, "role"))
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot find role")
+			return
+		}
+		list, err := roles.ListBindings(r.Context())
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot list role bindings")
+			return
+		}
+		out := []*core.RoleBinding{}
+		for _, binding := range list {
+			if binding.RoleID == role.ID {
+				out = append(out, binding)
+			}
+		}
+		render.JSON(w, out, 200)
+	}
+}
+
+// HandleCreateBinding returns an http.HandlerFunc that processes
+// http requests to grant the role to a user or organization.
+func HandleCreateBinding(roles core.RoleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in := new(bindingInput)
+		err := json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot unmarshal request body")
+			return
+		}
+
+		role, err := roles.FindName(r.Context(), chi.URLParam(r, "role"))
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot find role")
+			return
+		}
+
+		binding := &core.RoleBinding{
+			RoleID:    role.ID,
+			User:      in.User,
+			Org:       in.Org,
+			Namespace: in.Namespace,
+			Created:   time.Now().Unix(),
+		}
+		err = binding.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = roles.CreateBinding(r.Context(), binding)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot create role binding")
+			return
+		}
+		render.JSON(w, binding, 200)
+	}
+}
+
+// HandleDeleteBinding returns an http.HandlerFunc that processes
+// http requests to revoke the role from a user or organization.
+func HandleDeleteBinding(roles core.RoleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "binding"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		role, err := roles.FindName(r.Context(), chi.URLParam(r, "role"))
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot find role")
+			return
+		}
+		binding, err := roles.FindBinding(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		if binding.RoleID != role.ID {
+			render.NotFound(w, errors.ErrNotFound)
+			return
+		}
+		err = roles.DeleteBinding(r.Context(), binding)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot delete role binding")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roles
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+)
+
+type roleInput struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// HandleCreate returns an http.HandlerFunc that processes http
+// requests to create a role.
+func HandleCreate(roles core.RoleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		in := new(roleInput)
+		err := json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot unmarshal request body")
+			return
+		}
+
+		role := &core.Role{
+			Name:        in.Name,
+			Description: in.Description,
+			Permissions: in.Permissions,
+			Created:     time.Now().Unix(),
+			Updated:     time.Now().Unix(),
+		}
+		err = role.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = roles.Create(r.Context(), role)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot create role")
+			return
+		}
+		render.JSON(w, role, 200)
+	}
+}
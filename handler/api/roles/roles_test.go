@@ -0,0 +1,202 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package roles
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var mockRole = &core.Role{
+	ID:          1,
+	Name:        "release-managers",
+	Permissions: []string{"deploy:approve:prod"},
+}
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	roles := mock.NewMockRoleStore(controller)
+	roles.EXPECT().List(gomock.Any()).Return([]*core.Role{mockRole}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+
+	HandleList(roles).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*core.Role{}, []*core.Role{mockRole}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Error(diff)
+	}
+}
+
+func TestHandleCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	roles := mock.NewMockRoleStore(controller)
+	roles.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&roleInput{
+		Name:        "release-managers",
+		Permissions: []string{"deploy:approve:prod"},
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+
+	HandleCreate(roles).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleCreate_Invalid(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&roleInput{Name: "release-managers"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+
+	HandleCreate(mock.NewMockRoleStore(controller)).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleUpdate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	role := *mockRole
+	roles := mock.NewMockRoleStore(controller)
+	roles.EXPECT().FindName(gomock.Any(), "release-managers").Return(&role, nil)
+	roles.EXPECT().Update(gomock.Any(), &role).Return(nil)
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(map[string]interface{}{
+		"permissions": []string{"deploy:approve"},
+	})
+
+	c := new(chi.Context)
+	c.URLParams.Add("role", "release-managers")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PATCH", "/", in)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleUpdate(roles).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if diff := cmp.Diff(role.Permissions, []string{"deploy:approve"}); len(diff) != 0 {
+		t.Error(diff)
+	}
+}
+
+func TestHandleDelete_NotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	roles := mock.NewMockRoleStore(controller)
+	roles.EXPECT().FindName(gomock.Any(), "release-managers").Return(nil, sql.ErrNoRows)
+
+	c := new(chi.Context)
+	c.URLParams.Add("role", "release-managers")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(roles).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleCreateBinding(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	var created *core.RoleBinding
+	roles := mock.NewMockRoleStore(controller)
+	roles.EXPECT().FindName(gomock.Any(), "release-managers").Return(mockRole, nil)
+	roles.EXPECT().CreateBinding(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, binding *core.RoleBinding) error {
+		created = binding
+		return nil
+	})
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&bindingInput{Org: "octocat", Namespace: "octocat"})
+
+	c := new(chi.Context)
+	c.URLParams.Add("role", "release-managers")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleCreateBinding(roles).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+		return
+	}
+	if got, want := created.RoleID, mockRole.ID; got != want {
+		t.Errorf("Want binding role %d, got %d", want, got)
+	}
+}
+
+// this unit test ensures that a role binding cannot be
+// deleted using the name of a different role.
+func TestHandleDeleteBinding_RoleMismatch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	roles := mock.NewMockRoleStore(controller)
+	roles.EXPECT().FindName(gomock.Any(), "release-managers").Return(mockRole, nil)
+	roles.EXPECT().FindBinding(gomock.Any(), int64(3)).Return(&core.RoleBinding{ID: 3, RoleID: 2}, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("role", "release-managers")
+	c.URLParams.Add("binding", "3")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleDeleteBinding(roles).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceaccounts
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/dchest/uniuri"
+	"github.com/go-chi/chi"
+)
+
+var errAccountExists = errors.New("Service account already exists")
+
+type accountInput struct {
+	Name string `json:"name"`
+}
+
+type accountWithToken struct {
+	*core.User
+	Token string `json:"token"`
+}
+
+// HandleCreate returns an http.HandlerFunc that processes an
+// http.Request to create a service account owned by the named
+// organization. The api token is included in the response.
+func HandleCreate(users core.UserStore, audits core.AuditService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		namespace := chi.URLParam(r, "namespace")
+
+		in := new(accountInput)
+		err := json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot unmarshal request body")
+			return
+		}
+
+		// the name and namespace are validated separately,
+		// since the login includes the @ separator, which is
+		// not permitted in source control usernames. This
+		// prevents service accounts from impersonating users.
+		for _, s := range []string{in.Name, namespace} {
+			if err := (&core.User{Login: s}).Validate(); err != nil {
+				render.BadRequest(w, err)
+				return
+			}
+		}
+
+		login := Login(in.Name, namespace)
+		if _, err := users.FindLogin(r.Context(), login); err == nil {
+			render.ErrorCode(w, errAccountExists, http.StatusConflict)
+			return
+		}
+
+		user := &core.User{
+			Login:     login,
+			Active:    true,
+			Machine:   true,
+			Namespace: namespace,
+			Created:   time.Now().Unix(),
+			Updated:   time.Now().Unix(),
+			Hash:      uniuri.NewLen(32),
+		}
+		err = users.Create(r.Context(), user)
+		if err == core.ErrUserLimit {
+			render.ErrorCode(w, err, 402)
+			logger.FromRequest(r).WithError(err).
+				Errorln("api: cannot create service account")
+			return
+		}
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot create service account")
+			return
+		}
+
+		audit.Record(r, audits, core.AuditActionUserCreate, user.Login, nil, user)
+		render.JSON(w, &accountWithToken{user, user.Hash}, 200)
+	}
+}
+
+// Login returns the login of the named service account owned
+// by the organization.
+func Login(name, namespace string) string {
+	return name + "@" + namespace
+}
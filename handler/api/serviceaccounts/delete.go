@@ -0,0 +1,63 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serviceaccounts
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleDelete returns an http.HandlerFunc that processes an
+// http.Request to delete the named organization service account.
+func HandleDelete(users core.UserStore, audits core.AuditService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			namespace = chi.URLParam(r, "namespace")
+			name      = chi.URLParam(r, "name")
+		)
+		user, err := users.FindLogin(r.Context(), Login(name, namespace))
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot find service account")
+			return
+		}
+		// the account must be owned by the organization, and
+		// the existence of other accounts is not disclosed.
+		if !strings.EqualFold(user.Namespace, namespace) {
+			render.NotFound(w, errors.ErrNotFound)
+			return
+		}
+
+		err = users.Delete(r.Context(), user)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot delete service account")
+			return
+		}
+
+		audit.Record(r, audits, core.AuditActionUserDelete, user.Login, user, nil)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package serviceaccounts
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+)
+
+var mockAccount = &core.User{
+	ID:        2,
+	Login:     "release@octocat",
+	Machine:   true,
+	Active:    true,
+	Namespace: "octocat",
+}
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().List(gomock.Any()).Return([]*core.User{
+		{ID: 1, Login: "octocat"},
+		mockAccount,
+		{ID: 3, Login: "release@spaceghost", Machine: true, Namespace: "spaceghost"},
+	}, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "octocat")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleList(users).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	out := []*core.User{}
+	json.NewDecoder(w.Body).Decode(&out)
+	if len(out) != 1 || out[0].Login != mockAccount.Login {
+		t.Errorf("Want only the organization service accounts")
+	}
+}
+
+func TestHandleCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	var created *core.User
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), "release@octocat").Return(nil, sql.ErrNoRows)
+	users.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user *core.User) error {
+		created = user
+		return nil
+	})
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&accountInput{Name: "release"})
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "octocat")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(users, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+		return
+	}
+	if !created.Machine || created.Admin {
+		t.Errorf("Want non-admin machine account")
+	}
+	if got, want := created.Namespace, "octocat"; got != want {
+		t.Errorf("Want namespace %q, got %q", want, got)
+	}
+
+	out := map[string]interface{}{}
+	json.NewDecoder(w.Body).Decode(&out)
+	if got, want := out["token"], created.Hash; got != want {
+		t.Errorf("Want token returned in response")
+	}
+}
+
+func TestHandleCreate_Exists(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), "release@octocat").Return(mockAccount, nil)
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&accountInput{Name: "release"})
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "octocat")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(users, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusConflict; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleCreate_InvalidName(t *testing.T) {
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&accountInput{Name: "octocat@spaceghost"})
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "octocat")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleCreate(nil, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleDelete(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), "release@octocat").Return(mockAccount, nil)
+	users.EXPECT().Delete(gomock.Any(), mockAccount).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "octocat")
+	c.URLParams.Add("name", "release")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(users, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNoContent; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that an account that is not owned by the
+// organization cannot be deleted.
+func TestHandleDelete_NotOwner(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), "release@octocat").Return(&core.User{Login: "release@octocat"}, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("namespace", "octocat")
+	c.URLParams.Add("name", "release")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(r.Context(), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(users, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
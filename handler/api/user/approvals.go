@@ -0,0 +1,134 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+)
+
+type pendingApproval struct {
+	Repo      string `json:"repo"`
+	Build     int64  `json:"build"`
+	Stage     int    `json:"stage"`
+	Name      string `json:"name"`
+	Target    string `json:"target,omitempty"`
+	Approvals int    `json:"approvals"`
+	Required  int    `json:"required"`
+}
+
+// HandleApprovals returns an http.HandlerFunc that writes a
+// json-encoded list of blocked stages pending approval by the
+// currently authenticated user.
+func HandleApprovals(
+	repos core.RepositoryStore,
+	builds core.BuildStore,
+	stages core.StageStore,
+	perms core.PermStore,
+	approvals core.ApprovalStore,
+	approvalz core.ApprovalService,
+	roles core.RoleService,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		viewer, _ := request.UserFrom(ctx)
+		blocked, err := stages.ListState(ctx, core.StatusBlocked)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot list blocked stages")
+			return
+		}
+
+		list := []*pendingApproval{}
+		for _, stage := range blocked {
+			build, err := builds.Find(ctx, stage.BuildID)
+			if err != nil {
+				continue
+			}
+			repo, err := repos.Find(ctx, build.RepoID)
+			if err != nil {
+				continue
+			}
+			if !canApprove(ctx, perms, roles, viewer, repo, build) {
+				continue
+			}
+			rule, err := approvalz.Rule(ctx, repo, build)
+			if err != nil {
+				logger.FromRequest(r).WithError(err).
+					Warnln("api: cannot resolve approval rule")
+				continue
+			}
+			if approvalz.Authorize(ctx, viewer, repo, build, rule) != nil {
+				continue
+			}
+			reviews, err := approvals.List(ctx, stage.ID)
+			if err != nil || reviewed(viewer, reviews) {
+				continue
+			}
+			list = append(list, &pendingApproval{
+				Repo:      repo.Slug,
+				Build:     build.Number,
+				Stage:     stage.Number,
+				Name:      stage.Name,
+				Target:    build.Deploy,
+				Approvals: countApproved(reviews),
+				Required:  rule.Approvals,
+			})
+		}
+		render.JSON(w, list, 200)
+	}
+}
+
+// canApprove returns true if the user is permitted to approve
+// the build, mirroring the access checks applied to the approve
+// endpoint.
+func canApprove(ctx context.Context, perms core.PermStore, roles core.RoleService, user *core.User, repo *core.Repository, build *core.Build) bool {
+	if user.Admin {
+		return true
+	}
+	if perm, err := perms.Find(ctx, repo.UID, user.ID); err == nil && perm.Admin {
+		return true
+	}
+	if roles == nil || build.Deploy == "" {
+		return false
+	}
+	ok, _ := roles.Permits(ctx, user, repo.Namespace, core.PermissionDeployApprove+":"+build.Deploy)
+	return ok
+}
+
+func reviewed(user *core.User, approvals []*core.Approval) bool {
+	for _, approval := range approvals {
+		if strings.EqualFold(approval.Approver, user.Login) {
+			return true
+		}
+	}
+	return false
+}
+
+func countApproved(approvals []*core.Approval) (n int) {
+	for _, approval := range approvals {
+		if approval.Approved {
+			n++
+		}
+	}
+	return n
+}
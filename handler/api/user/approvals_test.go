@@ -0,0 +1,117 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package user
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApprovals(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Login: "octocat"}
+	mockRepo := &core.Repository{ID: 2, UID: "42", Namespace: "octocat", Slug: "octocat/hello-world"}
+	mockBuild := &core.Build{ID: 3, RepoID: 2, Number: 4, Author: "spaceghost", Deploy: "production"}
+	mockStages := []*core.Stage{
+		{ID: 5, BuildID: 3, Number: 1, Name: "deploy"},
+		{ID: 6, BuildID: 3, Number: 2, Name: "verify"},
+	}
+	mockRule := &core.ApprovalRule{Approvals: 2}
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().ListState(gomock.Any(), core.StatusBlocked).Return(mockStages, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Find(gomock.Any(), mockBuild.ID).Return(mockBuild, nil).Times(2)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), mockRepo.ID).Return(mockRepo, nil).Times(2)
+
+	perms := mock.NewMockPermStore(controller)
+	perms.EXPECT().Find(gomock.Any(), mockRepo.UID, mockUser.ID).Return(&core.Perm{Admin: true}, nil).Times(2)
+
+	approvalz := mock.NewMockApprovalService(controller)
+	approvalz.EXPECT().Rule(gomock.Any(), mockRepo, mockBuild).Return(mockRule, nil).Times(2)
+	approvalz.EXPECT().Authorize(gomock.Any(), mockUser, mockRepo, mockBuild, mockRule).Return(nil).Times(2)
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().List(gomock.Any(), int64(5)).Return([]*core.Approval{{Approver: "janecitizen", Approved: true}}, nil)
+	approvals.EXPECT().List(gomock.Any(), int64(6)).Return([]*core.Approval{{Approver: "octocat", Approved: true}}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/user/approvals", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleApprovals(repos, builds, stages, perms, approvals, approvalz, nil)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*pendingApproval{}, []*pendingApproval{
+		{
+			Repo:      "octocat/hello-world",
+			Build:     4,
+			Stage:     1,
+			Name:      "deploy",
+			Target:    "production",
+			Approvals: 1,
+			Required:  2,
+		},
+	}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Error(diff)
+	}
+}
+
+func TestApprovals_NotPermitted(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Login: "octocat"}
+	mockRepo := &core.Repository{ID: 2, UID: "42", Namespace: "octocat"}
+	mockBuild := &core.Build{ID: 3, RepoID: 2, Deploy: "production"}
+
+	stages := mock.NewMockStageStore(controller)
+	stages.EXPECT().ListState(gomock.Any(), core.StatusBlocked).Return([]*core.Stage{{ID: 5, BuildID: 3}}, nil)
+
+	builds := mock.NewMockBuildStore(controller)
+	builds.EXPECT().Find(gomock.Any(), mockBuild.ID).Return(mockBuild, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().Find(gomock.Any(), mockRepo.ID).Return(mockRepo, nil)
+
+	perms := mock.NewMockPermStore(controller)
+	perms.EXPECT().Find(gomock.Any(), mockRepo.UID, mockUser.ID).Return(&core.Perm{Write: true}, nil)
+
+	roles := mock.NewMockRoleService(controller)
+	roles.EXPECT().Permits(gomock.Any(), mockUser, "octocat", "deploy:approve:production").Return(false, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/user/approvals", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleApprovals(repos, builds, stages, perms, nil, nil, roles)(w, r)
+	if got, want := w.Code, 200; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if got, want := w.Body.String(), "[]\n"; got != want {
+		t.Errorf("Want empty list, got %q", got)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessions
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleDelete returns an http.HandlerFunc that processes http
+// requests to revoke a session.
+func HandleDelete(sessions core.SessionStore, session core.Session) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		viewer, _ := request.UserFrom(r.Context())
+		id, err := strconv.ParseInt(chi.URLParam(r, "session"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		record, err := sessions.Find(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		// a user can only revoke their own sessions, and the
+		// existence of other users' sessions is not disclosed.
+		if record.UserID != viewer.ID {
+			render.NotFound(w, errors.ErrNotFound)
+			return
+		}
+		err = sessions.Delete(r.Context(), record)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot revoke session")
+			return
+		}
+		session.Revoke(viewer.ID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	mockUser = &core.User{
+		ID:    1,
+		Login: "octocat",
+	}
+
+	mockSession = &core.UserSession{
+		ID:     1,
+		UserID: 1,
+		Hash:   "2a4a3e2b4d6d8b9c",
+		Device: "Mozilla/5.0",
+		IP:     "192.168.1.1",
+	}
+)
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	sessions := mock.NewMockSessionStore(controller)
+	sessions.EXPECT().List(gomock.Any(), mockUser.ID).Return([]*core.UserSession{mockSession}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleList(sessions).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := []*core.UserSession{}, []*core.UserSession{{ID: 1, UserID: 1, Device: "Mozilla/5.0", IP: "192.168.1.1"}}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Error(diff)
+	}
+}
+
+func TestHandleDelete(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	sessions := mock.NewMockSessionStore(controller)
+	sessions.EXPECT().Find(gomock.Any(), mockSession.ID).Return(mockSession, nil)
+	sessions.EXPECT().Delete(gomock.Any(), mockSession).Return(nil)
+
+	session := mock.NewMockSession(controller)
+	session.EXPECT().Revoke(mockUser.ID)
+
+	c := new(chi.Context)
+	c.URLParams.Add("session", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(sessions, session).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNoContent; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that a user cannot revoke a session that
+// belongs to another user.
+func TestHandleDelete_NotOwner(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	sessions := mock.NewMockSessionStore(controller)
+	sessions.EXPECT().Find(gomock.Any(), mockSession.ID).Return(&core.UserSession{ID: 1, UserID: 2}, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("session", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(sessions, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleDelete_NotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	sessions := mock.NewMockSessionStore(controller)
+	sessions.EXPECT().Find(gomock.Any(), int64(2)).Return(nil, sql.ErrNoRows)
+
+	c := new(chi.Context)
+	c.URLParams.Add("session", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(sessions, nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokens
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+
+	"github.com/dchest/uniuri"
+)
+
+type tokenInput struct {
+	Name      string `json:"name"`
+	Scope     string `json:"scope"`
+	Repo      string `json:"repo"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+type tokenWithValue struct {
+	*core.Token
+	Value string `json:"token"`
+}
+
+// HandleCreate returns an http.HandlerFunc that processes http
+// requests to create a personal access token. The token value
+// is only returned in the response body of this request.
+func HandleCreate(tokens core.TokenStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		viewer, _ := request.UserFrom(r.Context())
+
+		in := new(tokenInput)
+		err := json.NewDecoder(r.Body).Decode(in)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		value := uniuri.NewLen(32)
+		token := &core.Token{
+			UserID:  viewer.ID,
+			Name:    in.Name,
+			Hash:    core.HashToken(value),
+			Scope:   in.Scope,
+			Repo:    in.Repo,
+			Created: time.Now().Unix(),
+		}
+		if token.Scope == "" {
+			token.Scope = core.TokenScopeRead
+		}
+		if in.ExpiresIn > 0 {
+			token.Expires = token.Created + in.ExpiresIn
+		}
+
+		err = token.Validate()
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+
+		err = tokens.Create(r.Context(), token)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot create token")
+			return
+		}
+		render.JSON(w, &tokenWithValue{token, value}, 200)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokens
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/errors"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleDelete returns an http.HandlerFunc that processes http
+// requests to revoke a personal access token.
+func HandleDelete(tokens core.TokenStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		viewer, _ := request.UserFrom(r.Context())
+		id, err := strconv.ParseInt(chi.URLParam(r, "token"), 10, 64)
+		if err != nil {
+			render.BadRequest(w, err)
+			return
+		}
+		token, err := tokens.Find(r.Context(), id)
+		if err != nil {
+			render.NotFound(w, err)
+			return
+		}
+		// a user can only revoke their own tokens, and the
+		// existence of other users' tokens is not disclosed.
+		if token.UserID != viewer.ID {
+			render.NotFound(w, errors.ErrNotFound)
+			return
+		}
+		err = tokens.Delete(r.Context(), token)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot revoke token")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
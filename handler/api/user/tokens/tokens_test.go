@@ -0,0 +1,225 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package tokens
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	mockUser = &core.User{
+		ID:    1,
+		Login: "octocat",
+	}
+
+	mockToken = &core.Token{
+		ID:     1,
+		UserID: 1,
+		Name:   "deploy-script",
+		Hash:   "2a4a3e2b4d6d8b9c",
+		Scope:  core.TokenScopeRead,
+	}
+)
+
+func TestHandleList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tokens := mock.NewMockTokenStore(controller)
+	tokens.EXPECT().List(gomock.Any(), mockUser.ID).Return([]*core.Token{mockToken}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleList(tokens).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte(mockToken.Hash)) {
+		t.Errorf("Want token hash omitted from response")
+	}
+
+	got, want := []*core.Token{}, []*core.Token{{ID: 1, UserID: 1, Name: "deploy-script", Scope: "read"}}
+	json.NewDecoder(w.Body).Decode(&got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Error(diff)
+	}
+}
+
+func TestHandleCreate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	var created *core.Token
+	tokens := mock.NewMockTokenStore(controller)
+	tokens.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token *core.Token) error {
+		created = token
+		return nil
+	})
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&tokenInput{
+		Name:      "deploy-script",
+		Scope:     core.TokenScopeWrite,
+		Repo:      "octocat/hello-world",
+		ExpiresIn: 3600,
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleCreate(tokens).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+		return
+	}
+
+	out := new(tokenWithValue)
+	json.NewDecoder(w.Body).Decode(out)
+	if out.Value == "" {
+		t.Errorf("Want token value returned")
+	}
+	if got, want := created.Hash, core.HashToken(out.Value); got != want {
+		t.Errorf("Want token persisted as hash")
+	}
+	if got, want := created.UserID, mockUser.ID; got != want {
+		t.Errorf("Want token user %d, got %d", want, got)
+	}
+	if got, want := created.Expires-created.Created, int64(3600); got != want {
+		t.Errorf("Want token expiry %d, got %d", want, got)
+	}
+}
+
+func TestHandleCreate_DefaultScope(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tokens := mock.NewMockTokenStore(controller)
+	tokens.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&tokenInput{Name: "dashboard"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleCreate(tokens).ServeHTTP(w, r)
+	out := new(tokenWithValue)
+	json.NewDecoder(w.Body).Decode(out)
+	if got, want := out.Scope, core.TokenScopeRead; got != want {
+		t.Errorf("Want default scope %q, got %q", want, got)
+	}
+	if got, want := out.Expires, int64(0); got != want {
+		t.Errorf("Want no expiry, got %d", got)
+	}
+}
+
+func TestHandleCreate_BadRequest(t *testing.T) {
+	in := new(bytes.Buffer)
+	json.NewEncoder(in).Encode(&tokenInput{Name: "dashboard", Scope: "admin"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", in)
+	r = r.WithContext(
+		request.WithUser(r.Context(), mockUser),
+	)
+
+	HandleCreate(nil).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusBadRequest; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleDelete(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tokens := mock.NewMockTokenStore(controller)
+	tokens.EXPECT().Find(gomock.Any(), mockToken.ID).Return(mockToken, nil)
+	tokens.EXPECT().Delete(gomock.Any(), mockToken).Return(nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("token", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(tokens).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNoContent; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that a user cannot revoke a token that
+// belongs to another user.
+func TestHandleDelete_NotOwner(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tokens := mock.NewMockTokenStore(controller)
+	tokens.EXPECT().Find(gomock.Any(), mockToken.ID).Return(&core.Token{ID: 1, UserID: 2}, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("token", "1")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(tokens).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
+
+func TestHandleDelete_NotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tokens := mock.NewMockTokenStore(controller)
+	tokens.EXPECT().Find(gomock.Any(), int64(2)).Return(nil, sql.ErrNoRows)
+
+	c := new(chi.Context)
+	c.URLParams.Add("token", "2")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r = r.WithContext(
+		context.WithValue(request.WithUser(r.Context(), mockUser), chi.RouteCtxKey, c),
+	)
+
+	HandleDelete(tokens).ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
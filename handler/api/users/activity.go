@@ -0,0 +1,77 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package users
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+type userActivity struct {
+	Logins  []*core.LoginEvent `json:"logins"`
+	Actions []*core.AuditEvent `json:"actions"`
+}
+
+// HandleActivity returns an http.HandlerFunc that writes a
+// json-encoded summary of the named user's recent login
+// attempts and api actions to the response body.
+func HandleActivity(
+	users core.UserStore,
+	logins core.LoginStore,
+	audits core.AuditStore,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		size, _ := strconv.Atoi(r.FormValue("per_page"))
+		if size < 1 || size > 100 {
+			size = 25
+		}
+
+		login := chi.URLParam(r, "user")
+		user, err := users.FindLogin(r.Context(), login)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot find user")
+			return
+		}
+
+		out := new(userActivity)
+		out.Logins, err = logins.List(r.Context(), user.Login, size)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot list login events")
+			return
+		}
+		out.Actions, err = audits.List(r.Context(), core.AuditParams{
+			Actor: user.Login,
+			Page:  1,
+			Size:  size,
+		})
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot list audit events")
+			return
+		}
+		render.JSON(w, out, 200)
+	}
+}
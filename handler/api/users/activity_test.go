@@ -0,0 +1,85 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package users
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestActivity(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockLogins := []*core.LoginEvent{
+		{ID: 2, UserID: mockUser.ID, Login: "octocat", Provider: "scm", Success: true},
+		{ID: 1, Login: "octocat", Provider: "scm", Reason: "User registration is disabled"},
+	}
+	mockActions := []*core.AuditEvent{
+		{ID: 1, Actor: "octocat", Action: core.AuditActionBuildCancel, Target: "octocat/hello-world#1"},
+	}
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), mockUser.Login).Return(mockUser, nil)
+
+	logins := mock.NewMockLoginStore(controller)
+	logins.EXPECT().List(gomock.Any(), mockUser.Login, 10).Return(mockLogins, nil)
+
+	audits := mock.NewMockAuditStore(controller)
+	audits.EXPECT().List(gomock.Any(), core.AuditParams{Actor: "octocat", Page: 1, Size: 10}).Return(mockActions, nil)
+
+	c := new(chi.Context)
+	c.URLParams.Add("user", "octocat")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/?per_page=10", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleActivity(users, logins, audits)(w, r)
+	if got, want := w.Code, http.StatusOK; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+
+	got, want := new(userActivity), &userActivity{Logins: mockLogins, Actions: mockActions}
+	json.NewDecoder(w.Body).Decode(got)
+	if diff := cmp.Diff(got, want); len(diff) != 0 {
+		t.Error(diff)
+	}
+}
+
+func TestActivity_NotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), mockUser.Login).Return(nil, sql.ErrNoRows)
+
+	c := new(chi.Context)
+	c.URLParams.Add("user", "octocat")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(
+		context.WithValue(context.Background(), chi.RouteCtxKey, c),
+	)
+
+	HandleActivity(users, nil, nil)(w, r)
+	if got, want := w.Code, http.StatusNotFound; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/dchest/uniuri"
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/logger"
 )
@@ -32,7 +33,7 @@ type userWithToken struct {
 
 // HandleCreate returns an http.HandlerFunc that processes an http.Request
 // to create the named user account in the system.
-func HandleCreate(users core.UserStore, sender core.WebhookSender) http.HandlerFunc {
+func HandleCreate(users core.UserStore, sender core.WebhookSender, audits core.AuditService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		in := new(core.User)
 		err := json.NewDecoder(r.Body).Decode(in)
@@ -72,6 +73,8 @@ func HandleCreate(users core.UserStore, sender core.WebhookSender) http.HandlerF
 			return
 		}
 
+		audit.Record(r, audits, core.AuditActionUserCreate, user.Login, nil, user)
+
 		err = sender.Send(r.Context(), &core.WebhookData{
 			Event:  core.WebhookEventUser,
 			Action: core.WebhookActionCreated,
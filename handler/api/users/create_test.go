@@ -43,7 +43,7 @@ func TestCreate(t *testing.T) {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("POST", "/", in)
 
-	HandleCreate(users, webhook)(w, r)
+	HandleCreate(users, webhook, nil)(w, r)
 	if got, want := w.Code, 200; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -72,7 +72,7 @@ func TestCreate_BadRequest(t *testing.T) {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("POST", "/", in)
 
-	HandleCreate(nil, nil)(w, r)
+	HandleCreate(nil, nil, nil)(w, r)
 	if got, want := w.Code, http.StatusBadRequest; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -98,7 +98,7 @@ func TestCreateError(t *testing.T) {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("POST", "/", in)
 
-	HandleCreate(users, webhook)(w, r)
+	HandleCreate(users, webhook, nil)(w, r)
 	if got, want := w.Code, http.StatusInternalServerError; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/logger"
 
@@ -29,6 +30,7 @@ import (
 func HandleDelete(
 	users core.UserStore,
 	sender core.WebhookSender,
+	audits core.AuditService,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		login := chi.URLParam(r, "user")
@@ -48,6 +50,8 @@ func HandleDelete(
 			return
 		}
 
+		audit.Record(r, audits, core.AuditActionUserDelete, user.Login, user, nil)
+
 		err = sender.Send(r.Context(), &core.WebhookData{
 			Event:  core.WebhookEventUser,
 			Action: core.WebhookActionDeleted,
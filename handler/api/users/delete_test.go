@@ -37,7 +37,7 @@ func TestUserDelete(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleDelete(users, webhook)(w, r)
+	HandleDelete(users, webhook, nil)(w, r)
 	if got, want := w.Body.Len(), 0; want != got {
 		t.Errorf("Want response body size %d, got %d", want, got)
 	}
@@ -64,7 +64,7 @@ func TestUserDelete_NotFound(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleDelete(users, webhook)(w, r)
+	HandleDelete(users, webhook, nil)(w, r)
 	if got, want := w.Code, 404; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
@@ -89,7 +89,7 @@ func TestUserDelete_InternalError(t *testing.T) {
 		context.WithValue(context.Background(), chi.RouteCtxKey, c),
 	)
 
-	HandleDelete(users, webhook)(w, r)
+	HandleDelete(users, webhook, nil)(w, r)
 	if got, want := w.Code, http.StatusInternalServerError; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
 	}
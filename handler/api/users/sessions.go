@@ -0,0 +1,81 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package users
+
+import (
+	"net/http"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
+	"github.com/drone/drone/handler/api/render"
+	"github.com/drone/drone/logger"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleListSessions returns an http.HandlerFunc that writes a
+// json-encoded list of the named user's sessions to the response
+// body.
+func HandleListSessions(users core.UserStore, sessions core.SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login := chi.URLParam(r, "user")
+		user, err := users.FindLogin(r.Context(), login)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot find user")
+			return
+		}
+		list, err := sessions.List(r.Context(), user.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot list sessions")
+			return
+		}
+		render.JSON(w, list, 200)
+	}
+}
+
+// HandleDeleteSessions returns an http.HandlerFunc that processes
+// an http.Request to revoke all sessions for the named user,
+// logging the user out everywhere.
+func HandleDeleteSessions(
+	users core.UserStore,
+	sessions core.SessionStore,
+	session core.Session,
+	audits core.AuditService,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login := chi.URLParam(r, "user")
+		user, err := users.FindLogin(r.Context(), login)
+		if err != nil {
+			render.NotFound(w, err)
+			logger.FromRequest(r).WithError(err).
+				Debugln("api: cannot find user")
+			return
+		}
+		err = sessions.DeleteUser(r.Context(), user.ID)
+		if err != nil {
+			render.InternalError(w, err)
+			logger.FromRequest(r).WithError(err).
+				Warnln("api: cannot revoke sessions")
+			return
+		}
+		session.Revoke(user.ID)
+		audit.Record(r, audits, core.AuditActionUserLogout, user.Login, nil, nil)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"net/http"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/audit"
 	"github.com/drone/drone/handler/api/render"
 	"github.com/drone/drone/logger"
 
@@ -32,7 +33,7 @@ type userInput struct {
 
 // HandleUpdate returns an http.HandlerFunc that processes an http.Request
 // to update a user account.
-func HandleUpdate(users core.UserStore) http.HandlerFunc {
+func HandleUpdate(users core.UserStore, audits core.AuditService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		login := chi.URLParam(r, "user")
 
@@ -53,6 +54,7 @@ func HandleUpdate(users core.UserStore) http.HandlerFunc {
 			return
 		}
 
+		before := *user
 		if in.Admin != nil {
 			user.Admin = *in.Admin
 		}
@@ -71,6 +73,7 @@ func HandleUpdate(users core.UserStore) http.HandlerFunc {
 			logger.FromRequest(r).WithError(err).
 				Warnln("api: cannot update user")
 		} else {
+			audit.Record(r, audits, core.AuditActionUserUpdate, user.Login, &before, user)
 			render.JSON(w, user, 200)
 		}
 	}
@@ -39,11 +39,18 @@ func init() {
 	)
 }
 
+// fileCache is implemented by a file service that is wrapped
+// with a cache.
+type fileCache interface {
+	Invalidate(repo string)
+}
+
 // HandleHook returns an http.HandlerFunc that handles webhooks
 // triggered by source code management.
 func HandleHook(
 	repos core.RepositoryStore,
 	builds core.BuildStore,
+	files core.FileService,
 	triggerer core.Triggerer,
 	parser core.HookParser,
 ) http.HandlerFunc {
@@ -241,6 +248,15 @@ func HandleHook(
 			return
 		}
 
+		// files requested by branch or tag name, such as shared
+		// templates, are removed from the cache when the
+		// repository receives a push.
+		if hook.Event == core.EventPush || hook.Event == core.EventTag {
+			if cache, ok := files.(fileCache); ok {
+				cache.Invalidate(repo.Slug)
+			}
+		}
+
 		if !repo.Active {
 			log.Debugln("ignore webhook, repository inactive")
 			w.WriteHeader(200)
@@ -37,6 +37,11 @@ var syncPeriod = time.Hour * 24 * 7
 // period at which the sync should timeout
 var syncTimeout = time.Minute * 30
 
+var (
+	errLoginMachine  = errors.New("Machine account login is forbidden")
+	errLoginInactive = errors.New("Account is not active")
+)
+
 // HandleLogin creates and http.HandlerFunc that handles user
 // authentication and session initialization.
 func HandleLogin(
@@ -44,6 +49,7 @@ func HandleLogin(
 	userz core.UserService,
 	syncer core.Syncer,
 	session core.Session,
+	logins core.LoginStore,
 	admission core.AdmissionService,
 	sender core.WebhookSender,
 ) http.HandlerFunc {
@@ -95,6 +101,7 @@ func HandleLogin(
 			err = admission.Admit(ctx, user)
 			if err != nil {
 				writeLoginError(w, r, err)
+				recordLogin(r, logins, loginProviderSCM, account.Login, nil, err)
 				logger.Errorf("cannot admit user: %s", err)
 				return
 			}
@@ -102,6 +109,7 @@ func HandleLogin(
 			err = users.Create(ctx, user)
 			if err != nil {
 				writeLoginError(w, r, err)
+				recordLogin(r, logins, loginProviderSCM, account.Login, nil, err)
 				logger.Errorf("cannot create user: %s", err)
 				return
 			}
@@ -118,17 +126,20 @@ func HandleLogin(
 			}
 		} else if err != nil {
 			writeLoginError(w, r, err)
+			recordLogin(r, logins, loginProviderSCM, account.Login, nil, err)
 			logger.Errorf("cannot find user: %s", err)
 			return
 		}
 
 		if user.Machine {
-			writeLoginErrorStr(w, r, "Machine account login is forbidden")
+			writeLoginError(w, r, errLoginMachine)
+			recordLogin(r, logins, loginProviderSCM, account.Login, user, errLoginMachine)
 			return
 		}
 
 		if user.Active == false {
-			writeLoginErrorStr(w, r, "Account is not active")
+			writeLoginError(w, r, errLoginInactive)
+			recordLogin(r, logins, loginProviderSCM, account.Login, user, errLoginInactive)
 			return
 		}
 
@@ -164,7 +175,14 @@ func HandleLogin(
 
 		logger.Debugf("authentication successful")
 
-		session.Create(w, user)
+		err = session.Create(w, r, user)
+		if err != nil {
+			writeLoginError(w, r, err)
+			recordLogin(r, logins, loginProviderSCM, account.Login, user, err)
+			logger.Errorf("cannot create session: %s", err)
+			return
+		}
+		recordLogin(r, logins, loginProviderSCM, account.Login, user, nil)
 		http.Redirect(w, r, "/", 303)
 	}
 }
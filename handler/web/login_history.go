@@ -0,0 +1,65 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
+
+	"github.com/sirupsen/logrus"
+)
+
+// login providers recorded in the login history.
+const (
+	loginProviderSCM  = "scm"
+	loginProviderOIDC = "oidc"
+)
+
+// maxReason is the maximum length of the recorded reason a
+// login attempt failed.
+const maxReason = 2000
+
+// recordLogin records the login attempt in the login history.
+// A nil error records a successful login. If the login store
+// is nil, this is a no-op.
+func recordLogin(r *http.Request, logins core.LoginStore, provider, login string, user *core.User, err error) {
+	if logins == nil {
+		return
+	}
+	event := &core.LoginEvent{
+		Login:    login,
+		Provider: provider,
+		Success:  err == nil,
+		IP:       request.RemoteAddr(r),
+		Agent:    request.UserAgent(r),
+		Created:  time.Now().Unix(),
+	}
+	if user != nil {
+		event.UserID = user.ID
+	}
+	if err != nil {
+		event.Reason = err.Error()
+		if len(event.Reason) > maxReason {
+			event.Reason = event.Reason[:maxReason]
+		}
+	}
+	if err := logins.Create(r.Context(), event); err != nil {
+		logrus.WithField("login", login).
+			Warnf("cannot record login: %s", err)
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/web/oidc"
+	"github.com/drone/go-login/login"
+
+	"github.com/dchest/uniuri"
+	"github.com/sirupsen/logrus"
+)
+
+// name of the cookie used to store the oidc state, nonce
+// and code verifier for the duration of the login flow.
+const oidcCookie = "_oidc_state"
+
+var (
+	// errLoginIdentity is returned when the user matched by
+	// login is already linked to a different identity.
+	errLoginIdentity = errors.New("Account is linked to a different identity")
+
+	// errLoginConflict is returned when a user with the same
+	// login exists, but the identity cannot be linked to the
+	// user.
+	errLoginConflict = errors.New("Account already exists and is not linked to this identity")
+)
+
+// HandleOIDC creates an http.HandlerFunc that authenticates the
+// user with an OpenID Connect identity provider. If the user has
+// not yet linked a source control account, the user is redirected
+// to the source control login to link the account.
+//
+// Users are matched by the issuer and subject claims, which are
+// stable for the life of the identity provider account. Since the
+// login may be changed by the user, an existing user with the same
+// login is only linked to the identity on first login if linking
+// by login is enabled, or if the identity provider verified the
+// user email address. Otherwise the login is rejected.
+func HandleOIDC(
+	provider *oidc.Provider,
+	users core.UserStore,
+	identities core.IdentityStore,
+	session core.Session,
+	logins core.LoginStore,
+	admission core.AdmissionService,
+	sender core.WebhookSender,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if erro := r.FormValue("error"); erro != "" {
+			if desc := r.FormValue("error_description"); desc != "" {
+				erro = desc
+			}
+			writeLoginErrorStr(w, r, erro)
+			logrus.Debugf("oidc: cannot authenticate user: %s", erro)
+			return
+		}
+
+		// if the authorization code is not included in the
+		// request the user is redirected to the identity
+		// provider to authenticate.
+		code := r.FormValue("code")
+		if code == "" {
+			state := uniuri.NewLen(32)
+			nonce := uniuri.NewLen(32)
+			verifier := uniuri.NewLen(64)
+			redirect, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+			if err != nil {
+				writeLoginError(w, r, err)
+				logrus.Errorf("oidc: cannot discover identity provider: %s", err)
+				return
+			}
+			writeCookie(w, &http.Cookie{
+				Name:     oidcCookie,
+				Value:    strings.Join([]string{state, nonce, verifier}, "."),
+				Path:     "/login/oidc",
+				MaxAge:   600,
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+			})
+			http.Redirect(w, r, redirect, 303)
+			return
+		}
+
+		state, nonce, verifier, err := readOIDCState(r)
+		if err != nil {
+			writeLoginError(w, r, err)
+			logrus.Debugf("oidc: %s", err)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(state), []byte(r.FormValue("state"))) != 1 {
+			writeLoginErrorStr(w, r, "Invalid login state")
+			logrus.Debugln("oidc: state mismatch")
+			return
+		}
+		writeCookie(w, &http.Cookie{
+			Name:   oidcCookie,
+			Path:   "/login/oidc",
+			MaxAge: -1,
+		})
+
+		claims, err := provider.Exchange(ctx, code, nonce, verifier)
+		if err != nil {
+			writeLoginError(w, r, err)
+			logrus.Debugf("oidc: cannot exchange code: %s", err)
+			return
+		}
+
+		login := provider.Login(claims)
+		if login == "" {
+			writeLoginErrorStr(w, r, "Identity provider did not return a login")
+			return
+		}
+		issuer, subject := claims.String("iss"), claims.String("sub")
+		if subject == "" {
+			writeLoginErrorStr(w, r, "Identity provider did not return a subject")
+			return
+		}
+
+		logger := logrus.WithField("login", login)
+		logger.Debugf("oidc: attempting authentication")
+
+		admin, managed := provider.Admin(claims)
+
+		var user *core.User
+		identity, err := identities.Find(ctx, issuer, subject)
+		linked := err == nil
+		switch {
+		case linked:
+			user, err = users.Find(ctx, identity.UserID)
+		case err == sql.ErrNoRows:
+			user, err = users.FindLogin(ctx, login)
+		}
+		if err == nil && !linked && !canLink(provider, claims, user) {
+			writeLoginError(w, r, errLoginConflict)
+			recordLogin(r, logins, loginProviderOIDC, login, nil, errLoginConflict)
+			logger.Warnf("oidc: cannot link identity to existing user")
+			return
+		}
+		if err == sql.ErrNoRows && !linked {
+			user = &core.User{
+				Login:     login,
+				Email:     claims.String("email"),
+				Avatar:    claims.String("picture"),
+				Admin:     admin,
+				Machine:   false,
+				Active:    true,
+				LastLogin: time.Now().Unix(),
+				Created:   time.Now().Unix(),
+				Updated:   time.Now().Unix(),
+				Hash:      uniuri.NewLen(32),
+			}
+
+			err = admission.Admit(ctx, user)
+			if err != nil {
+				writeLoginError(w, r, err)
+				recordLogin(r, logins, loginProviderOIDC, login, nil, err)
+				logger.Errorf("oidc: cannot admit user: %s", err)
+				return
+			}
+
+			err = users.Create(ctx, user)
+			if err != nil {
+				writeLoginError(w, r, err)
+				recordLogin(r, logins, loginProviderOIDC, login, nil, err)
+				logger.Errorf("oidc: cannot create user: %s", err)
+				return
+			}
+
+			err = sender.Send(ctx, &core.WebhookData{
+				Event:  core.WebhookEventUser,
+				Action: core.WebhookActionCreated,
+				User:   user,
+			})
+			if err != nil {
+				logger.Errorf("oidc: cannot send webhook: %s", err)
+			} else {
+				logger.Debugf("oidc: successfully created user")
+			}
+		} else if err != nil {
+			writeLoginError(w, r, err)
+			recordLogin(r, logins, loginProviderOIDC, login, nil, err)
+			logger.Errorf("oidc: cannot find user: %s", err)
+			return
+		}
+
+		// the identity is linked to the user on first login.
+		// The link fails if the user is already linked to a
+		// different identity from the same issuer, which
+		// prevents account takeover by changing the login.
+		if !linked {
+			err = identities.Create(ctx, &core.Identity{
+				UserID:  user.ID,
+				Issuer:  issuer,
+				Subject: subject,
+				Created: time.Now().Unix(),
+			})
+			if err != nil {
+				writeLoginError(w, r, errLoginIdentity)
+				recordLogin(r, logins, loginProviderOIDC, login, user, errLoginIdentity)
+				logger.Errorf("oidc: cannot link identity: %s", err)
+				return
+			}
+		}
+
+		if user.Machine {
+			writeLoginError(w, r, errLoginMachine)
+			recordLogin(r, logins, loginProviderOIDC, login, user, errLoginMachine)
+			return
+		}
+
+		if user.Active == false {
+			writeLoginError(w, r, errLoginInactive)
+			recordLogin(r, logins, loginProviderOIDC, login, user, errLoginInactive)
+			return
+		}
+
+		// the administrator flag is synchronized with the
+		// identity provider group membership on every login,
+		// which revokes privileges when the user is removed
+		// from the administrator group.
+		if managed {
+			user.Admin = admin
+		}
+		if email := claims.String("email"); email != "" {
+			user.Email = email
+		}
+		user.LastLogin = time.Now().Unix()
+
+		err = users.Update(ctx, user)
+		if err != nil {
+			logger.Errorf("oidc: cannot update user: %s", err)
+		}
+
+		logger.Debugf("oidc: authentication successful")
+
+		err = session.Create(w, r, user)
+		if err != nil {
+			writeLoginError(w, r, err)
+			recordLogin(r, logins, loginProviderOIDC, login, user, err)
+			logger.Errorf("oidc: cannot create session: %s", err)
+			return
+		}
+		recordLogin(r, logins, loginProviderOIDC, login, user, nil)
+
+		// if the user has not linked a source control account
+		// the user is redirected to the source control login,
+		// since the source control token is required to list
+		// repositories and access the repository contents.
+		if user.Token == "" {
+			http.Redirect(w, r, "/login", 303)
+			return
+		}
+		http.Redirect(w, r, "/", 303)
+	}
+}
+
+// HandleLink creates an http.HandlerFunc that links the source
+// control account to the user authenticated with the identity
+// provider. Users without a session are redirected to the
+// identity provider login before the source control login, and
+// accounts are never created from the source control account.
+func HandleLink(
+	middleware login.Middleware,
+	users core.UserStore,
+	userz core.UserService,
+	syncer core.Syncer,
+	session core.Session,
+) http.HandlerFunc {
+	next := middleware.Handler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			user, _ := session.Get(r)
+			if user == nil {
+				http.Redirect(w, r, "/login/oidc", 303)
+				return
+			}
+
+			err := login.ErrorFrom(ctx)
+			if err != nil {
+				writeLoginError(w, r, err)
+				logrus.Debugf("cannot authenticate user: %s", err)
+				return
+			}
+
+			tok := login.TokenFrom(ctx)
+			account, err := userz.Find(ctx, tok.Access, tok.Refresh)
+			if err != nil {
+				writeLoginError(w, r, err)
+				logrus.Debugf("cannot find remote user: %s", err)
+				return
+			}
+
+			logger := logrus.WithField("login", user.Login).
+				WithField("account", account.Login)
+
+			if user.Avatar == "" {
+				user.Avatar = account.Avatar
+			}
+			user.Token = tok.Access
+			user.Refresh = tok.Refresh
+			if !tok.Expires.IsZero() {
+				user.Expiry = tok.Expires.Unix()
+			}
+			if time.Unix(user.Synced, 0).Add(syncPeriod).Before(time.Now()) {
+				user.Syncing = true
+			}
+
+			err = users.Update(ctx, user)
+			if err != nil {
+				writeLoginError(w, r, err)
+				logger.Errorf("cannot link account: %s", err)
+				return
+			}
+
+			if user.Syncing {
+				go synchornize(ctx, syncer, user)
+			}
+
+			logger.Debugf("successfully linked account")
+			http.Redirect(w, r, "/", 303)
+		}),
+	)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if user, _ := session.Get(r); user == nil {
+			http.Redirect(w, r, "/login/oidc", 303)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// helper function returns true if the identity can be linked
+// to the existing user with the same login.
+func canLink(provider *oidc.Provider, claims oidc.Claims, user *core.User) bool {
+	if provider.LinkLogin() {
+		return true
+	}
+	email, verified := provider.Email(claims)
+	return verified && email != "" && strings.EqualFold(email, user.Email)
+}
+
+// helper function returns the state, nonce and code verifier
+// stored in the login cookie.
+func readOIDCState(r *http.Request) (state, nonce, verifier string, err error) {
+	cookie, err := r.Cookie(oidcCookie)
+	if err != nil {
+		return "", "", "", errors.New("Login session expired")
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 {
+		return "", "", "", errors.New("Invalid login state")
+	}
+	return parts[0], parts[1], parts[2], nil
+}
@@ -0,0 +1,432 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/web/oidc"
+	"github.com/drone/drone/handler/web/oidc/oidctest"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/plugin/admission"
+	"github.com/drone/go-login/login"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandleOIDC_Redirect(t *testing.T) {
+	server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer server.Close()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login/oidc", nil)
+
+	HandleOIDC(newTestOIDC(server), nil, nil, nil, nil, nil, nil).ServeHTTP(w, r)
+
+	if got, want := w.Code, 303; want != got {
+		t.Errorf("Want response code %d, got %d", want, got)
+	}
+	location, _ := url.Parse(w.Header().Get("Location"))
+	if got, want := location.Path, "/authorize"; got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+	if got, want := location.Query().Get("code_challenge_method"), "S256"; got != want {
+		t.Errorf("Want code challenge method %s, got %s", want, got)
+	}
+	if cookie := w.Header().Get("Set-Cookie"); !strings.HasPrefix(cookie, oidcCookie+"=") {
+		t.Errorf("Want login state cookie, got %q", cookie)
+	}
+}
+
+func TestHandleOIDC_Create(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer server.Close()
+	server.SetClaims(map[string]interface{}{
+		"sub":                "248289761001",
+		"preferred_username": "octocat",
+		"email":              "octocat@github.com",
+		"groups":             []string{"drone-admins"},
+	})
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), "octocat").Return(nil, sql.ErrNoRows)
+	users.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	users.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	identities := mock.NewMockIdentityStore(controller)
+	identities.EXPECT().Find(gomock.Any(), server.URL, "248289761001").Return(nil, sql.ErrNoRows)
+	identities.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, identity *core.Identity) {
+		if got, want := identity.Subject, "248289761001"; got != want {
+			t.Errorf("Want identity subject %q, got %q", want, got)
+		}
+		if got, want := identity.Issuer, server.URL; got != want {
+			t.Errorf("Want identity issuer %q, got %q", want, got)
+		}
+	})
+
+	webhook := mock.NewMockWebhookSender(controller)
+	webhook.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+	session := mock.NewMockSession(controller)
+	session.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ http.ResponseWriter, _ *http.Request, user *core.User) {
+		if got, want := user.Login, "octocat"; got != want {
+			t.Errorf("Want user login %q, got %q", want, got)
+		}
+		if got, want := user.Email, "octocat@github.com"; got != want {
+			t.Errorf("Want user email %q, got %q", want, got)
+		}
+		if !user.Admin {
+			t.Errorf("Want admin privileges granted from group membership")
+		}
+	})
+
+	handler := HandleOIDC(newTestOIDC(server), users, identities, session, nil, admission.Open(false), webhook)
+	w := loginOIDC(t, handler)
+
+	// the user is redirected to the source control login to
+	// link the source control account.
+	if got, want := w.Header().Get("Location"), "/login"; got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+}
+
+func TestHandleOIDC_Existing(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer server.Close()
+	server.SetClaims(map[string]interface{}{
+		"sub":                "248289761001",
+		"preferred_username": "octocat",
+		"groups":             []string{"developers"},
+	})
+
+	user := &core.User{ID: 1, Login: "octocat", Admin: true, Active: true, Token: "d7c559e6"}
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Find(gomock.Any(), user.ID).Return(user, nil)
+	users.EXPECT().Update(gomock.Any(), user).Return(nil)
+
+	identity := &core.Identity{UserID: user.ID, Issuer: server.URL, Subject: "248289761001"}
+	identities := mock.NewMockIdentityStore(controller)
+	identities.EXPECT().Find(gomock.Any(), server.URL, "248289761001").Return(identity, nil)
+
+	session := mock.NewMockSession(controller)
+	session.EXPECT().Create(gomock.Any(), gomock.Any(), user)
+
+	logins := mock.NewMockLoginStore(controller)
+	logins.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *core.LoginEvent) {
+		if !event.Success || event.Login != "octocat" || event.Provider != "oidc" {
+			t.Errorf("Want successful login recorded, got %+v", event)
+		}
+	})
+
+	handler := HandleOIDC(newTestOIDC(server), users, identities, session, logins, nil, nil)
+	w := loginOIDC(t, handler)
+
+	if got, want := w.Header().Get("Location"), "/"; got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+	if user.Admin {
+		t.Errorf("Want admin privileges revoked without group membership")
+	}
+}
+
+// this test verifies that an admission denial is recorded in
+// the login history with the denial reason.
+func TestHandleOIDC_Denied(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer server.Close()
+	server.SetClaims(map[string]interface{}{
+		"sub":                "248289761001",
+		"preferred_username": "octocat",
+	})
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), "octocat").Return(nil, sql.ErrNoRows)
+
+	identities := mock.NewMockIdentityStore(controller)
+	identities.EXPECT().Find(gomock.Any(), server.URL, "248289761001").Return(nil, sql.ErrNoRows)
+
+	logins := mock.NewMockLoginStore(controller)
+	logins.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *core.LoginEvent) {
+		if event.Success {
+			t.Errorf("Want failed login recorded")
+		}
+		if got, want := event.Reason, admission.ErrClosed.Error(); got != want {
+			t.Errorf("Want denial reason %q, got %q", want, got)
+		}
+	})
+
+	handler := HandleOIDC(newTestOIDC(server), users, identities, nil, logins, admission.Open(true), nil)
+	w := loginOIDC(t, handler)
+
+	if got, want := w.Header().Get("Location"), "/login/error?message="+admission.ErrClosed.Error(); got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+}
+
+// this test verifies that an existing user is matched by login
+// the first time the identity is linked, if the identity provider
+// verified the user email address.
+func TestHandleOIDC_FirstLink(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer server.Close()
+	server.SetClaims(map[string]interface{}{
+		"sub":                "248289761001",
+		"preferred_username": "octocat",
+		"email":              "Octocat@github.com",
+		"email_verified":     true,
+	})
+
+	user := &core.User{ID: 1, Login: "octocat", Email: "octocat@github.com", Active: true, Token: "d7c559e6"}
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), "octocat").Return(user, nil)
+	users.EXPECT().Update(gomock.Any(), user).Return(nil)
+
+	identities := mock.NewMockIdentityStore(controller)
+	identities.EXPECT().Find(gomock.Any(), server.URL, "248289761001").Return(nil, sql.ErrNoRows)
+	identities.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, identity *core.Identity) {
+		if got, want := identity.UserID, user.ID; got != want {
+			t.Errorf("Want identity linked to user %d, got %d", want, got)
+		}
+	})
+
+	session := mock.NewMockSession(controller)
+	session.EXPECT().Create(gomock.Any(), gomock.Any(), user)
+
+	handler := HandleOIDC(newTestOIDC(server), users, identities, session, nil, nil, nil)
+	w := loginOIDC(t, handler)
+
+	if got, want := w.Header().Get("Location"), "/"; got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+}
+
+// this test verifies that a user cannot login to an account
+// that is linked to a different identity by changing the login
+// claim to match the account login.
+func TestHandleOIDC_LinkedToOther(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer server.Close()
+	server.SetClaims(map[string]interface{}{
+		"sub":                "981727002010",
+		"preferred_username": "octocat",
+	})
+
+	user := &core.User{ID: 1, Login: "octocat", Active: true, Token: "d7c559e6"}
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().FindLogin(gomock.Any(), "octocat").Return(user, nil)
+
+	identities := mock.NewMockIdentityStore(controller)
+	identities.EXPECT().Find(gomock.Any(), server.URL, "981727002010").Return(nil, sql.ErrNoRows)
+	identities.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("UNIQUE constraint failed"))
+
+	provider := oidc.New(oidc.Config{
+		Issuer:       server.URL,
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		RedirectURL:  "http://drone.company.com/login/oidc",
+		LinkLogin:    true,
+	})
+	handler := HandleOIDC(provider, users, identities, nil, nil, nil, nil)
+	w := loginOIDC(t, handler)
+
+	if got, want := w.Header().Get("Location"), "/login/error?message="+errLoginIdentity.Error(); got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+}
+
+// this test verifies that a user cannot login to an existing
+// account with the same login, if linking by login is disabled
+// and the email address is not verified.
+func TestHandleOIDC_Conflict(t *testing.T) {
+	tests := []map[string]interface{}{
+		{
+			"sub":                "981727002010",
+			"preferred_username": "octocat",
+		},
+		{
+			"sub":                "981727002010",
+			"preferred_username": "octocat",
+			"email":              "octocat@github.com",
+		},
+		{
+			"sub":                "981727002010",
+			"preferred_username": "octocat",
+			"email":              "attacker@example.com",
+			"email_verified":     true,
+		},
+	}
+	for i, claims := range tests {
+		controller := gomock.NewController(t)
+
+		server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+		server.SetClaims(claims)
+
+		user := &core.User{ID: 1, Login: "octocat", Email: "octocat@github.com", Admin: true, Active: true, Token: "d7c559e6"}
+		users := mock.NewMockUserStore(controller)
+		users.EXPECT().FindLogin(gomock.Any(), "octocat").Return(user, nil)
+
+		identities := mock.NewMockIdentityStore(controller)
+		identities.EXPECT().Find(gomock.Any(), server.URL, "981727002010").Return(nil, sql.ErrNoRows)
+
+		logins := mock.NewMockLoginStore(controller)
+		logins.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *core.LoginEvent) {
+			if event.Success || event.UserID != 0 {
+				t.Errorf("Want failed login recorded without user at index %d, got %+v", i, event)
+			}
+		})
+
+		handler := HandleOIDC(newTestOIDC(server), users, identities, nil, logins, nil, nil)
+		w := loginOIDC(t, handler)
+
+		if got, want := w.Header().Get("Location"), "/login/error?message="+errLoginConflict.Error(); got != want {
+			t.Errorf("Want redirect to %s at index %d, got %s", want, i, got)
+		}
+
+		server.Close()
+		controller.Finish()
+	}
+}
+
+func TestHandleOIDC_InvalidState(t *testing.T) {
+	server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer server.Close()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login/oidc?code=3da54155&state=forged", nil)
+	r.AddCookie(&http.Cookie{Name: oidcCookie, Value: "state.nonce.verifier"})
+
+	HandleOIDC(newTestOIDC(server), nil, nil, nil, nil, nil, nil).ServeHTTP(w, r)
+
+	if got := w.Header().Get("Location"); !strings.HasPrefix(got, "/login/error") {
+		t.Errorf("Want redirect to login error, got %s", got)
+	}
+}
+
+func TestHandleLink(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := &core.User{Login: "octocat", Synced: time.Now().Unix()}
+	session := mock.NewMockSession(controller)
+	session.EXPECT().Get(gomock.Any()).Return(user, nil).Times(2)
+
+	userz := mock.NewMockUserService(controller)
+	userz.EXPECT().Find(gomock.Any(), "d7c559e6", "cd5e0f5d").Return(&core.User{Login: "octocat"}, nil)
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().Update(gomock.Any(), user).Return(nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login", nil)
+
+	middleware := &fakeLogin{token: &login.Token{Access: "d7c559e6", Refresh: "cd5e0f5d"}}
+	HandleLink(middleware, users, userz, nil, session).ServeHTTP(w, r)
+
+	if got, want := w.Header().Get("Location"), "/"; got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+	if got, want := user.Token, "d7c559e6"; got != want {
+		t.Errorf("Want linked token %q, got %q", want, got)
+	}
+}
+
+func TestHandleLink_NoSession(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	session := mock.NewMockSession(controller)
+	session.EXPECT().Get(gomock.Any()).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login", nil)
+
+	middleware := &fakeLogin{}
+	HandleLink(middleware, nil, nil, nil, session).ServeHTTP(w, r)
+
+	if got, want := w.Header().Get("Location"), "/login/oidc"; got != want {
+		t.Errorf("Want redirect to %s, got %s", want, got)
+	}
+	if middleware.called {
+		t.Errorf("Want source control login skipped without a session")
+	}
+}
+
+func newTestOIDC(server *oidctest.Server) *oidc.Provider {
+	return oidc.New(oidc.Config{
+		Issuer:       server.URL,
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		RedirectURL:  "http://drone.company.com/login/oidc",
+		AdminGroups:  []string{"drone-admins"},
+	})
+}
+
+// loginOIDC simulates the oidc login flow, where the user is
+// redirected to the identity provider and back to the login
+// handler with the authorization code.
+func loginOIDC(t *testing.T, handler http.Handler) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/login/oidc", nil)
+	handler.ServeHTTP(w, r)
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	callback, _ := url.Parse(res.Header.Get("Location"))
+
+	r = httptest.NewRequest("GET", "/login/oidc?"+callback.RawQuery, nil)
+	for _, cookie := range w.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// fakeLogin is a fake login middleware that authenticates the
+// user with a static source control token.
+type fakeLogin struct {
+	token  *login.Token
+	called bool
+}
+
+func (f *fakeLogin) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.called = true
+		ctx := login.WithToken(r.Context(), f.token)
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
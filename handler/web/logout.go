@@ -18,13 +18,20 @@ import (
 	"net/http"
 
 	"github.com/drone/drone-ui/dist"
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/logger"
 )
 
 // HandleLogout creates an http.HandlerFunc that handles
-// session termination.
-func HandleLogout() http.HandlerFunc {
+// session termination. The session is revoked, and the
+// session cookie is removed.
+func HandleLogout(session core.Session) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add("Set-Cookie", "_session_=deleted; Path=/; Max-Age=0")
+		err := session.Delete(w, r)
+		if err != nil {
+			logger.FromRequest(r).WithError(err).
+				Warnln("cannot revoke session")
+		}
 		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
 		w.Write(
 			dist.MustLookup("/index.html"),
@@ -7,13 +7,17 @@ package web
 import (
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/drone/drone/session"
 )
 
 func TestLogout(t *testing.T) {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/logout", nil)
 
-	HandleLogout().ServeHTTP(w, r)
+	s := session.New(nil, nil, nil, session.NewConfig("correct-horse-battery-staple", time.Hour))
+	HandleLogout(s).ServeHTTP(w, r)
 
 	if got, want := w.Code, 200; want != got {
 		t.Errorf("Want response code %d, got %d", want, got)
@@ -0,0 +1,66 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+// Claims represents the id token claims.
+type Claims map[string]interface{}
+
+// String returns the named claim as a string.
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Bool returns the named claim as a boolean. Some identity
+// providers encode boolean claims as strings.
+func (c Claims) Bool(name string) bool {
+	switch v := c[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// Strings returns the named claim as a string slice. A claim
+// with a single string value is returned as a slice of length
+// one.
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// hasAudience returns true if the audience claim includes the
+// client id. The audience may be a string or a string slice.
+func (c Claims) hasAudience(client string) bool {
+	for _, aud := range c.Strings("aud") {
+		if aud == client {
+			return true
+		}
+	}
+	return false
+}
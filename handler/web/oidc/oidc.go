@@ -0,0 +1,392 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oidc implements the OpenID Connect authorization code
+// flow, with PKCE, used to authenticate users with an external
+// identity provider.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// clock skew tolerated when validating the token expiration.
+const leeway = time.Minute
+
+var (
+	errInvalidToken     = errors.New("oidc: invalid id token")
+	errInvalidSignature = errors.New("oidc: invalid id token signature")
+	errInvalidIssuer    = errors.New("oidc: invalid id token issuer")
+	errInvalidAudience  = errors.New("oidc: invalid id token audience")
+	errInvalidNonce     = errors.New("oidc: invalid id token nonce")
+	errExpiredToken     = errors.New("oidc: id token is expired")
+	errMissingToken     = errors.New("oidc: token response missing id_token")
+)
+
+// Config provides the identity provider configuration.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// LoginClaim is the name of the claim used as the
+	// user login. Defaults to preferred_username.
+	LoginClaim string
+
+	// GroupsClaim is the name of the claim that lists the
+	// user group membership. Defaults to groups.
+	GroupsClaim string
+
+	// AdminGroups is the list of identity provider groups
+	// that are granted system administrator privileges. If
+	// empty, the administrator flag is not managed by the
+	// identity provider.
+	AdminGroups []string
+
+	// LinkLogin allows the identity to be linked to an
+	// existing user with the same login on first login. This
+	// should only be enabled if the login claim cannot be
+	// changed by the user.
+	LinkLogin bool
+
+	// SkipVerify disables tls certificate verification.
+	SkipVerify bool
+}
+
+// Provider is an OpenID Connect identity provider.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]*rsa.PublicKey
+}
+
+// discovery is the provider metadata returned by the
+// openid-configuration endpoint.
+type discovery struct {
+	Issuer   string `json:"issuer"`
+	AuthURL  string `json:"authorization_endpoint"`
+	TokenURL string `json:"token_endpoint"`
+	JWKSURL  string `json:"jwks_uri"`
+}
+
+// New returns a new OpenID Connect identity provider. The
+// provider metadata is discovered on first use.
+func New(config Config) *Provider {
+	if config.LoginClaim == "" {
+		config.LoginClaim = "preferred_username"
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	client := &http.Client{Timeout: time.Minute}
+	if config.SkipVerify {
+		client.Transport = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+	return &Provider{config: config, client: client}
+}
+
+// AuthCodeURL returns the identity provider url to which the
+// user is redirected to authenticate. The state and nonce
+// values are verified on callback, and the verifier is used
+// to derive the PKCE code challenge.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	oauth, err := p.oauth2(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauth.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("code_challenge", Challenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), nil
+}
+
+// Exchange exchanges the authorization code for a token, and
+// returns the verified id token claims.
+func (p *Provider) Exchange(ctx context.Context, code, nonce, verifier string) (Claims, error) {
+	oauth, err := p.oauth2(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, err := oauth.Exchange(ctx, code,
+		oauth2.SetAuthURLParam("code_verifier", verifier),
+	)
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := token.Extra("id_token").(string)
+	if raw == "" {
+		return nil, errMissingToken
+	}
+	return p.Verify(ctx, raw, nonce)
+}
+
+// Verify verifies the id token signature and standard claims,
+// and returns the token claims.
+func (p *Provider) Verify(ctx context.Context, raw, nonce string) (Claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidToken
+	}
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errInvalidToken
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("oidc: unsupported signing algorithm %q", header.Alg)
+	}
+	key, err := p.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return nil, errInvalidSignature
+	}
+
+	claims := Claims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errInvalidToken
+	}
+	if claims.String("iss") != p.config.Issuer {
+		return nil, errInvalidIssuer
+	}
+	if !claims.hasAudience(p.config.ClientID) {
+		return nil, errInvalidAudience
+	}
+	if claims.String("nonce") != nonce {
+		return nil, errInvalidNonce
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok || time.Unix(int64(exp), 0).Add(leeway).Before(time.Now()) {
+		return nil, errExpiredToken
+	}
+	return claims, nil
+}
+
+// Login returns the user login from the token claims.
+func (p *Provider) Login(claims Claims) string {
+	return claims.String(p.config.LoginClaim)
+}
+
+// Email returns the user email from the token claims, and
+// true if the identity provider verified the email address.
+func (p *Provider) Email(claims Claims) (email string, verified bool) {
+	return claims.String("email"), claims.Bool("email_verified")
+}
+
+// LinkLogin returns true if the identity can be linked to an
+// existing user with the same login.
+func (p *Provider) LinkLogin() bool {
+	return p.config.LinkLogin
+}
+
+// Groups returns the user group membership from the token
+// claims.
+func (p *Provider) Groups(claims Claims) []string {
+	return claims.Strings(p.config.GroupsClaim)
+}
+
+// Admin returns true if the user is a member of an
+// administrator group. The second return value is false if
+// the administrator flag is not managed by the provider.
+func (p *Provider) Admin(claims Claims) (admin, managed bool) {
+	if len(p.config.AdminGroups) == 0 {
+		return false, false
+	}
+	for _, group := range p.Groups(claims) {
+		for _, admin := range p.config.AdminGroups {
+			if group == admin {
+				return true, true
+			}
+		}
+	}
+	return false, true
+}
+
+// oauth2 returns the oauth2 configuration using the endpoints
+// from the provider metadata.
+func (p *Provider) oauth2(ctx context.Context) (*oauth2.Config, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Scopes:       p.config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  meta.AuthURL,
+			TokenURL: meta.TokenURL,
+		},
+	}, nil
+}
+
+// discover returns the provider metadata, fetching it from the
+// openid-configuration endpoint if not already cached.
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	meta := new(discovery)
+	uri := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.get(ctx, uri, meta); err != nil {
+		return nil, err
+	}
+	// the issuer must match the issuer used to retrieve the
+	// configuration, per the discovery specification.
+	if meta.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("oidc: issuer mismatch, want %q, got %q", p.config.Issuer, meta.Issuer)
+	}
+	p.discovery = meta
+	return meta, nil
+}
+
+// key returns the public key used to verify the token
+// signature. The key set is refreshed if the key is not
+// found, since the provider may have rotated its keys.
+func (p *Provider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	keys, err := p.fetchKeys(ctx, meta.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	// if the token does not specify a key id, and the provider
+	// publishes a single key, the key is used.
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("oidc: cannot find signing key %q", kid)
+}
+
+// fetchKeys fetches the provider json web key set and returns
+// the rsa signing keys indexed by key id.
+func (p *Provider) fetchKeys(ctx context.Context, uri string) (map[string]*rsa.PublicKey, error) {
+	set := struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}{}
+	if err := p.get(ctx, uri, &set); err != nil {
+		return nil, err
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			continue
+		}
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+// get fetches the json document at the url.
+func (p *Provider) get(ctx context.Context, uri string, out interface{}) error {
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return err
+	}
+	res, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return fmt.Errorf("oidc: unexpected status code %d fetching %s", res.StatusCode, uri)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// Challenge returns the PKCE code challenge derived from the
+// code verifier using the S256 method.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func decodeSegment(seg string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,180 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/handler/web/oidc/oidctest"
+)
+
+var noContext = context.Background()
+
+func TestProvider(t *testing.T) {
+	server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer server.Close()
+	server.SetClaims(map[string]interface{}{
+		"sub":                "1",
+		"preferred_username": "octocat",
+		"groups":             []string{"developers", "drone-admins"},
+	})
+
+	provider := newTestProvider(server)
+	code, err := authorize(provider, "state", "nonce", "verifier")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	claims, err := provider.Exchange(noContext, code, "nonce", "verifier")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := provider.Login(claims), "octocat"; got != want {
+		t.Errorf("Want login %q, got %q", want, got)
+	}
+	if got, want := len(provider.Groups(claims)), 2; got != want {
+		t.Errorf("Want %d groups, got %d", want, got)
+	}
+	if admin, managed := provider.Admin(claims); !admin || !managed {
+		t.Errorf("Want admin privileges granted from group membership")
+	}
+}
+
+func TestProvider_PKCE(t *testing.T) {
+	server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer server.Close()
+
+	provider := newTestProvider(server)
+	code, err := authorize(provider, "state", "nonce", "verifier")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := provider.Exchange(noContext, code, "nonce", "forged"); err == nil {
+		t.Errorf("Want error when the code verifier does not match the challenge")
+	}
+}
+
+func TestProvider_Verify(t *testing.T) {
+	server := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer server.Close()
+	other := oidctest.NewServer("drone", "correct-horse-battery-staple")
+	defer other.Close()
+
+	provider := newTestProvider(server)
+
+	token := server.Sign(map[string]interface{}{"nonce": "nonce"})
+	if _, err := provider.Verify(noContext, token, "nonce"); err != nil {
+		t.Error(err)
+	}
+
+	tests := []struct {
+		token string
+		err   error
+	}{
+		{
+			token: server.Sign(map[string]interface{}{"nonce": "nonce", "iss": other.URL}),
+			err:   errInvalidIssuer,
+		},
+		{
+			token: server.Sign(map[string]interface{}{"nonce": "nonce", "aud": []string{"jenkins"}}),
+			err:   errInvalidAudience,
+		},
+		{
+			token: server.Sign(map[string]interface{}{"nonce": "forged"}),
+			err:   errInvalidNonce,
+		},
+		{
+			token: server.Sign(map[string]interface{}{"nonce": "nonce", "exp": time.Now().Add(-time.Hour).Unix()}),
+			err:   errExpiredToken,
+		},
+		{
+			token: other.Sign(map[string]interface{}{"nonce": "nonce", "iss": server.URL}),
+			err:   errInvalidSignature,
+		},
+		{
+			token: "not-a-token",
+			err:   errInvalidToken,
+		},
+	}
+	for i, test := range tests {
+		if _, err := provider.Verify(noContext, test.token, "nonce"); err != test.err {
+			t.Errorf("Want error %q, got %q at index %d", test.err, err, i)
+		}
+	}
+
+	// the token signing algorithm is changed to none, and the
+	// signature is removed.
+	parts := strings.Split(token, ".")
+	unsigned := "eyJhbGciOiJub25lIn0." + parts[1] + "."
+	if _, err := provider.Verify(noContext, unsigned, "nonce"); err == nil {
+		t.Errorf("Want error with unsigned token")
+	}
+}
+
+func TestProvider_Admin(t *testing.T) {
+	provider := New(Config{})
+	claims := Claims{"groups": []interface{}{"drone-admins"}}
+	if _, managed := provider.Admin(claims); managed {
+		t.Errorf("Want admin flag unmanaged when no admin groups are configured")
+	}
+
+	provider = New(Config{AdminGroups: []string{"drone-admins"}, GroupsClaim: "roles"})
+	if admin, managed := provider.Admin(claims); admin || !managed {
+		t.Errorf("Want admin privileges revoked without group membership")
+	}
+	claims = Claims{"roles": "drone-admins"}
+	if admin, _ := provider.Admin(claims); !admin {
+		t.Errorf("Want admin privileges granted from custom groups claim")
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	// test vector from rfc 7636 appendix b.
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	if got, want := Challenge(verifier), "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("Want code challenge %q, got %q", want, got)
+	}
+}
+
+func newTestProvider(server *oidctest.Server) *Provider {
+	return New(Config{
+		Issuer:       server.URL,
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		RedirectURL:  "http://drone.company.com/login/oidc",
+		AdminGroups:  []string{"drone-admins"},
+	})
+}
+
+// authorize simulates the user authenticating with the identity
+// provider, and returns the authorization code.
+func authorize(provider *Provider, state, nonce, verifier string) (string, error) {
+	uri, err := provider.AuthCodeURL(noContext, state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(uri)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+	redirect, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		return "", err
+	}
+	return redirect.Query().Get("code"), nil
+}
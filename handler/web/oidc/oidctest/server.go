@@ -0,0 +1,227 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oidctest provides a mock OpenID Connect identity
+// provider for testing.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Server is a mock OpenID Connect identity provider. The
+// authorization endpoint authenticates the user immediately,
+// and redirects back to the client with an authorization code
+// for an id token with the configured claims.
+type Server struct {
+	*httptest.Server
+
+	// Key is the key used to sign the id tokens.
+	Key *rsa.PrivateKey
+
+	// ClientID and ClientSecret are the client credentials
+	// accepted by the token endpoint.
+	ClientID     string
+	ClientSecret string
+
+	mu     sync.Mutex
+	claims map[string]interface{}
+	codes  map[string]*grant
+}
+
+// grant is a pending authorization code grant.
+type grant struct {
+	nonce     string
+	challenge string
+	claims    map[string]interface{}
+}
+
+// NewServer starts and returns a new mock identity provider.
+// The caller should call Close when finished.
+func NewServer(clientID, clientSecret string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{
+		Key:          key,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		claims:       map[string]interface{}{},
+		codes:        map[string]*grant{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("/authorize", s.handleAuthorize)
+	mux.HandleFunc("/token", s.handleToken)
+	mux.HandleFunc("/keys", s.handleKeys)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetClaims sets the claims included in id tokens issued for
+// subsequent authorization requests.
+func (s *Server) SetClaims(claims map[string]interface{}) {
+	s.mu.Lock()
+	s.claims = claims
+	s.mu.Unlock()
+}
+
+// Sign returns an id token with the claims, signed with the
+// server key. The issuer, audience and expiration claims are
+// set if not included in the claims.
+func (s *Server) Sign(claims map[string]interface{}) string {
+	out := map[string]interface{}{
+		"iss": s.URL,
+		"aud": s.ClientID,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		out[k] = v
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "1", "typ": "JWT"})
+	payload, _ := json.Marshal(out)
+	data := encode(header) + "." + encode(payload)
+	digest := sha256.Sum256([]byte(data))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.Key, crypto.SHA256, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return data + "." + encode(sig)
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, 200, map[string]interface{}{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/keys",
+		"response_types_supported":              []string{"code"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != s.ClientID || q.Get("response_type") != "code" {
+		http.Error(w, "invalid request", 400)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "pkce required", 400)
+		return
+	}
+	code := random()
+	s.mu.Lock()
+	s.codes[code] = &grant{
+		nonce:     q.Get("nonce"),
+		challenge: q.Get("code_challenge"),
+		claims:    s.claims,
+	}
+	s.mu.Unlock()
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect", 400)
+		return
+	}
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), 302)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if id != s.ClientID || secret != s.ClientSecret {
+		writeJSON(w, 401, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	g, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, 400, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if encode(sum[:]) != g.challenge {
+		writeJSON(w, 400, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := map[string]interface{}{"nonce": g.nonce}
+	for k, v := range g.claims {
+		claims[k] = v
+	}
+	writeJSON(w, 200, map[string]interface{}{
+		"access_token": random(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     s.Sign(claims),
+	})
+}
+
+func (s *Server) handleKeys(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, 200, map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "1",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   encode(s.Key.N.Bytes()),
+				"e":   encode(big.NewInt(int64(s.Key.E)).Bytes()),
+			},
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func random() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return encode(b)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/drone/drone-ui/dist"
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/handler/web/landingpage"
+	"github.com/drone/drone/handler/web/oidc"
 	"github.com/drone/drone/logger"
 	"github.com/drone/go-login/login"
 	"github.com/drone/go-scm/scm"
@@ -33,12 +34,16 @@ func New(
 	admitter core.AdmissionService,
 	builds core.BuildStore,
 	client *scm.Client,
+	files core.FileService,
 	hooks core.HookParser,
+	identities core.IdentityStore,
 	license *core.License,
 	licenses core.LicenseService,
 	login login.Middleware,
+	oidc *oidc.Provider,
 	repos core.RepositoryStore,
 	session core.Session,
+	logins core.LoginStore,
 	syncer core.Syncer,
 	triggerer core.Triggerer,
 	users core.UserStore,
@@ -48,43 +53,51 @@ func New(
 	system *core.System,
 ) Server {
 	return Server{
-		Admitter:  admitter,
-		Builds:    builds,
-		Client:    client,
-		Hooks:     hooks,
-		License:   license,
-		Licenses:  licenses,
-		Login:     login,
-		Repos:     repos,
-		Session:   session,
-		Syncer:    syncer,
-		Triggerer: triggerer,
-		Users:     users,
-		Userz:     userz,
-		Webhook:   webhook,
-		Options:   options,
-		Host:      system.Host,
+		Admitter:   admitter,
+		Builds:     builds,
+		Client:     client,
+		Files:      files,
+		Hooks:      hooks,
+		Identities: identities,
+		License:    license,
+		Licenses:   licenses,
+		Login:      login,
+		OIDC:       oidc,
+		Repos:      repos,
+		Session:    session,
+		Logins:     logins,
+		Syncer:     syncer,
+		Triggerer:  triggerer,
+		Users:      users,
+		Userz:      userz,
+		Webhook:    webhook,
+		Options:    options,
+		Host:       system.Host,
 	}
 }
 
 // Server is a http.Handler which exposes drone functionality over HTTP.
 type Server struct {
-	Admitter  core.AdmissionService
-	Builds    core.BuildStore
-	Client    *scm.Client
-	Hooks     core.HookParser
-	License   *core.License
-	Licenses  core.LicenseService
-	Login     login.Middleware
-	Repos     core.RepositoryStore
-	Session   core.Session
-	Syncer    core.Syncer
-	Triggerer core.Triggerer
-	Users     core.UserStore
-	Userz     core.UserService
-	Webhook   core.WebhookSender
-	Options   secure.Options
-	Host      string
+	Admitter   core.AdmissionService
+	Builds     core.BuildStore
+	Client     *scm.Client
+	Files      core.FileService
+	Hooks      core.HookParser
+	Identities core.IdentityStore
+	License    *core.License
+	Licenses   core.LicenseService
+	Login      login.Middleware
+	OIDC       *oidc.Provider
+	Repos      core.RepositoryStore
+	Session    core.Session
+	Logins     core.LoginStore
+	Syncer     core.Syncer
+	Triggerer  core.Triggerer
+	Users      core.UserStore
+	Userz      core.UserService
+	Webhook    core.WebhookSender
+	Options    secure.Options
+	Host       string
 }
 
 // Handler returns an http.Handler
@@ -98,28 +111,55 @@ func (s Server) Handler() http.Handler {
 	r.Use(sec.Handler)
 
 	r.Route("/hook", func(r chi.Router) {
-		r.Post("/", HandleHook(s.Repos, s.Builds, s.Triggerer, s.Hooks))
+		r.Post("/", HandleHook(s.Repos, s.Builds, s.Files, s.Triggerer, s.Hooks))
 	})
 
 	r.Get("/version", HandleVersion)
 	r.Get("/healthz", HandleHealthz())
 	r.Get("/varz", HandleVarz(s.Client, s.License))
 
-	r.Handle("/login",
-		s.Login.Handler(
-			http.HandlerFunc(
-				HandleLogin(
-					s.Users,
-					s.Userz,
-					s.Syncer,
-					s.Session,
-					s.Admitter,
-					s.Webhook,
+	// if an OpenID Connect identity provider is configured,
+	// users authenticate with the identity provider and the
+	// source control login is used to link the account.
+	if s.OIDC != nil {
+		r.Get("/login/oidc",
+			HandleOIDC(
+				s.OIDC,
+				s.Users,
+				s.Identities,
+				s.Session,
+				s.Logins,
+				s.Admitter,
+				s.Webhook,
+			),
+		)
+		r.Handle("/login",
+			HandleLink(
+				s.Login,
+				s.Users,
+				s.Userz,
+				s.Syncer,
+				s.Session,
+			),
+		)
+	} else {
+		r.Handle("/login",
+			s.Login.Handler(
+				http.HandlerFunc(
+					HandleLogin(
+						s.Users,
+						s.Userz,
+						s.Syncer,
+						s.Session,
+						s.Logins,
+						s.Admitter,
+						s.Webhook,
+					),
 				),
 			),
-		),
-	)
-	r.Get("/logout", HandleLogout())
+		)
+	}
+	r.Get("/logout", HandleLogout(s.Session))
 
 	h2 := http.FileServer(landingpage.New())
 	h := http.FileServer(dist.New())
@@ -0,0 +1,44 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package metric
+
+import (
+	"github.com/drone/drone/core"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cacheStats is implemented by a file service that is
+// wrapped with a cache.
+type cacheStats interface {
+	Hits() int64
+	Misses() int64
+}
+
+// ContentCache provides metrics for the file content cache
+// hits and misses. It is a no-op if the file service is not
+// cached.
+func ContentCache(files core.FileService) {
+	stats, ok := files.(cacheStats)
+	if !ok {
+		return
+	}
+	prometheus.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "drone_content_cache_hits_total",
+			Help: "Total number of file content cache hits.",
+		}, func() float64 {
+			return float64(stats.Hits())
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "drone_content_cache_misses_total",
+			Help: "Total number of file content cache misses.",
+		}, func() float64 {
+			return float64(stats.Misses())
+		}),
+	)
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package metric
+
+import (
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type mockCache struct {
+	core.FileService
+	hits, misses int64
+}
+
+func (c *mockCache) Hits() int64   { return c.hits }
+func (c *mockCache) Misses() int64 { return c.misses }
+
+func TestContentCache(t *testing.T) {
+	// restore the default prometheus registerer
+	// when the unit test is complete.
+	snapshot := prometheus.DefaultRegisterer
+	defer func() {
+		prometheus.DefaultRegisterer = snapshot
+	}()
+
+	// creates a blank registry
+	registry := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = registry
+
+	ContentCache(&mockCache{hits: 5, misses: 2})
+
+	metrics, err := registry.Gather()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if want, got := len(metrics), 2; want != got {
+		t.Errorf("Expect registered metrics")
+		return
+	}
+	for i, want := range []struct {
+		name  string
+		value float64
+	}{
+		{"drone_content_cache_hits_total", 5},
+		{"drone_content_cache_misses_total", 2},
+	} {
+		metric := metrics[i]
+		if got := metric.GetName(); want.name != got {
+			t.Errorf("Expect metric name %s, got %s", want.name, got)
+		}
+		if got := metric.Metric[0].Counter.GetValue(); want.value != got {
+			t.Errorf("Expect metric value %f, got %f", want.value, got)
+		}
+	}
+}
+
+func TestContentCache_NotCached(t *testing.T) {
+	controller := gomock.NewController(t)
+
+	snapshot := prometheus.DefaultRegisterer
+	defer func() {
+		prometheus.DefaultRegisterer = snapshot
+		controller.Finish()
+	}()
+
+	registry := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = registry
+
+	ContentCache(mock.NewMockFileService(controller))
+
+	metrics, err := registry.Gather()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(metrics) != 0 {
+		t.Errorf("Expect no registered metrics")
+	}
+}
@@ -25,3 +25,4 @@ func RunningJobCount(core.StageStore)   {}
 func PendingJobCount(core.StageStore)   {}
 func RepoCount(core.RepositoryStore)    {}
 func UserCount(core.UserStore)          {}
+func ContentCache(core.FileService)     {}
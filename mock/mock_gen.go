@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/drone/drone/core (interfaces: NetrcService,Renewer,HookParser,UserService,RepositoryService,CommitService,StatusService,HookService,FileService,Batcher,BuildStore,CronStore,LogStore,PermStore,SecretStore,StageStore,StepStore,RepositoryStore,UserStore,Scheduler,Session,OrganizationService,SecretService,RegistryService,ConfigService,Triggerer,Syncer,LogStream,WebhookSender,LicenseService)
+// Source: github.com/drone/drone/core (interfaces: NetrcService,Renewer,HookParser,UserService,RepositoryService,CommitService,StatusService,HookService,FileService,Batcher,BuildStore,CronStore,TokenStore,SessionStore,RoleStore,RoleService,AuditStore,AuditService,LoginStore,ApprovalStore,ApprovalService,LogStore,PermStore,SecretStore,StageStore,StepStore,RepositoryStore,UserStore,Scheduler,Session,OrganizationService,SecretService,RegistryService,ConfigService,ConfigStore,IdentityStore,ConvertService,PolicyService,Triggerer,Syncer,LogStream,WebhookSender,LicenseService)
 
 // Package mock is a generated GoMock package.
 package mock
@@ -399,6 +399,19 @@ func (mr *MockFileServiceMockRecorder) Find(arg0, arg1, arg2, arg3, arg4, arg5 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockFileService)(nil).Find), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// List mocks base method
+func (m *MockFileService) List(arg0 context.Context, arg1 *core.User, arg2, arg3, arg4, arg5 string) ([]string, error) {
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockFileServiceMockRecorder) List(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFileService)(nil).List), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
 // MockBatcher is a mock of Batcher interface
 type MockBatcher struct {
 	ctrl     *gomock.Controller
@@ -482,6 +495,18 @@ func (mr *MockBuildStoreMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBuildStore)(nil).Create), arg0, arg1, arg2)
 }
 
+// CreateWithConfig mocks base method
+func (m *MockBuildStore) CreateWithConfig(arg0 context.Context, arg1 *core.Build, arg2 []*core.Stage, arg3 *core.Config) error {
+	ret := m.ctrl.Call(m, "CreateWithConfig", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWithConfig indicates an expected call of CreateWithConfig
+func (mr *MockBuildStoreMockRecorder) CreateWithConfig(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithConfig", reflect.TypeOf((*MockBuildStore)(nil).CreateWithConfig), arg0, arg1, arg2, arg3)
+}
+
 // Delete mocks base method
 func (m *MockBuildStore) Delete(arg0 context.Context, arg1 *core.Build) error {
 	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
@@ -559,165 +584,884 @@ func (mr *MockBuildStoreMockRecorder) ListRef(arg0, arg1, arg2, arg3, arg4 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRef", reflect.TypeOf((*MockBuildStore)(nil).ListRef), arg0, arg1, arg2, arg3, arg4)
 }
 
-// Pending mocks base method
-func (m *MockBuildStore) Pending(arg0 context.Context) ([]*core.Build, error) {
-	ret := m.ctrl.Call(m, "Pending", arg0)
-	ret0, _ := ret[0].([]*core.Build)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+// Pending mocks base method
+func (m *MockBuildStore) Pending(arg0 context.Context) ([]*core.Build, error) {
+	ret := m.ctrl.Call(m, "Pending", arg0)
+	ret0, _ := ret[0].([]*core.Build)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pending indicates an expected call of Pending
+func (mr *MockBuildStoreMockRecorder) Pending(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockBuildStore)(nil).Pending), arg0)
+}
+
+// Purge mocks base method
+func (m *MockBuildStore) Purge(arg0 context.Context, arg1, arg2 int64) error {
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockBuildStoreMockRecorder) Purge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockBuildStore)(nil).Purge), arg0, arg1, arg2)
+}
+
+// Running mocks base method
+func (m *MockBuildStore) Running(arg0 context.Context) ([]*core.Build, error) {
+	ret := m.ctrl.Call(m, "Running", arg0)
+	ret0, _ := ret[0].([]*core.Build)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Running indicates an expected call of Running
+func (mr *MockBuildStoreMockRecorder) Running(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Running", reflect.TypeOf((*MockBuildStore)(nil).Running), arg0)
+}
+
+// Update mocks base method
+func (m *MockBuildStore) Update(arg0 context.Context, arg1 *core.Build) error {
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockBuildStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockBuildStore)(nil).Update), arg0, arg1)
+}
+
+// MockCronStore is a mock of CronStore interface
+type MockCronStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockCronStoreMockRecorder
+}
+
+// MockCronStoreMockRecorder is the mock recorder for MockCronStore
+type MockCronStoreMockRecorder struct {
+	mock *MockCronStore
+}
+
+// NewMockCronStore creates a new mock instance
+func NewMockCronStore(ctrl *gomock.Controller) *MockCronStore {
+	mock := &MockCronStore{ctrl: ctrl}
+	mock.recorder = &MockCronStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCronStore) EXPECT() *MockCronStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockCronStore) Create(arg0 context.Context, arg1 *core.Cron) error {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockCronStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCronStore)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method
+func (m *MockCronStore) Delete(arg0 context.Context, arg1 *core.Cron) error {
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockCronStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCronStore)(nil).Delete), arg0, arg1)
+}
+
+// Find mocks base method
+func (m *MockCronStore) Find(arg0 context.Context, arg1 int64) (*core.Cron, error) {
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.Cron)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockCronStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockCronStore)(nil).Find), arg0, arg1)
+}
+
+// FindName mocks base method
+func (m *MockCronStore) FindName(arg0 context.Context, arg1 int64, arg2 string) (*core.Cron, error) {
+	ret := m.ctrl.Call(m, "FindName", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*core.Cron)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindName indicates an expected call of FindName
+func (mr *MockCronStoreMockRecorder) FindName(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindName", reflect.TypeOf((*MockCronStore)(nil).FindName), arg0, arg1, arg2)
+}
+
+// List mocks base method
+func (m *MockCronStore) List(arg0 context.Context, arg1 int64) ([]*core.Cron, error) {
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.Cron)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockCronStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCronStore)(nil).List), arg0, arg1)
+}
+
+// Ready mocks base method
+func (m *MockCronStore) Ready(arg0 context.Context, arg1 int64) ([]*core.Cron, error) {
+	ret := m.ctrl.Call(m, "Ready", arg0, arg1)
+	ret0, _ := ret[0].([]*core.Cron)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ready indicates an expected call of Ready
+func (mr *MockCronStoreMockRecorder) Ready(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockCronStore)(nil).Ready), arg0, arg1)
+}
+
+// Update mocks base method
+func (m *MockCronStore) Update(arg0 context.Context, arg1 *core.Cron) error {
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockCronStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCronStore)(nil).Update), arg0, arg1)
+}
+
+// MockTokenStore is a mock of TokenStore interface
+type MockTokenStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenStoreMockRecorder
+}
+
+// MockTokenStoreMockRecorder is the mock recorder for MockTokenStore
+type MockTokenStoreMockRecorder struct {
+	mock *MockTokenStore
+}
+
+// NewMockTokenStore creates a new mock instance
+func NewMockTokenStore(ctrl *gomock.Controller) *MockTokenStore {
+	mock := &MockTokenStore{ctrl: ctrl}
+	mock.recorder = &MockTokenStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTokenStore) EXPECT() *MockTokenStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockTokenStore) Create(arg0 context.Context, arg1 *core.Token) error {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockTokenStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTokenStore)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method
+func (m *MockTokenStore) Delete(arg0 context.Context, arg1 *core.Token) error {
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockTokenStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTokenStore)(nil).Delete), arg0, arg1)
+}
+
+// Find mocks base method
+func (m *MockTokenStore) Find(arg0 context.Context, arg1 int64) (*core.Token, error) {
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockTokenStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockTokenStore)(nil).Find), arg0, arg1)
+}
+
+// FindHash mocks base method
+func (m *MockTokenStore) FindHash(arg0 context.Context, arg1 string) (*core.Token, error) {
+	ret := m.ctrl.Call(m, "FindHash", arg0, arg1)
+	ret0, _ := ret[0].(*core.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindHash indicates an expected call of FindHash
+func (mr *MockTokenStoreMockRecorder) FindHash(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHash", reflect.TypeOf((*MockTokenStore)(nil).FindHash), arg0, arg1)
+}
+
+// List mocks base method
+func (m *MockTokenStore) List(arg0 context.Context, arg1 int64) ([]*core.Token, error) {
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockTokenStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTokenStore)(nil).List), arg0, arg1)
+}
+
+// Update mocks base method
+func (m *MockTokenStore) Update(arg0 context.Context, arg1 *core.Token) error {
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockTokenStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTokenStore)(nil).Update), arg0, arg1)
+}
+
+// MockSessionStore is a mock of SessionStore interface
+type MockSessionStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionStoreMockRecorder
+}
+
+// MockSessionStoreMockRecorder is the mock recorder for MockSessionStore
+type MockSessionStoreMockRecorder struct {
+	mock *MockSessionStore
+}
+
+// NewMockSessionStore creates a new mock instance
+func NewMockSessionStore(ctrl *gomock.Controller) *MockSessionStore {
+	mock := &MockSessionStore{ctrl: ctrl}
+	mock.recorder = &MockSessionStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSessionStore) EXPECT() *MockSessionStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockSessionStore) Create(arg0 context.Context, arg1 *core.UserSession) error {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockSessionStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessionStore)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method
+func (m *MockSessionStore) Delete(arg0 context.Context, arg1 *core.UserSession) error {
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockSessionStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSessionStore)(nil).Delete), arg0, arg1)
+}
+
+// DeleteExpired mocks base method
+func (m *MockSessionStore) DeleteExpired(arg0 context.Context, arg1 int64) error {
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired
+func (mr *MockSessionStoreMockRecorder) DeleteExpired(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockSessionStore)(nil).DeleteExpired), arg0, arg1)
+}
+
+// DeleteUser mocks base method
+func (m *MockSessionStore) DeleteUser(arg0 context.Context, arg1 int64) error {
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser
+func (mr *MockSessionStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockSessionStore)(nil).DeleteUser), arg0, arg1)
+}
+
+// Find mocks base method
+func (m *MockSessionStore) Find(arg0 context.Context, arg1 int64) (*core.UserSession, error) {
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.UserSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockSessionStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockSessionStore)(nil).Find), arg0, arg1)
+}
+
+// FindHash mocks base method
+func (m *MockSessionStore) FindHash(arg0 context.Context, arg1 string) (*core.UserSession, error) {
+	ret := m.ctrl.Call(m, "FindHash", arg0, arg1)
+	ret0, _ := ret[0].(*core.UserSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindHash indicates an expected call of FindHash
+func (mr *MockSessionStoreMockRecorder) FindHash(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHash", reflect.TypeOf((*MockSessionStore)(nil).FindHash), arg0, arg1)
+}
+
+// List mocks base method
+func (m *MockSessionStore) List(arg0 context.Context, arg1 int64) ([]*core.UserSession, error) {
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.UserSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockSessionStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSessionStore)(nil).List), arg0, arg1)
+}
+
+// Update mocks base method
+func (m *MockSessionStore) Update(arg0 context.Context, arg1 *core.UserSession) error {
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockSessionStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSessionStore)(nil).Update), arg0, arg1)
+}
+
+// MockRoleStore is a mock of RoleStore interface
+type MockRoleStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleStoreMockRecorder
+}
+
+// MockRoleStoreMockRecorder is the mock recorder for MockRoleStore
+type MockRoleStoreMockRecorder struct {
+	mock *MockRoleStore
+}
+
+// NewMockRoleStore creates a new mock instance
+func NewMockRoleStore(ctrl *gomock.Controller) *MockRoleStore {
+	mock := &MockRoleStore{ctrl: ctrl}
+	mock.recorder = &MockRoleStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRoleStore) EXPECT() *MockRoleStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockRoleStore) Create(arg0 context.Context, arg1 *core.Role) error {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockRoleStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRoleStore)(nil).Create), arg0, arg1)
+}
+
+// CreateBinding mocks base method
+func (m *MockRoleStore) CreateBinding(arg0 context.Context, arg1 *core.RoleBinding) error {
+	ret := m.ctrl.Call(m, "CreateBinding", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBinding indicates an expected call of CreateBinding
+func (mr *MockRoleStoreMockRecorder) CreateBinding(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBinding", reflect.TypeOf((*MockRoleStore)(nil).CreateBinding), arg0, arg1)
+}
+
+// Delete mocks base method
+func (m *MockRoleStore) Delete(arg0 context.Context, arg1 *core.Role) error {
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockRoleStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRoleStore)(nil).Delete), arg0, arg1)
+}
+
+// DeleteBinding mocks base method
+func (m *MockRoleStore) DeleteBinding(arg0 context.Context, arg1 *core.RoleBinding) error {
+	ret := m.ctrl.Call(m, "DeleteBinding", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBinding indicates an expected call of DeleteBinding
+func (mr *MockRoleStoreMockRecorder) DeleteBinding(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBinding", reflect.TypeOf((*MockRoleStore)(nil).DeleteBinding), arg0, arg1)
+}
+
+// Find mocks base method
+func (m *MockRoleStore) Find(arg0 context.Context, arg1 int64) (*core.Role, error) {
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockRoleStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockRoleStore)(nil).Find), arg0, arg1)
+}
+
+// FindBinding mocks base method
+func (m *MockRoleStore) FindBinding(arg0 context.Context, arg1 int64) (*core.RoleBinding, error) {
+	ret := m.ctrl.Call(m, "FindBinding", arg0, arg1)
+	ret0, _ := ret[0].(*core.RoleBinding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBinding indicates an expected call of FindBinding
+func (mr *MockRoleStoreMockRecorder) FindBinding(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBinding", reflect.TypeOf((*MockRoleStore)(nil).FindBinding), arg0, arg1)
+}
+
+// FindName mocks base method
+func (m *MockRoleStore) FindName(arg0 context.Context, arg1 string) (*core.Role, error) {
+	ret := m.ctrl.Call(m, "FindName", arg0, arg1)
+	ret0, _ := ret[0].(*core.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindName indicates an expected call of FindName
+func (mr *MockRoleStoreMockRecorder) FindName(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindName", reflect.TypeOf((*MockRoleStore)(nil).FindName), arg0, arg1)
+}
+
+// List mocks base method
+func (m *MockRoleStore) List(arg0 context.Context) ([]*core.Role, error) {
+	ret := m.ctrl.Call(m, "List", arg0)
+	ret0, _ := ret[0].([]*core.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockRoleStoreMockRecorder) List(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRoleStore)(nil).List), arg0)
+}
+
+// ListBindingRoles mocks base method
+func (m *MockRoleStore) ListBindingRoles(arg0 context.Context) ([]*core.RoleBinding, error) {
+	ret := m.ctrl.Call(m, "ListBindingRoles", arg0)
+	ret0, _ := ret[0].([]*core.RoleBinding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBindingRoles indicates an expected call of ListBindingRoles
+func (mr *MockRoleStoreMockRecorder) ListBindingRoles(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBindingRoles", reflect.TypeOf((*MockRoleStore)(nil).ListBindingRoles), arg0)
+}
+
+// ListBindings mocks base method
+func (m *MockRoleStore) ListBindings(arg0 context.Context) ([]*core.RoleBinding, error) {
+	ret := m.ctrl.Call(m, "ListBindings", arg0)
+	ret0, _ := ret[0].([]*core.RoleBinding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBindings indicates an expected call of ListBindings
+func (mr *MockRoleStoreMockRecorder) ListBindings(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBindings", reflect.TypeOf((*MockRoleStore)(nil).ListBindings), arg0)
+}
+
+// Update mocks base method
+func (m *MockRoleStore) Update(arg0 context.Context, arg1 *core.Role) error {
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockRoleStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRoleStore)(nil).Update), arg0, arg1)
+}
+
+// MockRoleService is a mock of RoleService interface
+type MockRoleService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleServiceMockRecorder
+}
+
+// MockRoleServiceMockRecorder is the mock recorder for MockRoleService
+type MockRoleServiceMockRecorder struct {
+	mock *MockRoleService
+}
+
+// NewMockRoleService creates a new mock instance
+func NewMockRoleService(ctrl *gomock.Controller) *MockRoleService {
+	mock := &MockRoleService{ctrl: ctrl}
+	mock.recorder = &MockRoleServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRoleService) EXPECT() *MockRoleServiceMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method
+func (m *MockRoleService) List(arg0 context.Context, arg1 *core.User, arg2 string) ([]*core.Role, error) {
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*core.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockRoleServiceMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRoleService)(nil).List), arg0, arg1, arg2)
+}
+
+// Permits mocks base method
+func (m *MockRoleService) Permits(arg0 context.Context, arg1 *core.User, arg2, arg3 string) (bool, error) {
+	ret := m.ctrl.Call(m, "Permits", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Permits indicates an expected call of Permits
+func (mr *MockRoleServiceMockRecorder) Permits(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Permits", reflect.TypeOf((*MockRoleService)(nil).Permits), arg0, arg1, arg2, arg3)
+}
+
+// MockAuditStore is a mock of AuditStore interface
+type MockAuditStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditStoreMockRecorder
+}
+
+// MockAuditStoreMockRecorder is the mock recorder for MockAuditStore
+type MockAuditStoreMockRecorder struct {
+	mock *MockAuditStore
+}
+
+// NewMockAuditStore creates a new mock instance
+func NewMockAuditStore(ctrl *gomock.Controller) *MockAuditStore {
+	mock := &MockAuditStore{ctrl: ctrl}
+	mock.recorder = &MockAuditStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditStore) EXPECT() *MockAuditStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockAuditStore) Create(arg0 context.Context, arg1 *core.AuditEvent) error {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockAuditStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuditStore)(nil).Create), arg0, arg1)
+}
+
+// List mocks base method
+func (m *MockAuditStore) List(arg0 context.Context, arg1 core.AuditParams) ([]*core.AuditEvent, error) {
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*core.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockAuditStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditStore)(nil).List), arg0, arg1)
+}
+
+// MockAuditService is a mock of AuditService interface
+type MockAuditService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditServiceMockRecorder
+}
+
+// MockAuditServiceMockRecorder is the mock recorder for MockAuditService
+type MockAuditServiceMockRecorder struct {
+	mock *MockAuditService
+}
+
+// NewMockAuditService creates a new mock instance
+func NewMockAuditService(ctrl *gomock.Controller) *MockAuditService {
+	mock := &MockAuditService{ctrl: ctrl}
+	mock.recorder = &MockAuditServiceMockRecorder{mock}
+	return mock
 }
 
-// Pending indicates an expected call of Pending
-func (mr *MockBuildStoreMockRecorder) Pending(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockBuildStore)(nil).Pending), arg0)
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditService) EXPECT() *MockAuditServiceMockRecorder {
+	return m.recorder
 }
 
-// Purge mocks base method
-func (m *MockBuildStore) Purge(arg0 context.Context, arg1, arg2 int64) error {
-	ret := m.ctrl.Call(m, "Purge", arg0, arg1, arg2)
+// Record mocks base method
+func (m *MockAuditService) Record(arg0 context.Context, arg1 *core.AuditEvent) error {
+	ret := m.ctrl.Call(m, "Record", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Purge indicates an expected call of Purge
-func (mr *MockBuildStoreMockRecorder) Purge(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockBuildStore)(nil).Purge), arg0, arg1, arg2)
+// Record indicates an expected call of Record
+func (mr *MockAuditServiceMockRecorder) Record(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditService)(nil).Record), arg0, arg1)
 }
 
-// Running mocks base method
-func (m *MockBuildStore) Running(arg0 context.Context) ([]*core.Build, error) {
-	ret := m.ctrl.Call(m, "Running", arg0)
-	ret0, _ := ret[0].([]*core.Build)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+// MockLoginStore is a mock of LoginStore interface
+type MockLoginStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginStoreMockRecorder
 }
 
-// Running indicates an expected call of Running
-func (mr *MockBuildStoreMockRecorder) Running(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Running", reflect.TypeOf((*MockBuildStore)(nil).Running), arg0)
+// MockLoginStoreMockRecorder is the mock recorder for MockLoginStore
+type MockLoginStoreMockRecorder struct {
+	mock *MockLoginStore
 }
 
-// Update mocks base method
-func (m *MockBuildStore) Update(arg0 context.Context, arg1 *core.Build) error {
-	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+// NewMockLoginStore creates a new mock instance
+func NewMockLoginStore(ctrl *gomock.Controller) *MockLoginStore {
+	mock := &MockLoginStore{ctrl: ctrl}
+	mock.recorder = &MockLoginStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLoginStore) EXPECT() *MockLoginStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockLoginStore) Create(arg0 context.Context, arg1 *core.LoginEvent) error {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Update indicates an expected call of Update
-func (mr *MockBuildStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockBuildStore)(nil).Update), arg0, arg1)
+// Create indicates an expected call of Create
+func (mr *MockLoginStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockLoginStore)(nil).Create), arg0, arg1)
 }
 
-// MockCronStore is a mock of CronStore interface
-type MockCronStore struct {
+// List mocks base method
+func (m *MockLoginStore) List(arg0 context.Context, arg1 string, arg2 int) ([]*core.LoginEvent, error) {
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*core.LoginEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockLoginStoreMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLoginStore)(nil).List), arg0, arg1, arg2)
+}
+
+// MockApprovalStore is a mock of ApprovalStore interface
+type MockApprovalStore struct {
 	ctrl     *gomock.Controller
-	recorder *MockCronStoreMockRecorder
+	recorder *MockApprovalStoreMockRecorder
 }
 
-// MockCronStoreMockRecorder is the mock recorder for MockCronStore
-type MockCronStoreMockRecorder struct {
-	mock *MockCronStore
+// MockApprovalStoreMockRecorder is the mock recorder for MockApprovalStore
+type MockApprovalStoreMockRecorder struct {
+	mock *MockApprovalStore
 }
 
-// NewMockCronStore creates a new mock instance
-func NewMockCronStore(ctrl *gomock.Controller) *MockCronStore {
-	mock := &MockCronStore{ctrl: ctrl}
-	mock.recorder = &MockCronStoreMockRecorder{mock}
+// NewMockApprovalStore creates a new mock instance
+func NewMockApprovalStore(ctrl *gomock.Controller) *MockApprovalStore {
+	mock := &MockApprovalStore{ctrl: ctrl}
+	mock.recorder = &MockApprovalStoreMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (m *MockCronStore) EXPECT() *MockCronStoreMockRecorder {
+func (m *MockApprovalStore) EXPECT() *MockApprovalStoreMockRecorder {
 	return m.recorder
 }
 
 // Create mocks base method
-func (m *MockCronStore) Create(arg0 context.Context, arg1 *core.Cron) error {
+func (m *MockApprovalStore) Create(arg0 context.Context, arg1 *core.Approval) error {
 	ret := m.ctrl.Call(m, "Create", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create
-func (mr *MockCronStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCronStore)(nil).Create), arg0, arg1)
+func (mr *MockApprovalStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockApprovalStore)(nil).Create), arg0, arg1)
 }
 
-// Delete mocks base method
-func (m *MockCronStore) Delete(arg0 context.Context, arg1 *core.Cron) error {
-	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+// CreateRule mocks base method
+func (m *MockApprovalStore) CreateRule(arg0 context.Context, arg1 *core.ApprovalRule) error {
+	ret := m.ctrl.Call(m, "CreateRule", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Delete indicates an expected call of Delete
-func (mr *MockCronStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCronStore)(nil).Delete), arg0, arg1)
+// CreateRule indicates an expected call of CreateRule
+func (mr *MockApprovalStoreMockRecorder) CreateRule(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRule", reflect.TypeOf((*MockApprovalStore)(nil).CreateRule), arg0, arg1)
 }
 
-// Find mocks base method
-func (m *MockCronStore) Find(arg0 context.Context, arg1 int64) (*core.Cron, error) {
-	ret := m.ctrl.Call(m, "Find", arg0, arg1)
-	ret0, _ := ret[0].(*core.Cron)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+// DeleteRule mocks base method
+func (m *MockApprovalStore) DeleteRule(arg0 context.Context, arg1 *core.ApprovalRule) error {
+	ret := m.ctrl.Call(m, "DeleteRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// Find indicates an expected call of Find
-func (mr *MockCronStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockCronStore)(nil).Find), arg0, arg1)
+// DeleteRule indicates an expected call of DeleteRule
+func (mr *MockApprovalStoreMockRecorder) DeleteRule(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRule", reflect.TypeOf((*MockApprovalStore)(nil).DeleteRule), arg0, arg1)
 }
 
-// FindName mocks base method
-func (m *MockCronStore) FindName(arg0 context.Context, arg1 int64, arg2 string) (*core.Cron, error) {
-	ret := m.ctrl.Call(m, "FindName", arg0, arg1, arg2)
-	ret0, _ := ret[0].(*core.Cron)
+// FindRule mocks base method
+func (m *MockApprovalStore) FindRule(arg0 context.Context, arg1 int64) (*core.ApprovalRule, error) {
+	ret := m.ctrl.Call(m, "FindRule", arg0, arg1)
+	ret0, _ := ret[0].(*core.ApprovalRule)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindName indicates an expected call of FindName
-func (mr *MockCronStoreMockRecorder) FindName(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindName", reflect.TypeOf((*MockCronStore)(nil).FindName), arg0, arg1, arg2)
+// FindRule indicates an expected call of FindRule
+func (mr *MockApprovalStoreMockRecorder) FindRule(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRule", reflect.TypeOf((*MockApprovalStore)(nil).FindRule), arg0, arg1)
 }
 
 // List mocks base method
-func (m *MockCronStore) List(arg0 context.Context, arg1 int64) ([]*core.Cron, error) {
+func (m *MockApprovalStore) List(arg0 context.Context, arg1 int64) ([]*core.Approval, error) {
 	ret := m.ctrl.Call(m, "List", arg0, arg1)
-	ret0, _ := ret[0].([]*core.Cron)
+	ret0, _ := ret[0].([]*core.Approval)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List
-func (mr *MockCronStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCronStore)(nil).List), arg0, arg1)
+func (mr *MockApprovalStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockApprovalStore)(nil).List), arg0, arg1)
 }
 
-// Ready mocks base method
-func (m *MockCronStore) Ready(arg0 context.Context, arg1 int64) ([]*core.Cron, error) {
-	ret := m.ctrl.Call(m, "Ready", arg0, arg1)
-	ret0, _ := ret[0].([]*core.Cron)
+// ListRules mocks base method
+func (m *MockApprovalStore) ListRules(arg0 context.Context) ([]*core.ApprovalRule, error) {
+	ret := m.ctrl.Call(m, "ListRules", arg0)
+	ret0, _ := ret[0].([]*core.ApprovalRule)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Ready indicates an expected call of Ready
-func (mr *MockCronStoreMockRecorder) Ready(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockCronStore)(nil).Ready), arg0, arg1)
+// ListRules indicates an expected call of ListRules
+func (mr *MockApprovalStoreMockRecorder) ListRules(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRules", reflect.TypeOf((*MockApprovalStore)(nil).ListRules), arg0)
 }
 
-// Update mocks base method
-func (m *MockCronStore) Update(arg0 context.Context, arg1 *core.Cron) error {
-	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+// UpdateRule mocks base method
+func (m *MockApprovalStore) UpdateRule(arg0 context.Context, arg1 *core.ApprovalRule) error {
+	ret := m.ctrl.Call(m, "UpdateRule", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Update indicates an expected call of Update
-func (mr *MockCronStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCronStore)(nil).Update), arg0, arg1)
+// UpdateRule indicates an expected call of UpdateRule
+func (mr *MockApprovalStoreMockRecorder) UpdateRule(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRule", reflect.TypeOf((*MockApprovalStore)(nil).UpdateRule), arg0, arg1)
+}
+
+// MockApprovalService is a mock of ApprovalService interface
+type MockApprovalService struct {
+	ctrl     *gomock.Controller
+	recorder *MockApprovalServiceMockRecorder
+}
+
+// MockApprovalServiceMockRecorder is the mock recorder for MockApprovalService
+type MockApprovalServiceMockRecorder struct {
+	mock *MockApprovalService
+}
+
+// NewMockApprovalService creates a new mock instance
+func NewMockApprovalService(ctrl *gomock.Controller) *MockApprovalService {
+	mock := &MockApprovalService{ctrl: ctrl}
+	mock.recorder = &MockApprovalServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockApprovalService) EXPECT() *MockApprovalServiceMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method
+func (m *MockApprovalService) Authorize(arg0 context.Context, arg1 *core.User, arg2 *core.Repository, arg3 *core.Build, arg4 *core.ApprovalRule) error {
+	ret := m.ctrl.Call(m, "Authorize", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize
+func (mr *MockApprovalServiceMockRecorder) Authorize(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockApprovalService)(nil).Authorize), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Rule mocks base method
+func (m *MockApprovalService) Rule(arg0 context.Context, arg1 *core.Repository, arg2 *core.Build) (*core.ApprovalRule, error) {
+	ret := m.ctrl.Call(m, "Rule", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*core.ApprovalRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rule indicates an expected call of Rule
+func (mr *MockApprovalServiceMockRecorder) Rule(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rule", reflect.TypeOf((*MockApprovalService)(nil).Rule), arg0, arg1, arg2)
 }
 
 // MockLogStore is a mock of LogStore interface
@@ -1162,6 +1906,19 @@ func (mr *MockStepStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockStepStore)(nil).List), arg0, arg1)
 }
 
+// ListAfter mocks base method
+func (m *MockStepStore) ListAfter(arg0 context.Context, arg1 int64, arg2 int) ([]*core.Step, error) {
+	ret := m.ctrl.Call(m, "ListAfter", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*core.Step)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter
+func (mr *MockStepStoreMockRecorder) ListAfter(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*MockStepStore)(nil).ListAfter), arg0, arg1, arg2)
+}
+
 // Update mocks base method
 func (m *MockStepStore) Update(arg0 context.Context, arg1 *core.Step) error {
 	ret := m.ctrl.Call(m, "Update", arg0, arg1)
@@ -1607,27 +2364,27 @@ func (m *MockSession) EXPECT() *MockSessionMockRecorder {
 }
 
 // Create mocks base method
-func (m *MockSession) Create(arg0 http.ResponseWriter, arg1 *core.User) error {
-	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+func (m *MockSession) Create(arg0 http.ResponseWriter, arg1 *http.Request, arg2 *core.User) error {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create
-func (mr *MockSessionMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSession)(nil).Create), arg0, arg1)
+func (mr *MockSessionMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSession)(nil).Create), arg0, arg1, arg2)
 }
 
 // Delete mocks base method
-func (m *MockSession) Delete(arg0 http.ResponseWriter) error {
-	ret := m.ctrl.Call(m, "Delete", arg0)
+func (m *MockSession) Delete(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockSessionMockRecorder) Delete(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSession)(nil).Delete), arg0)
+func (mr *MockSessionMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSession)(nil).Delete), arg0, arg1)
 }
 
 // Get mocks base method
@@ -1643,6 +2400,28 @@ func (mr *MockSessionMockRecorder) Get(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSession)(nil).Get), arg0)
 }
 
+// Revoke mocks base method
+func (m *MockSession) Revoke(arg0 int64) {
+	m.ctrl.Call(m, "Revoke", arg0)
+}
+
+// Revoke indicates an expected call of Revoke
+func (mr *MockSessionMockRecorder) Revoke(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockSession)(nil).Revoke), arg0)
+}
+
+// Upgrade mocks base method
+func (m *MockSession) Upgrade(arg0 http.ResponseWriter, arg1 *http.Request, arg2 *core.User) error {
+	ret := m.ctrl.Call(m, "Upgrade", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upgrade indicates an expected call of Upgrade
+func (mr *MockSessionMockRecorder) Upgrade(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upgrade", reflect.TypeOf((*MockSession)(nil).Upgrade), arg0, arg1, arg2)
+}
+
 // MockOrganizationService is a mock of OrganizationService interface
 type MockOrganizationService struct {
 	ctrl     *gomock.Controller
@@ -1679,6 +2458,20 @@ func (mr *MockOrganizationServiceMockRecorder) List(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOrganizationService)(nil).List), arg0, arg1)
 }
 
+// Membership mocks base method
+func (m *MockOrganizationService) Membership(arg0 context.Context, arg1 *core.User, arg2 string) (bool, bool, error) {
+	ret := m.ctrl.Call(m, "Membership", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Membership indicates an expected call of Membership
+func (mr *MockOrganizationServiceMockRecorder) Membership(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Membership", reflect.TypeOf((*MockOrganizationService)(nil).Membership), arg0, arg1, arg2)
+}
+
 // MockSecretService is a mock of SecretService interface
 type MockSecretService struct {
 	ctrl     *gomock.Controller
@@ -1787,6 +2580,174 @@ func (mr *MockConfigServiceMockRecorder) Find(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockConfigService)(nil).Find), arg0, arg1)
 }
 
+// MockConfigStore is a mock of ConfigStore interface
+type MockConfigStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockConfigStoreMockRecorder
+}
+
+// MockConfigStoreMockRecorder is the mock recorder for MockConfigStore
+type MockConfigStoreMockRecorder struct {
+	mock *MockConfigStore
+}
+
+// NewMockConfigStore creates a new mock instance
+func NewMockConfigStore(ctrl *gomock.Controller) *MockConfigStore {
+	mock := &MockConfigStore{ctrl: ctrl}
+	mock.recorder = &MockConfigStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockConfigStore) EXPECT() *MockConfigStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockConfigStore) Create(arg0 context.Context, arg1 int64, arg2 *core.Config) error {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockConfigStoreMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockConfigStore)(nil).Create), arg0, arg1, arg2)
+}
+
+// Find mocks base method
+func (m *MockConfigStore) Find(arg0 context.Context, arg1 int64) (*core.Config, error) {
+	ret := m.ctrl.Call(m, "Find", arg0, arg1)
+	ret0, _ := ret[0].(*core.Config)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockConfigStoreMockRecorder) Find(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockConfigStore)(nil).Find), arg0, arg1)
+}
+
+// MockIdentityStore is a mock of IdentityStore interface
+type MockIdentityStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityStoreMockRecorder
+}
+
+// MockIdentityStoreMockRecorder is the mock recorder for MockIdentityStore
+type MockIdentityStoreMockRecorder struct {
+	mock *MockIdentityStore
+}
+
+// NewMockIdentityStore creates a new mock instance
+func NewMockIdentityStore(ctrl *gomock.Controller) *MockIdentityStore {
+	mock := &MockIdentityStore{ctrl: ctrl}
+	mock.recorder = &MockIdentityStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockIdentityStore) EXPECT() *MockIdentityStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockIdentityStore) Create(arg0 context.Context, arg1 *core.Identity) error {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create
+func (mr *MockIdentityStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIdentityStore)(nil).Create), arg0, arg1)
+}
+
+// Find mocks base method
+func (m *MockIdentityStore) Find(arg0 context.Context, arg1, arg2 string) (*core.Identity, error) {
+	ret := m.ctrl.Call(m, "Find", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*core.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockIdentityStoreMockRecorder) Find(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockIdentityStore)(nil).Find), arg0, arg1, arg2)
+}
+
+// MockConvertService is a mock of ConvertService interface
+type MockConvertService struct {
+	ctrl     *gomock.Controller
+	recorder *MockConvertServiceMockRecorder
+}
+
+// MockConvertServiceMockRecorder is the mock recorder for MockConvertService
+type MockConvertServiceMockRecorder struct {
+	mock *MockConvertService
+}
+
+// NewMockConvertService creates a new mock instance
+func NewMockConvertService(ctrl *gomock.Controller) *MockConvertService {
+	mock := &MockConvertService{ctrl: ctrl}
+	mock.recorder = &MockConvertServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockConvertService) EXPECT() *MockConvertServiceMockRecorder {
+	return m.recorder
+}
+
+// Convert mocks base method
+func (m *MockConvertService) Convert(arg0 context.Context, arg1 *core.ConvertArgs) (*core.Config, error) {
+	ret := m.ctrl.Call(m, "Convert", arg0, arg1)
+	ret0, _ := ret[0].(*core.Config)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Convert indicates an expected call of Convert
+func (mr *MockConvertServiceMockRecorder) Convert(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockConvertService)(nil).Convert), arg0, arg1)
+}
+
+// MockPolicyService is a mock of PolicyService interface
+type MockPolicyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPolicyServiceMockRecorder
+}
+
+// MockPolicyServiceMockRecorder is the mock recorder for MockPolicyService
+type MockPolicyServiceMockRecorder struct {
+	mock *MockPolicyService
+}
+
+// NewMockPolicyService creates a new mock instance
+func NewMockPolicyService(ctrl *gomock.Controller) *MockPolicyService {
+	mock := &MockPolicyService{ctrl: ctrl}
+	mock.recorder = &MockPolicyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPolicyService) EXPECT() *MockPolicyServiceMockRecorder {
+	return m.recorder
+}
+
+// Evaluate mocks base method
+func (m *MockPolicyService) Evaluate(arg0 context.Context, arg1 *core.PolicyArgs) ([]*core.PolicyViolation, error) {
+	ret := m.ctrl.Call(m, "Evaluate", arg0, arg1)
+	ret0, _ := ret[0].([]*core.PolicyViolation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Evaluate indicates an expected call of Evaluate
+func (mr *MockPolicyServiceMockRecorder) Evaluate(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockPolicyService)(nil).Evaluate), arg0, arg1)
+}
+
 // MockTriggerer is a mock of Triggerer interface
 type MockTriggerer struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,279 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/drone/drone/core"
+
+	"github.com/dustin/go-humanize"
+)
+
+// TruncateLines limits the log lines to the maximum size in
+// bytes. The head and tail of the logs are retained, and a
+// marker line is inserted in place of the omitted lines. It
+// returns true if the logs were truncated.
+func TruncateLines(lines []*core.Line, limit int64) ([]*core.Line, bool) {
+	if limit <= 0 {
+		return lines, false
+	}
+	t := newTruncator(limit)
+	for _, line := range lines {
+		t.add(line)
+	}
+	return t.result()
+}
+
+// truncateReader reads the json-encoded log lines from the
+// reader and limits the lines to the maximum size in bytes.
+// The lines are decoded one at a time, so that memory usage
+// is bounded by the limit and not by the size of the input.
+func truncateReader(r io.Reader, limit int64) ([]*core.Line, bool, error) {
+	t := newTruncator(limit)
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, false, fmt.Errorf("manager: invalid log format")
+	}
+	for dec.More() {
+		line := new(core.Line)
+		if err := dec.Decode(line); err != nil {
+			return nil, false, err
+		}
+		t.add(line)
+	}
+	lines, truncated := t.result()
+	return lines, truncated, nil
+}
+
+// truncationMarker returns the line that replaces the omitted
+// log lines.
+func truncationMarker(number int, timestamp int64, lines int, size int64) *core.Line {
+	return &core.Line{
+		Number:    number,
+		Timestamp: timestamp,
+		Message: fmt.Sprintf(
+			"[drone] log output truncated: %d lines (%s) omitted\n",
+			lines,
+			humanize.Bytes(uint64(size)),
+		),
+	}
+}
+
+// truncator retains the head and the tail of the logs. Half
+// of the limit is allocated to the head, and the remainder to
+// the tail, which is maintained as a sliding window.
+type truncator struct {
+	headLimit int64
+	tailLimit int64
+
+	head     []*core.Line
+	headSize int64
+	tail     []*core.Line
+	tailSize int64
+
+	omitted     int
+	omittedSize int64
+	first       *core.Line
+}
+
+func newTruncator(limit int64) *truncator {
+	return &truncator{
+		headLimit: limit / 2,
+		tailLimit: limit - limit/2,
+	}
+}
+
+func (t *truncator) add(line *core.Line) {
+	size := int64(len(line.Message))
+	if len(t.tail) == 0 && t.omitted == 0 && t.headSize+size <= t.headLimit {
+		t.head = append(t.head, line)
+		t.headSize += size
+		return
+	}
+	t.tail = append(t.tail, line)
+	t.tailSize += size
+	for t.tailSize > t.tailLimit && len(t.tail) > 0 {
+		drop := t.tail[0]
+		if t.first == nil {
+			t.first = drop
+		}
+		t.tail[0] = nil
+		t.tail = t.tail[1:]
+		t.tailSize -= int64(len(drop.Message))
+		t.omitted++
+		t.omittedSize += int64(len(drop.Message))
+	}
+}
+
+func (t *truncator) result() ([]*core.Line, bool) {
+	if t.omitted == 0 {
+		return append(t.head, t.tail...), false
+	}
+	lines := make([]*core.Line, 0, len(t.head)+len(t.tail)+1)
+	lines = append(lines, t.head...)
+	lines = append(lines, truncationMarker(
+		t.first.Number,
+		t.first.Timestamp,
+		t.omitted,
+		t.omittedSize,
+	))
+	lines = append(lines, t.tail...)
+	return lines, true
+}
+
+// limiter tracks log usage for steps and stages that are
+// currently executing, and enforces the configured limits.
+type limiter struct {
+	sync.Mutex
+
+	limits core.LogLimits
+	steps  map[int64]*usage
+	stages map[int64]int64
+}
+
+// usage tracks the log usage for a single step.
+type usage struct {
+	stage     int64
+	written   int64
+	exceeded  bool
+	truncated bool
+}
+
+func newLimiter(limits core.LogLimits) *limiter {
+	return &limiter{
+		limits: limits,
+		steps:  map[int64]*usage{},
+		stages: map[int64]int64{},
+	}
+}
+
+// enabled returns true if log limits are configured.
+func (l *limiter) enabled() bool {
+	return l != nil && (l.limits.Step > 0 || l.limits.Stage > 0)
+}
+
+// start registers the step with its parent stage.
+func (l *limiter) start(step *core.Step) {
+	if !l.enabled() {
+		return
+	}
+	l.Lock()
+	l.steps[step.ID] = &usage{stage: step.StageID}
+	l.Unlock()
+}
+
+// limit returns the effective log limit for the step.
+func (l *limiter) limit(step int64) int64 {
+	l.Lock()
+	defer l.Unlock()
+	return l.limitLocked(step)
+}
+
+func (l *limiter) limitLocked(step int64) int64 {
+	var used int64
+	if u, ok := l.steps[step]; ok {
+		used = l.stages[u.stage]
+	}
+	return l.limits.Limit(used)
+}
+
+// write records the line written to the live log stream. It
+// returns true if the line should be written, and a non-nil
+// marker line the first time the limit is exceeded.
+func (l *limiter) write(step int64, line *core.Line) (bool, *core.Line) {
+	if !l.enabled() {
+		return true, nil
+	}
+	l.Lock()
+	defer l.Unlock()
+	u, ok := l.steps[step]
+	if !ok {
+		u = new(usage)
+		l.steps[step] = u
+	}
+	if u.exceeded {
+		return false, nil
+	}
+	size := int64(len(line.Message))
+	if limit := l.limitLocked(step); u.written+size > limit {
+		u.exceeded = true
+		return false, &core.Line{
+			Number:    line.Number,
+			Timestamp: line.Timestamp,
+			Message: fmt.Sprintf(
+				"[drone] log output exceeded the %s limit and is no longer streamed\n",
+				humanize.Bytes(uint64(limit)),
+			),
+		}
+	}
+	u.written += size
+	return true, nil
+}
+
+// upload records the size of the uploaded step logs.
+func (l *limiter) upload(step int64, lines []*core.Line, truncated bool) {
+	var size int64
+	for _, line := range lines {
+		size += int64(len(line.Message))
+	}
+	l.Lock()
+	u, ok := l.steps[step]
+	if !ok {
+		u = new(usage)
+		l.steps[step] = u
+	}
+	u.truncated = u.truncated || truncated
+	if u.stage != 0 {
+		l.stages[u.stage] += size
+	}
+	l.Unlock()
+}
+
+// done removes the step and returns true if the step logs
+// were truncated.
+func (l *limiter) done(step int64) bool {
+	if !l.enabled() {
+		return false
+	}
+	l.Lock()
+	defer l.Unlock()
+	u, ok := l.steps[step]
+	if !ok {
+		return false
+	}
+	delete(l.steps, step)
+	return u.truncated
+}
+
+// release removes the stage.
+func (l *limiter) release(stage int64) {
+	if !l.enabled() {
+		return
+	}
+	l.Lock()
+	delete(l.stages, stage)
+	l.Unlock()
+}
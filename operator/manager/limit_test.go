@@ -0,0 +1,151 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/drone/drone/core"
+)
+
+func TestTruncateLines(t *testing.T) {
+	lines := []*core.Line{
+		{Number: 0, Message: "aaaa\n"},
+		{Number: 1, Message: "bbbb\n"},
+		{Number: 2, Message: "cccc\n"},
+		{Number: 3, Message: "dddd\n"},
+		{Number: 4, Message: "eeee\n"},
+		{Number: 5, Message: "ffff\n"},
+	}
+
+	got, truncated := TruncateLines(lines, 20)
+	if !truncated {
+		t.Errorf("Want logs truncated")
+	}
+	if len(got) != 5 {
+		t.Errorf("Want 5 lines, got %d", len(got))
+		return
+	}
+	want := []string{"aaaa\n", "bbbb\n", "", "eeee\n", "ffff\n"}
+	for i, line := range got {
+		if i == 2 {
+			if line.Number != 2 {
+				t.Errorf("Want marker line number 2, got %d", line.Number)
+			}
+			if got, want := line.Message, "[drone] log output truncated: 2 lines (10 B) omitted\n"; got != want {
+				t.Errorf("Want marker %q, got %q", want, got)
+			}
+			continue
+		}
+		if line.Message != want[i] {
+			t.Errorf("Want line %q, got %q", want[i], line.Message)
+		}
+	}
+}
+
+func TestTruncateLines_NoLimit(t *testing.T) {
+	lines := []*core.Line{
+		{Number: 0, Message: "aaaa\n"},
+		{Number: 1, Message: "bbbb\n"},
+	}
+	got, truncated := TruncateLines(lines, 0)
+	if truncated {
+		t.Errorf("Want logs not truncated")
+	}
+	if len(got) != 2 {
+		t.Errorf("Want 2 lines, got %d", len(got))
+	}
+
+	got, truncated = TruncateLines(lines, 100)
+	if truncated {
+		t.Errorf("Want logs not truncated")
+	}
+	if len(got) != 2 {
+		t.Errorf("Want 2 lines, got %d", len(got))
+	}
+}
+
+func TestTruncateReader(t *testing.T) {
+	var lines []*core.Line
+	for i := 0; i < 100; i++ {
+		lines = append(lines, &core.Line{Number: i, Message: "0123456789\n"})
+	}
+	raw, _ := json.Marshal(lines)
+
+	got, truncated, err := truncateReader(bytes.NewReader(raw), 110)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !truncated {
+		t.Errorf("Want logs truncated")
+	}
+	if got, want := len(got), 11; got != want {
+		t.Errorf("Want %d lines, got %d", want, got)
+		return
+	}
+	if got, want := got[0].Number, 0; got != want {
+		t.Errorf("Want first line %d, got %d", want, got)
+	}
+	if got, want := got[len(got)-1].Number, 99; got != want {
+		t.Errorf("Want last line %d, got %d", want, got)
+	}
+}
+
+func TestTruncateReader_Invalid(t *testing.T) {
+	_, _, err := truncateReader(bytes.NewBufferString(`{}`), 100)
+	if err == nil {
+		t.Errorf("Want error for invalid log format")
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(core.LogLimits{Step: 10, Stage: 15})
+	l.start(&core.Step{ID: 1, StageID: 2})
+	l.start(&core.Step{ID: 3, StageID: 2})
+
+	if ok, marker := l.write(1, &core.Line{Message: "01234"}); !ok || marker != nil {
+		t.Errorf("Want line written")
+	}
+	if ok, marker := l.write(1, &core.Line{Message: "0123456789"}); ok || marker == nil {
+		t.Errorf("Want line dropped and marker written")
+	}
+	if ok, marker := l.write(1, &core.Line{Message: "0"}); ok || marker != nil {
+		t.Errorf("Want line dropped without marker")
+	}
+
+	l.upload(1, []*core.Line{{Message: "0123456789"}}, true)
+	if !l.done(1) {
+		t.Errorf("Want step logs truncated")
+	}
+	if got, want := l.limit(3), int64(5); got != want {
+		t.Errorf("Want remaining stage limit %d, got %d", want, got)
+	}
+	l.release(2)
+	if got, want := l.limit(3), int64(10); got != want {
+		t.Errorf("Want step limit %d, got %d", want, got)
+	}
+}
+
+func TestLogLimits(t *testing.T) {
+	tests := []struct {
+		limits core.LogLimits
+		used   int64
+		want   int64
+	}{
+		{core.LogLimits{}, 0, 0},
+		{core.LogLimits{Step: 10}, 100, 10},
+		{core.LogLimits{Stage: 10}, 4, 6},
+		{core.LogLimits{Step: 5, Stage: 10}, 4, 5},
+		{core.LogLimits{Step: 5, Stage: 10}, 10, 1},
+	}
+	for _, test := range tests {
+		if got := test.limits.Limit(test.used); got != test.want {
+			t.Errorf("Want limit %d, got %d", test.want, got)
+		}
+	}
+}
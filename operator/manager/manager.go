@@ -17,6 +17,8 @@ package manager
 import (
 	"bytes"
 	"context"
+	"database/sql"
+	"encoding/json"
 	"io"
 	"time"
 
@@ -101,7 +103,10 @@ type (
 func New(
 	builds core.BuildStore,
 	config core.ConfigService,
+	configs core.ConfigStore,
+	converter core.ConvertService,
 	events core.Pubsub,
+	limits core.LogLimits,
 	logs core.LogStore,
 	logz core.LogStream,
 	netrcs core.NetrcService,
@@ -118,6 +123,8 @@ func New(
 	return &Manager{
 		Builds:    builds,
 		Config:    config,
+		Configs:   configs,
+		Converter: converter,
 		Events:    events,
 		Logs:      logs,
 		Logz:      logz,
@@ -131,6 +138,7 @@ func New(
 		System:    system,
 		Users:     users,
 		Webhook:   webhook,
+		limiter:   newLimiter(limits),
 	}
 }
 
@@ -139,6 +147,8 @@ func New(
 type Manager struct {
 	Builds    core.BuildStore
 	Config    core.ConfigService
+	Configs   core.ConfigStore
+	Converter core.ConvertService
 	Events    core.Pubsub
 	Logs      core.LogStore
 	Logz      core.LogStream
@@ -152,6 +162,8 @@ type Manager struct {
 	System    *core.System
 	Users     core.UserStore
 	Webhook   core.WebhookSender
+
+	limiter *limiter
 }
 
 // Request requests the next available build stage for execution.
@@ -270,11 +282,14 @@ func (m *Manager) Details(ctx context.Context, id int64) (*Context, error) {
 		logger.Warnln("manager: cannot find repository owner")
 		return nil, err
 	}
-	config, err := m.Config.Find(noContext, &core.ConfigArgs{
-		User:  user,
-		Repo:  repo,
-		Build: build,
-	})
+	// the build executes the configuration that was persisted
+	// when the build was created. If the build was created
+	// before configurations were persisted, the configuration
+	// is fetched and converted.
+	config, err := m.Configs.Find(noContext, build.ID)
+	if err == sql.ErrNoRows {
+		config, err = m.resolve(user, repo, build)
+	}
 	if err != nil {
 		logger = logger.WithError(err)
 		logger.Warnln("manager: cannot find configuration")
@@ -307,6 +322,24 @@ func (m *Manager) Details(ctx context.Context, id int64) (*Context, error) {
 	}, nil
 }
 
+// resolve fetches and converts the configuration file.
+func (m *Manager) resolve(user *core.User, repo *core.Repository, build *core.Build) (*core.Config, error) {
+	config, err := m.Config.Find(noContext, &core.ConfigArgs{
+		User:  user,
+		Repo:  repo,
+		Build: build,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.Converter.Convert(noContext, &core.ConvertArgs{
+		User:   user,
+		Repo:   repo,
+		Build:  build,
+		Config: config,
+	})
+}
+
 // Before signals the build step is about to start.
 func (m *Manager) Before(ctx context.Context, step *core.Step) error {
 	logger := logrus.WithFields(
@@ -324,6 +357,7 @@ func (m *Manager) Before(ctx context.Context, step *core.Step) error {
 		logger.Warnln("manager: cannot create log stream")
 		return err
 	}
+	m.limiter.start(step)
 	updater := &updater{
 		Builds:  m.Builds,
 		Events:  m.Events,
@@ -346,6 +380,10 @@ func (m *Manager) After(ctx context.Context, step *core.Step) error {
 	)
 	logger.Debugln("manager: updating step status")
 
+	if m.limiter.done(step.ID) {
+		step.LogTruncated = true
+	}
+
 	var errs error
 	updater := &updater{
 		Builds:  m.Builds,
@@ -385,6 +423,7 @@ func (m *Manager) BeforeAll(ctx context.Context, stage *core.Stage) error {
 
 // AfterAll signals the build stage is complete.
 func (m *Manager) AfterAll(ctx context.Context, stage *core.Stage) error {
+	m.limiter.release(stage.ID)
 	t := &teardown{
 		Builds:    m.Builds,
 		Events:    m.Events,
@@ -447,8 +486,16 @@ func (m *Manager) Watch(ctx context.Context, id int64) (bool, error) {
 	return stage.IsDone(), nil
 }
 
-// Write writes a line to the build logs.
+// Write writes a line to the build logs. Lines that exceed
+// the log limit are not written to the stream.
 func (m *Manager) Write(ctx context.Context, step int64, line *core.Line) error {
+	ok, marker := m.limiter.write(step, line)
+	if !ok && marker == nil {
+		return nil
+	}
+	if marker != nil {
+		line = marker
+	}
 	err := m.Logz.Write(ctx, step, line)
 	if err != nil {
 		logger := logrus.WithError(err)
@@ -458,9 +505,15 @@ func (m *Manager) Write(ctx context.Context, step int64, line *core.Line) error
 	return err
 }
 
-// Upload uploads the full logs.
+// Upload uploads the full logs. If the logs exceed the log
+// limit, only the head and tail of the logs are stored.
 func (m *Manager) Upload(ctx context.Context, step int64, r io.Reader) error {
-	err := m.Logs.Create(ctx, step, r)
+	var err error
+	if m.limiter.enabled() {
+		err = m.uploadLimited(ctx, step, r)
+	} else {
+		err = m.Logs.Create(ctx, step, r)
+	}
 	if err != nil {
 		logger := logrus.WithError(err)
 		logger = logger.WithField("step-id", step)
@@ -471,12 +524,21 @@ func (m *Manager) Upload(ctx context.Context, step int64, r io.Reader) error {
 
 // UploadBytes uploads the full logs.
 func (m *Manager) UploadBytes(ctx context.Context, step int64, data []byte) error {
-	buf := bytes.NewBuffer(data)
-	err := m.Logs.Create(ctx, step, buf)
+	return m.Upload(ctx, step, bytes.NewBuffer(data))
+}
+
+func (m *Manager) uploadLimited(ctx context.Context, step int64, r io.Reader) error {
+	lines, truncated, err := truncateReader(r, m.limiter.limit(step))
 	if err != nil {
-		logger := logrus.WithError(err)
-		logger = logger.WithField("step-id", step)
-		logger.Warnln("manager: cannot upload complete logs")
+		return err
 	}
-	return err
+	m.limiter.upload(step, lines, truncated)
+	if lines == nil {
+		lines = []*core.Line{}
+	}
+	data, err := json.Marshal(lines)
+	if err != nil {
+		return err
+	}
+	return m.Logs.Create(ctx, step, bytes.NewBuffer(data))
 }
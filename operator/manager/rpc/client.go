@@ -16,6 +16,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/drone/drone/operator/manager"
@@ -34,8 +35,18 @@ var bufpool = bpool.NewBufferPool(64)
 // Client defines an RPC client.
 type Client struct {
 	token  string
+	agent  string
 	server string
 	client *retryablehttp.Client
+
+	mu     sync.Mutex
+	stages map[string]*stageToken
+}
+
+// stageToken is a stage token issued by the server.
+type stageToken struct {
+	stage int64
+	value string
 }
 
 // NewClient returns a new rpc client that is able to
@@ -51,9 +62,16 @@ func NewClient(server, token string) *Client {
 		client: client,
 		server: strings.TrimSuffix(server, "/"),
 		token:  token,
+		stages: map[string]*stageToken{},
 	}
 }
 
+// SetAgent sets the agent name used to authenticate with
+// the server using per-agent credentials.
+func (s *Client) SetAgent(name string) {
+	s.agent = name
+}
+
 // SetDebug enabled debug-level logging within the retryable
 // http.Client. This can be useful if you are debugging network
 // connectivity issues and want to monitor disconnects,
@@ -73,7 +91,7 @@ func (s *Client) Request(ctx context.Context, args *manager.Request) (*core.Stag
 
 	in := &requestRequest{Request: args}
 	out := &core.Stage{}
-	err := s.send(timeout, "/rpc/v1/request", in, out)
+	err := s.send(timeout, "/rpc/v1/request", "", in, out)
 
 	// The request is performing long polling and is subject
 	// to a client-side and server-side timeout. The timeout
@@ -88,14 +106,14 @@ func (s *Client) Request(ctx context.Context, args *manager.Request) (*core.Stag
 // Accept accepts the build stage for execution.
 func (s *Client) Accept(ctx context.Context, stage int64, machine string) error {
 	in := &acceptRequest{Stage: stage, Machine: machine}
-	return s.send(noContext, "/rpc/v1/accept", in, nil)
+	return s.send(noContext, "/rpc/v1/accept", "", in, nil)
 }
 
 // Netrc returns a valid netrc for execution.
 func (s *Client) Netrc(ctx context.Context, repo int64) (*core.Netrc, error) {
 	in := &netrcRequest{repo}
 	out := &core.Netrc{}
-	err := s.send(noContext, "/rpc/v1/netrc", in, out)
+	err := s.send(noContext, "/rpc/v1/netrc", s.lookup("repo", repo), in, out)
 	return out, err
 }
 
@@ -103,7 +121,7 @@ func (s *Client) Netrc(ctx context.Context, repo int64) (*core.Netrc, error) {
 func (s *Client) Details(ctx context.Context, stage int64) (*manager.Context, error) {
 	in := &detailsRequest{Stage: stage}
 	out := &buildContextToken{}
-	err := s.send(noContext, "/rpc/v1/details", in, out)
+	err := s.send(noContext, "/rpc/v1/details", s.lookup("stage", stage), in, out)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +136,7 @@ func (s *Client) Details(ctx context.Context, stage int64) (*manager.Context, er
 func (s *Client) Before(ctx context.Context, step *core.Step) error {
 	in := &stepRequest{Step: step}
 	out := &core.Step{}
-	err := s.send(noContext, "/rpc/v1/before", in, out)
+	err := s.send(noContext, "/rpc/v1/before", s.lookup("stage", step.StageID), in, out)
 	if err != nil {
 		return err
 	}
@@ -134,7 +152,7 @@ func (s *Client) Before(ctx context.Context, step *core.Step) error {
 func (s *Client) After(ctx context.Context, step *core.Step) error {
 	in := &stepRequest{Step: step}
 	out := &core.Step{}
-	err := s.send(noContext, "/rpc/v1/after", in, out)
+	err := s.send(noContext, "/rpc/v1/after", s.lookup("stage", step.StageID), in, out)
 	if err != nil {
 		return err
 	}
@@ -149,7 +167,7 @@ func (s *Client) After(ctx context.Context, step *core.Step) error {
 func (s *Client) BeforeAll(ctx context.Context, stage *core.Stage) error {
 	in := &stageRequest{Stage: stage}
 	out := &core.Stage{}
-	err := s.send(noContext, "/rpc/v1/beforeAll", in, out)
+	err := s.send(noContext, "/rpc/v1/beforeAll", s.lookup("stage", stage.ID), in, out)
 	if err != nil {
 		return err
 	}
@@ -169,10 +187,13 @@ func (s *Client) BeforeAll(ctx context.Context, stage *core.Stage) error {
 func (s *Client) AfterAll(ctx context.Context, stage *core.Stage) error {
 	in := &stageRequest{Stage: stage}
 	out := &core.Stage{}
-	err := s.send(noContext, "/rpc/v1/afterAll", in, out)
+	err := s.send(noContext, "/rpc/v1/afterAll", s.lookup("stage", stage.ID), in, out)
 	if err != nil {
 		return err
 	}
+	// the stage is complete and the stage token is no
+	// longer required.
+	s.forget(stage.ID)
 	// the stage timestamps and version (optomistic locking)
 	// are updated when the step is created. Copy the updated
 	// values back to the original step object.
@@ -185,7 +206,7 @@ func (s *Client) AfterAll(ctx context.Context, stage *core.Stage) error {
 func (s *Client) Watch(ctx context.Context, build int64) (bool, error) {
 	in := &watchRequest{build}
 	out := &watchResponse{}
-	err := s.send(ctx, "/rpc/v1/watch", in, out)
+	err := s.send(ctx, "/rpc/v1/watch", s.lookup("build", build), in, out)
 	return out.Done, err
 }
 
@@ -193,22 +214,22 @@ func (s *Client) Write(ctx context.Context, step int64, line *core.Line) error {
 	in := writePool.Get().(*writeRequest)
 	in.Step = step
 	in.Line = line
-	err := s.send(noContext, "/rpc/v1/write", in, nil)
+	err := s.send(noContext, "/rpc/v1/write", s.lookup("step", step), in, nil)
 	writePool.Put(in)
 	return err
 }
 
 func (s *Client) Upload(ctx context.Context, step int64, r io.Reader) error {
 	endpoint := "/rpc/v1/upload?id=" + fmt.Sprint(step)
-	return s.upload(noContext, endpoint, r)
+	return s.upload(noContext, endpoint, s.lookup("step", step), r)
 }
 
 func (s *Client) UploadBytes(ctx context.Context, step int64, data []byte) error {
 	endpoint := "/rpc/v1/upload?id=" + fmt.Sprint(step)
-	return s.upload(noContext, endpoint, data)
+	return s.upload(noContext, endpoint, s.lookup("step", step), data)
 }
 
-func (s *Client) send(ctx context.Context, path string, in, out interface{}) error {
+func (s *Client) send(ctx context.Context, path, token string, in, out interface{}) error {
 	// Source a buffer from a pool. The agent may generate a
 	// large number of small requests for log entries. This will
 	// help reduce pressure on the garbage collector.
@@ -226,7 +247,7 @@ func (s *Client) send(ctx context.Context, path string, in, out interface{}) err
 		return err
 	}
 	req = req.WithContext(ctx)
-	req.Header.Set("X-Drone-Token", s.token)
+	s.setHeaders(req, token)
 
 	res, err := s.client.Do(req)
 	if res != nil {
@@ -235,6 +256,7 @@ func (s *Client) send(ctx context.Context, path string, in, out interface{}) err
 	if err != nil {
 		return err
 	}
+	s.store(res.Header.Get("X-Drone-Stage-Token"))
 
 	// Check the response for a 409 conflict. This indicates an
 	// optimtistic lock error, in which case multiple clients may
@@ -268,20 +290,21 @@ func (s *Client) send(ctx context.Context, path string, in, out interface{}) err
 	return json.NewDecoder(res.Body).Decode(out)
 }
 
-func (s *Client) upload(ctx context.Context, path string, body interface{}) error {
+func (s *Client) upload(ctx context.Context, path, token string, body interface{}) error {
 	url := s.server + path
 	req, err := retryablehttp.NewRequest("POST", url, body)
 	if err != nil {
 		return err
 	}
 	req = req.WithContext(ctx)
-	req.Header.Set("X-Drone-Token", s.token)
+	s.setHeaders(req, token)
 
 	res, err := s.client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
+	s.store(res.Header.Get("X-Drone-Stage-Token"))
 
 	if res.StatusCode > 299 {
 		body, _ := ioutil.ReadAll(res.Body)
@@ -293,6 +316,64 @@ func (s *Client) upload(ctx context.Context, path string, body interface{}) erro
 	return nil
 }
 
+// helper function sets the authentication headers.
+func (s *Client) setHeaders(req *retryablehttp.Request, token string) {
+	req.Header.Set("X-Drone-Token", s.token)
+	if s.agent != "" {
+		req.Header.Set("X-Drone-Agent", s.agent)
+	}
+	if token != "" {
+		req.Header.Set("X-Drone-Stage-Token", token)
+	}
+}
+
+// store indexes the stage token issued by the server by
+// stage, repository and step, so that it can be included
+// in subsequent requests for the stage.
+func (s *Client) store(token string) {
+	if token == "" {
+		return
+	}
+	claims, err := decodeToken(token)
+	if err != nil {
+		return
+	}
+	t := &stageToken{stage: claims.Stage, value: token}
+	s.mu.Lock()
+	s.stages[fmt.Sprintf("stage:%d", claims.Stage)] = t
+	if claims.Repo != 0 {
+		s.stages[fmt.Sprintf("repo:%d", claims.Repo)] = t
+	}
+	if claims.Build != 0 {
+		s.stages[fmt.Sprintf("build:%d", claims.Build)] = t
+	}
+	for _, step := range claims.Steps {
+		s.stages[fmt.Sprintf("step:%d", step)] = t
+	}
+	s.mu.Unlock()
+}
+
+// lookup returns the stage token for the resource.
+func (s *Client) lookup(kind string, id int64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.stages[fmt.Sprintf("%s:%d", kind, id)]; ok {
+		return t.value
+	}
+	return ""
+}
+
+// forget removes the stage tokens for the stage.
+func (s *Client) forget(stage int64) {
+	s.mu.Lock()
+	for key, t := range s.stages {
+		if t.stage == stage {
+			delete(s.stages, key)
+		}
+	}
+	s.mu.Unlock()
+}
+
 // helper function returns true if the http.Request should be
 // retried based on error and http status code. This function
 // is used by the retryablehttp.Client.
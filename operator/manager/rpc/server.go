@@ -8,14 +8,20 @@ package rpc
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/json"
 	"io"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/drone/drone/core"
 	"github.com/drone/drone/operator/manager"
 	"github.com/drone/drone/store/shared/db"
+
+	"github.com/hashicorp/golang-lru"
 )
 
 // default http request timeout
@@ -23,59 +29,153 @@ var defaultTimeout = time.Second * 30
 
 var noContext = context.Background()
 
+// default stage token lifetime
+var defaultTokenTTL = time.Hour
+
 // Server is an rpc handler that enables remote interaction
 // between the server and controller using the http transport.
 type Server struct {
 	manager manager.BuildManager
+	steps   core.StepStore
 	secret  string
+	agents  map[string]string
+	key     []byte
+	ttl     time.Duration
+
+	// stages caches the stage identifier of each step,
+	// which is used to authorize the step scoped calls.
+	stages *lru.Cache
 }
 
 // NewServer returns a new rpc server that enables remote
 // interaction with the build controller using the http transport.
-func NewServer(manager manager.BuildManager, secret string) *Server {
+func NewServer(manager manager.BuildManager, steps core.StepStore, secret string) *Server {
+	stages, _ := lru.New(10000)
 	return &Server{
 		manager: manager,
+		steps:   steps,
 		secret:  secret,
+		key:     deriveKey(secret),
+		ttl:     defaultTokenTTL,
+		stages:  stages,
 	}
 }
 
-func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if s.secret == "" {
-		w.WriteHeader(401) // not found
-		return
+// SetAgents sets the per-agent credentials, a map of agent
+// name to secret. An agent authenticates with its name in the
+// X-Drone-Agent header and its secret in the X-Drone-Token
+// header.
+func (s *Server) SetAgents(agents map[string]string) {
+	s.agents = agents
+}
+
+// SetSigningKey sets the key used to sign stage tokens. If
+// unset, the key is derived from the shared secret, so that
+// stage tokens survive a server restart and are accepted by
+// every server in the cluster.
+func (s *Server) SetSigningKey(key string) {
+	if key != "" {
+		s.key = []byte(key)
+	}
+}
+
+// SetTokenTTL sets the stage token lifetime. The token is
+// renewed with each rpc call made for the stage.
+func (s *Server) SetTokenTTL(ttl time.Duration) {
+	if ttl > 0 {
+		s.ttl = ttl
 	}
-	if r.Header.Get("X-Drone-Token") != s.secret {
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	agent, ok := s.authenticate(r)
+	if !ok {
 		w.WriteHeader(401) // not authorized
 		return
 	}
 	switch r.URL.Path {
-	case "/rpc/v1/write":
-		s.handleWrite(w, r)
 	case "/rpc/v1/request":
 		s.handleRequest(w, r)
 	case "/rpc/v1/accept":
-		s.handleAccept(w, r)
+		s.handleAccept(w, r, agent)
+	case "/rpc/v1/watch":
+		s.handleWatch(w, r, agent)
+	default:
+		s.serveStage(w, r, agent)
+	}
+}
+
+// serveStage serves rpc calls that are scoped to a stage,
+// and require a valid stage token issued to the agent.
+func (s *Server) serveStage(w http.ResponseWriter, r *http.Request, agent string) {
+	claims, err := parseToken(s.key, r.Header.Get("X-Drone-Stage-Token"))
+	if err != nil || claims.Agent != agent {
+		w.WriteHeader(403) // forbidden
+		io.WriteString(w, errInvalidToken.Error())
+		return
+	}
+	switch r.URL.Path {
+	case "/rpc/v1/write":
+		s.handleWrite(w, r, claims)
 	case "/rpc/v1/netrc":
-		s.handleNetrc(w, r)
+		s.handleNetrc(w, r, claims)
 	case "/rpc/v1/details":
-		s.handleDetails(w, r)
+		s.handleDetails(w, r, claims)
 	case "/rpc/v1/before":
-		s.handleBefore(w, r)
+		s.handleBefore(w, r, claims)
 	case "/rpc/v1/after":
-		s.handleAfter(w, r)
+		s.handleAfter(w, r, claims)
 	case "/rpc/v1/beforeAll":
-		s.handleBeforeAll(w, r)
+		s.handleBeforeAll(w, r, claims)
 	case "/rpc/v1/afterAll":
-		s.handleAfterAll(w, r)
-	case "/rpc/v1/watch":
-		s.handleWatch(w, r)
+		s.handleAfterAll(w, r, claims)
 	case "/rpc/v1/upload":
-		s.handleUpload(w, r)
+		s.handleUpload(w, r, claims)
 	default:
 		w.WriteHeader(404)
 	}
 }
 
+// authenticate returns the name of the authenticated agent.
+// Agents that authenticate with the shared secret have an
+// empty name.
+func (s *Server) authenticate(r *http.Request) (string, bool) {
+	token := r.Header.Get("X-Drone-Token")
+	if token == "" {
+		return "", false
+	}
+	if name := r.Header.Get("X-Drone-Agent"); name != "" {
+		secret, ok := s.agents[name]
+		return name, ok && secret != "" && equal(secret, token)
+	}
+	return "", s.secret != "" && equal(s.secret, token)
+}
+
+// renew writes a renewed stage token to the response header
+// if the request includes a valid stage token issued to the
+// agent for the build. It is used by calls that are not scoped
+// to a stage, but that are made periodically while the stage
+// is running, to prevent the token from expiring.
+func (s *Server) renew(w http.ResponseWriter, r *http.Request, agent string, build int64) {
+	token := r.Header.Get("X-Drone-Stage-Token")
+	if token == "" {
+		return
+	}
+	claims, err := parseToken(s.key, token)
+	if err == nil && claims.Agent == agent && claims.Build == build {
+		s.issue(w, claims)
+	}
+}
+
+// issue writes a renewed stage token to the response header.
+func (s *Server) issue(w http.ResponseWriter, claims *stageClaims) {
+	claims.Expires = time.Now().Add(s.ttl).Unix()
+	token, err := signToken(s.key, claims)
+	if err == nil {
+		w.Header().Set("X-Drone-Stage-Token", token)
+	}
+}
+
 func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
@@ -95,7 +195,7 @@ func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stage)
 }
 
-func (s *Server) handleAccept(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleAccept(w http.ResponseWriter, r *http.Request, agent string) {
 	ctx := r.Context()
 	in := &acceptRequest{}
 	err := json.NewDecoder(r.Body).Decode(in)
@@ -108,10 +208,11 @@ func (s *Server) handleAccept(w http.ResponseWriter, r *http.Request) {
 		writeError(w, err)
 		return
 	}
+	s.issue(w, &stageClaims{Agent: agent, Stage: in.Stage})
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (s *Server) handleNetrc(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleNetrc(w http.ResponseWriter, r *http.Request, claims *stageClaims) {
 	ctx := r.Context()
 	in := &netrcRequest{}
 	err := json.NewDecoder(r.Body).Decode(in)
@@ -119,15 +220,20 @@ func (s *Server) handleNetrc(w http.ResponseWriter, r *http.Request) {
 		writeBadRequest(w, err)
 		return
 	}
+	if in.Repo == 0 || in.Repo != claims.Repo {
+		writeForbidden(w)
+		return
+	}
 	netrc, err := s.manager.Netrc(ctx, in.Repo)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
+	s.issue(w, claims)
 	json.NewEncoder(w).Encode(netrc)
 }
 
-func (s *Server) handleDetails(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleDetails(w http.ResponseWriter, r *http.Request, claims *stageClaims) {
 	ctx := r.Context()
 	in := &detailsRequest{}
 	err := json.NewDecoder(r.Body).Decode(in)
@@ -135,11 +241,22 @@ func (s *Server) handleDetails(w http.ResponseWriter, r *http.Request) {
 		writeBadRequest(w, err)
 		return
 	}
+	if in.Stage != claims.Stage {
+		writeForbidden(w)
+		return
+	}
 	build, err := s.manager.Details(ctx, in.Stage)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
+	// the repository is added to the token scope, which
+	// authorizes the agent to request the repository netrc.
+	// The build is added so that the token is renewed when
+	// the agent watches the build for cancellation.
+	claims.Repo = build.Repo.ID
+	claims.Build = build.Build.ID
+	s.issue(w, claims)
 	out := &buildContextToken{
 		Secret:  build.Repo.Secret,
 		Context: build,
@@ -147,7 +264,7 @@ func (s *Server) handleDetails(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(out)
 }
 
-func (s *Server) handleBefore(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleBefore(w http.ResponseWriter, r *http.Request, claims *stageClaims) {
 	ctx := r.Context()
 	in := &stepRequest{}
 	err := json.NewDecoder(r.Body).Decode(in)
@@ -155,15 +272,23 @@ func (s *Server) handleBefore(w http.ResponseWriter, r *http.Request) {
 		writeBadRequest(w, err)
 		return
 	}
+	if in.Step == nil || !s.permitsStep(ctx, claims, in.Step.ID) {
+		writeForbidden(w)
+		return
+	}
+	// the stage is not accepted from the agent, since it
+	// is persisted when the step is updated.
+	in.Step.StageID = claims.Stage
 	err = s.manager.Before(ctx, in.Step)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
+	s.issue(w, claims)
 	json.NewEncoder(w).Encode(in.Step)
 }
 
-func (s *Server) handleAfter(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleAfter(w http.ResponseWriter, r *http.Request, claims *stageClaims) {
 	ctx := r.Context()
 	in := &stepRequest{}
 	err := json.NewDecoder(r.Body).Decode(in)
@@ -171,15 +296,23 @@ func (s *Server) handleAfter(w http.ResponseWriter, r *http.Request) {
 		writeBadRequest(w, err)
 		return
 	}
+	if in.Step == nil || !s.permitsStep(ctx, claims, in.Step.ID) {
+		writeForbidden(w)
+		return
+	}
+	// the stage is not accepted from the agent, since it
+	// is persisted when the step is updated.
+	in.Step.StageID = claims.Stage
 	err = s.manager.After(ctx, in.Step)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
+	s.issue(w, claims)
 	json.NewEncoder(w).Encode(in.Step)
 }
 
-func (s *Server) handleBeforeAll(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleBeforeAll(w http.ResponseWriter, r *http.Request, claims *stageClaims) {
 	ctx := r.Context()
 	in := &stageRequest{}
 	err := json.NewDecoder(r.Body).Decode(in)
@@ -187,15 +320,35 @@ func (s *Server) handleBeforeAll(w http.ResponseWriter, r *http.Request) {
 		writeBadRequest(w, err)
 		return
 	}
+	if in.Stage == nil || in.Stage.ID != claims.Stage {
+		writeForbidden(w)
+		return
+	}
+	// the steps are persisted with the stage identifier
+	// provided by the agent, which must match the stage
+	// token to prevent creating steps in another stage.
+	for _, step := range in.Stage.Steps {
+		if step == nil || step.StageID != claims.Stage {
+			writeForbidden(w)
+			return
+		}
+	}
 	err = s.manager.BeforeAll(ctx, in.Stage)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
+	// the steps are created when the stage starts, and are
+	// added to the token scope.
+	for _, step := range in.Stage.Steps {
+		claims.addStep(step.ID)
+		s.stages.Add(step.ID, step.StageID)
+	}
+	s.issue(w, claims)
 	json.NewEncoder(w).Encode(in.Stage)
 }
 
-func (s *Server) handleAfterAll(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleAfterAll(w http.ResponseWriter, r *http.Request, claims *stageClaims) {
 	ctx := r.Context()
 	in := &stageRequest{}
 	err := json.NewDecoder(r.Body).Decode(in)
@@ -203,6 +356,10 @@ func (s *Server) handleAfterAll(w http.ResponseWriter, r *http.Request) {
 		writeBadRequest(w, err)
 		return
 	}
+	if in.Stage == nil || in.Stage.ID != claims.Stage {
+		writeForbidden(w)
+		return
+	}
 	err = s.manager.AfterAll(ctx, in.Stage)
 	if err != nil {
 		writeError(w, err)
@@ -211,7 +368,7 @@ func (s *Server) handleAfterAll(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(in.Stage)
 }
 
-func (s *Server) handleWrite(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleWrite(w http.ResponseWriter, r *http.Request, claims *stageClaims) {
 	in := writePool.Get().(*writeRequest)
 	in.Line = nil
 	in.Step = 0
@@ -221,16 +378,21 @@ func (s *Server) handleWrite(w http.ResponseWriter, r *http.Request) {
 		writeBadRequest(w, err)
 		return
 	}
+	if !s.permitsStep(r.Context(), claims, in.Step) {
+		writeForbidden(w)
+		return
+	}
 	err = s.manager.Write(noContext, in.Step, in.Line)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
+	s.issue(w, claims)
 	w.WriteHeader(http.StatusNoContent)
 	writePool.Put(in)
 }
 
-func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request, claims *stageClaims) {
 	ctx := r.Context()
 	in := r.FormValue("id")
 	id, err := strconv.ParseInt(in, 10, 64)
@@ -238,15 +400,20 @@ func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
 		writeBadRequest(w, err)
 		return
 	}
+	if !s.permitsStep(ctx, claims, id) {
+		writeForbidden(w)
+		return
+	}
 	err = s.manager.Upload(ctx, id, r.Body)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
+	s.issue(w, claims)
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request, agent string) {
 	ctx := r.Context()
 	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
 	defer cancel()
@@ -262,16 +429,64 @@ func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
 		writeError(w, err)
 		return
 	}
+	s.renew(w, r, agent, in.Build)
 	json.NewEncoder(w).Encode(&watchResponse{
 		Done: done,
 	})
 }
 
+// permitsStep returns true if the step is in the token scope,
+// and the stored step belongs to the stage.
+func (s *Server) permitsStep(ctx context.Context, claims *stageClaims, id int64) bool {
+	if !claims.hasStep(id) {
+		return false
+	}
+	stage, err := s.stageOf(ctx, id)
+	return err == nil && stage == claims.Stage
+}
+
+// stageOf returns the stage identifier of the stored step.
+// The result is cached, since a step never changes stages and
+// the logs are written to the step many times.
+func (s *Server) stageOf(ctx context.Context, id int64) (int64, error) {
+	if stage, ok := s.stages.Get(id); ok {
+		return stage.(int64), nil
+	}
+	step, err := s.steps.Find(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	s.stages.Add(id, step.StageID)
+	return step.StageID, nil
+}
+
 func writeBadRequest(w http.ResponseWriter, err error) {
 	w.WriteHeader(500) // should retry
 	io.WriteString(w, err.Error())
 }
 
+func writeForbidden(w http.ResponseWriter) {
+	w.WriteHeader(403) // should fail
+	io.WriteString(w, "rpc: stage token does not permit request")
+}
+
+// helper function derives the stage token signing key from
+// the shared secret. An empty secret returns an empty key,
+// in which case stage tokens are never issued.
+func deriveKey(secret string) []byte {
+	if secret == "" {
+		return nil
+	}
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte("drone-stage-token"))
+	return h.Sum(nil)
+}
+
+// helper function compares the secrets in constant time.
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
 func writeError(w http.ResponseWriter, err error) {
 	if err == context.DeadlineExceeded {
 		w.WriteHeader(524) // should retry
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/operator/manager"
@@ -33,10 +34,19 @@ type Server struct {
 }
 
 // NewServer returns a no-op rpc server.
-func NewServer(manager.BuildManager, string) *Server {
+func NewServer(manager.BuildManager, core.StepStore, string) *Server {
 	return &Server{}
 }
 
+// SetAgents is a no-op.
+func (Server) SetAgents(map[string]string) {}
+
+// SetSigningKey is a no-op.
+func (Server) SetSigningKey(string) {}
+
+// SetTokenTTL is a no-op.
+func (Server) SetTokenTTL(time.Duration) {}
+
 // Request requests the next available build stage for execution.
 func (Server) Request(ctx context.Context, args *manager.Request) (*core.Stage, error) {
 	return nil, errors.New("not implemented")
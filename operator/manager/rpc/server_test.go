@@ -5,3 +5,391 @@
 // +build !oss
 
 package rpc
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/operator/manager"
+)
+
+// this test verifies that an agent is able to call the stage
+// scoped rpc endpoints for a stage it accepted, using the
+// stage token issued by the server.
+func TestServer_StageToken(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	client := newTestClient(server.URL, "agent-1", "correct-horse-battery-staple")
+
+	if err := client.Accept(noContext, 1, "localhost"); err != nil {
+		t.Error(err)
+		return
+	}
+	m, err := client.Details(noContext, 1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := client.Netrc(noContext, m.Repo.ID); err != nil {
+		t.Error(err)
+	}
+	stage := &core.Stage{ID: 1, Steps: []*core.Step{{StageID: 1, Number: 1}}}
+	if err := client.BeforeAll(noContext, stage); err != nil {
+		t.Error(err)
+		return
+	}
+	step := stage.Steps[0]
+	if err := client.Before(noContext, step); err != nil {
+		t.Error(err)
+	}
+	if err := client.Write(noContext, step.ID, &core.Line{Message: "hello"}); err != nil {
+		t.Error(err)
+	}
+	if err := client.UploadBytes(noContext, step.ID, []byte("[]")); err != nil {
+		t.Error(err)
+	}
+	if err := client.After(noContext, step); err != nil {
+		t.Error(err)
+	}
+	if err := client.AfterAll(noContext, stage); err != nil {
+		t.Error(err)
+	}
+	if got := client.lookup("stage", 1); got != "" {
+		t.Errorf("Want stage token removed when the stage completes")
+	}
+}
+
+// this test verifies that an agent cannot call the stage
+// scoped rpc endpoints for a stage accepted by another agent.
+func TestServer_StageToken_OtherAgent(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	client := newTestClient(server.URL, "agent-1", "correct-horse-battery-staple")
+	if err := client.Accept(noContext, 1, "localhost"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	other := newTestClient(server.URL, "agent-2", "battery-staple-correct-horse")
+	_, err := other.Details(noContext, 1)
+	if got, want := statusCode(err), 403; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+
+	// the stage token is issued to the accepting agent and
+	// cannot be replayed by another agent.
+	other.store(client.lookup("stage", 1))
+	_, err = other.Details(noContext, 1)
+	if got, want := statusCode(err), 403; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that the stage token does not permit
+// access to resources outside of the stage scope.
+func TestServer_StageToken_Scope(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	client := newTestClient(server.URL, "agent-1", "correct-horse-battery-staple")
+	client.Accept(noContext, 1, "localhost")
+	client.Accept(noContext, 2, "localhost")
+
+	// the netrc is not in scope until the stage details,
+	// and therefore the repository, are requested.
+	token := client.lookup("stage", 1)
+	err := client.send(noContext, "/rpc/v1/netrc", token, &netrcRequest{Repo: 42}, new(core.Netrc))
+	if got, want := statusCode(err), 403; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+
+	// the stage token cannot be used for another stage.
+	err = client.send(noContext, "/rpc/v1/details", token, &detailsRequest{Stage: 2}, new(buildContextToken))
+	if got, want := statusCode(err), 403; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+
+	// the stage token cannot be used to write to steps
+	// outside of the stage.
+	err = client.send(noContext, "/rpc/v1/write", token, &writeRequest{Step: 99, Line: new(core.Line)}, nil)
+	if got, want := statusCode(err), 403; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+	err = client.send(noContext, "/rpc/v1/after", token, &stepRequest{Step: &core.Step{ID: 99, StageID: 1}}, new(core.Step))
+	if got, want := statusCode(err), 403; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+}
+
+// this test verifies that an agent cannot create steps in
+// another stage, and cannot access a step that belongs to
+// another stage even if the step is in the token scope.
+func TestServer_StageToken_OtherStage(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	client := newTestClient(server.URL, "agent-1", "correct-horse-battery-staple")
+	if err := client.Accept(noContext, 1, "localhost"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	stage := &core.Stage{ID: 1, Steps: []*core.Step{{StageID: 1, Number: 1}, {StageID: 2, Number: 2}}}
+	err := client.BeforeAll(noContext, stage)
+	if got, want := statusCode(err), 403; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+
+	// the token is signed with step 7 in scope, which is
+	// stored in stage 2.
+	claims := &stageClaims{
+		Agent:   "agent-1",
+		Stage:   1,
+		Steps:   []int64{7},
+		Expires: time.Now().Add(time.Hour).Unix(),
+	}
+	token, _ := signToken(deriveKey("shared-secret"), claims)
+	err = client.send(noContext, "/rpc/v1/write", token, &writeRequest{Step: 7, Line: new(core.Line)}, nil)
+	if got, want := statusCode(err), 403; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+	err = client.send(noContext, "/rpc/v1/before", token, &stepRequest{Step: &core.Step{ID: 7, StageID: 1}}, new(core.Step))
+	if got, want := statusCode(err), 403; got != want {
+		t.Errorf("Want status code %d, got %d", want, got)
+	}
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	tests := []struct {
+		agent  string
+		secret string
+	}{
+		{"agent-1", "battery-staple-correct-horse"},
+		{"agent-3", "correct-horse-battery-staple"},
+		{"", "correct-horse-battery-staple"},
+		{"", ""},
+	}
+	for i, test := range tests {
+		client := newTestClient(server.URL, test.agent, test.secret)
+		err := client.Accept(noContext, 1, "localhost")
+		if got, want := statusCode(err), 401; got != want {
+			t.Errorf("Want status code %d, got %d at index %d", want, got, i)
+		}
+	}
+}
+
+func TestServer_SharedSecret(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	client := newTestClient(server.URL, "", "shared-secret")
+	if err := client.Accept(noContext, 1, "localhost"); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := client.Details(noContext, 1); err != nil {
+		t.Error(err)
+	}
+}
+
+// this test verifies that the stage token is renewed when
+// the agent watches the build for cancellation.
+func TestServer_Watch_RenewsToken(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	client := newTestClient(server.URL, "agent-1", "correct-horse-battery-staple")
+	if err := client.Accept(noContext, 1, "localhost"); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := client.Details(noContext, 1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the token is removed from the stage index, and is
+	// only restored if the server renews the token.
+	client.mu.Lock()
+	delete(client.stages, "stage:1")
+	client.mu.Unlock()
+
+	if _, err := client.Watch(noContext, 2); err != nil {
+		t.Error(err)
+		return
+	}
+	if client.lookup("stage", 1) == "" {
+		t.Errorf("Want stage token renewed when watching the build")
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	a := deriveKey("shared-secret")
+	b := deriveKey("shared-secret")
+	if len(a) == 0 || string(a) != string(b) {
+		t.Errorf("Want the same signing key derived from the secret")
+	}
+	if string(a) == "shared-secret" {
+		t.Errorf("Want signing key different than the secret")
+	}
+	if deriveKey("") != nil {
+		t.Errorf("Want empty signing key when the secret is empty")
+	}
+	if _, err := signToken(nil, &stageClaims{Stage: 1}); err != errInvalidToken {
+		t.Errorf("Want error signing a token with an empty key")
+	}
+}
+
+func TestToken(t *testing.T) {
+	key := []byte("correct-horse-battery-staple")
+	claims := &stageClaims{
+		Agent:   "agent-1",
+		Stage:   1,
+		Repo:    2,
+		Steps:   []int64{3, 4},
+		Expires: time.Now().Add(time.Hour).Unix(),
+	}
+	token, err := signToken(key, claims)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	got, err := parseToken(key, token)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got.Agent != "agent-1" || got.Stage != 1 || got.Repo != 2 || !got.hasStep(4) {
+		t.Errorf("Unexpected token claims %+v", got)
+	}
+
+	if _, err := parseToken([]byte("battery-staple-correct-horse"), token); err != errInvalidToken {
+		t.Errorf("Want error with invalid signing key")
+	}
+
+	// the payload is modified to include an additional
+	// step, and the signature is retained.
+	claims.Steps = append(claims.Steps, 5)
+	forged, _ := signToken([]byte("battery-staple-correct-horse"), claims)
+	forged = strings.Split(forged, ".")[0] + "." + strings.Split(token, ".")[1]
+	if _, err := parseToken(key, forged); err != errInvalidToken {
+		t.Errorf("Want error with tampered payload")
+	}
+
+	claims.Expires = time.Now().Add(-time.Minute).Unix()
+	expired, _ := signToken(key, claims)
+	if _, err := parseToken(key, expired); err != errInvalidToken {
+		t.Errorf("Want error with expired token")
+	}
+
+	if _, err := parseToken(key, "not-a-token"); err != errInvalidToken {
+		t.Errorf("Want error with malformed token")
+	}
+}
+
+func newTestServer() *httptest.Server {
+	steps := &fakeSteps{steps: map[int64]*core.Step{
+		7: {ID: 7, StageID: 2},
+	}}
+	server := NewServer(new(fakeManager), steps, "shared-secret")
+	server.SetAgents(map[string]string{
+		"agent-1": "correct-horse-battery-staple",
+		"agent-2": "battery-staple-correct-horse",
+	})
+	return httptest.NewServer(server)
+}
+
+func newTestClient(server, agent, secret string) *Client {
+	client := NewClient(server, secret)
+	client.SetAgent(agent)
+	client.client.RetryMax = 0
+	return client
+}
+
+func statusCode(err error) int {
+	if err, ok := err.(*serverError); ok {
+		return err.Status
+	}
+	return 0
+}
+
+// fakeSteps is a fake step store that finds the stored
+// steps by identifier.
+type fakeSteps struct {
+	core.StepStore
+	steps map[int64]*core.Step
+}
+
+func (f *fakeSteps) Find(_ context.Context, id int64) (*core.Step, error) {
+	if step, ok := f.steps[id]; ok {
+		return step, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// fakeManager is a fake build manager that assigns step
+// identifiers when the stage starts.
+type fakeManager struct {
+	manager.BuildManager
+}
+
+func (fakeManager) Accept(context.Context, int64, string) error {
+	return nil
+}
+
+func (fakeManager) Details(_ context.Context, stage int64) (*manager.Context, error) {
+	return &manager.Context{
+		Repo:  &core.Repository{ID: 42},
+		Build: &core.Build{ID: 2},
+		Stage: &core.Stage{ID: stage},
+	}, nil
+}
+
+func (fakeManager) Netrc(context.Context, int64) (*core.Netrc, error) {
+	return &core.Netrc{Machine: "github.com"}, nil
+}
+
+func (fakeManager) BeforeAll(_ context.Context, stage *core.Stage) error {
+	for i, step := range stage.Steps {
+		step.ID = int64(100 + i)
+	}
+	return nil
+}
+
+func (fakeManager) AfterAll(context.Context, *core.Stage) error {
+	return nil
+}
+
+func (fakeManager) Before(context.Context, *core.Step) error {
+	return nil
+}
+
+func (fakeManager) After(context.Context, *core.Step) error {
+	return nil
+}
+
+func (fakeManager) Write(context.Context, int64, *core.Line) error {
+	return nil
+}
+
+func (fakeManager) Watch(context.Context, int64) (bool, error) {
+	return false, nil
+}
+
+func (fakeManager) Upload(_ context.Context, _ int64, r io.Reader) error {
+	_, err := io.Copy(ioutil.Discard, r)
+	return err
+}
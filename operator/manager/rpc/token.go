@@ -0,0 +1,107 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package rpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// errInvalidToken is returned when the stage token is
+// missing, malformed, expired or has an invalid signature.
+var errInvalidToken = errors.New("rpc: invalid stage token")
+
+// stageClaims defines the claims of a stage token. A stage
+// token is issued to the agent that accepts the stage, and
+// scopes rpc calls to the stage, its repository and its steps.
+type stageClaims struct {
+	Agent   string  `json:"agent,omitempty"`
+	Stage   int64   `json:"stage"`
+	Repo    int64   `json:"repo,omitempty"`
+	Build   int64   `json:"build,omitempty"`
+	Steps   []int64 `json:"steps,omitempty"`
+	Expires int64   `json:"exp"`
+}
+
+// hasStep returns true if the step is in scope.
+func (c *stageClaims) hasStep(id int64) bool {
+	for _, step := range c.Steps {
+		if step == id {
+			return true
+		}
+	}
+	return false
+}
+
+// addStep adds the step to the scope.
+func (c *stageClaims) addStep(id int64) {
+	if id != 0 && !c.hasStep(id) {
+		c.Steps = append(c.Steps, id)
+	}
+}
+
+// signToken returns the claims encoded and signed with the
+// key, in the format payload.signature.
+func signToken(key []byte, claims *stageClaims) (string, error) {
+	if len(key) == 0 {
+		return "", errInvalidToken
+	}
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + sign(key, payload), nil
+}
+
+// parseToken verifies the token signature and expiration and
+// returns the token claims.
+func parseToken(key []byte, token string) (*stageClaims, error) {
+	if len(key) == 0 {
+		return nil, errInvalidToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, errInvalidToken
+	}
+	if !hmac.Equal([]byte(sign(key, parts[0])), []byte(parts[1])) {
+		return nil, errInvalidToken
+	}
+	claims, err := decodeToken(token)
+	if err != nil {
+		return nil, errInvalidToken
+	}
+	if claims.Expires < time.Now().Unix() {
+		return nil, errInvalidToken
+	}
+	return claims, nil
+}
+
+// decodeToken returns the token claims without verifying
+// the signature. It is used by the client to index tokens,
+// and must never be used to authorize a request.
+func decodeToken(token string) (*stageClaims, error) {
+	payload := strings.SplitN(token, ".", 2)[0]
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, err
+	}
+	claims := new(stageClaims)
+	err = json.Unmarshal(data, claims)
+	return claims, err
+}
+
+func sign(key []byte, payload string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/operator/manager"
+
+	"github.com/dustin/go-humanize"
+)
+
+// limiter enforces the log limits for the steps in a
+// single stage.
+type limiter struct {
+	sync.Mutex
+
+	limits   core.LogLimits
+	used     int64
+	written  map[string]int64
+	exceeded map[string]bool
+}
+
+func newLimiter(limits core.LogLimits) *limiter {
+	return &limiter{
+		limits:   limits,
+		written:  map[string]int64{},
+		exceeded: map[string]bool{},
+	}
+}
+
+func (l *limiter) enabled() bool {
+	return l.limits.Step > 0 || l.limits.Stage > 0
+}
+
+// write records the line written to the live log stream for
+// the named step. It returns the line that should be written,
+// which is a marker line the first time the limit is exceeded,
+// or nil if the line should be dropped.
+func (l *limiter) write(name string, line *core.Line) *core.Line {
+	if !l.enabled() {
+		return line
+	}
+	l.Lock()
+	defer l.Unlock()
+	if l.exceeded[name] {
+		return nil
+	}
+	size := int64(len(line.Message))
+	if limit := l.limits.Limit(l.used); l.written[name]+size > limit {
+		l.exceeded[name] = true
+		return &core.Line{
+			Number:    line.Number,
+			Timestamp: line.Timestamp,
+			Message: fmt.Sprintf(
+				"[drone] log output exceeded the %s limit and is no longer streamed\n",
+				humanize.Bytes(uint64(limit)),
+			),
+		}
+	}
+	l.written[name] += size
+	return line
+}
+
+// truncate limits the complete logs for the step, and records
+// the size of the retained logs against the stage budget. It
+// returns true if the logs were truncated.
+func (l *limiter) truncate(lines []*core.Line) ([]*core.Line, bool) {
+	if !l.enabled() {
+		return lines, false
+	}
+	l.Lock()
+	defer l.Unlock()
+	lines, truncated := manager.TruncateLines(lines, l.limits.Limit(l.used))
+	for _, line := range lines {
+		l.used += int64(len(line.Message))
+	}
+	return lines, truncated
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package runner
+
+import (
+	"testing"
+
+	"github.com/drone/drone/core"
+)
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(core.LogLimits{Step: 10, Stage: 16})
+
+	line := &core.Line{Message: "01234"}
+	if got := l.write("build", line); got != line {
+		t.Errorf("Want line written")
+	}
+	if got := l.write("build", &core.Line{Message: "0123456789"}); got == nil || got == line {
+		t.Errorf("Want marker line written")
+	}
+	if got := l.write("build", line); got != nil {
+		t.Errorf("Want line dropped")
+	}
+	if got := l.write("test", line); got != line {
+		t.Errorf("Want line written for a different step")
+	}
+
+	lines := []*core.Line{
+		{Number: 0, Message: "01234"},
+		{Number: 1, Message: "56789"},
+		{Number: 2, Message: "01234"},
+	}
+	out, truncated := l.truncate(lines)
+	if !truncated {
+		t.Errorf("Want logs truncated")
+	}
+	if got, want := len(out), 3; got != want {
+		t.Errorf("Want %d lines, got %d", want, got)
+	}
+
+	// the remaining stage budget is less than the step
+	// limit, and is applied to subsequent steps.
+	out, truncated = l.truncate(lines[:2])
+	if !truncated {
+		t.Errorf("Want logs truncated by the stage limit")
+	}
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	l := newLimiter(core.LogLimits{})
+	line := &core.Line{Message: "01234"}
+	if got := l.write("build", line); got != line {
+		t.Errorf("Want line written")
+	}
+	lines := []*core.Line{line, line}
+	if out, truncated := l.truncate(lines); truncated || len(out) != 2 {
+		t.Errorf("Want logs not truncated")
+	}
+}
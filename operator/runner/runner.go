@@ -70,6 +70,7 @@ type Runner struct {
 	Environ    map[string]string
 	Machine    string
 	Labels     map[string]string
+	LogLimits  core.LogLimits
 
 	Kind     string
 	Type     string
@@ -356,6 +357,8 @@ func (r *Runner) Run(ctx context.Context, id int64) error {
 		m.Stage.Steps = append(m.Stage.Steps, dst)
 	}
 
+	limits := newLimiter(r.LogLimits)
+
 	hooks := &runtime.Hook{
 		BeforeEach: func(s *runtime.State) error {
 			r.Lock()
@@ -451,7 +454,11 @@ func (r *Runner) Run(ctx context.Context, id int64) error {
 				// TODO log error
 				return nil
 			}
-			return r.Manager.Write(ctx, step.ID, convertLine(line))
+			out := limits.write(step.Name, convertLine(line))
+			if out == nil {
+				return nil
+			}
+			return r.Manager.Write(ctx, step.ID, out)
 		},
 
 		GotLogs: func(s *runtime.State, lines []*runtime.Line) error {
@@ -462,9 +469,13 @@ func (r *Runner) Run(ctx context.Context, id int64) error {
 				// TODO log error
 				return nil
 			}
-			raw, _ := json.Marshal(
-				convertLines(lines),
-			)
+			out, truncated := limits.truncate(convertLines(lines))
+			if truncated {
+				r.Lock()
+				step.LogTruncated = true
+				r.Unlock()
+			}
+			raw, _ := json.Marshal(out)
 			return r.Manager.UploadBytes(ctx, step.ID, raw)
 		},
 	}
@@ -16,7 +16,16 @@
 
 package admission
 
-import "github.com/drone/drone/core"
+import (
+	"errors"
+
+	"github.com/drone/drone/core"
+)
+
+// ErrMembership is returned when attempting to create a new
+// user account for a user that is not a member of an approved
+// organization.
+var ErrMembership = errors.New("User must be a member of an approved organization")
 
 // Membership is a no-op admission controller
 func Membership(core.OrganizationService, []string) core.AdmissionService {
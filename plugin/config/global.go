@@ -10,9 +10,9 @@ import (
 	"context"
 	"time"
 
-	"github.com/drone/drone-go/drone"
 	"github.com/drone/drone-go/plugin/config"
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/plugin/internal/transform"
 )
 
 // Global returns a configuration service that fetches the yaml
@@ -43,8 +43,8 @@ func (g *global) Find(ctx context.Context, in *core.ConfigArgs) (*core.Config, e
 	defer cancel()
 
 	req := &config.Request{
-		Repo:  toRepo(in.Repo),
-		Build: toBuild(in.Build),
+		Repo:  transform.ToRepo(in.Repo),
+		Build: transform.ToBuild(in.Build),
 	}
 	client := config.Client(g.endpoint, g.secret, g.skipVerify)
 	res, err := client.Find(ctx, req)
@@ -64,62 +64,3 @@ func (g *global) Find(ctx context.Context, in *core.ConfigArgs) (*core.Config, e
 		Data: res.Data,
 	}, nil
 }
-
-func toRepo(from *core.Repository) drone.Repo {
-	return drone.Repo{
-		ID:         from.ID,
-		UID:        from.UID,
-		UserID:     from.UserID,
-		Namespace:  from.Namespace,
-		Name:       from.Name,
-		Slug:       from.Slug,
-		SCM:        from.SCM,
-		HTTPURL:    from.HTTPURL,
-		SSHURL:     from.SSHURL,
-		Link:       from.Link,
-		Branch:     from.Branch,
-		Private:    from.Private,
-		Visibility: from.Visibility,
-		Active:     from.Active,
-		Config:     from.Config,
-		Trusted:    from.Trusted,
-		Protected:  from.Protected,
-		Timeout:    from.Timeout,
-	}
-}
-
-func toBuild(from *core.Build) drone.Build {
-	return drone.Build{
-		ID:           from.ID,
-		RepoID:       from.RepoID,
-		Trigger:      from.Trigger,
-		Number:       from.Number,
-		Parent:       from.Parent,
-		Status:       from.Status,
-		Error:        from.Error,
-		Event:        from.Event,
-		Action:       from.Action,
-		Link:         from.Link,
-		Timestamp:    from.Timestamp,
-		Title:        from.Title,
-		Message:      from.Message,
-		Before:       from.Before,
-		After:        from.After,
-		Ref:          from.Ref,
-		Fork:         from.Fork,
-		Source:       from.Source,
-		Target:       from.Target,
-		Author:       from.Author,
-		AuthorName:   from.AuthorName,
-		AuthorEmail:  from.AuthorEmail,
-		AuthorAvatar: from.AuthorAvatar,
-		Sender:       from.Sender,
-		Params:       from.Params,
-		Deploy:       from.Deploy,
-		Started:      from.Started,
-		Finished:     from.Finished,
-		Created:      from.Created,
-		Updated:      from.Updated,
-		Version:      from.Version,
-	}
-}
@@ -16,12 +16,40 @@ package config
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
 
+	"github.com/drone/drone-yaml/yaml"
 	"github.com/drone/drone/core"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// maximum number of configuration files that are fetched
+// and combined for a single build.
+const maxFiles = 50
+
+var (
+	errNoMatch = errors.New("yaml: no configuration files match the configuration path")
+	errTooMany = fmt.Errorf("yaml: configuration path matches more than %d files", maxFiles)
+	errGlobDir = errors.New("yaml: glob patterns are only supported in the file name of the configuration path")
+	errConvert = errors.New("yaml: jsonnet and starlark files cannot be combined using a configuration path pattern or list")
 )
 
 // Repository returns a configuration service that fetches the yaml
 // directly from the source code management (scm) system.
+//
+// The repository configuration path may be a single file, a glob
+// pattern, or a comma-separated list of files and patterns. Glob
+// patterns match the file names in a single directory, and may
+// not be used in the directory segments of the path. When more
+// than one file matches, the files are combined into a single
+// multi-document yaml. Jsonnet and starlark files must be
+// configured as a single file, since the files are converted
+// after they are combined.
 func Repository(service core.FileService) core.ConfigService {
 	return &repo{files: service}
 }
@@ -31,11 +59,139 @@ type repo struct {
 }
 
 func (r *repo) Find(ctx context.Context, req *core.ConfigArgs) (*core.Config, error) {
-	raw, err := r.files.Find(ctx, req.User, req.Repo.Slug, req.Build.After, req.Build.Ref, req.Repo.Config)
+	patterns := splitPatterns(req.Repo.Config)
+	if len(patterns) == 1 && !isPattern(patterns[0]) {
+		raw, err := r.files.Find(ctx, req.User, req.Repo.Slug, req.Build.After, req.Build.Ref, patterns[0])
+		if err != nil {
+			return nil, err
+		}
+		return &core.Config{
+			Data: string(raw.Data),
+		}, err
+	}
+
+	paths, err := r.match(ctx, req, patterns)
 	if err != nil {
 		return nil, err
 	}
+
+	var buf strings.Builder
+	names := map[string]string{}
+	for _, name := range paths {
+		raw, err := r.files.Find(ctx, req.User, req.Repo.Slug, req.Build.After, req.Build.Ref, name)
+		if err != nil {
+			return nil, err
+		}
+		data := string(raw.Data)
+		if err := checkNames(names, name, data); err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(data, "---") {
+			buf.WriteString("---\n")
+		}
+		buf.WriteString(data)
+		if !strings.HasSuffix(data, "\n") {
+			buf.WriteString("\n")
+		}
+	}
 	return &core.Config{
-		Data: string(raw.Data),
-	}, err
+		Data: buf.String(),
+	}, nil
+}
+
+// helper function returns the sorted and de-duplicated list
+// of files that match the configuration path patterns.
+func (r *repo) match(ctx context.Context, req *core.ConfigArgs, patterns []string) ([]string, error) {
+	var paths []string
+	seen := map[string]struct{}{}
+	add := func(name string) {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			paths = append(paths, name)
+		}
+	}
+	for _, pattern := range patterns {
+		if !isPattern(pattern) {
+			add(pattern)
+			continue
+		}
+		// the directory is listed to match the file names,
+		// and directories are therefore matched literally.
+		dir := path.Dir(pattern)
+		if isPattern(dir) {
+			return nil, errGlobDir
+		}
+		files, err := r.files.List(ctx, req.User, req.Repo.Slug, req.Build.After, req.Build.Ref, dir)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			if ok, _ := path.Match(pattern, file); ok {
+				add(file)
+			}
+		}
+	}
+	switch {
+	case len(paths) == 0:
+		return nil, errNoMatch
+	case len(paths) > maxFiles:
+		return nil, errTooMany
+	}
+	for _, name := range paths {
+		switch path.Ext(name) {
+		case ".jsonnet", ".star", ".starlark":
+			return nil, errConvert
+		}
+	}
+	return paths, nil
+}
+
+// helper function verifies the pipelines defined in the
+// file do not share a name with previously loaded pipelines.
+// Pipelines without a name are named default.
+func checkNames(names map[string]string, file, data string) error {
+	resources, err := yaml.ParseRawString(data)
+	if err != nil {
+		return fmt.Errorf("yaml: %s: %s", file, err)
+	}
+	for _, resource := range resources {
+		if resource.Kind != "" && resource.Kind != yaml.KindPipeline {
+			continue
+		}
+		out := struct {
+			Name string `yaml:"name"`
+		}{}
+		yamlv2.Unmarshal(resource.Data, &out)
+		if out.Name == "" {
+			out.Name = "default"
+		}
+		if prev, ok := names[out.Name]; ok {
+			return fmt.Errorf("yaml: duplicate pipeline name %q in %s and %s", out.Name, prev, file)
+		}
+		names[out.Name] = file
+	}
+	return nil
+}
+
+// helper function splits the comma-separated configuration
+// path into a list of files and patterns.
+func splitPatterns(s string) []string {
+	var patterns []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			patterns = append(patterns, part)
+		}
+	}
+	if len(patterns) == 0 {
+		return []string{s}
+	}
+	return patterns
+}
+
+// helper function returns true if the path contains glob
+// pattern characters.
+func isPattern(s string) bool {
+	return strings.ContainsAny(s, "*?[")
 }
@@ -73,3 +73,160 @@ func TestRepositoryErr(t *testing.T) {
 		t.Errorf("expect error returned from file service")
 	}
 }
+
+func TestRepository_Glob(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConfigArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone/*.yml, .drone.yml"},
+		Build: &core.Build{After: "6d144de7", Ref: "refs/heads/master"},
+	}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().List(noContext, args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, ".drone").Return([]string{".drone/foo.yml", ".drone/README.md", ".drone/bar.yml"}, nil)
+	files.EXPECT().Find(noContext, args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, ".drone/bar.yml").Return(&core.File{Data: []byte("kind: pipeline\nname: bar\n")}, nil)
+	files.EXPECT().Find(noContext, args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, ".drone/foo.yml").Return(&core.File{Data: []byte("---\nkind: pipeline\nname: foo")}, nil)
+	files.EXPECT().Find(noContext, args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, ".drone.yml").Return(&core.File{Data: []byte("kind: secret\nname: foo\n")}, nil)
+
+	service := Repository(files)
+	result, err := service.Find(noContext, args)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	want := "---\nkind: pipeline\nname: bar\n---\nkind: pipeline\nname: foo\n---\nkind: secret\nname: foo\n"
+	if result.Data != want {
+		t.Errorf("unexpected file contents %q", result.Data)
+	}
+}
+
+func TestRepository_GlobNoMatch(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConfigArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone/*.yml"},
+		Build: &core.Build{After: "6d144de7"},
+	}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().List(noContext, args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, ".drone").Return([]string{".drone/README.md"}, nil)
+
+	service := Repository(files)
+	_, err := service.Find(noContext, args)
+	if err != errNoMatch {
+		t.Errorf("Want no match error, got %v", err)
+	}
+}
+
+func TestRepository_GlobDir(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConfigArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: "services/*/.drone.yml"},
+		Build: &core.Build{After: "6d144de7"},
+	}
+
+	files := mock.NewMockFileService(controller)
+
+	service := Repository(files)
+	_, err := service.Find(noContext, args)
+	if err != errGlobDir {
+		t.Errorf("Want glob directory error, got %v", err)
+	}
+}
+
+func TestRepository_DuplicateName(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConfigArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: "a.yml,b.yml"},
+		Build: &core.Build{After: "6d144de7"},
+	}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().Find(noContext, args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, "a.yml").Return(&core.File{Data: mockFile}, nil)
+	files.EXPECT().Find(noContext, args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, "b.yml").Return(&core.File{Data: mockFile}, nil)
+
+	service := Repository(files)
+	_, err := service.Find(noContext, args)
+	if err == nil {
+		t.Errorf("Want duplicate pipeline name error")
+		return
+	}
+	if got, want := err.Error(), `yaml: duplicate pipeline name "default" in a.yml and b.yml`; got != want {
+		t.Errorf("Want error %q, got %q", want, got)
+	}
+}
+
+func TestRepository_DuplicateDefaultName(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConfigArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: "a.yml,b.yml"},
+		Build: &core.Build{After: "6d144de7"},
+	}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().Find(noContext, args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, "a.yml").Return(&core.File{Data: []byte("kind: pipeline\nsteps: []\n")}, nil)
+	files.EXPECT().Find(noContext, args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, "b.yml").Return(&core.File{Data: mockFile}, nil)
+
+	service := Repository(files)
+	_, err := service.Find(noContext, args)
+	if err == nil {
+		t.Errorf("Want duplicate pipeline name error")
+		return
+	}
+	if got, want := err.Error(), `yaml: duplicate pipeline name "default" in a.yml and b.yml`; got != want {
+		t.Errorf("Want error %q, got %q", want, got)
+	}
+}
+
+func TestRepository_GlobConvert(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConfigArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone/*.jsonnet"},
+		Build: &core.Build{After: "6d144de7"},
+	}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().List(noContext, args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, ".drone").Return([]string{".drone/foo.jsonnet", ".drone/bar.jsonnet"}, nil)
+
+	service := Repository(files)
+	_, err := service.Find(noContext, args)
+	if err != errConvert {
+		t.Errorf("Want conversion error, got %v", err)
+	}
+}
+
+func TestRepository_ListConvert(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConfigArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml,.drone.star"},
+		Build: &core.Build{After: "6d144de7"},
+	}
+
+	files := mock.NewMockFileService(controller)
+
+	service := Repository(files)
+	_, err := service.Find(noContext, args)
+	if err != errConvert {
+		t.Errorf("Want conversion error, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/drone/drone-yaml/yaml"
+	"github.com/drone/drone-yaml/yaml/linter"
+	"github.com/drone/drone/core"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// kindTemplate is the resource kind of a configuration
+// file that loads a shared template.
+const kindTemplate = "template"
+
+var (
+	errTemplateLoad   = errors.New("template: invalid load path, expected namespace/name/path")
+	errTemplateDenied = errors.New("template: repository is not an allowed template repository")
+	errTemplateNested = errors.New("template: templates cannot load other templates")
+)
+
+// Template returns a configuration service that resolves
+// configuration files of kind template. The template is
+// fetched from an allowed template repository at the pinned
+// ref, rendered with the inputs provided by the repository
+// configuration file, and validated. Templates are cached
+// by the file service, which invalidates the cached template
+// when the template repository receives a push.
+func Template(service core.FileService, repos []string, ref string) core.ConfigService {
+	return &templatePlugin{
+		allowed: repos,
+		ref:     ref,
+		files:   service,
+		repos:   &repo{files: service},
+	}
+}
+
+type templatePlugin struct {
+	allowed []string
+	ref     string
+	files   core.FileService
+	repos   *repo
+}
+
+// templateArgs represents a configuration file of kind
+// template.
+type templateArgs struct {
+	Kind string                 `yaml:"kind"`
+	Load string                 `yaml:"load"`
+	Data map[string]interface{} `yaml:"data"`
+}
+
+func (p *templatePlugin) Find(ctx context.Context, req *core.ConfigArgs) (*core.Config, error) {
+	if len(p.allowed) == 0 {
+		return nil, nil
+	}
+
+	// get the file contents.
+	config, err := p.repos.Find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// if the configuration file is not of kind template
+	// we can skip this plugin by returning zero values.
+	resources, err := yaml.ParseRaw(strings.NewReader(config.Data))
+	if err != nil || len(resources) != 1 ||
+		resources[0].Kind != kindTemplate {
+		return nil, nil
+	}
+
+	args := new(templateArgs)
+	err = yamlv2.Unmarshal(resources[0].Data, args)
+	if err != nil {
+		return nil, err
+	}
+
+	slug, path, err := p.split(args.Load)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := p.find(ctx, req.User, slug, path)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(args.Load).
+		Option("missingkey=error").
+		Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("template: %s", err)
+	}
+
+	buf := new(bytes.Buffer)
+	err = tmpl.Execute(buf, map[string]interface{}{
+		"input": args.Data,
+		"build": map[string]interface{}{
+			"event":  req.Build.Event,
+			"branch": req.Build.Target,
+			"ref":    req.Build.Ref,
+			"commit": req.Build.After,
+		},
+		"repo": map[string]interface{}{
+			"namespace": req.Repo.Namespace,
+			"name":      req.Repo.Name,
+			"slug":      req.Repo.Slug,
+			"branch":    req.Repo.Branch,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("template: %s", err)
+	}
+
+	err = validateTemplate(buf.String(), req.Repo.Trusted)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.Config{Data: buf.String()}, nil
+}
+
+// split splits the load path into the template repository
+// slug and the file path, and verifies the repository is an
+// allowed template repository.
+func (p *templatePlugin) split(load string) (slug, path string, err error) {
+	parts := strings.SplitN(load, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", errTemplateLoad
+	}
+	slug = parts[0] + "/" + parts[1]
+	path = parts[2]
+	for _, allowed := range p.allowed {
+		if allowed == slug {
+			return slug, path, nil
+		}
+	}
+	return "", "", errTemplateDenied
+}
+
+// find fetches the template from the template repository at
+// the pinned ref.
+func (p *templatePlugin) find(ctx context.Context, user *core.User, slug, path string) (string, error) {
+	file, err := p.files.Find(ctx, user, slug, p.ref, p.ref, path)
+	if err != nil {
+		return "", err
+	}
+	return string(file.Data), nil
+}
+
+// validateTemplate parses and lints the rendered template.
+func validateTemplate(data string, trusted bool) error {
+	resources, err := yaml.ParseRaw(strings.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("template: %s", err)
+	}
+	for _, resource := range resources {
+		if resource.Kind == kindTemplate {
+			return errTemplateNested
+		}
+	}
+	manifest, err := yaml.ParseString(data)
+	if err != nil {
+		return fmt.Errorf("template: %s", err)
+	}
+	return linter.Manifest(manifest, trusted)
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package config
+
+import (
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+var templateArgsFixture = &core.ConfigArgs{
+	User:  &core.User{Login: "octocat"},
+	Repo:  &core.Repository{Slug: "octocat/hello-world", Name: "hello-world", Config: ".drone.yml"},
+	Build: &core.Build{After: "6d144de7", Target: "master", Event: core.EventPush},
+}
+
+func TestTemplate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := templateArgsFixture
+	root := &core.File{Data: []byte(`
+kind: template
+load: octocat/templates/go.yml
+data:
+  image: golang:1.12
+`)}
+	tmpl := &core.File{Data: []byte(`
+kind: pipeline
+name: {{ .repo.name }}
+
+steps:
+- name: test
+  image: {{ .input.image }}
+  commands:
+  - go test ./...
+`)}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().Find(gomock.Any(), args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, args.Repo.Config).Return(root, nil).Times(2)
+	files.EXPECT().Find(gomock.Any(), args.User, "octocat/templates", "refs/heads/master", "refs/heads/master", "go.yml").Return(tmpl, nil).Times(2)
+
+	service := Template(files, []string{"octocat/templates"}, "refs/heads/master")
+	for i := 0; i < 2; i++ {
+		result, err := service.Find(noContext, args)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		want := `
+kind: pipeline
+name: hello-world
+
+steps:
+- name: test
+  image: golang:1.12
+  commands:
+  - go test ./...
+`
+		if got := result.Data; got != want {
+			t.Errorf("Want rendered template %q, got %q", want, got)
+		}
+	}
+}
+
+func TestTemplate_Skip(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := templateArgsFixture
+	root := &core.File{Data: []byte("kind: pipeline\nname: default\n")}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().Find(gomock.Any(), args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, args.Repo.Config).Return(root, nil)
+
+	result, err := Template(files, []string{"octocat/templates"}, "refs/heads/master").Find(noContext, args)
+	if err != nil {
+		t.Error(err)
+	}
+	if result != nil {
+		t.Errorf("Expect nil configuration for non-template file")
+	}
+}
+
+func TestTemplate_Disabled(t *testing.T) {
+	result, err := Template(nil, nil, "refs/heads/master").Find(noContext, templateArgsFixture)
+	if err != nil {
+		t.Error(err)
+	}
+	if result != nil {
+		t.Errorf("Expect nil configuration when disabled")
+	}
+}
+
+func TestTemplate_Denied(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := templateArgsFixture
+	root := &core.File{Data: []byte("kind: template\nload: spaceghost/templates/go.yml\n")}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().Find(gomock.Any(), args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, args.Repo.Config).Return(root, nil)
+
+	_, err := Template(files, []string{"octocat/templates"}, "refs/heads/master").Find(noContext, args)
+	if err != errTemplateDenied {
+		t.Errorf("Want error %s, got %v", errTemplateDenied, err)
+	}
+}
+
+func TestTemplate_InvalidLoad(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := templateArgsFixture
+	root := &core.File{Data: []byte("kind: template\nload: go.yml\n")}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().Find(gomock.Any(), args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, args.Repo.Config).Return(root, nil)
+
+	_, err := Template(files, []string{"octocat/templates"}, "refs/heads/master").Find(noContext, args)
+	if err != errTemplateLoad {
+		t.Errorf("Want error %s, got %v", errTemplateLoad, err)
+	}
+}
+
+func TestTemplate_MissingInput(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := templateArgsFixture
+	root := &core.File{Data: []byte("kind: template\nload: octocat/templates/go.yml\n")}
+	tmpl := &core.File{Data: []byte("kind: pipeline\nsteps:\n- name: test\n  image: {{ .input.image }}\n")}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().Find(gomock.Any(), args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, args.Repo.Config).Return(root, nil)
+	files.EXPECT().Find(gomock.Any(), args.User, "octocat/templates", "refs/heads/master", "refs/heads/master", "go.yml").Return(tmpl, nil)
+
+	_, err := Template(files, []string{"octocat/templates"}, "refs/heads/master").Find(noContext, args)
+	if err == nil {
+		t.Errorf("Expect error when template input is missing")
+	}
+}
+
+func TestTemplate_Nested(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := templateArgsFixture
+	root := &core.File{Data: []byte("kind: template\nload: octocat/templates/go.yml\n")}
+	tmpl := &core.File{Data: []byte("kind: template\nload: octocat/templates/other.yml\n")}
+
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().Find(gomock.Any(), args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, args.Repo.Config).Return(root, nil)
+	files.EXPECT().Find(gomock.Any(), args.User, "octocat/templates", "refs/heads/master", "refs/heads/master", "go.yml").Return(tmpl, nil)
+
+	_, err := Template(files, []string{"octocat/templates"}, "refs/heads/master").Find(noContext, args)
+	if err != errTemplateNested {
+		t.Errorf("Want error %s, got %v", errTemplateNested, err)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+)
+
+// Combine combines the conversion services, allowing the
+// system to convert pipeline configuration files using
+// multiple services. Each service receives the configuration
+// returned by the previous service, and a service that does
+// not convert the configuration returns zero values.
+func Combine(services ...core.ConvertService) core.ConvertService {
+	return &combined{services}
+}
+
+type combined struct {
+	sources []core.ConvertService
+}
+
+func (c *combined) Convert(ctx context.Context, req *core.ConvertArgs) (*core.Config, error) {
+	config := req.Config
+	for _, source := range c.sources {
+		args := &core.ConvertArgs{
+			User:   req.User,
+			Repo:   req.Repo,
+			Build:  req.Build,
+			Config: config,
+		}
+		converted, err := source.Convert(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		if converted == nil {
+			continue
+		}
+		if converted.Data == "" {
+			continue
+		}
+		config = converted
+	}
+	return config, nil
+}
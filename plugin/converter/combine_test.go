@@ -0,0 +1,98 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package converter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+var noContext = context.Background()
+
+func TestCombine(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConvertArgs{
+		User:   &core.User{Login: "octocat"},
+		Repo:   &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml"},
+		Build:  &core.Build{After: "6d144de7"},
+		Config: &core.Config{Data: "kind: pipeline"},
+	}
+
+	first := &core.Config{Data: "kind: pipeline\nname: first"}
+	second := &core.Config{Data: "kind: pipeline\nname: second"}
+
+	checkFirst := func(_ context.Context, req *core.ConvertArgs) {
+		if req.Config != args.Config {
+			t.Errorf("Want first service to receive the original configuration")
+		}
+	}
+	checkSecond := func(_ context.Context, req *core.ConvertArgs) {
+		if req.Config != first {
+			t.Errorf("Want second service to receive the converted configuration")
+		}
+	}
+
+	service1 := mock.NewMockConvertService(controller)
+	service1.EXPECT().Convert(noContext, gomock.Any()).Do(checkFirst).Return(first, nil)
+
+	service2 := mock.NewMockConvertService(controller)
+	service2.EXPECT().Convert(noContext, gomock.Any()).Do(checkSecond).Return(second, nil)
+
+	result, err := Combine(service1, service2).Convert(noContext, args)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if result != second {
+		t.Errorf("Expect result from the last service")
+	}
+}
+
+func TestCombine_NoConversion(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConvertArgs{
+		Config: &core.Config{Data: "kind: pipeline"},
+	}
+
+	service := mock.NewMockConvertService(controller)
+	service.EXPECT().Convert(noContext, gomock.Any()).Return(nil, nil)
+
+	result, err := Combine(service).Convert(noContext, args)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if result != args.Config {
+		t.Errorf("Expect original configuration returned when not converted")
+	}
+}
+
+func TestCombine_Err(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConvertArgs{
+		Config: &core.Config{Data: "kind: pipeline"},
+	}
+
+	errFake := errors.New("not found")
+	service := mock.NewMockConvertService(controller)
+	service.EXPECT().Convert(noContext, gomock.Any()).Return(nil, errFake)
+
+	_, err := Combine(service).Convert(noContext, args)
+	if err != errFake {
+		t.Errorf("Expect error returned from service")
+	}
+}
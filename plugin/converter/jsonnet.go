@@ -4,7 +4,7 @@
 
 // +build !oss
 
-package config
+package converter
 
 import (
 	"bytes"
@@ -16,22 +16,21 @@ import (
 	"github.com/google/go-jsonnet"
 )
 
-// Jsonnet returns a configuration service that fetches the
-// jsonnet file directly from the source code management (scm)
-// system and converts to a yaml file.
-func Jsonnet(service core.FileService, enabled bool) core.ConfigService {
+// Jsonnet returns a conversion service that converts the
+// jsonnet file to a yaml file.
+func Jsonnet(service core.FileService, enabled bool) core.ConvertService {
 	return &jsonnetPlugin{
 		enabled: enabled,
-		repos:   &repo{files: service},
+		files:   service,
 	}
 }
 
 type jsonnetPlugin struct {
 	enabled bool
-	repos   *repo
+	files   core.FileService
 }
 
-func (p *jsonnetPlugin) Find(ctx context.Context, req *core.ConfigArgs) (*core.Config, error) {
+func (p *jsonnetPlugin) Convert(ctx context.Context, req *core.ConvertArgs) (*core.Config, error) {
 	if p.enabled == false {
 		return nil, nil
 	}
@@ -42,24 +41,20 @@ func (p *jsonnetPlugin) Find(ctx context.Context, req *core.ConfigArgs) (*core.C
 		return nil, nil
 	}
 
-	// get the file contents.
-	config, err := p.repos.Find(ctx, req)
-	if err != nil {
-		return nil, err
-	}
-
-	// TODO(bradrydzewski) temporarily disable file imports
 	// TODO(bradrydzewski) handle object vs array output
 
-	// create the jsonnet vm
+	// create the jsonnet vm. File imports are fetched from
+	// the repository at the commit being built.
 	vm := jsonnet.MakeVM()
 	vm.MaxStack = 500
 	vm.StringOutput = false
 	vm.ErrorFormatter.SetMaxStackTraceSize(20)
+	vm.Importer(newImporter(ctx, p.files, req))
+	setExtVars(vm, req)
 
 	// convert the jsonnet file to yaml
 	buf := new(bytes.Buffer)
-	docs, err := vm.EvaluateSnippetStream(req.Repo.Config, config.Data)
+	docs, err := vm.EvaluateSnippetStream(req.Repo.Config, req.Config.Data)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +67,8 @@ func (p *jsonnetPlugin) Find(ctx context.Context, req *core.ConfigArgs) (*core.C
 		buf.WriteString(doc)
 	}
 
-	config.Data = buf.String()
-	return config, nil
+	return &core.Config{
+		Kind: req.Config.Kind,
+		Data: buf.String(),
+	}, nil
 }
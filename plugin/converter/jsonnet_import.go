@@ -0,0 +1,121 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package converter
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/drone/drone/core"
+
+	"github.com/google/go-jsonnet"
+)
+
+// limits applied to jsonnet file imports.
+const (
+	maxImportDepth = 10
+	maxImportCount = 100
+	maxImportSize  = 1 << 20 // 1MB
+)
+
+// importer is a jsonnet importer that fetches imported files
+// from the repository at the commit being built.
+type importer struct {
+	ctx   context.Context
+	files core.FileService
+	args  *core.ConvertArgs
+
+	// cache of imported files, keyed by path, for the
+	// duration of the evaluation.
+	cache map[string]jsonnet.Contents
+	depth map[string]int
+	size  int
+}
+
+func newImporter(ctx context.Context, files core.FileService, args *core.ConvertArgs) *importer {
+	return &importer{
+		ctx:   ctx,
+		files: files,
+		args:  args,
+		cache: map[string]jsonnet.Contents{},
+		depth: map[string]int{},
+	}
+}
+
+// Import fetches the imported file from the repository. Paths
+// are relative to the importing file, or to the repository root
+// if prefixed with a slash, and may not reference files outside
+// the repository.
+func (i *importer) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	var name string
+	if strings.HasPrefix(importedPath, "/") {
+		name = path.Clean(strings.TrimPrefix(importedPath, "/"))
+	} else {
+		name = path.Join(path.Dir(importedFrom), importedPath)
+	}
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return jsonnet.Contents{}, "", fmt.Errorf("jsonnet: cannot import %q: path outside the repository", importedPath)
+	}
+
+	if contents, ok := i.cache[name]; ok {
+		return contents, name, nil
+	}
+
+	depth := i.depth[importedFrom] + 1
+	if depth > maxImportDepth {
+		return jsonnet.Contents{}, "", fmt.Errorf("jsonnet: cannot import %q: maximum import depth exceeded", importedPath)
+	}
+	if len(i.cache) >= maxImportCount {
+		return jsonnet.Contents{}, "", fmt.Errorf("jsonnet: cannot import %q: maximum number of imports exceeded", importedPath)
+	}
+
+	file, err := i.files.Find(i.ctx,
+		i.args.User,
+		i.args.Repo.Slug,
+		i.args.Build.After,
+		i.args.Build.Ref,
+		name,
+	)
+	if err != nil {
+		return jsonnet.Contents{}, "", fmt.Errorf("jsonnet: cannot import %q: %s", importedPath, err)
+	}
+
+	i.size += len(file.Data)
+	if i.size > maxImportSize {
+		return jsonnet.Contents{}, "", fmt.Errorf("jsonnet: cannot import %q: maximum import size exceeded", importedPath)
+	}
+
+	contents := jsonnet.MakeContents(string(file.Data))
+	i.cache[name] = contents
+	i.depth[name] = depth
+	return contents, name, nil
+}
+
+// helper function binds the build metadata to jsonnet external
+// variables, so that the generated pipeline can adapt to the
+// build.
+func setExtVars(vm *jsonnet.VM, args *core.ConvertArgs) {
+	if build := args.Build; build != nil {
+		vm.ExtVar("build.event", build.Event)
+		vm.ExtVar("build.action", build.Action)
+		vm.ExtVar("build.branch", build.Target)
+		vm.ExtVar("build.source", build.Source)
+		vm.ExtVar("build.target", build.Target)
+		vm.ExtVar("build.ref", build.Ref)
+		vm.ExtVar("build.commit", build.After)
+		vm.ExtVar("build.author", build.Author)
+		vm.ExtVar("build.deploy", build.Deploy)
+	}
+	if repo := args.Repo; repo != nil {
+		vm.ExtVar("repo.slug", repo.Slug)
+		vm.ExtVar("repo.namespace", repo.Namespace)
+		vm.ExtVar("repo.name", repo.Name)
+		vm.ExtVar("repo.branch", repo.Branch)
+	}
+}
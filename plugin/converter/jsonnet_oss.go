@@ -14,11 +14,11 @@
 
 // +build oss
 
-package config
+package converter
 
 import "github.com/drone/drone/core"
 
-// Jsonnet returns a no-op configuration service.
-func Jsonnet(service core.FileService, enabled bool) core.ConfigService {
+// Jsonnet returns a no-op conversion service.
+func Jsonnet(service core.FileService, enabled bool) core.ConvertService {
 	return new(noop)
 }
@@ -0,0 +1,101 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+//go:build !oss
+// +build !oss
+
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestJsonnet_Import(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConvertArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone.jsonnet"},
+		Build: &core.Build{After: "6d144de7", Ref: "refs/heads/master", Target: "master", Event: core.EventPush},
+	}
+
+	root := &core.File{Data: []byte(`
+local lib = import 'lib/pipeline.libsonnet';
+[lib.pipeline(std.extVar('build.branch'), std.extVar('build.event'))]
+`)}
+	lib := &core.File{Data: []byte(`
+local steps = import 'steps.libsonnet';
+{ pipeline(branch, event):: { kind: 'pipeline', name: branch + '-' + event, steps: steps } }
+`)}
+	steps := &core.File{Data: []byte(`[]`)}
+
+	files := mock.NewMockFileService(controller)
+	args.Config = &core.Config{Data: string(root.Data)}
+	files.EXPECT().Find(gomock.Any(), args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, "lib/pipeline.libsonnet").Return(lib, nil)
+	files.EXPECT().Find(gomock.Any(), args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, "lib/steps.libsonnet").Return(steps, nil)
+
+	result, err := Jsonnet(files, true).Convert(noContext, args)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(result.Data, `"name": "master-push"`) {
+		t.Errorf("Want build metadata in generated pipeline, got %s", result.Data)
+	}
+}
+
+func TestJsonnet_ImportOutsideRepo(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConvertArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone.jsonnet"},
+		Build: &core.Build{After: "6d144de7"},
+	}
+
+	root := &core.File{Data: []byte(`import '../../etc/passwd'`)}
+
+	files := mock.NewMockFileService(controller)
+	args.Config = &core.Config{Data: string(root.Data)}
+
+	_, err := Jsonnet(files, true).Convert(noContext, args)
+	if err == nil || !strings.Contains(err.Error(), "path outside the repository") {
+		t.Errorf("Want import outside the repository rejected, got %v", err)
+	}
+}
+
+func TestJsonnet_ImportDepth(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConvertArgs{
+		User:   &core.User{Login: "octocat"},
+		Repo:   &core.Repository{Slug: "octocat/hello-world", Config: ".drone.jsonnet"},
+		Build:  &core.Build{After: "6d144de7"},
+		Config: &core.Config{Data: "import 'a'"},
+	}
+
+	// each file imports the next file, exceeding the
+	// maximum import depth.
+	files := mock.NewMockFileService(controller)
+	files.EXPECT().Find(gomock.Any(), args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, gomock.Any()).DoAndReturn(
+		func(_, _, _, _, _ interface{}, name string) (*core.File, error) {
+			next := "a" + strings.TrimPrefix(name, ".drone.jsonnet")
+			return &core.File{Data: []byte("import '" + next + "'")}, nil
+		},
+	).AnyTimes()
+
+	_, err := Jsonnet(files, true).Convert(noContext, args)
+	if err == nil || !strings.Contains(err.Error(), "maximum import depth exceeded") {
+		t.Errorf("Want import depth limit enforced, got %v", err)
+	}
+}
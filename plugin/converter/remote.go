@@ -0,0 +1,161 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package converter
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/drone/drone-go/drone"
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/plugin/internal/transform"
+
+	"github.com/99designs/httpsignatures-go"
+)
+
+// mime type of the conversion request.
+const mimeConvert = "application/vnd.drone.convert.v1+json"
+
+// required http headers, matching the configuration
+// extension signature scheme.
+var headers = []string{
+	"accept",
+	"accept-encoding",
+	"content-type",
+	"date",
+	"digest",
+}
+
+var signer = httpsignatures.NewSigner(
+	httpsignatures.AlgorithmHmacSha256,
+	headers...,
+)
+
+// Remote returns a conversion service that converts the
+// configuration file using a remote http service. Requests
+// are signed with the shared secret.
+func Remote(endpoint, secret string, skipVerify bool) core.ConvertService {
+	remote := &remote{
+		endpoint: endpoint,
+		secret:   secret,
+		client:   http.DefaultClient,
+	}
+	if skipVerify {
+		remote.client = &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true,
+				},
+			},
+		}
+	}
+	return remote
+}
+
+type remote struct {
+	endpoint string
+	secret   string
+	client   *http.Client
+}
+
+type remoteRequest struct {
+	Repo   drone.Repo   `json:"repo"`
+	Build  drone.Build  `json:"build"`
+	Config drone.Config `json:"config"`
+}
+
+func (g *remote) Convert(ctx context.Context, in *core.ConvertArgs) (*core.Config, error) {
+	if g.endpoint == "" {
+		return nil, nil
+	}
+	// include a timeout to prevent an API call from
+	// hanging the build process indefinitely. The
+	// external service must return a request within
+	// one minute.
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	data, err := json.Marshal(&remoteRequest{
+		Repo:  transform.ToRepo(in.Repo),
+		Build: transform.ToBuild(in.Build),
+		Config: drone.Config{
+			Kind: in.Config.Kind,
+			Data: in.Config.Data,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", g.endpoint, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Add("Accept", mimeConvert)
+	req.Header.Add("Accept-Encoding", "identity")
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Digest", "SHA-256="+digest(data))
+	req.Header.Add("Date", time.Now().UTC().Format(http.TimeFormat))
+	err = signer.SignRequest("hmac-key", g.secret, req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode > 299 {
+		if len(body) != 0 {
+			return nil, errors.New(string(body))
+		}
+		return nil, errors.New(http.StatusText(res.StatusCode))
+	}
+
+	// if the service returns No Content the configuration
+	// is not converted, and we should exit with no
+	// configuration, but no error.
+	if res.StatusCode == 204 {
+		return nil, nil
+	}
+
+	out := new(drone.Config)
+	err = json.Unmarshal(body, out)
+	if err != nil {
+		return nil, err
+	}
+	if out.Data == "" {
+		return nil, nil
+	}
+	return &core.Config{
+		Kind: out.Kind,
+		Data: out.Data,
+	}, nil
+}
+
+func digest(data []byte) string {
+	h := sha256.New()
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package converter
+
+import (
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/h2non/gock"
+)
+
+func TestRemote(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://company.com").
+		Post("/convert").
+		MatchHeader("Accept", "application/vnd.drone.convert.v1\\+json").
+		MatchHeader("Accept-Encoding", "identity").
+		MatchHeader("Content-Type", "application/json").
+		MatchHeader("Signature", "hmac-sha256").
+		BodyString(`"data":"kind: pipeline"`).
+		Reply(200).
+		BodyString(`{"data": "{ kind: pipeline, name: default }"}`).
+		Done()
+
+	args := &core.ConvertArgs{
+		User:   &core.User{Login: "octocat"},
+		Repo:   &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml"},
+		Build:  &core.Build{After: "6d144de7"},
+		Config: &core.Config{Data: "kind: pipeline"},
+	}
+
+	service := Remote("https://company.com/convert", "GMEuUHQfmrMRsseWxi9YlIeBtn9lm6im", false)
+	result, err := service.Convert(noContext, args)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if result.Data != "{ kind: pipeline, name: default }" {
+		t.Errorf("unexpected file contents")
+	}
+
+	if gock.IsPending() {
+		t.Errorf("Unfinished requests")
+		return
+	}
+}
+
+func TestRemote_NoContent(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://company.com").
+		Post("/convert").
+		Reply(204).
+		Done()
+
+	args := &core.ConvertArgs{
+		User:   &core.User{Login: "octocat"},
+		Repo:   &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml"},
+		Build:  &core.Build{After: "6d144de7"},
+		Config: &core.Config{Data: "kind: pipeline"},
+	}
+
+	service := Remote("https://company.com/convert", "GMEuUHQfmrMRsseWxi9YlIeBtn9lm6im", false)
+	result, err := service.Convert(noContext, args)
+	if err != nil {
+		t.Error(err)
+	}
+	if result != nil {
+		t.Errorf("Expect nil configuration when not converted")
+	}
+}
+
+func TestRemote_Err(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://company.com").
+		Post("/convert").
+		Reply(404).
+		Done()
+
+	args := &core.ConvertArgs{
+		User:   &core.User{Login: "octocat"},
+		Repo:   &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml"},
+		Build:  &core.Build{After: "6d144de7"},
+		Config: &core.Config{Data: "kind: pipeline"},
+	}
+
+	service := Remote("https://company.com/convert", "GMEuUHQfmrMRsseWxi9YlIeBtn9lm6im", false)
+	_, err := service.Convert(noContext, args)
+	if err == nil {
+		t.Errorf("Expect http.Reponse error")
+	} else if err.Error() != "Not Found" {
+		t.Errorf("Expect Not Found error")
+	}
+}
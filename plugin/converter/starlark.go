@@ -0,0 +1,217 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/plugin/internal/sandbox"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// limits applied to starlark execution. The number of
+// execution steps is limited by the sandbox.
+const (
+	// maxExecutionTime limits the execution time.
+	maxExecutionTime = 5 * time.Second
+
+	// maxLoadCount limits the number of modules loaded.
+	maxLoadCount = 100
+)
+
+var (
+	errStarlarkMain  = errors.New("starlark: main function is not defined")
+	errStarlarkLoads = errors.New("starlark: maximum number of loaded modules exceeded")
+)
+
+// Starlark returns a conversion service that executes the
+// main function of the starlark file, and converts the
+// returned pipelines to a yaml file.
+func Starlark(service core.FileService, enabled bool) core.ConvertService {
+	return &starlarkPlugin{
+		enabled: enabled,
+		files:   service,
+	}
+}
+
+type starlarkPlugin struct {
+	enabled bool
+	files   core.FileService
+}
+
+func (p *starlarkPlugin) Convert(ctx context.Context, req *core.ConvertArgs) (*core.Config, error) {
+	if p.enabled == false {
+		return nil, nil
+	}
+
+	// if the file extension is not starlark we can
+	// skip this plugin by returning zero values.
+	switch {
+	case strings.HasSuffix(req.Repo.Config, ".star"):
+	case strings.HasSuffix(req.Repo.Config, ".starlark"):
+	default:
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, maxExecutionTime)
+	defer cancel()
+
+	data, err := newStarlarkExec(ctx, p.files, req).run(req.Config.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &core.Config{
+		Kind: req.Config.Kind,
+		Data: data,
+	}, nil
+}
+
+// starlarkExec executes a single starlark configuration file.
+type starlarkExec struct {
+	ctx   context.Context
+	files core.FileService
+	args  *core.ConvertArgs
+
+	// modules caches the loaded modules for the duration
+	// of the execution. A nil entry indicates the module is
+	// being loaded, and is used to detect load cycles.
+	modules map[string]*starlarkModule
+}
+
+type starlarkModule struct {
+	globals starlark.StringDict
+	err     error
+}
+
+func newStarlarkExec(ctx context.Context, files core.FileService, args *core.ConvertArgs) *starlarkExec {
+	return &starlarkExec{
+		ctx:     ctx,
+		files:   files,
+		args:    args,
+		modules: map[string]*starlarkModule{},
+	}
+}
+
+func (e *starlarkExec) run(data string) (string, error) {
+	thread := sandbox.NewThread(e.args.Repo.Config)
+	thread.Load = e.load
+
+	var value starlark.Value
+	err := sandbox.Run(e.ctx, thread, func() error {
+		globals, err := starlark.ExecFile(thread, e.args.Repo.Config, data, nil)
+		if err != nil {
+			return err
+		}
+		main, ok := globals["main"]
+		if !ok {
+			return errStarlarkMain
+		}
+		ctx := starlark.Tuple{e.context()}
+		value, err = starlark.Call(thread, main, ctx, nil)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// the main function returns a single pipeline, or a
+	// list of pipelines, that are combined into a single
+	// multi-document yaml file.
+	var docs []starlark.Value
+	switch v := value.(type) {
+	case *starlark.List:
+		for i := 0; i < v.Len(); i++ {
+			docs = append(docs, v.Index(i))
+		}
+	case starlark.Tuple:
+		docs = v
+	default:
+		docs = append(docs, v)
+	}
+
+	buf := new(bytes.Buffer)
+	for _, doc := range docs {
+		if _, ok := doc.(*starlark.Dict); !ok {
+			return "", fmt.Errorf("starlark: main returned %s, want dict or list of dicts", doc.Type())
+		}
+		v, err := sandbox.ToGo(doc)
+		if err != nil {
+			return "", err
+		}
+		// json is a subset of yaml, and is therefore
+		// written directly to the yaml document.
+		out, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString("---")
+		buf.WriteString("\n")
+		buf.Write(out)
+		buf.WriteString("\n")
+	}
+	return buf.String(), nil
+}
+
+// load loads the named module from the repository at the
+// commit being built. Module paths are relative to the
+// repository root.
+func (e *starlarkExec) load(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	name := path.Clean(strings.TrimPrefix(module, "/"))
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return nil, fmt.Errorf("starlark: cannot load %q: path outside the repository", module)
+	}
+	if m, ok := e.modules[name]; ok {
+		if m == nil {
+			return nil, fmt.Errorf("starlark: cannot load %q: cycle in load graph", module)
+		}
+		return m.globals, m.err
+	}
+	if len(e.modules) >= maxLoadCount {
+		return nil, errStarlarkLoads
+	}
+
+	file, err := e.files.Find(e.ctx,
+		e.args.User,
+		e.args.Repo.Slug,
+		e.args.Build.After,
+		e.args.Build.Ref,
+		name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("starlark: cannot load %q: %s", module, err)
+	}
+
+	// the module is executed on the calling thread so that
+	// the execution step limit and cancellation apply to the
+	// loaded modules.
+	e.modules[name] = nil
+	globals, err := starlark.ExecFile(thread, name, file.Data, nil)
+	e.modules[name] = &starlarkModule{globals, err}
+	return globals, err
+}
+
+// context returns the ctx argument passed to the main function,
+// which exposes the build and repository metadata.
+func (e *starlarkExec) context() starlark.Value {
+	return starlarkstruct.FromStringDict(
+		starlark.String("context"),
+		starlark.StringDict{
+			"build": sandbox.Build(e.args.Build),
+			"repo":  sandbox.Repo(e.args.Repo),
+		},
+	)
+}
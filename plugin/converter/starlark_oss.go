@@ -14,12 +14,11 @@
 
 // +build oss
 
-package cache
+package converter
 
 import "github.com/drone/drone/core"
 
-// Contents returns the default FileService with no caching
-// enabled.
-func Contents(base core.FileService) core.FileService {
-	return base
+// Starlark returns a no-op conversion service.
+func Starlark(service core.FileService, enabled bool) core.ConvertService {
+	return new(noop)
 }
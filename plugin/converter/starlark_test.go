@@ -0,0 +1,175 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package converter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/plugin/internal/sandbox"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestStarlark(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConvertArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone.star"},
+		Build: &core.Build{After: "6d144de7", Target: "master", Event: core.EventPush},
+	}
+
+	root := &core.File{Data: []byte(`
+load("lib/steps.star", "step")
+
+def main(ctx):
+    return [
+        {
+            "kind": "pipeline",
+            "name": ctx.build.branch + "-" + ctx.build.event,
+            "steps": [step(n) for n in range(2)],
+        },
+        {"kind": "pipeline", "name": ctx.repo.slug},
+    ]
+`)}
+	lib := &core.File{Data: []byte(`
+def step(n):
+    return {"name": "step-%d" % n, "image": "alpine"}
+`)}
+
+	files := mock.NewMockFileService(controller)
+	args.Config = &core.Config{Data: string(root.Data)}
+	files.EXPECT().Find(gomock.Any(), args.User, args.Repo.Slug, args.Build.After, args.Build.Ref, "lib/steps.star").Return(lib, nil)
+
+	result, err := Starlark(files, true).Convert(noContext, args)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	want := `---
+{"kind":"pipeline","name":"master-push","steps":[{"image":"alpine","name":"step-0"},{"image":"alpine","name":"step-1"}]}
+---
+{"kind":"pipeline","name":"octocat/hello-world"}
+`
+	if got := result.Data; got != want {
+		t.Errorf("Want yaml %q, got %q", want, got)
+	}
+}
+
+func TestStarlark_Skip(t *testing.T) {
+	args := &core.ConvertArgs{
+		Repo: &core.Repository{Config: ".drone.yml"},
+	}
+	result, err := Starlark(nil, true).Convert(noContext, args)
+	if result != nil || err != nil {
+		t.Errorf("Want non-starlark configuration skipped")
+	}
+	args.Repo.Config = ".drone.star"
+	result, err = Starlark(nil, false).Convert(noContext, args)
+	if result != nil || err != nil {
+		t.Errorf("Want starlark configuration skipped when disabled")
+	}
+}
+
+func TestStarlark_Steps(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConvertArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone.star"},
+		Build: &core.Build{After: "6d144de7"},
+	}
+
+	root := &core.File{Data: []byte(`
+def main(ctx):
+    n = 0
+    for i in range(1000):
+        for j in range(1000):
+            n += 1
+    return {"kind": "pipeline", "name": str(n)}
+`)}
+
+	files := mock.NewMockFileService(controller)
+	args.Config = &core.Config{Data: string(root.Data)}
+
+	_, err := Starlark(files, true).Convert(noContext, args)
+	if err == nil || !strings.Contains(err.Error(), sandbox.ErrSteps.Error()) {
+		t.Errorf("Want execution step limit enforced, got %v", err)
+	}
+}
+
+// this test verifies that the execution step limit applies to
+// loops that do not use the range builtin.
+func TestStarlark_StepsNoRange(t *testing.T) {
+	args := &core.ConvertArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone.star"},
+		Build: &core.Build{After: "6d144de7"},
+		Config: &core.Config{Data: `
+def main(ctx):
+    s = "x" * 1000
+    n = len([a + b for a in s.elems() for b in s.elems()])
+    return {"kind": "pipeline", "name": str(n)}
+`},
+	}
+	_, err := Starlark(nil, true).Convert(noContext, args)
+	if got, want := err, sandbox.ErrSteps; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
+
+// this test verifies that execution is cancelled when the
+// context expires, including long running builtin calls that
+// consume few execution steps.
+func TestStarlark_Timeout(t *testing.T) {
+	args := &core.ConvertArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone.star"},
+		Build: &core.Build{After: "6d144de7"},
+		Config: &core.Config{Data: `
+def main(ctx):
+    for i in range(500):
+        s = ("x" * 10000000).upper()
+    return {"kind": "pipeline", "name": "default"}
+`},
+	}
+	ctx, cancel := context.WithTimeout(noContext, 50*time.Millisecond)
+	defer cancel()
+	_, err := Starlark(nil, true).Convert(ctx, args)
+	if got, want := err, sandbox.ErrTimeout; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
+
+func TestStarlark_Load(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	args := &core.ConvertArgs{
+		User:  &core.User{Login: "octocat"},
+		Repo:  &core.Repository{Slug: "octocat/hello-world", Config: ".drone.star"},
+		Build: &core.Build{After: "6d144de7"},
+	}
+
+	root := &core.File{Data: []byte(`load("../secrets.star", "x")`)}
+
+	files := mock.NewMockFileService(controller)
+	args.Config = &core.Config{Data: string(root.Data)}
+
+	_, err := Starlark(files, true).Convert(noContext, args)
+	if err == nil || !strings.Contains(err.Error(), "path outside the repository") {
+		t.Errorf("Want load outside the repository rejected, got %v", err)
+	}
+}
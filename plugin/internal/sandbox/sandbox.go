@@ -0,0 +1,81 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sandbox provides a sandboxed starlark execution
+// environment that limits the number of execution steps and
+// the execution time.
+package sandbox
+
+import (
+	"context"
+	"errors"
+
+	"go.starlark.net/starlark"
+)
+
+// MaxExecutionSteps limits the number of computation steps
+// executed by a thread, including the steps executed by
+// loaded modules.
+const MaxExecutionSteps = 50000
+
+var (
+	// ErrSteps is returned when execution exceeds the
+	// maximum number of execution steps.
+	ErrSteps = errors.New("starlark: maximum execution steps exceeded")
+
+	// ErrTimeout is returned when execution is cancelled
+	// because the context expired.
+	ErrTimeout = errors.New("starlark: maximum execution time exceeded")
+)
+
+// NewThread returns a new thread that enforces the execution
+// step limit. Print statements are ignored.
+func NewThread(name string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(*starlark.Thread, string) {
+			// print statements are ignored.
+		},
+	}
+	thread.SetMaxExecutionSteps(MaxExecutionSteps)
+	return thread
+}
+
+// Run calls fn, which executes starlark code on the thread,
+// and cancels the thread if the context expires. Cancellation
+// stops execution at the next computation step. If execution
+// is cancelled, ErrTimeout or ErrSteps is returned.
+func Run(ctx context.Context, thread *starlark.Thread, fn func() error) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ErrTimeout.Error())
+		case <-done:
+		}
+	}()
+
+	err := fn()
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return ErrTimeout
+	case thread.ExecutionSteps() >= MaxExecutionSteps:
+		return ErrSteps
+	default:
+		return err
+	}
+}
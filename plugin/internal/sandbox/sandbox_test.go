@@ -0,0 +1,155 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+
+	"github.com/google/go-cmp/cmp"
+	"go.starlark.net/starlark"
+)
+
+func TestRun(t *testing.T) {
+	thread := NewThread("test.star")
+	var globals starlark.StringDict
+	err := Run(context.Background(), thread, func() (err error) {
+		globals, err = starlark.ExecFile(thread, "test.star", "x = 1 + 1\nprint(x)\n", nil)
+		return err
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := globals["x"], starlark.MakeInt(2); got != want {
+		t.Errorf("Want x %s, got %s", want, got)
+	}
+}
+
+func TestRun_Error(t *testing.T) {
+	thread := NewThread("test.star")
+	want := errors.New("not found")
+	err := Run(context.Background(), thread, func() error {
+		return want
+	})
+	if err != want {
+		t.Errorf("Want error %v, got %v", want, err)
+	}
+}
+
+func TestRun_Steps(t *testing.T) {
+	thread := NewThread("test.star")
+	err := Run(context.Background(), thread, func() error {
+		_, err := starlark.ExecFile(thread, "test.star", "x = [i for i in range(1000000)]\n", nil)
+		return err
+	})
+	if err != ErrSteps {
+		t.Errorf("Want error %v, got %v", ErrSteps, err)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	thread := NewThread("test.star")
+	err := Run(ctx, thread, func() error {
+		_, err := starlark.ExecFile(thread, "test.star", "[('x' * 10000000).upper() for i in range(100)]\n", nil)
+		return err
+	})
+	if err != ErrTimeout {
+		t.Errorf("Want error %v, got %v", ErrTimeout, err)
+	}
+}
+
+func TestToGo(t *testing.T) {
+	thread := NewThread("test.star")
+	value, err := starlark.Eval(thread, "test.star", `{"name": "build", "commands": ["go build", 1, True, None]}`, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got, err := ToGo(value)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := map[string]interface{}{
+		"name":     "build",
+		"commands": []interface{}{"go build", int64(1), true, nil},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestToGo_Error(t *testing.T) {
+	thread := NewThread("test.star")
+	for _, expr := range []string{`{1: "build"}`, `len`, `1 << 64`} {
+		value, err := starlark.Eval(thread, "test.star", expr, nil)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if _, err := ToGo(value); err == nil {
+			t.Errorf("Want error converting %s", expr)
+		}
+	}
+}
+
+func TestToStarlark(t *testing.T) {
+	value, err := ToStarlark(map[interface{}]interface{}{
+		"name":     "build",
+		"commands": []interface{}{"go build", 1, 1.5, true, nil},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got, err := ToGo(value)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := map[string]interface{}{
+		"name":     "build",
+		"commands": []interface{}{"go build", int64(1), 1.5, true, nil},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestRepo(t *testing.T) {
+	repo := &core.Repository{Slug: "octocat/hello-world", Trusted: true}
+	thread := NewThread("test.star")
+	value, err := starlark.Eval(thread, "test.star", "repo.slug if repo.trusted else None", starlark.StringDict{"repo": Repo(repo)})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := value, starlark.String("octocat/hello-world"); got != want {
+		t.Errorf("Want slug %s, got %s", want, got)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	build := &core.Build{Event: core.EventPush, Target: "master", After: "7fd1a60"}
+	thread := NewThread("test.star")
+	value, err := starlark.Eval(thread, "test.star", "[build.event, build.branch, build.commit]", starlark.StringDict{"build": Build(build)})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got, _ := ToGo(value)
+	want := []interface{}{"push", "master", "7fd1a60"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
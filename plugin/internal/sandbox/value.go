@@ -0,0 +1,172 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"fmt"
+
+	"github.com/drone/drone/core"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// Build returns the starlark representation of the build
+// metadata.
+func Build(build *core.Build) starlark.Value {
+	return starlarkstruct.FromStringDict(
+		starlarkstruct.Default,
+		starlark.StringDict{
+			"event":   starlark.String(build.Event),
+			"action":  starlark.String(build.Action),
+			"branch":  starlark.String(build.Target),
+			"source":  starlark.String(build.Source),
+			"target":  starlark.String(build.Target),
+			"ref":     starlark.String(build.Ref),
+			"before":  starlark.String(build.Before),
+			"commit":  starlark.String(build.After),
+			"message": starlark.String(build.Message),
+			"title":   starlark.String(build.Title),
+			"link":    starlark.String(build.Link),
+			"author":  starlark.String(build.Author),
+			"sender":  starlark.String(build.Sender),
+			"deploy":  starlark.String(build.Deploy),
+			"trigger": starlark.String(build.Trigger),
+		},
+	)
+}
+
+// Repo returns the starlark representation of the repository
+// metadata.
+func Repo(repo *core.Repository) starlark.Value {
+	return starlarkstruct.FromStringDict(
+		starlarkstruct.Default,
+		starlark.StringDict{
+			"uid":        starlark.String(repo.UID),
+			"slug":       starlark.String(repo.Slug),
+			"namespace":  starlark.String(repo.Namespace),
+			"name":       starlark.String(repo.Name),
+			"branch":     starlark.String(repo.Branch),
+			"link":       starlark.String(repo.Link),
+			"private":    starlark.Bool(repo.Private),
+			"visibility": starlark.String(repo.Visibility),
+			"trusted":    starlark.Bool(repo.Trusted),
+			"protected":  starlark.Bool(repo.Protected),
+			"config":     starlark.String(repo.Config),
+		},
+	)
+}
+
+// ToGo converts the starlark value to a value that can be
+// encoded to json or yaml.
+func ToGo(v starlark.Value) (interface{}, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.Int:
+		i, ok := v.Int64()
+		if !ok {
+			return nil, fmt.Errorf("starlark: integer %s out of range", v)
+		}
+		return i, nil
+	case starlark.Float:
+		return float64(v), nil
+	case starlark.String:
+		return string(v), nil
+	case *starlark.Dict:
+		out := map[string]interface{}{}
+		for _, item := range v.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("starlark: dict key %s is not a string", item[0])
+			}
+			val, err := ToGo(item[1])
+			if err != nil {
+				return nil, err
+			}
+			out[string(key)] = val
+		}
+		return out, nil
+	case starlark.Indexable:
+		out := []interface{}{}
+		for i := 0; i < v.Len(); i++ {
+			val, err := ToGo(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, val)
+		}
+		return out, nil
+	case *starlarkstruct.Struct:
+		dict := starlark.StringDict{}
+		v.ToStringDict(dict)
+		out := map[string]interface{}{}
+		for key, val := range dict {
+			conv, err := ToGo(val)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = conv
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("starlark: cannot convert %s to yaml", v.Type())
+	}
+}
+
+// ToStarlark converts the value decoded from yaml to a
+// starlark value.
+func ToStarlark(v interface{}) (starlark.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(v), nil
+	case int:
+		return starlark.MakeInt(v), nil
+	case int64:
+		return starlark.MakeInt64(v), nil
+	case uint64:
+		return starlark.MakeUint64(v), nil
+	case float64:
+		return starlark.Float(v), nil
+	case string:
+		return starlark.String(v), nil
+	case []interface{}:
+		var items []starlark.Value
+		for _, item := range v {
+			value, err := ToStarlark(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return starlark.NewList(items), nil
+	case map[interface{}]interface{}:
+		dict := new(starlark.Dict)
+		for key, item := range v {
+			value, err := ToStarlark(item)
+			if err != nil {
+				return nil, err
+			}
+			dict.SetKey(starlark.String(fmt.Sprint(key)), value)
+		}
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("starlark: cannot convert %T to starlark", v)
+	}
+}
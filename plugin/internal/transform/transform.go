@@ -0,0 +1,83 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transform converts core types to the types exchanged
+// with external plugins.
+package transform
+
+import (
+	"github.com/drone/drone-go/drone"
+	"github.com/drone/drone/core"
+)
+
+// ToRepo converts the repository to the plugin representation.
+func ToRepo(from *core.Repository) drone.Repo {
+	return drone.Repo{
+		ID:         from.ID,
+		UID:        from.UID,
+		UserID:     from.UserID,
+		Namespace:  from.Namespace,
+		Name:       from.Name,
+		Slug:       from.Slug,
+		SCM:        from.SCM,
+		HTTPURL:    from.HTTPURL,
+		SSHURL:     from.SSHURL,
+		Link:       from.Link,
+		Branch:     from.Branch,
+		Private:    from.Private,
+		Visibility: from.Visibility,
+		Active:     from.Active,
+		Config:     from.Config,
+		Trusted:    from.Trusted,
+		Protected:  from.Protected,
+		Timeout:    from.Timeout,
+	}
+}
+
+// ToBuild converts the build to the plugin representation.
+func ToBuild(from *core.Build) drone.Build {
+	return drone.Build{
+		ID:           from.ID,
+		RepoID:       from.RepoID,
+		Trigger:      from.Trigger,
+		Number:       from.Number,
+		Parent:       from.Parent,
+		Status:       from.Status,
+		Error:        from.Error,
+		Event:        from.Event,
+		Action:       from.Action,
+		Link:         from.Link,
+		Timestamp:    from.Timestamp,
+		Title:        from.Title,
+		Message:      from.Message,
+		Before:       from.Before,
+		After:        from.After,
+		Ref:          from.Ref,
+		Fork:         from.Fork,
+		Source:       from.Source,
+		Target:       from.Target,
+		Author:       from.Author,
+		AuthorName:   from.AuthorName,
+		AuthorEmail:  from.AuthorEmail,
+		AuthorAvatar: from.AuthorAvatar,
+		Sender:       from.Sender,
+		Params:       from.Params,
+		Deploy:       from.Deploy,
+		Started:      from.Started,
+		Finished:     from.Finished,
+		Created:      from.Created,
+		Updated:      from.Updated,
+		Version:      from.Version,
+	}
+}
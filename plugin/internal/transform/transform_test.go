@@ -0,0 +1,69 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/drone/drone-go/drone"
+	"github.com/drone/drone/core"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestToRepo(t *testing.T) {
+	from := &core.Repository{
+		ID:         1,
+		UID:        "42",
+		Namespace:  "octocat",
+		Name:       "hello-world",
+		Slug:       "octocat/hello-world",
+		Branch:     "master",
+		Visibility: "public",
+		Config:     ".drone.yml",
+		Trusted:    true,
+		Timeout:    60,
+	}
+	want := drone.Repo{
+		ID:         1,
+		UID:        "42",
+		Namespace:  "octocat",
+		Name:       "hello-world",
+		Slug:       "octocat/hello-world",
+		Branch:     "master",
+		Visibility: "public",
+		Config:     ".drone.yml",
+		Trusted:    true,
+		Timeout:    60,
+	}
+	if diff := cmp.Diff(ToRepo(from), want); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestToBuild(t *testing.T) {
+	from := &core.Build{
+		ID:     1,
+		RepoID: 2,
+		Number: 3,
+		Status: core.StatusPending,
+		Event:  core.EventPush,
+		Ref:    "refs/heads/master",
+		After:  "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d",
+		Params: map[string]string{"foo": "bar"},
+	}
+	want := drone.Build{
+		ID:     1,
+		RepoID: 2,
+		Number: 3,
+		Status: core.StatusPending,
+		Event:  core.EventPush,
+		Ref:    "refs/heads/master",
+		After:  "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d",
+		Params: map[string]string{"foo": "bar"},
+	}
+	if diff := cmp.Diff(ToBuild(from), want); diff != "" {
+		t.Errorf(diff)
+	}
+}
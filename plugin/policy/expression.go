@@ -0,0 +1,288 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/drone/drone-yaml/yaml"
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/plugin/internal/sandbox"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// maxExecutionTime limits the time spent evaluating all
+// policies for a single build. The number of execution steps
+// is limited by the sandbox.
+const maxExecutionTime = 5 * time.Second
+
+// Policy scopes.
+const (
+	scopeBuild    = "build"
+	scopePipeline = "pipeline"
+	scopeStep     = "step"
+)
+
+var (
+	errPolicyDeny    = errors.New("policy: deny expression is required")
+	errPolicyMessage = errors.New("policy: message is required")
+)
+
+// Expression returns a policy service that evaluates the
+// policy files (*.yml) in the directory. Each file defines a
+// single policy, named after the file:
+//
+//   action: block
+//   scope: step
+//   deny: step.get("privileged", False) and not repo.trusted
+//   message: privileged mode requires a trusted repository
+//
+// The deny expression is a single starlark expression with
+// access to the build, repo, pipeline and step variables. The
+// build and repo variables expose the build and repository
+// metadata, and the pipeline and step variables are the
+// dictionaries decoded from the configuration file. A truthy
+// result is a violation.
+//
+// The scope determines whether the expression is evaluated
+// once per build, once per pipeline, or once per step and
+// service (default). Variables outside the scope are None.
+//
+// The action determines whether violations fail the build
+// (error, default) or block the build pending approval.
+func Expression(dir string) (core.PolicyService, error) {
+	plugin := new(expressionPlugin)
+	if dir == "" {
+		return plugin, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		policy, err := parsePolicy(filepath.Base(path), data)
+		if err != nil {
+			return nil, err
+		}
+		plugin.policies = append(plugin.policies, policy)
+	}
+	return plugin, nil
+}
+
+type expressionPlugin struct {
+	policies []*policy
+}
+
+func (p *expressionPlugin) Evaluate(ctx context.Context, req *core.PolicyArgs) ([]*core.PolicyViolation, error) {
+	if len(p.policies) == 0 {
+		return nil, nil
+	}
+	pipelines, err := parsePipelines(req.Config.Data)
+	if err != nil {
+		return nil, err
+	}
+	build := sandbox.Build(req.Build)
+	repo := sandbox.Repo(req.Repo)
+	build.Freeze()
+	repo.Freeze()
+
+	ctx, cancel := context.WithTimeout(ctx, maxExecutionTime)
+	defer cancel()
+
+	// all policies are evaluated on a single thread, which
+	// limits the execution steps for the build as a whole.
+	var violations []*core.PolicyViolation
+	thread := sandbox.NewThread("policy")
+	err = sandbox.Run(ctx, thread, func() error {
+		for _, policy := range p.policies {
+			messages, err := policy.eval(thread, build, repo, pipelines)
+			if err != nil {
+				return fmt.Errorf("policy: %s: %s", policy.name, err)
+			}
+			for _, message := range messages {
+				violations = append(violations, &core.PolicyViolation{
+					Policy:  policy.name,
+					Message: message,
+					Action:  policy.action,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+// policy is a single compiled policy file.
+type policy struct {
+	name    string
+	action  string
+	scope   string
+	message string
+	deny    starlark.Callable
+}
+
+// helper function parses the policy file and compiles the
+// deny expression.
+func parsePolicy(name string, data []byte) (*policy, error) {
+	out := struct {
+		Action  string
+		Scope   string
+		Deny    string
+		Message string
+	}{}
+	if err := yamlv2.UnmarshalStrict(data, &out); err != nil {
+		return nil, fmt.Errorf("policy: %s: %s", name, err)
+	}
+
+	policy := &policy{
+		name:    strings.TrimSuffix(name, filepath.Ext(name)),
+		action:  out.Action,
+		scope:   out.Scope,
+		message: out.Message,
+	}
+	switch policy.action {
+	case "":
+		policy.action = core.PolicyError
+	case core.PolicyError, core.PolicyBlock:
+	default:
+		return nil, fmt.Errorf("policy: %s: action must be %q or %q", name, core.PolicyError, core.PolicyBlock)
+	}
+	switch policy.scope {
+	case "":
+		policy.scope = scopeStep
+	case scopeBuild, scopePipeline, scopeStep:
+	default:
+		return nil, fmt.Errorf("policy: %s: scope must be %q, %q or %q", name, scopeBuild, scopePipeline, scopeStep)
+	}
+	if strings.TrimSpace(out.Deny) == "" {
+		return nil, fmt.Errorf("%s: %s", errPolicyDeny, name)
+	}
+	if out.Message == "" {
+		return nil, fmt.Errorf("%s: %s", errPolicyMessage, name)
+	}
+
+	// the deny expression is parsed on its own to ensure it
+	// is a single expression, and is then compiled to a
+	// function that accepts the policy variables.
+	if _, err := syntax.ParseExpr(name, out.Deny, 0); err != nil {
+		return nil, err
+	}
+	src := "def deny(build, repo, pipeline, step):\n    return (" + out.Deny + "\n)\n"
+	globals, err := starlark.ExecFile(sandbox.NewThread(name), name, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	policy.deny = globals["deny"].(starlark.Callable)
+	return policy, nil
+}
+
+// helper function evaluates the deny expression for the
+// build, or for each pipeline or step in scope, and returns
+// the violation messages.
+func (p *policy) eval(thread *starlark.Thread, build, repo starlark.Value, pipelines []*starlark.Dict) ([]string, error) {
+	var messages []string
+	deny := func(pipeline, step starlark.Value, prefix string) error {
+		args := starlark.Tuple{build, repo, pipeline, step}
+		value, err := starlark.Call(thread, p.deny, args, nil)
+		if err != nil {
+			return err
+		}
+		if value.Truth() {
+			messages = append(messages, prefix+p.message)
+		}
+		return nil
+	}
+
+	if p.scope == scopeBuild {
+		if err := deny(starlark.None, starlark.None, ""); err != nil {
+			return nil, err
+		}
+		return messages, nil
+	}
+	for _, pipeline := range pipelines {
+		if p.scope == scopePipeline {
+			prefix := fmt.Sprintf("pipeline %s: ", nameOf(pipeline))
+			if err := deny(pipeline, starlark.None, prefix); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, key := range []string{"services", "steps"} {
+			value, _, _ := pipeline.Get(starlark.String(key))
+			steps, ok := value.(*starlark.List)
+			if !ok {
+				continue
+			}
+			for i := 0; i < steps.Len(); i++ {
+				step, ok := steps.Index(i).(*starlark.Dict)
+				if !ok {
+					continue
+				}
+				prefix := fmt.Sprintf("step %s: ", nameOf(step))
+				if err := deny(pipeline, step, prefix); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return messages, nil
+}
+
+// helper function parses the configuration file and returns
+// the pipeline resources. The pipelines are frozen to prevent
+// one policy from modifying the pipelines evaluated by the
+// next policy.
+func parsePipelines(data string) ([]*starlark.Dict, error) {
+	raw, err := yaml.ParseRawString(data)
+	if err != nil {
+		return nil, err
+	}
+	var pipelines []*starlark.Dict
+	for _, resource := range raw {
+		if resource.Kind != "" && resource.Kind != yaml.KindPipeline {
+			continue
+		}
+		var out interface{}
+		if err := yamlv2.Unmarshal(resource.Data, &out); err != nil {
+			return nil, err
+		}
+		value, err := sandbox.ToStarlark(out)
+		if err != nil {
+			return nil, err
+		}
+		pipeline, ok := value.(*starlark.Dict)
+		if !ok {
+			continue
+		}
+		pipeline.Freeze()
+		pipelines = append(pipelines, pipeline)
+	}
+	return pipelines, nil
+}
+
+// helper function returns the name of the pipeline or step.
+func nameOf(dict *starlark.Dict) string {
+	value, _, _ := dict.Get(starlark.String("name"))
+	name, _ := starlark.AsString(value)
+	return name
+}
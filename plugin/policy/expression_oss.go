@@ -0,0 +1,34 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build oss
+
+package policy
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+)
+
+// Expression returns a no-op policy service.
+func Expression(dir string) (core.PolicyService, error) {
+	return new(noop), nil
+}
+
+type noop struct{}
+
+func (noop) Evaluate(context.Context, *core.PolicyArgs) ([]*core.PolicyViolation, error) {
+	return nil, nil
+}
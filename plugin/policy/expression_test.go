@@ -0,0 +1,228 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/plugin/internal/sandbox"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var noContext = context.Background()
+
+var mockConfig = `
+kind: pipeline
+name: default
+
+steps:
+- name: build
+  image: golang
+  privileged: true
+- name: deploy
+  image: registry.corp/deploy:1
+  environment:
+    DEPLOY_TARGET: production
+  when:
+    event: push
+---
+kind: secret
+name: token
+get:
+  path: secret/data/token
+`
+
+func TestEvaluate(t *testing.T) {
+	service, err := Expression("testdata")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	args := &core.PolicyArgs{
+		Repo:   &core.Repository{Slug: "octocat/hello-world"},
+		Build:  &core.Build{Event: core.EventPush},
+		Config: &core.Config{Data: mockConfig},
+	}
+	got, err := service.Evaluate(noContext, args)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := []*core.PolicyViolation{
+		{Policy: "latest", Message: "step build: image uses the latest tag", Action: core.PolicyError},
+		{Policy: "privileged", Message: "step build: privileged mode requires a trusted repository", Action: core.PolicyError},
+		{Policy: "promote", Message: "step deploy: production deployments require when.event: promote", Action: core.PolicyBlock},
+		{Policy: "registry", Message: "step build: image is not from registry.corp", Action: core.PolicyError},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestEvaluate_Trusted(t *testing.T) {
+	service, err := Expression("testdata")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	args := &core.PolicyArgs{
+		Repo:   &core.Repository{Slug: "octocat/hello-world", Trusted: true},
+		Build:  &core.Build{Event: core.EventPromote},
+		Config: &core.Config{Data: "kind: pipeline\nsteps:\n- name: build\n  image: registry.corp/golang:1.12\n  privileged: true\n"},
+	}
+	got, err := service.Evaluate(noContext, args)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Want no violations, got %d", len(got))
+	}
+}
+
+func TestEvaluate_NoPolicies(t *testing.T) {
+	service, err := Expression("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got, err := service.Evaluate(noContext, &core.PolicyArgs{})
+	if err != nil {
+		t.Error(err)
+	}
+	if got != nil {
+		t.Errorf("Want nil violations")
+	}
+}
+
+func TestEvaluate_Scope(t *testing.T) {
+	tests := []struct {
+		data string
+		want []string
+	}{
+		{
+			data: "scope: build\ndeny: build.event == 'push' and pipeline == None\nmessage: push builds are disabled",
+			want: []string{"push builds are disabled"},
+		},
+		{
+			data: "scope: pipeline\ndeny: len(pipeline.get('steps', [])) > 1 and step == None\nmessage: too many steps",
+			want: []string{"pipeline default: too many steps"},
+		},
+		{
+			data: "deny: pipeline['name'] == 'default'\nmessage: step denied",
+			want: []string{"step build: step denied", "step deploy: step denied"},
+		},
+	}
+	args := &core.PolicyArgs{
+		Repo:   &core.Repository{},
+		Build:  &core.Build{Event: core.EventPush},
+		Config: &core.Config{Data: mockConfig},
+	}
+	for i, test := range tests {
+		compiled, err := parsePolicy("test.yml", []byte(test.data))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		service := &expressionPlugin{policies: []*policy{compiled}}
+		violations, err := service.Evaluate(noContext, args)
+		if err != nil {
+			t.Errorf("Want no error at index %d, got %s", i, err)
+			continue
+		}
+		var got []string
+		for _, violation := range violations {
+			got = append(got, violation.Message)
+		}
+		if diff := cmp.Diff(got, test.want); diff != "" {
+			t.Errorf("Unexpected violations at index %d", i)
+			t.Log(diff)
+		}
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		data string
+		err  bool
+	}{
+		{data: "deny: 'True'\nmessage: denied"},
+		{data: "action: block\nscope: pipeline\ndeny: 'True'\nmessage: denied"},
+		{data: "action: ignore\ndeny: 'True'\nmessage: denied", err: true},
+		{data: "scope: stage\ndeny: 'True'\nmessage: denied", err: true},
+		{data: "message: denied", err: true},
+		{data: "deny: 'True'", err: true},
+		{data: "deny: 'True'\nmessage: denied\nallow: 'False'", err: true},
+		{data: "deny: 'true)\\ndef f():\\n  pass\\n('\nmessage: denied", err: true},
+		{data: "deny: 'x = 1'\nmessage: denied", err: true},
+		{data: "deny: undefined\nmessage: denied", err: true},
+	}
+	for i, test := range tests {
+		_, err := parsePolicy("test.yml", []byte(test.data))
+		if got, want := err != nil, test.err; got != want {
+			t.Errorf("Want error %v at index %d, got %v", want, i, err)
+		}
+	}
+}
+
+func TestEvaluate_Error(t *testing.T) {
+	compiled, err := parsePolicy("test.yml", []byte("deny: step['name'] + 1\nmessage: denied"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	service := &expressionPlugin{policies: []*policy{compiled}}
+	args := &core.PolicyArgs{
+		Repo:   &core.Repository{},
+		Build:  &core.Build{},
+		Config: &core.Config{Data: mockConfig},
+	}
+	_, err = service.Evaluate(noContext, args)
+	if err == nil {
+		t.Errorf("Want policy evaluation error")
+	}
+}
+
+func TestEvaluate_Steps(t *testing.T) {
+	compiled, err := parsePolicy("test.yml", []byte("deny: len([i for i in range(100000)]) == 0\nmessage: denied"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	service := &expressionPlugin{policies: []*policy{compiled}}
+	args := &core.PolicyArgs{
+		Repo:   &core.Repository{},
+		Build:  &core.Build{},
+		Config: &core.Config{Data: mockConfig},
+	}
+	_, err = service.Evaluate(noContext, args)
+	if got, want := err, sandbox.ErrSteps; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
+
+func TestEvaluate_Immutable(t *testing.T) {
+	compiled, err := parsePolicy("test.yml", []byte("deny: step.pop('name')\nmessage: denied"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	service := &expressionPlugin{policies: []*policy{compiled}}
+	args := &core.PolicyArgs{
+		Repo:   &core.Repository{},
+		Build:  &core.Build{},
+		Config: &core.Config{Data: mockConfig},
+	}
+	_, err = service.Evaluate(noContext, args)
+	if err == nil {
+		t.Errorf("Want error modifying a frozen step")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/drone/drone-go/plugin/registry"
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/logger"
+	"github.com/drone/drone/plugin/internal/transform"
 )
 
 // EndpointSource returns a registry credential provider
@@ -38,8 +39,8 @@ func (c *service) List(ctx context.Context, in *core.RegistryArgs) ([]*core.Regi
 	logger.Trace("registry: plugin: get credentials")
 
 	req := &registry.Request{
-		Repo:  toRepo(in.Repo),
-		Build: toBuild(in.Build),
+		Repo:  transform.ToRepo(in.Repo),
+		Build: transform.ToBuild(in.Build),
 	}
 	client := registry.Client(c.endpoint, c.secret, c.skipVerify)
 	res, err := client.List(ctx, req)
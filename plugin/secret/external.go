@@ -12,8 +12,8 @@ import (
 
 	"github.com/drone/drone-yaml/yaml"
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/plugin/internal/transform"
 
-	"github.com/drone/drone-go/drone"
 	"github.com/drone/drone-go/plugin/secret"
 )
 
@@ -56,8 +56,8 @@ func (c *externalController) Find(ctx context.Context, in *core.SecretArgs) (*co
 	req := &secret.Request{
 		Name:  name,
 		Path:  path,
-		Repo:  toRepo(in.Repo),
-		Build: toBuild(in.Build),
+		Repo:  transform.ToRepo(in.Repo),
+		Build: transform.ToBuild(in.Build),
 	}
 	client := secret.Client(c.endpoint, c.secret, c.skipVerify)
 	res, err := client.Find(ctx, req)
@@ -103,62 +103,3 @@ func getExternal(manifest *yaml.Manifest, match string) (path, name string, ok b
 	}
 	return
 }
-
-func toRepo(from *core.Repository) drone.Repo {
-	return drone.Repo{
-		ID:         from.ID,
-		UID:        from.UID,
-		UserID:     from.UserID,
-		Namespace:  from.Namespace,
-		Name:       from.Name,
-		Slug:       from.Slug,
-		SCM:        from.SCM,
-		HTTPURL:    from.HTTPURL,
-		SSHURL:     from.SSHURL,
-		Link:       from.Link,
-		Branch:     from.Branch,
-		Private:    from.Private,
-		Visibility: from.Visibility,
-		Active:     from.Active,
-		Config:     from.Config,
-		Trusted:    from.Trusted,
-		Protected:  from.Protected,
-		Timeout:    from.Timeout,
-	}
-}
-
-func toBuild(from *core.Build) drone.Build {
-	return drone.Build{
-		ID:           from.ID,
-		RepoID:       from.RepoID,
-		Trigger:      from.Trigger,
-		Number:       from.Number,
-		Parent:       from.Parent,
-		Status:       from.Status,
-		Error:        from.Error,
-		Event:        from.Event,
-		Action:       from.Action,
-		Link:         from.Link,
-		Timestamp:    from.Timestamp,
-		Title:        from.Title,
-		Message:      from.Message,
-		Before:       from.Before,
-		After:        from.After,
-		Ref:          from.Ref,
-		Fork:         from.Fork,
-		Source:       from.Source,
-		Target:       from.Target,
-		Author:       from.Author,
-		AuthorName:   from.AuthorName,
-		AuthorEmail:  from.AuthorEmail,
-		AuthorAvatar: from.AuthorAvatar,
-		Sender:       from.Sender,
-		Params:       from.Params,
-		Deploy:       from.Deploy,
-		Started:      from.Started,
-		Finished:     from.Finished,
-		Created:      from.Created,
-		Updated:      from.Updated,
-		Version:      from.Version,
-	}
-}
@@ -0,0 +1,79 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"context"
+	"strings"
+
+	"github.com/drone/drone/core"
+)
+
+// New returns a new ApprovalService. Approver groups are
+// resolved using the roles granted to the user.
+func New(approvals core.ApprovalStore, roles core.RoleService) core.ApprovalService {
+	return &service{
+		approvals: approvals,
+		roles:     roles,
+	}
+}
+
+type service struct {
+	approvals core.ApprovalStore
+	roles     core.RoleService
+}
+
+func (s *service) Rule(ctx context.Context, repo *core.Repository, build *core.Build) (*core.ApprovalRule, error) {
+	rules, err := s.approvals.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.Match(repo, build) {
+			return rule, nil
+		}
+	}
+	return core.DefaultApprovalRule(), nil
+}
+
+func (s *service) Authorize(ctx context.Context, user *core.User, repo *core.Repository, build *core.Build, rule *core.ApprovalRule) error {
+	// the build author, and the user that triggered the
+	// build (for example, a promotion), are considered the
+	// build author.
+	if !rule.AllowSelf {
+		if strings.EqualFold(user.Login, build.Author) ||
+			strings.EqualFold(user.Login, build.Sender) {
+			return core.ErrApprovalSelf
+		}
+	}
+	if len(rule.Groups) == 0 {
+		return nil
+	}
+	if s.roles == nil {
+		return core.ErrApprovalGroup
+	}
+	roles, err := s.roles.List(ctx, user, repo.Namespace)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		for _, group := range rule.Groups {
+			if role.Name == group {
+				return nil
+			}
+		}
+	}
+	return core.ErrApprovalGroup
+}
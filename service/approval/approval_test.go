@@ -0,0 +1,91 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package approval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+var noContext = context.Background()
+
+func TestRule(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	rules := []*core.ApprovalRule{
+		{ID: 1, Target: "production", Approvals: 2},
+		{ID: 2, Repo: "octocat/*", Approvals: 3},
+	}
+
+	approvals := mock.NewMockApprovalStore(controller)
+	approvals.EXPECT().ListRules(gomock.Any()).Return(rules, nil).Times(3)
+
+	service := New(approvals, nil)
+	repo := &core.Repository{Slug: "octocat/hello-world"}
+
+	rule, _ := service.Rule(noContext, repo, &core.Build{Deploy: "production"})
+	if got, want := rule.ID, int64(1); got != want {
+		t.Errorf("Want first matching rule %d, got %d", want, got)
+	}
+	rule, _ = service.Rule(noContext, repo, &core.Build{})
+	if got, want := rule.ID, int64(2); got != want {
+		t.Errorf("Want matching rule %d, got %d", want, got)
+	}
+	rule, _ = service.Rule(noContext, &core.Repository{Slug: "spaceghost/hello-world"}, &core.Build{})
+	if got, want := rule.Approvals, 1; got != want || !rule.AllowSelf {
+		t.Errorf("Want default rule")
+	}
+}
+
+func TestAuthorize_Self(t *testing.T) {
+	user := &core.User{Login: "octocat"}
+	repo := &core.Repository{Namespace: "octocat"}
+	rule := &core.ApprovalRule{Approvals: 2}
+
+	service := New(nil, nil)
+	for _, build := range []*core.Build{{Author: "octocat"}, {Sender: "Octocat"}} {
+		if got, want := service.Authorize(noContext, user, repo, build, rule), core.ErrApprovalSelf; got != want {
+			t.Errorf("Want error %v, got %v", want, got)
+		}
+	}
+	if err := service.Authorize(noContext, user, repo, &core.Build{Author: "spaceghost"}, rule); err != nil {
+		t.Errorf("Want other build authors approved, got %v", err)
+	}
+
+	rule.AllowSelf = true
+	if err := service.Authorize(noContext, user, repo, &core.Build{Author: "octocat"}, rule); err != nil {
+		t.Errorf("Want self approval permitted, got %v", err)
+	}
+}
+
+func TestAuthorize_Groups(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := &core.User{Login: "octocat"}
+	repo := &core.Repository{Namespace: "octocat"}
+	build := &core.Build{Author: "spaceghost"}
+
+	roles := mock.NewMockRoleService(controller)
+	roles.EXPECT().List(gomock.Any(), user, "octocat").Return([]*core.Role{{Name: "developers"}}, nil).Times(2)
+
+	service := New(nil, roles)
+
+	rule := &core.ApprovalRule{Approvals: 1, Groups: []string{"release-managers"}}
+	if got, want := service.Authorize(noContext, user, repo, build, rule), core.ErrApprovalGroup; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+
+	rule.Groups = append(rule.Groups, "developers")
+	if err := service.Authorize(noContext, user, repo, build, rule); err != nil {
+		t.Errorf("Want group member approved, got %v", err)
+	}
+}
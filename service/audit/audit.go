@@ -0,0 +1,62 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/logger"
+)
+
+// New returns a new AuditService that persists audit events
+// to the datastore, and optionally forwards the audit events
+// to the webhook sender.
+func New(store core.AuditStore, sender core.WebhookSender, forward bool) core.AuditService {
+	return &service{
+		store:   store,
+		sender:  sender,
+		forward: forward,
+	}
+}
+
+type service struct {
+	store   core.AuditStore
+	sender  core.WebhookSender
+	forward bool
+}
+
+func (s *service) Record(ctx context.Context, event *core.AuditEvent) error {
+	err := s.store.Create(ctx, event)
+	if err != nil {
+		return err
+	}
+	if !s.forward {
+		return nil
+	}
+	// the audit event is persisted before it is forwarded, and
+	// a failure to forward the event is therefore not fatal.
+	err = s.sender.Send(ctx, &core.WebhookData{
+		Event:  core.WebhookEventAudit,
+		Action: event.Action,
+		Audit:  event,
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).
+			WithField("action", event.Action).
+			Warnln("audit: cannot forward audit event")
+	}
+	return nil
+}
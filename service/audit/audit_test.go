@@ -0,0 +1,57 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+var noContext = context.Background()
+
+func TestRecord(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	event := &core.AuditEvent{Actor: "octocat", Action: core.AuditActionQueuePause}
+
+	store := mock.NewMockAuditStore(controller)
+	store.EXPECT().Create(gomock.Any(), event).Return(nil)
+
+	// the webhook sender is not invoked when forwarding
+	// is disabled.
+	sender := mock.NewMockWebhookSender(controller)
+
+	if err := New(store, sender, false).Record(noContext, event); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecord_Forward(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	event := &core.AuditEvent{Actor: "octocat", Action: core.AuditActionQueuePause}
+
+	store := mock.NewMockAuditStore(controller)
+	store.EXPECT().Create(gomock.Any(), event).Return(nil)
+
+	sender := mock.NewMockWebhookSender(controller)
+	sender.EXPECT().Send(gomock.Any(), &core.WebhookData{
+		Event:  core.WebhookEventAudit,
+		Action: core.AuditActionQueuePause,
+		Audit:  event,
+	}).Return(errors.New("connection refused"))
+
+	if err := New(store, sender, true).Record(noContext, event); err != nil {
+		t.Errorf("Want webhook errors ignored, got %s", err)
+	}
+}
@@ -1,16 +1,29 @@
-// Copyright 2019 Drone.IO Inc. All rights reserved.
-// Use of this source code is governed by the Drone Non-Commercial License
-// that can be found in the LICENSE file.
-
-// +build !oss
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
 
 package cache
 
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/go-scm/scm"
 
 	"github.com/hashicorp/golang-lru"
 )
@@ -19,34 +32,167 @@ import (
 // repository slug, commit and path.
 const contentKey = "%s/%s/%s"
 
+// listing key pattern used in the cache, comprised of the
+// repository slug, commit and directory.
+const listKey = "list:%s/%s/%s"
+
+// reference key prefix used in the cache for contents
+// requested by branch or tag name, comprised of the
+// repository slug. The prefix allows all contents requested
+// by reference to be invalidated when the repository
+// receives a push.
+const refKey = "ref:%s:"
+
+// shaRE matches a commit sha. The contents of a commit never
+// change, and are cached until evicted. Contents requested by
+// branch or tag name, such as shared templates, are cached
+// until the repository receives a push, or until the ref ttl
+// elapses.
+var shaRE = regexp.MustCompile("^[a-fA-F0-9]{40}$|^[a-fA-F0-9]{64}$")
+
 // Contents returns a new FileService that is wrapped
-// with an in-memory cache.
-func Contents(base core.FileService) core.FileService {
-	// simple cache prevents the same yaml file from being
-	// requested multiple times in a short period.
-	cache, _ := lru.New(25)
+// with an in-memory cache. The cache stores up to size
+// entries. Files that are not found are cached for the
+// duration of the ttl, which accounts for the delay
+// between a push and the commit becoming visible in the
+// source control management api. Files requested by branch
+// or tag name are cached for the duration of the refTTL, in
+// case a push webhook is never received. A zero refTTL
+// caches these files until invalidated.
+func Contents(base core.FileService, size int, ttl, refTTL time.Duration) core.FileService {
+	if size <= 0 {
+		return base
+	}
+	cache, _ := lru.New(size)
 	return &service{
 		service: base,
 		cache:   cache,
+		ttl:     ttl,
+		refTTL:  refTTL,
 	}
 }
 
 type service struct {
 	cache   *lru.Cache
 	service core.FileService
-	user    *core.User
+	ttl     time.Duration
+	refTTL  time.Duration
+
+	hits   int64
+	misses int64
+}
+
+// item is a cached result. A zero expiry indicates the
+// result does not expire.
+type item struct {
+	file     *core.File
+	paths    []string
+	notFound bool
+	expires  time.Time
 }
 
 func (s *service) Find(ctx context.Context, user *core.User, repo, commit, ref, path string) (*core.File, error) {
-	key := fmt.Sprintf(contentKey, repo, commit, path)
-	cached, ok := s.cache.Get(key)
-	if ok {
-		return cached.(*core.File), nil
+	key := s.key(contentKey, repo, commit, path)
+	if cached, ok := s.get(key); ok {
+		if cached.notFound {
+			return nil, scm.ErrNotFound
+		}
+		return cached.file, nil
 	}
 	file, err := s.service.Find(ctx, user, repo, commit, ref, path)
+	if err == scm.ErrNotFound {
+		s.negative(key)
+	}
 	if err != nil {
 		return nil, err
 	}
-	s.cache.Add(key, file)
+	s.add(key, commit, &item{file: file})
 	return file, nil
 }
+
+func (s *service) List(ctx context.Context, user *core.User, repo, commit, ref, dir string) ([]string, error) {
+	key := s.key(listKey, repo, commit, dir)
+	if cached, ok := s.get(key); ok {
+		if cached.notFound {
+			return nil, scm.ErrNotFound
+		}
+		return cached.paths, nil
+	}
+	paths, err := s.service.List(ctx, user, repo, commit, ref, dir)
+	if err == scm.ErrNotFound {
+		s.negative(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.add(key, commit, &item{paths: paths})
+	return paths, nil
+}
+
+// Invalidate removes the contents requested by branch or tag
+// name for the named repository from the cache. It should be
+// called when the repository receives a push.
+func (s *service) Invalidate(repo string) {
+	prefix := fmt.Sprintf(refKey, strings.ToLower(repo))
+	for _, key := range s.cache.Keys() {
+		if strings.HasPrefix(key.(string), prefix) {
+			s.cache.Remove(key)
+		}
+	}
+}
+
+// Hits returns the number of cache hits.
+func (s *service) Hits() int64 {
+	return atomic.LoadInt64(&s.hits)
+}
+
+// Misses returns the number of cache misses.
+func (s *service) Misses() int64 {
+	return atomic.LoadInt64(&s.misses)
+}
+
+// helper function returns the cached item, removing the
+// item from the cache if expired.
+func (s *service) get(key string) (*item, bool) {
+	cached, ok := s.cache.Get(key)
+	if ok {
+		item := cached.(*item)
+		if item.expires.IsZero() || time.Now().Before(item.expires) {
+			atomic.AddInt64(&s.hits, 1)
+			return item, true
+		}
+		s.cache.Remove(key)
+	}
+	atomic.AddInt64(&s.misses, 1)
+	return nil, false
+}
+
+// helper function returns the cache key. Contents requested
+// by branch or tag name are prefixed with the repository slug
+// so they can be invalidated.
+func (s *service) key(pattern, repo, commit, path string) string {
+	key := fmt.Sprintf(pattern, repo, commit, path)
+	if shaRE.MatchString(commit) {
+		return key
+	}
+	return fmt.Sprintf(refKey, strings.ToLower(repo)) + key
+}
+
+// helper function caches the result. Results requested by
+// branch or tag name expire after the ref ttl.
+func (s *service) add(key, commit string, item *item) {
+	if !shaRE.MatchString(commit) && s.refTTL > 0 {
+		item.expires = time.Now().Add(s.refTTL)
+	}
+	s.cache.Add(key, item)
+}
+
+// helper function caches a negative result.
+func (s *service) negative(key string) {
+	if s.ttl > 0 {
+		s.cache.Add(key, &item{
+			notFound: true,
+			expires:  time.Now().Add(s.ttl),
+		})
+	}
+}
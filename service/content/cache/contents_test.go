@@ -2,14 +2,14 @@
 // Use of this source code is governed by the Drone Non-Commercial License
 // that can be found in the LICENSE file.
 
-// +build !oss
-
 package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/mock"
@@ -34,7 +34,7 @@ func TestFind(t *testing.T) {
 	mockContents := mock.NewMockFileService(controller)
 	mockContents.EXPECT().Find(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone.yml").Return(mockFile, nil)
 
-	service := Contents(mockContents).(*service)
+	service := Contents(mockContents, 25, time.Minute, time.Minute).(*service)
 
 	want := &core.File{
 		Data: []byte("hello world"),
@@ -63,7 +63,7 @@ func TestFindError(t *testing.T) {
 	mockContents := mock.NewMockFileService(controller)
 	mockContents.EXPECT().Find(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone.yml").Return(nil, scm.ErrNotFound)
 
-	service := Contents(mockContents).(*service)
+	service := Contents(mockContents, 25, time.Minute, time.Minute).(*service)
 
 	_, err := service.Find(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone.yml")
 	if err != scm.ErrNotFound {
@@ -82,8 +82,8 @@ func TestFindCache(t *testing.T) {
 	}
 
 	key := fmt.Sprintf(contentKey, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", ".drone.yml")
-	service := Contents(nil).(*service)
-	service.cache.Add(key, mockFile)
+	service := Contents(nil, 25, time.Minute, time.Minute).(*service)
+	service.cache.Add(key, &item{file: mockFile})
 
 	want := &core.File{
 		Data: []byte("hello world"),
@@ -98,3 +98,201 @@ func TestFindCache(t *testing.T) {
 		t.Errorf(diff)
 	}
 }
+
+func TestList(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockPaths := []string{".drone/a.yml", ".drone/b.yml"}
+
+	mockContents := mock.NewMockFileService(controller)
+	mockContents.EXPECT().List(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone").Return(mockPaths, nil).Times(1)
+
+	service := Contents(mockContents, 25, time.Minute, time.Minute).(*service)
+
+	for i := 0; i < 2; i++ {
+		got, err := service.List(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone")
+		if err != nil {
+			t.Error(err)
+		}
+		if diff := cmp.Diff(got, mockPaths); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
+
+func TestListError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockErr := errors.New("Internal Server Error")
+
+	mockContents := mock.NewMockFileService(controller)
+	mockContents.EXPECT().List(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone").Return(nil, mockErr)
+
+	service := Contents(mockContents, 25, time.Minute, time.Minute).(*service)
+
+	_, err := service.List(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone")
+	if err != mockErr {
+		t.Errorf("Expect error returned from file service")
+	}
+	if len(service.cache.Keys()) != 0 {
+		t.Errorf("Expect error not added to cache")
+	}
+}
+
+func TestFindNotFoundCache(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+
+	mockContents := mock.NewMockFileService(controller)
+	mockContents.EXPECT().Find(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone.yml").Return(nil, scm.ErrNotFound).Times(1)
+
+	service := Contents(mockContents, 25, time.Minute, time.Minute).(*service)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.Find(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone.yml")
+		if err != scm.ErrNotFound {
+			t.Errorf("Expect not found error")
+		}
+	}
+	if got, want := service.Hits(), int64(1); got != want {
+		t.Errorf("Want %d cache hits, got %d", want, got)
+	}
+	if got, want := service.Misses(), int64(1); got != want {
+		t.Errorf("Want %d cache misses, got %d", want, got)
+	}
+}
+
+func TestFindNotFoundExpired(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockFile := &core.File{
+		Data: []byte("hello world"),
+		Hash: []byte(""),
+	}
+
+	mockContents := mock.NewMockFileService(controller)
+	mockContents.EXPECT().Find(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone.yml").Return(mockFile, nil)
+
+	key := fmt.Sprintf(contentKey, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", ".drone.yml")
+	service := Contents(mockContents, 25, time.Minute, time.Minute).(*service)
+	service.cache.Add(key, &item{notFound: true, expires: time.Now().Add(-time.Second)})
+
+	got, err := service.Find(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone.yml")
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := cmp.Diff(got, mockFile); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestFindRef(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockFile := &core.File{
+		Data: []byte("hello world"),
+		Hash: []byte(""),
+	}
+
+	mockContents := mock.NewMockFileService(controller)
+	mockContents.EXPECT().Find(noContext, mockUser, "octocat/templates", "refs/heads/master", "refs/heads/master", "go.yml").Return(mockFile, nil).Times(1)
+
+	service := Contents(mockContents, 25, time.Minute, time.Minute).(*service)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.Find(noContext, mockUser, "octocat/templates", "refs/heads/master", "refs/heads/master", "go.yml")
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if got, want := service.Hits(), int64(1); got != want {
+		t.Errorf("Want %d cache hits, got %d", want, got)
+	}
+}
+
+func TestFindRefExpired(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockFile := &core.File{
+		Data: []byte("hello world"),
+		Hash: []byte(""),
+	}
+
+	mockContents := mock.NewMockFileService(controller)
+	mockContents.EXPECT().Find(noContext, mockUser, "octocat/templates", "refs/heads/master", "refs/heads/master", "go.yml").Return(mockFile, nil).Times(2)
+
+	service := Contents(mockContents, 25, time.Minute, time.Minute).(*service)
+
+	_, err := service.Find(noContext, mockUser, "octocat/templates", "refs/heads/master", "refs/heads/master", "go.yml")
+	if err != nil {
+		t.Error(err)
+	}
+	for _, key := range service.cache.Keys() {
+		cached, _ := service.cache.Peek(key)
+		cached.(*item).expires = time.Now().Add(-time.Second)
+	}
+	_, err = service.Find(noContext, mockUser, "octocat/templates", "refs/heads/master", "refs/heads/master", "go.yml")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockFile := &core.File{
+		Data: []byte("hello world"),
+		Hash: []byte(""),
+	}
+
+	mockContents := mock.NewMockFileService(controller)
+	mockContents.EXPECT().Find(noContext, mockUser, "octocat/templates", "refs/heads/master", "refs/heads/master", "go.yml").Return(mockFile, nil).Times(2)
+	mockContents.EXPECT().Find(noContext, mockUser, "octocat/templates", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "refs/heads/master", "go.yml").Return(mockFile, nil).Times(1)
+	mockContents.EXPECT().Find(noContext, mockUser, "octocat/hello-world", "refs/heads/master", "refs/heads/master", ".drone.yml").Return(mockFile, nil).Times(1)
+
+	service := Contents(mockContents, 25, time.Minute, 0).(*service)
+
+	find := func() {
+		for _, args := range [][]string{
+			{"octocat/templates", "refs/heads/master", "go.yml"},
+			{"octocat/templates", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "go.yml"},
+			{"octocat/hello-world", "refs/heads/master", ".drone.yml"},
+		} {
+			_, err := service.Find(noContext, mockUser, args[0], args[1], "refs/heads/master", args[2])
+			if err != nil {
+				t.Error(err)
+			}
+		}
+	}
+
+	find()
+	service.Invalidate("Octocat/Templates")
+	if got, want := len(service.cache.Keys()), 2; got != want {
+		t.Errorf("Want %d cached items after invalidation, got %d", want, got)
+	}
+	find()
+}
+
+func TestContentsDisabled(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockContents := mock.NewMockFileService(controller)
+	if Contents(mockContents, 0, time.Minute, time.Minute) != mockContents {
+		t.Errorf("Expect cache disabled when size is zero")
+	}
+}
@@ -86,11 +86,17 @@ func (s *service) Find(ctx context.Context, user *core.User, repo, commit, ref,
 // consistency issues with the github datastore.
 func (s *service) findRetry(ctx context.Context, repo, path, commit string) (content *scm.Content, err error) {
 	for i := 0; i < s.attempts; i++ {
-		content, _, err = s.client.Contents.Find(ctx, repo, path, commit)
+		var res *scm.Response
+		content, res, err = s.client.Contents.Find(ctx, repo, path, commit)
 		// if no error is returned we can exit immediately.
 		if err == nil {
 			return
 		}
+		// the not found error is normalized so that it can
+		// be identified, and cached, by the caller.
+		if res != nil && res.Status == 404 {
+			err = scm.ErrNotFound
+		}
 		// wait a few seconds before retry. according to github
 		// support 30 seconds total should be enough time. we
 		// try 3 x 15 seconds, giving a total of 45 seconds.
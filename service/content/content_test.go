@@ -6,15 +6,22 @@ package contents
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/mock"
 	"github.com/drone/drone/mock/mockscm"
 	"github.com/drone/go-scm/scm"
+	"github.com/drone/go-scm/scm/driver/bitbucket"
+	"github.com/drone/go-scm/scm/driver/github"
+	"github.com/drone/go-scm/scm/driver/gitlab"
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/golang/mock/gomock"
+	"github.com/h2non/gock"
 )
 
 var noContext = context.Background()
@@ -77,6 +84,31 @@ func TestFind_Error(t *testing.T) {
 	}
 }
 
+func TestFind_NotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockResp := &scm.Response{Status: 404}
+
+	mockContents := mockscm.NewMockContentService(controller)
+	mockContents.EXPECT().Find(gomock.Any(), "octocat/hello-world", ".drone.yml", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa").Return(nil, mockResp, errors.New("Not Found"))
+
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	client := new(scm.Client)
+	client.Contents = mockContents
+
+	s := New(client, mockRenewer)
+	s.(*service).attempts = 1
+	s.(*service).wait = 0
+	_, err := s.Find(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone.yml")
+	if err != scm.ErrNotFound {
+		t.Errorf("Expect not found error, got %s", err)
+	}
+}
+
 func TestFind_RenewalError(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -94,3 +126,236 @@ func TestFind_RenewalError(t *testing.T) {
 		t.Errorf("Expect error refreshing token")
 	}
 }
+
+func TestList_GitHub(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/octocat/hello-world/contents/.drone").
+		MatchParam("ref", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa").
+		Reply(200).
+		BodyString(`[
+			{"type": "file", "path": ".drone/a.yml"},
+			{"type": "dir", "path": ".drone/lib"},
+			{"type": "file", "path": ".drone/b.yml"}
+		]`)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	s := New(github.NewDefault(), mockRenewer)
+	s.(*service).attempts = 1
+	s.(*service).wait = 0
+	got, err := s.List(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone/")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := []string{".drone/a.yml", ".drone/b.yml"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+	if gock.IsPending() {
+		t.Errorf("Unfinished requests")
+	}
+}
+
+func TestList_GitLab(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/diaspora/diaspora/repository/tree").
+		MatchParam("path", ".drone").
+		MatchParam("ref", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa").
+		Reply(200).
+		BodyString(`[
+			{"type": "blob", "path": ".drone/a.yml"},
+			{"type": "tree", "path": ".drone/lib"}
+		]`)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	s := New(gitlab.NewDefault(), mockRenewer)
+	s.(*service).attempts = 1
+	s.(*service).wait = 0
+	got, err := s.List(noContext, mockUser, "diaspora/diaspora", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := []string{".drone/a.yml"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// helper function returns a json encoded page of gitlab
+// tree entries.
+func gitlabPage(size int) string {
+	var entries []string
+	for i := 0; i < size; i++ {
+		entries = append(entries, fmt.Sprintf(`{"type": "blob", "path": ".drone/%d.yml"}`, i))
+	}
+	return "[" + strings.Join(entries, ",") + "]"
+}
+
+func TestList_GitLabPages(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/diaspora/diaspora/repository/tree").
+		MatchParam("page", "1").
+		Reply(200).
+		BodyString(gitlabPage(listLimit))
+
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/diaspora/diaspora/repository/tree").
+		MatchParam("page", "2").
+		Reply(200).
+		BodyString(`[{"type": "blob", "path": ".drone/last.yml"}]`)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	s := New(gitlab.NewDefault(), mockRenewer)
+	s.(*service).attempts = 1
+	s.(*service).wait = 0
+	got, err := s.List(noContext, mockUser, "diaspora/diaspora", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(got), listLimit+1; got != want {
+		t.Errorf("Want %d paths, got %d", want, got)
+	}
+	if got, want := got[len(got)-1], ".drone/last.yml"; got != want {
+		t.Errorf("Want last path %q, got %q", want, got)
+	}
+	if gock.IsPending() {
+		t.Errorf("Unfinished requests")
+	}
+}
+
+func TestList_GitLabTooLarge(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/diaspora/diaspora/repository/tree").
+		Times(listPages).
+		Reply(200).
+		BodyString(gitlabPage(listLimit))
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	s := New(gitlab.NewDefault(), mockRenewer)
+	s.(*service).attempts = 2
+	s.(*service).wait = 0
+	_, err := s.List(noContext, mockUser, "diaspora/diaspora", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone")
+	if err != errListTooLarge {
+		t.Errorf("Expect too many entries error, got %v", err)
+	}
+	if gock.IsPending() {
+		t.Errorf("Unfinished requests")
+	}
+}
+
+func TestList_BitbucketPages(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/atlassian/stash-example-plugin/src/a6586b3db244fb6b1198f2b25c213ded5b44f9fa/.drone/").
+		MatchParam("page", "AbC2").
+		Reply(200).
+		BodyString(`{"values": [{"type": "commit_file", "path": ".drone/b.yml"}]}`)
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/atlassian/stash-example-plugin/src/a6586b3db244fb6b1198f2b25c213ded5b44f9fa/.drone/").
+		Reply(200).
+		BodyString(`{
+			"values": [
+				{"type": "commit_file", "path": ".drone/a.yml"},
+				{"type": "commit_directory", "path": ".drone/lib"}
+			],
+			"next": "https://evil.example.com/2.0/repositories/atlassian/stash-example-plugin/src/a6586b3db244fb6b1198f2b25c213ded5b44f9fa/.drone/?page=AbC2"
+		}`)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	s := New(bitbucket.NewDefault(), mockRenewer)
+	s.(*service).attempts = 1
+	s.(*service).wait = 0
+	got, err := s.List(noContext, mockUser, "atlassian/stash-example-plugin", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := []string{".drone/a.yml", ".drone/b.yml"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+	if gock.IsPending() {
+		t.Errorf("Unfinished requests")
+	}
+}
+
+func TestList_NotFound(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/octocat/hello-world/contents/.drone").
+		Reply(404)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	s := New(github.NewDefault(), mockRenewer)
+	s.(*service).attempts = 1
+	s.(*service).wait = 0
+	_, err := s.List(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone")
+	if err != scm.ErrNotFound {
+		t.Errorf("Expect not found error, got %v", err)
+	}
+}
+
+func TestList_NotSupported(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	client := new(scm.Client)
+	client.Driver = scm.DriverCoding
+	_, err := New(client, mockRenewer).List(noContext, mockUser, "octocat/hello-world", "a6586b3db244fb6b1198f2b25c213ded5b44f9fa", "master", ".drone")
+	if err != errListNotSupported {
+		t.Errorf("Expect not supported error, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/go-scm/scm"
+)
+
+var (
+	// errListNotSupported is returned when the source code
+	// management system does not support listing directories.
+	errListNotSupported = errors.New("Cannot list directory contents for this provider")
+
+	// errListTooLarge is returned when the directory
+	// contains more entries than can be listed.
+	errListTooLarge = errors.New("Cannot list directory contents: too many entries")
+)
+
+const (
+	// maximum number of entries requested per page.
+	listLimit = 100
+
+	// maximum number of pages requested per directory.
+	listPages = 10
+)
+
+func (s *service) List(ctx context.Context, user *core.User, repo, commit, ref, dir string) ([]string, error) {
+	// TODO(gogs) see the workaround in Find.
+	if s.client.Driver == scm.DriverGogs &&
+		strings.HasPrefix(ref, "refs/pull") {
+		commit = "master"
+	}
+	if s.client.Driver == scm.DriverGogs &&
+		strings.HasPrefix(ref, "refs/tag") {
+		commit = ref
+	}
+	dir = strings.Trim(path.Clean(dir), "/")
+	if dir == "." {
+		dir = ""
+	}
+	err := s.renewer.Renew(ctx, user, false)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, scm.TokenKey{}, &scm.Token{
+		Token:   user.Token,
+		Refresh: user.Refresh,
+	})
+	return s.listRetry(ctx, repo, dir, commit)
+}
+
+// helper function attempts to list the directory contents
+// with backoff on failure.
+func (s *service) listRetry(ctx context.Context, repo, dir, commit string) (paths []string, err error) {
+	for i := 0; i < s.attempts; i++ {
+		paths, err = s.list(ctx, repo, dir, commit)
+		if err == nil || err == errListNotSupported || err == errListTooLarge {
+			return
+		}
+		time.Sleep(s.wait)
+	}
+	return
+}
+
+// helper function lists the files in the directory. The
+// go-scm client does not expose a directory listing, so the
+// provider endpoints are requested directly.
+func (s *service) list(ctx context.Context, repo, dir, commit string) ([]string, error) {
+	switch s.client.Driver {
+	case scm.DriverGithub, scm.DriverGitea, scm.DriverGogs:
+		uri := fmt.Sprintf("repos/%s/contents/%s?ref=%s", repo, dir, url.QueryEscape(commit))
+		if s.client.Driver != scm.DriverGithub {
+			uri = "api/v1/" + uri
+		}
+		out := []*entry{}
+		if err := s.do(ctx, uri, &out); err != nil {
+			return nil, err
+		}
+		return files(out, "file"), nil
+	case scm.DriverGitlab:
+		return s.listGitlab(ctx, repo, dir, commit)
+	case scm.DriverBitbucket:
+		return s.listBitbucket(ctx, repo, dir, commit)
+	case scm.DriverStash:
+		return s.listStash(ctx, repo, dir, commit)
+	default:
+		return nil, errListNotSupported
+	}
+}
+
+// helper function lists the files in the gitlab directory,
+// requesting each page until a partial page is returned.
+func (s *service) listGitlab(ctx context.Context, repo, dir, commit string) ([]string, error) {
+	var paths []string
+	for page := 1; page <= listPages; page++ {
+		params := url.Values{}
+		params.Set("path", dir)
+		params.Set("ref", commit)
+		params.Set("page", fmt.Sprint(page))
+		params.Set("per_page", fmt.Sprint(listLimit))
+		uri := fmt.Sprintf("api/v4/projects/%s/repository/tree?%s",
+			strings.Replace(url.PathEscape(repo), "/", "%2F", -1), params.Encode())
+		out := []*entry{}
+		if err := s.do(ctx, uri, &out); err != nil {
+			return nil, err
+		}
+		paths = append(paths, files(out, "blob")...)
+		if len(out) < listLimit {
+			return paths, nil
+		}
+	}
+	return nil, errListTooLarge
+}
+
+// helper function lists the files in the bitbucket directory,
+// requesting each page until the next page link is empty.
+func (s *service) listBitbucket(ctx context.Context, repo, dir, commit string) ([]string, error) {
+	var paths []string
+	var page string
+	for i := 0; i < listPages; i++ {
+		params := url.Values{}
+		params.Set("pagelen", fmt.Sprint(listLimit))
+		if page != "" {
+			params.Set("page", page)
+		}
+		uri := fmt.Sprintf("/2.0/repositories/%s/src/%s/?%s", repo, path.Join(commit, dir), params.Encode())
+		out := struct {
+			Values []*entry `json:"values"`
+			Next   string   `json:"next"`
+		}{}
+		if err := s.do(ctx, uri, &out); err != nil {
+			return nil, err
+		}
+		paths = append(paths, files(out.Values, "commit_file")...)
+		if out.Next == "" {
+			return paths, nil
+		}
+		// only the page token is read from the next page
+		// link, so that requests are always sent to the
+		// configured server.
+		next, err := url.Parse(out.Next)
+		if err != nil {
+			return nil, err
+		}
+		page = next.Query().Get("page")
+		if page == "" {
+			return paths, nil
+		}
+	}
+	return nil, errListTooLarge
+}
+
+// helper function lists the files in the stash directory,
+// requesting each page until the last page is returned.
+func (s *service) listStash(ctx context.Context, repo, dir, commit string) ([]string, error) {
+	namespace, name := scm.Split(repo)
+	var paths []string
+	var start int
+	for i := 0; i < listPages; i++ {
+		uri := fmt.Sprintf("rest/api/1.0/projects/%s/repos/%s/files/%s?at=%s&limit=%d&start=%d",
+			namespace, name, dir, url.QueryEscape(commit), listLimit, start)
+		out := struct {
+			Values     []string `json:"values"`
+			IsLastPage bool     `json:"isLastPage"`
+			NextStart  int      `json:"nextPageStart"`
+		}{}
+		if err := s.do(ctx, uri, &out); err != nil {
+			return nil, err
+		}
+		// the stash api returns paths relative to the
+		// requested directory.
+		for _, name := range out.Values {
+			paths = append(paths, path.Join(dir, name))
+		}
+		if out.IsLastPage || len(out.Values) == 0 {
+			return paths, nil
+		}
+		start = out.NextStart
+	}
+	return nil, errListTooLarge
+}
+
+// helper function sends a GET request to the provider and
+// unmarshals the json response body.
+func (s *service) do(ctx context.Context, uri string, out interface{}) error {
+	res, err := s.client.Do(ctx, &scm.Request{
+		Method: "GET",
+		Path:   uri,
+	})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.Status == 404 {
+		return scm.ErrNotFound
+	}
+	if res.Status > 299 {
+		return fmt.Errorf("Cannot list directory contents: status code %d", res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// entry represents a directory entry returned by the
+// provider api.
+type entry struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// helper function returns the paths of the entries that
+// match the file type.
+func files(entries []*entry, kind string) []string {
+	var paths []string
+	for _, entry := range entries {
+		if entry.Type == kind {
+			paths = append(paths, entry.Path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,304 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deprovision provides a reconciler that deactivates
+// user accounts that are revoked in the source control management
+// system, or that are no longer members of an approved
+// organization.
+package deprovision
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/plugin/admission"
+	"github.com/drone/go-scm/scm"
+
+	"github.com/dchest/uniuri"
+	"github.com/hashicorp/go-multierror"
+	"github.com/sirupsen/logrus"
+)
+
+// actor is the audit event actor for changes made by the
+// reconciler.
+const actor = "drone"
+
+// New returns a new Reconciler. If owner is not empty, the
+// repositories owned by deactivated accounts are reassigned to
+// the named user. Otherwise the repositories are flagged in the
+// audit log.
+func New(
+	users core.UserStore,
+	userz core.UserService,
+	renewer core.Renewer,
+	membership core.AdmissionService,
+	repos core.RepositoryStore,
+	sessions core.SessionStore,
+	tokens core.TokenStore,
+	audits core.AuditService,
+	owner string,
+) *Reconciler {
+	return &Reconciler{
+		users:      users,
+		userz:      userz,
+		renewer:    renewer,
+		membership: membership,
+		repos:      repos,
+		sessions:   sessions,
+		tokens:     tokens,
+		audits:     audits,
+		owner:      owner,
+	}
+}
+
+// Reconciler periodically verifies user accounts with the
+// source control management system and deactivates accounts
+// that fail verification.
+type Reconciler struct {
+	users      core.UserStore
+	userz      core.UserService
+	renewer    core.Renewer
+	membership core.AdmissionService
+	repos      core.RepositoryStore
+	sessions   core.SessionStore
+	tokens     core.TokenStore
+	audits     core.AuditService
+	owner      string
+}
+
+// Start starts the reconciler.
+func (r *Reconciler) Start(ctx context.Context, dur time.Duration) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(dur):
+			r.run(ctx)
+		}
+	}
+}
+
+func (r *Reconciler) run(ctx context.Context) error {
+	var result error
+
+	logrus.Debugln("deprovision: begin reconciling user accounts")
+
+	defer func() {
+		if err := recover(); err != nil {
+			logger := logrus.WithField("error", err)
+			logger.Errorln("deprovision: unexpected panic")
+		}
+	}()
+
+	users, err := r.users.List(ctx)
+	if err != nil {
+		logger := logrus.WithError(err)
+		logger.Error("deprovision: cannot list users")
+		return err
+	}
+
+	for _, user := range users {
+		// machine accounts and service accounts do not have
+		// a source control account, and accounts that have
+		// not linked a source control account cannot be
+		// verified.
+		if !user.Active || user.Machine || user.Namespace != "" || user.Token == "" {
+			continue
+		}
+
+		logger := logrus.WithField("login", user.Login)
+
+		reason, err := r.verify(ctx, user)
+		if err != nil {
+			// errors are not conclusive, for example the
+			// source control management system may be
+			// unavailable, and the account is not modified.
+			logger.WithError(err).
+				Warnln("deprovision: cannot verify user account")
+			result = multierror.Append(result, err)
+			continue
+		}
+		if reason == "" {
+			continue
+		}
+
+		logger = logger.WithField("reason", reason)
+		err = r.deactivate(ctx, user, reason)
+		if err != nil {
+			logger.WithError(err).
+				Errorln("deprovision: cannot deactivate user account")
+			result = multierror.Append(result, err)
+			continue
+		}
+		logger.Infoln("deprovision: deactivated user account")
+	}
+
+	logrus.Debugln("deprovision: finished reconciling user accounts")
+	return result
+}
+
+// verify returns the reason the account should be deactivated,
+// or an empty string if the account is verified. An error is
+// returned if the account cannot be verified.
+func (r *Reconciler) verify(ctx context.Context, user *core.User) (string, error) {
+	err := r.renewer.Renew(ctx, user, false)
+	if err == scm.ErrNotAuthorized {
+		return "Source control authorization is revoked", nil
+	} else if err != nil {
+		return "", err
+	}
+	_, err = r.userz.Find(ctx, user.Token, user.Refresh)
+	if err == scm.ErrNotAuthorized {
+		return "Source control authorization is revoked", nil
+	} else if err != nil {
+		return "", err
+	}
+	if r.membership == nil {
+		return "", nil
+	}
+	err = r.membership.Admit(ctx, user)
+	if err == admission.ErrMembership {
+		return err.Error(), nil
+	}
+	return "", err
+}
+
+// deactivate deactivates the user account, revokes the user
+// sessions and tokens, and reassigns or flags the repositories
+// owned by the user.
+func (r *Reconciler) deactivate(ctx context.Context, user *core.User, reason string) error {
+	user.Active = false
+	user.Token = ""
+	user.Refresh = ""
+	user.Expiry = 0
+	// the user hash is rotated to revoke the legacy
+	// user token, which is derived from the hash.
+	user.Hash = uniuri.NewLen(32)
+	user.Updated = time.Now().Unix()
+	err := r.users.Update(ctx, user)
+	if err != nil {
+		return err
+	}
+	r.record(ctx, core.AuditActionUserDisable, user.Login, nil, map[string]string{"reason": reason})
+
+	var result error
+	if r.sessions != nil {
+		err = r.sessions.DeleteUser(ctx, user.ID)
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	if r.tokens != nil {
+		err = r.revokeTokens(ctx, user)
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	err = r.reassign(ctx, user)
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+	return result
+}
+
+func (r *Reconciler) revokeTokens(ctx context.Context, user *core.User) error {
+	tokens, err := r.tokens.List(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	var result error
+	for _, token := range tokens {
+		err := r.tokens.Delete(ctx, token)
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result
+}
+
+// reassign reassigns the repositories owned by the user to the
+// configured owner, consistent with the repository chown
+// endpoint. If no owner is configured, or the owner is not
+// active, the repositories are flagged in the audit log.
+func (r *Reconciler) reassign(ctx context.Context, user *core.User) error {
+	// the repository owner is always granted access to the
+	// repository, and the owned repositories are therefore
+	// a subset of the repositories the user can access.
+	repos, err := r.repos.List(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	var owner *core.User
+	if r.owner != "" {
+		owner, err = r.users.FindLogin(ctx, r.owner)
+		if err != nil || !owner.Active {
+			logrus.WithError(err).
+				WithField("owner", r.owner).
+				Warnln("deprovision: cannot find repository owner")
+			owner = nil
+		}
+	}
+
+	var result error
+	for _, repo := range repos {
+		if repo.UserID != user.ID {
+			continue
+		}
+		if owner == nil {
+			logrus.WithField("repo", repo.Slug).
+				WithField("login", user.Login).
+				Warnln("deprovision: repository owner is deactivated")
+			r.record(ctx, core.AuditActionRepoOrphan, repo.Slug, nil, map[string]string{"owner": user.Login})
+			continue
+		}
+		repo.UserID = owner.ID
+		err := r.repos.Update(ctx, repo)
+		if err != nil {
+			result = multierror.Append(result, err)
+			continue
+		}
+		r.record(ctx, core.AuditActionRepoChown, repo.Slug,
+			map[string]string{"owner": user.Login},
+			map[string]string{"owner": owner.Login},
+		)
+	}
+	return result
+}
+
+func (r *Reconciler) record(ctx context.Context, action, target string, before, after interface{}) {
+	if r.audits == nil {
+		return
+	}
+	event := &core.AuditEvent{
+		Actor:   actor,
+		Action:  action,
+		Target:  target,
+		Created: time.Now().Unix(),
+	}
+	if before != nil {
+		event.Before, _ = json.Marshal(before)
+	}
+	if after != nil {
+		event.After, _ = json.Marshal(after)
+	}
+	err := r.audits.Record(ctx, event)
+	if err != nil {
+		logrus.WithError(err).
+			WithField("action", action).
+			Warnln("deprovision: cannot record audit event")
+	}
+}
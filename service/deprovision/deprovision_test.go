@@ -0,0 +1,192 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package deprovision
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/drone/plugin/admission"
+	"github.com/drone/go-scm/scm"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+)
+
+var noContext = context.Background()
+
+func init() {
+	logrus.SetOutput(ioutil.Discard)
+}
+
+func TestReconcile_Revoked(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Login: "octocat", Active: true, Token: "755bb80e5b", Hash: "MjAxOC0wOC0xMVQxNTo1OD"}
+	mockOwner := &core.User{ID: 2, Login: "drone-bot", Active: true, Machine: true}
+	mockRepos := []*core.Repository{
+		{ID: 1, UserID: 1, Slug: "octocat/hello-world"},
+		{ID: 2, UserID: 3, Slug: "octocat/spoon-knife"},
+	}
+	mockTokens := []*core.Token{{ID: 1, UserID: 1}}
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().List(gomock.Any()).Return([]*core.User{mockUser, mockOwner}, nil)
+	users.EXPECT().Update(gomock.Any(), mockUser).Return(nil)
+	users.EXPECT().FindLogin(gomock.Any(), "drone-bot").Return(mockOwner, nil)
+
+	renewer := mock.NewMockRenewer(controller)
+	renewer.EXPECT().Renew(gomock.Any(), mockUser, false).Return(nil)
+
+	userz := mock.NewMockUserService(controller)
+	userz.EXPECT().Find(gomock.Any(), "755bb80e5b", "").Return(nil, scm.ErrNotAuthorized)
+
+	sessions := mock.NewMockSessionStore(controller)
+	sessions.EXPECT().DeleteUser(gomock.Any(), mockUser.ID).Return(nil)
+
+	tokens := mock.NewMockTokenStore(controller)
+	tokens.EXPECT().List(gomock.Any(), mockUser.ID).Return(mockTokens, nil)
+	tokens.EXPECT().Delete(gomock.Any(), mockTokens[0]).Return(nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().List(gomock.Any(), mockUser.ID).Return(mockRepos, nil)
+	repos.EXPECT().Update(gomock.Any(), mockRepos[0]).Return(nil)
+
+	var actions []string
+	audits := mock.NewMockAuditService(controller)
+	audits.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).Times(2).Do(func(_ context.Context, event *core.AuditEvent) {
+		actions = append(actions, event.Action)
+	})
+
+	r := New(users, userz, renewer, nil, repos, sessions, tokens, audits, "drone-bot")
+	if err := r.run(noContext); err != nil {
+		t.Error(err)
+	}
+
+	if mockUser.Active {
+		t.Errorf("Want user account deactivated")
+	}
+	if mockUser.Token != "" || mockUser.Hash == "MjAxOC0wOC0xMVQxNTo1OD" {
+		t.Errorf("Want user credentials revoked")
+	}
+	if got, want := mockRepos[0].UserID, mockOwner.ID; got != want {
+		t.Errorf("Want repository reassigned to user %d, got %d", want, got)
+	}
+	if got, want := mockRepos[1].UserID, int64(3); got != want {
+		t.Errorf("Want repository owned by other user unchanged")
+	}
+	if len(actions) != 2 || actions[0] != core.AuditActionUserDisable || actions[1] != core.AuditActionRepoChown {
+		t.Errorf("Unexpected audit actions %v", actions)
+	}
+}
+
+// this test verifies that the account is deactivated if the
+// refresh token is revoked and cannot be renewed.
+func TestReconcile_RefreshRevoked(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Login: "octocat", Active: true, Token: "755bb80e5b", Refresh: "e08f3fa43e"}
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().List(gomock.Any()).Return([]*core.User{mockUser}, nil)
+	users.EXPECT().Update(gomock.Any(), mockUser).Return(nil)
+
+	renewer := mock.NewMockRenewer(controller)
+	renewer.EXPECT().Renew(gomock.Any(), mockUser, false).Return(scm.ErrNotAuthorized)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().List(gomock.Any(), mockUser.ID).Return(nil, nil)
+
+	r := New(users, nil, renewer, nil, repos, nil, nil, nil, "")
+	if err := r.run(noContext); err != nil {
+		t.Error(err)
+	}
+	if mockUser.Active {
+		t.Errorf("Want user account deactivated")
+	}
+}
+
+func TestReconcile_Membership(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{ID: 1, Login: "octocat", Active: true, Token: "755bb80e5b"}
+	mockRepos := []*core.Repository{
+		{ID: 1, UserID: 1, Slug: "octocat/hello-world"},
+	}
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().List(gomock.Any()).Return([]*core.User{mockUser}, nil)
+	users.EXPECT().Update(gomock.Any(), mockUser).Return(nil)
+
+	renewer := mock.NewMockRenewer(controller)
+	renewer.EXPECT().Renew(gomock.Any(), mockUser, false).Return(nil)
+
+	userz := mock.NewMockUserService(controller)
+	userz.EXPECT().Find(gomock.Any(), "755bb80e5b", "").Return(&core.User{Login: "octocat"}, nil)
+
+	orgs := mock.NewMockOrganizationService(controller)
+	orgs.EXPECT().List(gomock.Any(), mockUser).Return([]*core.Organization{{Name: "github"}}, nil)
+
+	repos := mock.NewMockRepositoryStore(controller)
+	repos.EXPECT().List(gomock.Any(), mockUser.ID).Return(mockRepos, nil)
+
+	audits := mock.NewMockAuditService(controller)
+	audits.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+	audits.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).Do(func(_ context.Context, event *core.AuditEvent) {
+		if got, want := event.Action, core.AuditActionRepoOrphan; got != want {
+			t.Errorf("Want repository flagged with action %s, got %s", want, got)
+		}
+	})
+
+	membership := admission.Membership(orgs, []string{"drone"})
+	r := New(users, userz, renewer, membership, repos, nil, nil, audits, "")
+	if err := r.run(noContext); err != nil {
+		t.Error(err)
+	}
+	if mockUser.Active {
+		t.Errorf("Want user account deactivated")
+	}
+	if got, want := mockRepos[0].UserID, mockUser.ID; got != want {
+		t.Errorf("Want repository owner unchanged")
+	}
+}
+
+// this test verifies that the account is not modified when the
+// source control management system cannot be reached.
+func TestReconcile_Inconclusive(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := []*core.User{
+		{ID: 1, Login: "octocat", Active: true, Token: "755bb80e5b"},
+		{ID: 2, Login: "spaceghost", Active: false, Token: "e08f3fa43e"},
+		{ID: 3, Login: "drone-bot", Active: true, Machine: true},
+		{ID: 4, Login: "deploy@octocat", Active: true, Namespace: "octocat"},
+	}
+
+	users := mock.NewMockUserStore(controller)
+	users.EXPECT().List(gomock.Any()).Return(mockUsers, nil)
+
+	renewer := mock.NewMockRenewer(controller)
+	renewer.EXPECT().Renew(gomock.Any(), mockUsers[0], false).Return(nil)
+
+	userz := mock.NewMockUserService(controller)
+	userz.EXPECT().Find(gomock.Any(), "755bb80e5b", "").Return(nil, errors.New("connection refused"))
+
+	r := New(users, userz, renewer, nil, nil, nil, nil, nil, "")
+	if err := r.run(noContext); err == nil {
+		t.Errorf("Want verification error returned")
+	}
+	if !mockUsers[0].Active {
+		t.Errorf("Want user account unchanged")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orgs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/go-scm/scm"
+)
+
+// errMembershipNotSupported is returned when the source code
+// management system does not support organization membership.
+var errMembershipNotSupported = errors.New("Cannot find organization membership for this provider")
+
+// gitlab access level granted to group owners.
+const gitlabOwner = 50
+
+func (s *service) Membership(ctx context.Context, user *core.User, name string) (bool, bool, error) {
+	err := s.renewer.Renew(ctx, user, false)
+	if err != nil {
+		return false, false, err
+	}
+	token := &scm.Token{
+		Token:   user.Token,
+		Refresh: user.Refresh,
+	}
+	if user.Expiry != 0 {
+		token.Expires = time.Unix(user.Expiry, 0)
+	}
+	ctx = context.WithValue(ctx, scm.TokenKey{}, token)
+
+	// the go-scm client does not expose the organization
+	// membership, so the provider endpoints are requested
+	// directly.
+	switch s.client.Driver {
+	case scm.DriverGithub:
+		out := struct {
+			State string `json:"state"`
+			Role  string `json:"role"`
+		}{}
+		err := s.do(ctx, fmt.Sprintf("user/memberships/orgs/%s", name), &out)
+		if err == scm.ErrNotFound {
+			return false, false, nil
+		} else if err != nil {
+			return false, false, err
+		}
+		member := out.State == "active"
+		return member, member && out.Role == "admin", nil
+	case scm.DriverGitlab:
+		self := struct {
+			ID int64 `json:"id"`
+		}{}
+		if err := s.do(ctx, "api/v4/user", &self); err != nil {
+			return false, false, err
+		}
+		out := struct {
+			AccessLevel int `json:"access_level"`
+		}{}
+		uri := fmt.Sprintf("api/v4/groups/%s/members/all/%d",
+			strings.Replace(url.PathEscape(name), "/", "%2F", -1), self.ID)
+		err := s.do(ctx, uri, &out)
+		if err == scm.ErrNotFound {
+			return false, false, nil
+		} else if err != nil {
+			return false, false, err
+		}
+		return true, out.AccessLevel >= gitlabOwner, nil
+	default:
+		return false, false, errMembershipNotSupported
+	}
+}
+
+// helper function sends a GET request to the provider and
+// unmarshals the json response body.
+func (s *service) do(ctx context.Context, uri string, out interface{}) error {
+	res, err := s.client.Do(ctx, &scm.Request{
+		Method: "GET",
+		Path:   uri,
+	})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.Status == 404 {
+		return scm.ErrNotFound
+	}
+	if res.Status > 299 {
+		return fmt.Errorf("Cannot find organization membership: status code %d", res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package orgs
+
+import (
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+	"github.com/drone/go-scm/scm/driver/github"
+	"github.com/drone/go-scm/scm/driver/gitlab"
+
+	"github.com/golang/mock/gomock"
+	"github.com/h2non/gock"
+)
+
+func TestMembership_GitHub(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/user/memberships/orgs/github").
+		Reply(200).
+		BodyString(`{"state": "active", "role": "admin"}`)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	member, admin, err := New(github.NewDefault(), mockRenewer).Membership(noContext, mockUser, "github")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !member || !admin {
+		t.Errorf("Want organization admin membership")
+	}
+	if gock.IsPending() {
+		t.Errorf("Unfinished requests")
+	}
+}
+
+func TestMembership_GitHub_NotMember(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/user/memberships/orgs/github").
+		Reply(404)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	member, admin, err := New(github.NewDefault(), mockRenewer).Membership(noContext, mockUser, "github")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if member || admin {
+		t.Errorf("Want no organization membership")
+	}
+}
+
+func TestMembership_GitLab(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Get("/api/v4/user").
+		Reply(200).
+		BodyString(`{"id": 1}`)
+
+	gock.New("https://gitlab.com").
+		Get("/api/v4/groups/gitlab-org/members/all/1").
+		Reply(200).
+		BodyString(`{"id": 1, "access_level": 30}`)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUser := &core.User{}
+	mockRenewer := mock.NewMockRenewer(controller)
+	mockRenewer.EXPECT().Renew(gomock.Any(), mockUser, false)
+
+	member, admin, err := New(gitlab.NewDefault(), mockRenewer).Membership(noContext, mockUser, "gitlab-org")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !member {
+		t.Errorf("Want organization membership")
+	}
+	if admin {
+		t.Errorf("Want developer access level not granted admin")
+	}
+	if gock.IsPending() {
+		t.Errorf("Unfinished requests")
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/logger"
+
+	"github.com/hashicorp/golang-lru"
+)
+
+// period for which the user organization membership is
+// cached, since membership is resolved on every request.
+var membershipTTL = time.Minute * 5
+
+// period for which the role bindings are cached, since the
+// bindings are resolved on every request. Changes to the role
+// bindings may take up to this duration to take effect.
+var bindingsTTL = time.Second * 30
+
+// New returns a new RoleService. Organization role bindings
+// are resolved using the user organization membership in the
+// source control management system.
+func New(roles core.RoleStore, orgs core.OrganizationService) core.RoleService {
+	cache, _ := lru.New(1000)
+	return &service{
+		roles: roles,
+		orgs:  orgs,
+		cache: cache,
+	}
+}
+
+type service struct {
+	roles core.RoleStore
+	orgs  core.OrganizationService
+	cache *lru.Cache
+
+	mu       sync.Mutex
+	bindings []*core.RoleBinding
+	expires  time.Time
+}
+
+// membership is a cached list of organizations.
+type membership struct {
+	orgs    map[string]bool
+	expires time.Time
+}
+
+func (s *service) List(ctx context.Context, user *core.User, namespace string) ([]*core.Role, error) {
+	bindings, err := s.listBindings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orgs map[string]bool
+	var roles []*core.Role
+	seen := map[int64]bool{}
+	for _, binding := range bindings {
+		if seen[binding.RoleID] {
+			continue
+		}
+		if binding.Namespace != "" && !strings.EqualFold(binding.Namespace, namespace) {
+			continue
+		}
+		switch {
+		case binding.User != "":
+			if !strings.EqualFold(binding.User, user.Login) {
+				continue
+			}
+		case binding.Org != "":
+			// the organization membership is only requested
+			// if the user is not bound to the role directly.
+			// If the membership cannot be requested, only the
+			// roles bound to the user directly are granted.
+			if orgs == nil {
+				orgs, err = s.membership(ctx, user)
+				if err != nil {
+					logger.FromContext(ctx).
+						WithError(err).
+						WithField("user", user.Login).
+						Warnln("role: cannot list organization membership")
+					orgs = map[string]bool{}
+				}
+			}
+			if !orgs[strings.ToLower(binding.Org)] {
+				continue
+			}
+		default:
+			continue
+		}
+
+		seen[binding.RoleID] = true
+		roles = append(roles, binding.Role)
+	}
+	return roles, nil
+}
+
+func (s *service) Permits(ctx context.Context, user *core.User, namespace, permission string) (bool, error) {
+	roles, err := s.List(ctx, user, namespace)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role.Permits(permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listBindings returns the role bindings with their roles.
+// The bindings are listed in a single query, and are cached
+// for a short period.
+func (s *service) listBindings(ctx context.Context) ([]*core.RoleBinding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Now().Before(s.expires) {
+		return s.bindings, nil
+	}
+	bindings, err := s.roles.ListBindingRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.bindings = bindings
+	s.expires = time.Now().Add(bindingsTTL)
+	return bindings, nil
+}
+
+// membership returns the user organization membership.
+func (s *service) membership(ctx context.Context, user *core.User) (map[string]bool, error) {
+	if cached, ok := s.cache.Get(user.ID); ok {
+		if m := cached.(*membership); time.Now().Before(m.expires) {
+			return m.orgs, nil
+		}
+	}
+	list, err := s.orgs.List(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	orgs := map[string]bool{}
+	for _, org := range list {
+		orgs[strings.ToLower(org.Name)] = true
+	}
+	s.cache.Add(user.ID, &membership{
+		orgs:    orgs,
+		expires: time.Now().Add(membershipTTL),
+	})
+	return orgs, nil
+}
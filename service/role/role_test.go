@@ -0,0 +1,143 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package role
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+)
+
+var noContext = context.Background()
+
+func TestPermits(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := &core.User{ID: 1, Login: "octocat"}
+	releasers := &core.Role{ID: 1, Permissions: []string{"deploy:approve:prod"}}
+	operators := &core.Role{ID: 2, Permissions: []string{"queue:pause"}}
+
+	roles := mock.NewMockRoleStore(controller)
+	roles.EXPECT().ListBindingRoles(gomock.Any()).Return([]*core.RoleBinding{
+		{RoleID: 1, User: "OctoCat", Namespace: "octocat", Role: releasers},
+		{RoleID: 2, Org: "github", Role: operators},
+		{RoleID: 3, User: "spaceghost", Role: &core.Role{ID: 3}},
+	}, nil)
+
+	// the role bindings and the organization membership are
+	// cached, and requested only once.
+	orgs := mock.NewMockOrganizationService(controller)
+	orgs.EXPECT().List(gomock.Any(), user).Return([]*core.Organization{{Name: "GitHub"}}, nil)
+
+	service := New(roles, orgs)
+
+	tests := []struct {
+		namespace  string
+		permission string
+		permits    bool
+	}{
+		{"octocat", "deploy:approve:prod", true},
+		{"octocat", "deploy:approve:staging", false},
+		{"spaceghost", "deploy:approve:prod", false},
+		{"spaceghost", "queue:pause", true},
+		{"", "queue:pause", true},
+		{"octocat", "secret:manage", false},
+	}
+	for i, test := range tests {
+		got, err := service.Permits(noContext, user, test.namespace, test.permission)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got != test.permits {
+			t.Errorf("Want permits %v for %s in %q at index %d", test.permits, test.permission, test.namespace, i)
+		}
+	}
+}
+
+func TestList_NoOrgs(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := &core.User{ID: 1, Login: "octocat"}
+	roles := mock.NewMockRoleStore(controller)
+	roles.EXPECT().ListBindingRoles(gomock.Any()).Return([]*core.RoleBinding{
+		{RoleID: 1, User: "octocat", Role: &core.Role{ID: 1}},
+	}, nil)
+
+	// the organization membership is not requested if there
+	// are no organization role bindings.
+	orgs := mock.NewMockOrganizationService(controller)
+
+	list, err := New(roles, orgs).List(noContext, user, "octocat")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want %d roles, got %d", want, got)
+	}
+}
+
+// this test verifies that roles bound to the user are granted
+// if the organization membership cannot be requested.
+func TestList_OrgsError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := &core.User{ID: 1, Login: "octocat"}
+	roles := mock.NewMockRoleStore(controller)
+	roles.EXPECT().ListBindingRoles(gomock.Any()).Return([]*core.RoleBinding{
+		{RoleID: 1, Org: "github", Role: &core.Role{ID: 1}},
+		{RoleID: 2, User: "octocat", Role: &core.Role{ID: 2}},
+	}, nil)
+
+	orgs := mock.NewMockOrganizationService(controller)
+	orgs.EXPECT().List(gomock.Any(), user).Return(nil, errors.New("rate limit exceeded"))
+
+	list, err := New(roles, orgs).List(noContext, user, "octocat")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want %d roles, got %d", want, got)
+		return
+	}
+	if got, want := list[0].ID, int64(2); got != want {
+		t.Errorf("Want role %d, got %d", want, got)
+	}
+}
+
+func TestList_BindingsExpired(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := &core.User{ID: 1, Login: "octocat"}
+	roles := mock.NewMockRoleStore(controller)
+	roles.EXPECT().ListBindingRoles(gomock.Any()).Return([]*core.RoleBinding{}, nil)
+	roles.EXPECT().ListBindingRoles(gomock.Any()).Return([]*core.RoleBinding{
+		{RoleID: 1, User: "octocat", Role: &core.Role{ID: 1}},
+	}, nil)
+
+	service := New(roles, nil).(*service)
+	if list, _ := service.List(noContext, user, "octocat"); len(list) != 0 {
+		t.Errorf("Want no roles, got %d", len(list))
+	}
+
+	// the cached role bindings are listed again when the
+	// cache expires.
+	service.expires = time.Now().Add(-time.Second)
+	if list, _ := service.List(noContext, user, "octocat"); len(list) != 1 {
+		t.Errorf("Want 1 role, got %d", len(list))
+	}
+}
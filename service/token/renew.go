@@ -8,6 +8,7 @@ package token
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/drone/drone/core"
@@ -47,7 +48,9 @@ func (r *renewer) Renew(ctx context.Context, user *core.User, force bool) error
 		return nil
 	}
 	err := r.refresh.Refresh(t)
-	if err != nil {
+	if revoked(err) {
+		return scm.ErrNotAuthorized
+	} else if err != nil {
 		return err
 	}
 	user.Token = t.Token
@@ -67,3 +70,20 @@ func expired(token *scm.Token) bool {
 	return token.Expires.Add(-expiryDelta).
 		Before(time.Now())
 }
+
+// revoked reports whether the refresh error indicates the
+// refresh token is expired or revoked. The authorization
+// server responds with an invalid_grant error code, however
+// the refresher does not export the error type, and the code
+// is read from the json representation of the error.
+func revoked(err error) bool {
+	if err == nil {
+		return false
+	}
+	out := struct {
+		Code string `json:"error"`
+	}{}
+	raw, _ := json.Marshal(err)
+	json.Unmarshal(raw, &out)
+	return out.Code == "invalid_grant"
+}
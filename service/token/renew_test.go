@@ -5,3 +5,53 @@
 // +build !oss
 
 package token
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/go-scm/scm"
+	"github.com/drone/go-scm/scm/transport/oauth2"
+)
+
+func TestRenew_Revoked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"The refresh token is invalid."}`))
+	}))
+	defer server.Close()
+
+	user := &core.User{
+		Token:   "755bb80e5b",
+		Refresh: "e08f3fa43e",
+		Expiry:  time.Now().Add(-time.Hour).Unix(),
+	}
+	renewer := Renewer(&oauth2.Refresher{Endpoint: server.URL}, nil)
+	err := renewer.Renew(context.Background(), user, false)
+	if got, want := err, scm.ErrNotAuthorized; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
+
+func TestRenew_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+		w.Write([]byte(`{"error":"server_error","error_description":"Internal error."}`))
+	}))
+	defer server.Close()
+
+	user := &core.User{
+		Token:   "755bb80e5b",
+		Refresh: "e08f3fa43e",
+		Expiry:  time.Now().Add(-time.Hour).Unix(),
+	}
+	renewer := Renewer(&oauth2.Refresher{Endpoint: server.URL}, nil)
+	err := renewer.Renew(context.Background(), user, false)
+	if err == nil || err == scm.ErrNotAuthorized {
+		t.Errorf("Want inconclusive refresh error, got %v", err)
+	}
+}
@@ -16,6 +16,7 @@ package user
 
 import (
 	"context"
+	"strings"
 
 	"github.com/drone/drone/core"
 	"github.com/drone/go-scm/scm"
@@ -36,7 +37,18 @@ func (s *service) Find(ctx context.Context, access, refresh string) (*core.User,
 		Token:   access,
 		Refresh: refresh,
 	})
-	src, _, err := s.client.Users.Find(ctx)
+	src, res, err := s.client.Users.Find(ctx)
+	if res != nil && res.Status == 401 {
+		// the token is revoked, which is reported
+		// consistently across providers.
+		return nil, scm.ErrNotAuthorized
+	}
+	if res != nil && res.Status == 403 && suspended(err) {
+		// the account is suspended or blocked. Other 403
+		// errors, for example rate limiting, are not an
+		// indication the account is revoked.
+		return nil, scm.ErrNotAuthorized
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -53,3 +65,14 @@ func (s *service) Find(ctx context.Context, access, refresh string) (*core.User,
 	}
 	return dst, nil
 }
+
+// helper function returns true if the error message reports
+// that the account is suspended or blocked.
+func suspended(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "suspended") ||
+		strings.Contains(msg, "blocked")
+}
@@ -6,6 +6,7 @@ package user
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -87,3 +88,52 @@ func TestFind_Error(t *testing.T) {
 		t.Errorf("Expect nil user on error")
 	}
 }
+
+func TestFind_NotAuthorized(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := mockscm.NewMockUserService(controller)
+	mockUsers.EXPECT().Find(gomock.Any()).Return(nil, &scm.Response{Status: 401}, errors.New("Bad credentials"))
+
+	client := new(scm.Client)
+	client.Users = mockUsers
+
+	_, err := New(client).Find(noContext, "755bb80e5b", "e08f3fa43e")
+	if got, want := err, scm.ErrNotAuthorized; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
+
+func TestFind_Suspended(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := mockscm.NewMockUserService(controller)
+	mockUsers.EXPECT().Find(gomock.Any()).Return(nil, &scm.Response{Status: 403}, errors.New("Sorry. Your account was suspended."))
+
+	client := new(scm.Client)
+	client.Users = mockUsers
+
+	_, err := New(client).Find(noContext, "755bb80e5b", "e08f3fa43e")
+	if got, want := err, scm.ErrNotAuthorized; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
+
+func TestFind_Forbidden(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockErr := errors.New("API rate limit exceeded")
+	mockUsers := mockscm.NewMockUserService(controller)
+	mockUsers.EXPECT().Find(gomock.Any()).Return(nil, &scm.Response{Status: 403}, mockErr)
+
+	client := new(scm.Client)
+	client.Users = mockUsers
+
+	_, err := New(client).Find(noContext, "755bb80e5b", "e08f3fa43e")
+	if got, want := err, mockErr; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
+}
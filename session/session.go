@@ -15,42 +15,101 @@
 package session
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/drone/drone/core"
+	"github.com/drone/drone/handler/api/request"
 
 	"github.com/dchest/authcookie"
+	"github.com/dchest/uniuri"
+	"github.com/hashicorp/golang-lru"
 )
 
-// New returns a new cookie-based session management.
-func New(users core.UserStore, config Config) core.Session {
+// cacheTTL is the duration a session record is cached before
+// the session is checked for revocation again. Revoking a
+// session may take up to this duration to take effect on other
+// server instances.
+const cacheTTL = 30 * time.Second
+
+// sessionSep separates the login and session key in the
+// signed session cookie.
+const sessionSep = "|"
+
+var (
+	errTokenExpired   = errors.New("session: token expired")
+	errTokenScope     = errors.New("session: token scope does not permit request")
+	errSessionRevoked = errors.New("session: session revoked")
+)
+
+// New returns a new cookie-based session management. If the
+// session store is not nil, a server-side record is created
+// for each session, and sessions can be revoked.
+func New(users core.UserStore, tokens core.TokenStore, sessions core.SessionStore, config Config) core.Session {
+	cache, _ := lru.New(10000)
 	return &session{
-		secret:  []byte(config.Secret),
-		timeout: config.Timeout,
-		users:   users,
+		secret:   []byte(config.Secret),
+		timeout:  config.Timeout,
+		users:    users,
+		tokens:   tokens,
+		sessions: sessions,
+		cache:    cache,
 	}
 }
 
 type session struct {
-	users   core.UserStore
-	secret  []byte
-	timeout time.Duration
+	users    core.UserStore
+	tokens   core.TokenStore
+	sessions core.SessionStore
+	secret   []byte
+	timeout  time.Duration
+	cache    *lru.Cache
 
 	administrator string // administrator account
 	prometheus    string // prometheus account
 	autoscaler    string // autoscaler account
 }
 
-func (s *session) Create(w http.ResponseWriter, user *core.User) error {
+// cached is a session record, and the time the record was
+// last checked for revocation.
+type cached struct {
+	session *core.UserSession
+	checked time.Time
+}
+
+func (s *session) Create(w http.ResponseWriter, r *http.Request, user *core.User) error {
+	value := user.Login
+	if s.sessions != nil {
+		key := uniuri.NewLen(32)
+		now := time.Now()
+		record := &core.UserSession{
+			UserID:   user.ID,
+			Hash:     core.HashToken(key),
+			Device:   request.UserAgent(r),
+			IP:       request.RemoteAddr(r),
+			Expires:  now.Add(s.timeout).Unix(),
+			LastSeen: now.Unix(),
+			Created:  now.Unix(),
+		}
+		err := s.sessions.Create(r.Context(), record)
+		if err != nil {
+			return err
+		}
+		// expired sessions are purged when a session is
+		// created, since expired sessions cannot be used.
+		s.sessions.DeleteExpired(r.Context(), now.Unix())
+		value = user.Login + sessionSep + key
+	}
+
 	cookie := &http.Cookie{
 		Name:     "_session_",
 		Path:     "/",
 		MaxAge:   2147483647,
 		HttpOnly: true,
 		Value: authcookie.NewSinceNow(
-			user.Login,
+			value,
 			s.timeout,
 			s.secret,
 		),
@@ -59,9 +118,50 @@ func (s *session) Create(w http.ResponseWriter, user *core.User) error {
 	return nil
 }
 
-func (s *session) Delete(w http.ResponseWriter) error {
+func (s *session) Delete(w http.ResponseWriter, r *http.Request) error {
 	w.Header().Add("Set-Cookie", "_session_=deleted; Path=/; Max-Age=0")
-	return nil
+	if s.sessions == nil {
+		return nil
+	}
+	cookie, err := r.Cookie("_session_")
+	if err != nil {
+		return nil
+	}
+	_, key := splitValue(authcookie.Login(cookie.Value, s.secret))
+	if key == "" {
+		return nil
+	}
+	hash := core.HashToken(key)
+	s.cache.Remove(hash)
+	record, err := s.sessions.FindHash(r.Context(), hash)
+	if err != nil {
+		return nil
+	}
+	return s.sessions.Delete(r.Context(), record)
+}
+
+func (s *session) Revoke(user int64) {
+	for _, key := range s.cache.Keys() {
+		v, ok := s.cache.Peek(key)
+		if ok && v.(*cached).session.UserID == user {
+			s.cache.Remove(key)
+		}
+	}
+}
+
+func (s *session) Upgrade(w http.ResponseWriter, r *http.Request, user *core.User) error {
+	if s.sessions == nil {
+		return nil
+	}
+	cookie, err := r.Cookie("_session_")
+	if err != nil {
+		return nil
+	}
+	login, key := splitValue(authcookie.Login(cookie.Value, s.secret))
+	if login == "" || key != "" || login != user.Login {
+		return nil
+	}
+	return s.Create(w, r, user)
 }
 
 func (s *session) Get(r *http.Request) (*core.User, error) {
@@ -84,13 +184,141 @@ func (s *session) fromSession(r *http.Request) (*core.User, error) {
 	if login == "" {
 		return nil, nil
 	}
-	return s.users.FindLogin(r.Context(), login)
+	if s.sessions == nil {
+		return s.users.FindLogin(r.Context(), login)
+	}
+
+	// cookies created before server-side sessions were
+	// enabled do not include a session key, and cannot be
+	// revoked. These sessions are accepted until the cookie
+	// expires, to avoid logging out every user on upgrade,
+	// and are replaced with a revocable session by Upgrade.
+	login, key := splitValue(login)
+	if key == "" {
+		return s.users.FindLogin(r.Context(), login)
+	}
+	record, err := s.lookup(r, key)
+	if err != nil {
+		return nil, errSessionRevoked
+	}
+	user, err := s.users.FindLogin(r.Context(), login)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID != record.UserID {
+		return nil, errSessionRevoked
+	}
+	return user, nil
+}
+
+// lookup returns the session record for the session key. The
+// record is cached to avoid a database lookup per request, and
+// the last seen timestamp is updated when the cache entry is
+// refreshed.
+func (s *session) lookup(r *http.Request, key string) (*core.UserSession, error) {
+	hash := core.HashToken(key)
+	now := time.Now()
+	if v, ok := s.cache.Get(hash); ok {
+		entry := v.(*cached)
+		if now.Sub(entry.checked) < cacheTTL {
+			return entry.session, nil
+		}
+	}
+
+	record, err := s.sessions.FindHash(r.Context(), hash)
+	if err != nil {
+		s.cache.Remove(hash)
+		return nil, err
+	}
+	if record.IsExpired() {
+		s.cache.Remove(hash)
+		return nil, errSessionRevoked
+	}
+
+	// the last seen timestamp is only updated at minute
+	// granularity to avoid a database write per request.
+	if now.Unix()-record.LastSeen > 60 {
+		record.LastSeen = now.Unix()
+		record.IP = request.RemoteAddr(r)
+		s.sessions.Update(r.Context(), record)
+	}
+	s.cache.Add(hash, &cached{session: record, checked: now})
+	return record, nil
 }
 
 func (s *session) fromToken(r *http.Request) (*core.User, error) {
-	return s.users.FindToken(r.Context(),
-		extractToken(r),
-	)
+	raw := extractToken(r)
+	if s.tokens != nil {
+		token, err := s.tokens.FindHash(r.Context(), core.HashToken(raw))
+		if err == nil {
+			return s.fromPersonalToken(r, token)
+		}
+	}
+	return s.users.FindToken(r.Context(), raw)
+}
+
+// fromPersonalToken returns the user that owns the personal
+// access token, if the token is not expired and its scopes
+// permit the request.
+func (s *session) fromPersonalToken(r *http.Request, token *core.Token) (*core.User, error) {
+	if token.IsExpired() {
+		return nil, errTokenExpired
+	}
+	if !permits(r, token) {
+		return nil, errTokenScope
+	}
+	user, err := s.users.Find(r.Context(), token.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the last used timestamp is only updated at minute
+	// granularity to avoid a database write per request.
+	now := time.Now().Unix()
+	if now-token.LastUsed > 60 {
+		token.LastUsed = now
+		s.tokens.Update(r.Context(), token)
+	}
+	return user, nil
+}
+
+// permits returns true if the token scopes permit the request.
+// Read-only tokens are limited to safe http methods, and tokens
+// restricted to a repository are limited to the repository
+// endpoints.
+func permits(r *http.Request, token *core.Token) bool {
+	if token.Scope != core.TokenScopeWrite {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			return false
+		}
+	}
+	if token.Repo == "" {
+		return true
+	}
+	for _, prefix := range []string{"/api/repos/", "/api/stream/", "/api/badges/"} {
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+		if path == r.URL.Path {
+			continue
+		}
+		parts := strings.SplitN(path, "/", 3)
+		if len(parts) < 2 {
+			return false
+		}
+		return strings.EqualFold(parts[0]+"/"+parts[1], token.Repo)
+	}
+	return false
+}
+
+// helper function splits the signed cookie value into the
+// login and session key.
+func splitValue(value string) (login, key string) {
+	i := strings.LastIndex(value, sessionSep)
+	if i == -1 {
+		return value, ""
+	}
+	return value[:i], value[i+1:]
 }
 
 func isAuthorizationToken(r *http.Request) bool {
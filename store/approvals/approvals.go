@@ -0,0 +1,270 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approvals
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new Approval database store.
+func New(db *db.DB) core.ApprovalStore {
+	return &approvalStore{db}
+}
+
+type approvalStore struct {
+	db *db.DB
+}
+
+func (s *approvalStore) List(ctx context.Context, id int64) ([]*core.Approval, error) {
+	var out []*core.Approval
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"approval_stage_id": id}
+		stmt, args, err := binder.BindNamed(queryStage, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *approvalStore) Create(ctx context.Context, approval *core.Approval) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, approval)
+	}
+	return s.create(ctx, approval)
+}
+
+func (s *approvalStore) create(ctx context.Context, approval *core.Approval) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(approval)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		approval.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *approvalStore) createPostgres(ctx context.Context, approval *core.Approval) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(approval)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&approval.ID)
+	})
+}
+
+func (s *approvalStore) ListRules(ctx context.Context) ([]*core.ApprovalRule, error) {
+	var out []*core.ApprovalRule
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		rows, err := queryer.Query(queryRules)
+		if err != nil {
+			return err
+		}
+		out, err = scanRuleRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *approvalStore) FindRule(ctx context.Context, id int64) (*core.ApprovalRule, error) {
+	out := &core.ApprovalRule{ID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toRuleParams(out)
+		query, args, err := binder.BindNamed(queryRuleKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRuleRow(row, out)
+	})
+	return out, err
+}
+
+func (s *approvalStore) CreateRule(ctx context.Context, rule *core.ApprovalRule) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createRulePostgres(ctx, rule)
+	}
+	return s.createRule(ctx, rule)
+}
+
+func (s *approvalStore) createRule(ctx context.Context, rule *core.ApprovalRule) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toRuleParams(rule)
+		stmt, args, err := binder.BindNamed(stmtInsertRule, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		rule.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *approvalStore) createRulePostgres(ctx context.Context, rule *core.ApprovalRule) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toRuleParams(rule)
+		stmt, args, err := binder.BindNamed(stmtInsertRulePg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&rule.ID)
+	})
+}
+
+func (s *approvalStore) UpdateRule(ctx context.Context, rule *core.ApprovalRule) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toRuleParams(rule)
+		stmt, args, err := binder.BindNamed(stmtUpdateRule, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *approvalStore) DeleteRule(ctx context.Context, rule *core.ApprovalRule) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toRuleParams(rule)
+		stmt, args, err := binder.BindNamed(stmtDeleteRule, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryBase = `
+SELECT
+ approval_id
+,approval_stage_id
+,approval_approver
+,approval_approved
+,approval_comment
+,approval_created
+`
+
+const queryStage = queryBase + `
+FROM approvals
+WHERE approval_stage_id = :approval_stage_id
+ORDER BY approval_id
+`
+
+const stmtInsert = `
+INSERT INTO approvals (
+ approval_stage_id
+,approval_approver
+,approval_approved
+,approval_comment
+,approval_created
+) VALUES (
+ :approval_stage_id
+,:approval_approver
+,:approval_approved
+,:approval_comment
+,:approval_created
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING approval_id
+`
+
+const queryRuleBase = `
+SELECT
+ rule_id
+,rule_repo
+,rule_target
+,rule_protected
+,rule_approvals
+,rule_allow_self
+,rule_groups
+,rule_created
+,rule_updated
+`
+
+const queryRuleKey = queryRuleBase + `
+FROM approval_rules
+WHERE rule_id = :rule_id
+`
+
+const queryRules = queryRuleBase + `
+FROM approval_rules
+ORDER BY rule_id
+`
+
+const stmtUpdateRule = `
+UPDATE approval_rules SET
+ rule_repo = :rule_repo
+,rule_target = :rule_target
+,rule_protected = :rule_protected
+,rule_approvals = :rule_approvals
+,rule_allow_self = :rule_allow_self
+,rule_groups = :rule_groups
+,rule_updated = :rule_updated
+WHERE rule_id = :rule_id
+`
+
+const stmtDeleteRule = `
+DELETE FROM approval_rules
+WHERE rule_id = :rule_id
+`
+
+const stmtInsertRule = `
+INSERT INTO approval_rules (
+ rule_repo
+,rule_target
+,rule_protected
+,rule_approvals
+,rule_allow_self
+,rule_groups
+,rule_created
+,rule_updated
+) VALUES (
+ :rule_repo
+,:rule_target
+,:rule_protected
+,:rule_approvals
+,:rule_allow_self
+,:rule_groups
+,:rule_created
+,:rule_updated
+)
+`
+
+const stmtInsertRulePg = stmtInsertRule + `
+RETURNING rule_id
+`
@@ -0,0 +1,136 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package approvals
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/build"
+	"github.com/drone/drone/store/repos"
+	"github.com/drone/drone/store/shared/db/dbtest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var noContext = context.TODO()
+
+func TestApproval(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seed with a dummy repository
+	arepo := &core.Repository{UID: "1", Slug: "octocat/hello-world"}
+	repos := repos.New(conn)
+	repos.Create(noContext, arepo)
+
+	// seed with a dummy stage
+	stage := &core.Stage{Number: 1}
+	stages := []*core.Stage{stage}
+
+	// seed with a dummy build
+	abuild := &core.Build{Number: 1, RepoID: arepo.ID}
+	builds := build.New(conn)
+	builds.Create(noContext, abuild, stages)
+
+	store := New(conn).(*approvalStore)
+	t.Run("Create", testApprovalCreate(store, stage))
+	t.Run("Rules", testRules(store))
+}
+
+func testApprovalCreate(store *approvalStore, stage *core.Stage) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Approval{
+			StageID:  stage.ID,
+			Approver: "octocat",
+			Approved: true,
+			Comment:  "lgtm",
+			Created:  1522878684,
+		}
+		err := store.Create(noContext, item)
+		if err != nil {
+			t.Error(err)
+		}
+		if item.ID == 0 {
+			t.Errorf("Want approval ID assigned, got %d", item.ID)
+		}
+
+		// the approver can only review the stage once.
+		err = store.Create(noContext, &core.Approval{StageID: stage.ID, Approver: "octocat"})
+		if err == nil {
+			t.Errorf("Want unique constraint violation")
+		}
+
+		list, err := store.List(noContext, stage.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(list, []*core.Approval{item}); len(diff) != 0 {
+			t.Error(diff)
+		}
+	}
+}
+
+func testRules(store *approvalStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		first := &core.ApprovalRule{
+			Repo:      "octocat/*",
+			Target:    "production",
+			Protected: true,
+			Approvals: 2,
+			Groups:    []string{"release-managers"},
+			Created:   1522878684,
+			Updated:   1522878684,
+		}
+		second := &core.ApprovalRule{Approvals: 1, AllowSelf: true}
+		for _, rule := range []*core.ApprovalRule{first, second} {
+			if err := store.CreateRule(noContext, rule); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		list, err := store.ListRules(noContext)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(list, []*core.ApprovalRule{first, second}); len(diff) != 0 {
+			t.Error(diff)
+		}
+
+		first.Approvals = 3
+		if err := store.UpdateRule(noContext, first); err != nil {
+			t.Error(err)
+			return
+		}
+		found, err := store.FindRule(noContext, first.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := found.Approvals, 3; got != want {
+			t.Errorf("Want rule approvals %d, got %d", want, got)
+		}
+
+		if err := store.DeleteRule(noContext, first); err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err := store.FindRule(noContext, first.ID); err != sql.ErrNoRows {
+			t.Errorf("Want sql.ErrNoRows, got %v", err)
+		}
+	}
+}
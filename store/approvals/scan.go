@@ -0,0 +1,125 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approvals
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// helper function converts the Approval structure to a set
+// of named query parameters.
+func toParams(approval *core.Approval) map[string]interface{} {
+	return map[string]interface{}{
+		"approval_id":       approval.ID,
+		"approval_stage_id": approval.StageID,
+		"approval_approver": approval.Approver,
+		"approval_approved": approval.Approved,
+		"approval_comment":  approval.Comment,
+		"approval_created":  approval.Created,
+	}
+}
+
+// helper function converts the ApprovalRule structure to a
+// set of named query parameters.
+func toRuleParams(rule *core.ApprovalRule) map[string]interface{} {
+	return map[string]interface{}{
+		"rule_id":         rule.ID,
+		"rule_repo":       rule.Repo,
+		"rule_target":     rule.Target,
+		"rule_protected":  rule.Protected,
+		"rule_approvals":  rule.Approvals,
+		"rule_allow_self": rule.AllowSelf,
+		"rule_groups":     encodeSlice(rule.Groups),
+		"rule_created":    rule.Created,
+		"rule_updated":    rule.Updated,
+	}
+}
+
+func encodeSlice(v []string) types.JSONText {
+	raw, _ := json.Marshal(v)
+	return types.JSONText(raw)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dst *core.Approval) error {
+	return scanner.Scan(
+		&dst.ID,
+		&dst.StageID,
+		&dst.Approver,
+		&dst.Approved,
+		&dst.Comment,
+		&dst.Created,
+	)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.Approval, error) {
+	defer rows.Close()
+
+	approvals := []*core.Approval{}
+	for rows.Next() {
+		approval := new(core.Approval)
+		err := scanRow(rows, approval)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals, nil
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRuleRow(scanner db.Scanner, dst *core.ApprovalRule) error {
+	groupsJSON := types.JSONText{}
+	err := scanner.Scan(
+		&dst.ID,
+		&dst.Repo,
+		&dst.Target,
+		&dst.Protected,
+		&dst.Approvals,
+		&dst.AllowSelf,
+		&groupsJSON,
+		&dst.Created,
+		&dst.Updated,
+	)
+	json.Unmarshal(groupsJSON, &dst.Groups)
+	return err
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRuleRows(rows *sql.Rows) ([]*core.ApprovalRule, error) {
+	defer rows.Close()
+
+	rules := []*core.ApprovalRule{}
+	for rows.Next() {
+		rule := new(core.ApprovalRule)
+		err := scanRuleRow(rows, rule)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
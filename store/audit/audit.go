@@ -0,0 +1,145 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new AuditStore.
+func New(db *db.DB) core.AuditStore {
+	return &auditStore{db}
+}
+
+type auditStore struct {
+	db *db.DB
+}
+
+func (s *auditStore) List(ctx context.Context, params core.AuditParams) ([]*core.AuditEvent, error) {
+	var out []*core.AuditEvent
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		size := params.Size
+		if size <= 0 {
+			size = 25
+		}
+		page := params.Page
+		if page <= 0 {
+			page = 1
+		}
+		args := map[string]interface{}{
+			"audit_actor":  params.Actor,
+			"audit_action": params.Action,
+			"audit_target": params.Target,
+			"since":        params.Since,
+			"until":        params.Until,
+			"limit":        size,
+			"offset":       (page - 1) * size,
+		}
+		stmt, vals, err := binder.BindNamed(queryFilter, args)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, vals...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *auditStore) Create(ctx context.Context, event *core.AuditEvent) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, event)
+	}
+	return s.create(ctx, event)
+}
+
+func (s *auditStore) create(ctx context.Context, event *core.AuditEvent) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(event)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		event.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *auditStore) createPostgres(ctx context.Context, event *core.AuditEvent) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(event)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&event.ID)
+	})
+}
+
+// the empty filter values are ignored, which allows a single
+// query to be used for all combinations of filters.
+const queryFilter = `
+SELECT
+ audit_id
+,audit_actor
+,audit_action
+,audit_target
+,audit_before
+,audit_after
+,audit_ip
+,audit_created
+FROM audit
+WHERE (:audit_actor = '' OR audit_actor = :audit_actor)
+  AND (:audit_action = '' OR audit_action = :audit_action)
+  AND (:audit_target = '' OR audit_target = :audit_target)
+  AND (:since = 0 OR audit_created >= :since)
+  AND (:until = 0 OR audit_created < :until)
+ORDER BY audit_id DESC
+LIMIT :limit OFFSET :offset
+`
+
+const stmtInsert = `
+INSERT INTO audit (
+ audit_actor
+,audit_action
+,audit_target
+,audit_before
+,audit_after
+,audit_ip
+,audit_created
+) VALUES (
+ :audit_actor
+,:audit_action
+,:audit_target
+,:audit_before
+,:audit_after
+,:audit_ip
+,:audit_created
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING audit_id
+`
@@ -0,0 +1,139 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db/dbtest"
+)
+
+var noContext = context.TODO()
+
+func TestAudit(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	store := New(conn).(*auditStore)
+	t.Run("Create", testAuditCreate(store))
+	t.Run("List", testAuditList(store))
+	t.Run("Filter", testAuditFilter(store))
+	t.Run("Page", testAuditPage(store))
+}
+
+func testAuditCreate(store *auditStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		events := []*core.AuditEvent{
+			{
+				Actor:   "octocat",
+				Action:  core.AuditActionRepoUpdate,
+				Target:  "octocat/hello-world",
+				Before:  json.RawMessage(`{"trusted":false}`),
+				After:   json.RawMessage(`{"trusted":true}`),
+				IP:      "192.168.1.1",
+				Created: 1000000000,
+			},
+			{
+				Actor:   "octocat",
+				Action:  core.AuditActionQueuePause,
+				Target:  "queue",
+				Created: 1000000001,
+			},
+			{
+				Actor:   "spaceghost",
+				Action:  core.AuditActionBuildCancel,
+				Target:  "octocat/hello-world#1",
+				Created: 1000000002,
+			},
+		}
+		for _, event := range events {
+			if err := store.Create(noContext, event); err != nil {
+				t.Error(err)
+				return
+			}
+			if event.ID == 0 {
+				t.Errorf("Want audit event ID assigned, got %d", event.ID)
+			}
+		}
+	}
+}
+
+func testAuditList(store *auditStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, core.AuditParams{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 3; got != want {
+			t.Errorf("Want %d events, got %d", want, got)
+			return
+		}
+		// the most recent events are returned first.
+		if got, want := list[0].Actor, "spaceghost"; got != want {
+			t.Errorf("Want actor %q, got %q", want, got)
+		}
+		event := list[2]
+		if got, want := string(event.After), `{"trusted":true}`; got != want {
+			t.Errorf("Want after %s, got %s", want, got)
+		}
+		if got, want := event.IP, "192.168.1.1"; got != want {
+			t.Errorf("Want ip %s, got %s", want, got)
+		}
+		if list[1].Before != nil {
+			t.Errorf("Want empty before omitted, got %s", list[1].Before)
+		}
+	}
+}
+
+func testAuditFilter(store *auditStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		tests := []struct {
+			params core.AuditParams
+			count  int
+		}{
+			{core.AuditParams{Actor: "octocat"}, 2},
+			{core.AuditParams{Action: core.AuditActionBuildCancel}, 1},
+			{core.AuditParams{Target: "octocat/hello-world"}, 1},
+			{core.AuditParams{Actor: "octocat", Action: core.AuditActionQueuePause}, 1},
+			{core.AuditParams{Since: 1000000001}, 2},
+			{core.AuditParams{Until: 1000000001}, 1},
+			{core.AuditParams{Actor: "unknown"}, 0},
+		}
+		for i, test := range tests {
+			list, err := store.List(noContext, test.params)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got, want := len(list), test.count; got != want {
+				t.Errorf("Want %d events, got %d at index %d", want, got, i)
+			}
+		}
+	}
+}
+
+func testAuditPage(store *auditStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, core.AuditParams{Page: 2, Size: 2})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want %d events, got %d", want, got)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// helper function converts the AuditEvent structure to a set
+// of named query parameters.
+func toParams(event *core.AuditEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"audit_id":      event.ID,
+		"audit_actor":   event.Actor,
+		"audit_action":  event.Action,
+		"audit_target":  event.Target,
+		"audit_before":  string(event.Before),
+		"audit_after":   string(event.After),
+		"audit_ip":      event.IP,
+		"audit_created": event.Created,
+	}
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dst *core.AuditEvent) error {
+	var before, after string
+	err := scanner.Scan(
+		&dst.ID,
+		&dst.Actor,
+		&dst.Action,
+		&dst.Target,
+		&before,
+		&after,
+		&dst.IP,
+		&dst.Created,
+	)
+	if before != "" {
+		dst.Before = json.RawMessage(before)
+	}
+	if after != "" {
+		dst.After = json.RawMessage(after)
+	}
+	return err
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.AuditEvent, error) {
+	defer rows.Close()
+
+	events := []*core.AuditEvent{}
+	for rows.Next() {
+		event := new(core.AuditEvent)
+		err := scanRow(rows, event)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -16,6 +16,7 @@ package build
 
 import (
 	"context"
+	"time"
 
 	"github.com/drone/drone/core"
 	"github.com/drone/drone/store/shared/db"
@@ -152,13 +153,19 @@ func (s *buildStore) Running(ctx context.Context) ([]*core.Build, error) {
 
 // Create persists a build to the datacore.
 func (s *buildStore) Create(ctx context.Context, build *core.Build, stages []*core.Stage) error {
+	return s.CreateWithConfig(ctx, build, stages, nil)
+}
+
+// CreateWithConfig persists a build, and the resolved
+// configuration file, to the datastore.
+func (s *buildStore) CreateWithConfig(ctx context.Context, build *core.Build, stages []*core.Stage, config *core.Config) error {
 	if s.db.Driver() == db.Postgres {
-		return s.createPostgres(ctx, build, stages)
+		return s.createPostgres(ctx, build, stages, config)
 	}
-	return s.create(ctx, build, stages)
+	return s.create(ctx, build, stages, config)
 }
 
-func (s *buildStore) create(ctx context.Context, build *core.Build, stages []*core.Stage) error {
+func (s *buildStore) create(ctx context.Context, build *core.Build, stages []*core.Stage, config *core.Config) error {
 	build.Version = 1
 	return s.db.Update(func(execer db.Execer, binder db.Binder) error {
 		params := toParams(build)
@@ -188,12 +195,15 @@ func (s *buildStore) create(ctx context.Context, build *core.Build, stages []*co
 				return err
 			}
 			stage.ID, err = res.LastInsertId()
+			if err != nil {
+				return err
+			}
 		}
-		return err
+		return createConfig(execer, binder, build, config)
 	})
 }
 
-func (s *buildStore) createPostgres(ctx context.Context, build *core.Build, stages []*core.Stage) error {
+func (s *buildStore) createPostgres(ctx context.Context, build *core.Build, stages []*core.Stage, config *core.Config) error {
 	build.Version = 1
 	return s.db.Update(func(execer db.Execer, binder db.Binder) error {
 		params := toParams(build)
@@ -219,10 +229,30 @@ func (s *buildStore) createPostgres(ctx context.Context, build *core.Build, stag
 				return err
 			}
 		}
-		return err
+		return createConfig(execer, binder, build, config)
 	})
 }
 
+// helper function persists the configuration file within the
+// build transaction. The configuration is optional.
+func createConfig(execer db.Execer, binder db.Binder, build *core.Build, config *core.Config) error {
+	if config == nil {
+		return nil
+	}
+	params := map[string]interface{}{
+		"config_build_id": build.ID,
+		"config_kind":     config.Kind,
+		"config_data":     []byte(config.Data),
+		"config_created":  time.Now().Unix(),
+	}
+	stmt, args, err := binder.BindNamed(stmtConfigInsert, params)
+	if err != nil {
+		return err
+	}
+	_, err = execer.Exec(stmt, args...)
+	return err
+}
+
 // Update updates a build in the datacore.
 func (s *buildStore) Update(ctx context.Context, build *core.Build) error {
 	versionNew := build.Version + 1
@@ -573,3 +603,17 @@ DELETE FROM builds
 WHERE build_repo_id = :build_repo_id
 AND build_number < :build_number
 `
+
+const stmtConfigInsert = `
+INSERT INTO configs (
+ config_build_id
+,config_kind
+,config_data
+,config_created
+) VALUES (
+ :config_build_id
+,:config_kind
+,:config_data
+,:config_created
+)
+`
@@ -12,7 +12,9 @@ import (
 	"github.com/drone/drone/store/shared/db"
 	"github.com/drone/drone/core"
 
+	"github.com/drone/drone/store/configs"
 	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/google/go-cmp/cmp"
 )
 
 var noContext = context.TODO()
@@ -30,6 +32,7 @@ func TestBuild(t *testing.T) {
 
 	store := New(conn).(*buildStore)
 	t.Run("Create", testBuildCreate(store))
+	t.Run("CreateWithConfig", testBuildCreateWithConfig(store))
 	t.Run("Purge", testBuildPurge(store))
 	t.Run("Count", testBuildCount(store))
 	t.Run("Pending", testBuildPending(store))
@@ -68,6 +71,38 @@ func testBuildCreate(store *buildStore) func(t *testing.T) {
 	}
 }
 
+func testBuildCreateWithConfig(store *buildStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		build := &core.Build{
+			RepoID: 1,
+			Number: 100,
+			Ref:    "refs/heads/master",
+		}
+		stage := &core.Stage{
+			RepoID: 42,
+			Number: 1,
+		}
+		config := &core.Config{
+			Kind: "pipeline",
+			Data: "kind: pipeline\nname: default\n",
+		}
+		err := store.CreateWithConfig(noContext, build, []*core.Stage{stage}, config)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		result, err := configs.New(store.db).Find(noContext, build.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if diff := cmp.Diff(result, config); diff != "" {
+			t.Errorf(diff)
+		}
+		store.Delete(noContext, build)
+	}
+}
+
 func testBuildFind(store *buildStore, build *core.Build) func(t *testing.T) {
 	return func(t *testing.T) {
 		result, err := store.Find(noContext, build.ID)
@@ -0,0 +1,96 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import (
+	"context"
+	"time"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new ConfigStore.
+func New(db *db.DB) core.ConfigStore {
+	return &configStore{db}
+}
+
+type configStore struct {
+	db *db.DB
+}
+
+func (s *configStore) Find(ctx context.Context, build int64) (*core.Config, error) {
+	out := &configs{BuildID: build}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		query, args, err := binder.BindNamed(queryKey, out)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return &core.Config{
+		Kind: out.Kind,
+		Data: string(out.Data),
+	}, err
+}
+
+func (s *configStore) Create(ctx context.Context, build int64, config *core.Config) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := &configs{
+			BuildID: build,
+			Kind:    config.Kind,
+			Data:    []byte(config.Data),
+			Created: time.Now().Unix(),
+		}
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+type configs struct {
+	BuildID int64  `db:"config_build_id"`
+	Kind    string `db:"config_kind"`
+	Data    []byte `db:"config_data"`
+	Created int64  `db:"config_created"`
+}
+
+const queryKey = `
+SELECT
+ config_build_id
+,config_kind
+,config_data
+,config_created
+FROM configs
+WHERE config_build_id = :config_build_id
+`
+
+const stmtInsert = `
+INSERT INTO configs (
+ config_build_id
+,config_kind
+,config_data
+,config_created
+) VALUES (
+ :config_build_id
+,:config_kind
+,:config_data
+,:config_created
+)
+`
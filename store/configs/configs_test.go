@@ -0,0 +1,97 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package configs
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/build"
+	"github.com/drone/drone/store/repos"
+	"github.com/drone/drone/store/shared/db/dbtest"
+)
+
+var noContext = context.TODO()
+
+func TestConfigs(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seed with a dummy repository
+	arepo := &core.Repository{UID: "1", Slug: "octocat/hello-world"}
+	repos := repos.New(conn)
+	repos.Create(noContext, arepo)
+
+	// seed with a dummy build
+	abuild := &core.Build{Number: 1, RepoID: arepo.ID}
+	builds := build.New(conn)
+	builds.Create(noContext, abuild, nil)
+
+	store := New(conn).(*configStore)
+	t.Run("Create", testConfigsCreate(store, abuild))
+	t.Run("Find", testConfigsFind(store, abuild))
+	t.Run("NotFound", testConfigsNotFound(store))
+	t.Run("Cascade", testConfigsCascade(store, builds, abuild))
+}
+
+func testConfigsCreate(store *configStore, build *core.Build) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Create(noContext, build.ID, &core.Config{
+			Kind: "pipeline",
+			Data: "kind: pipeline\nname: default\n",
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func testConfigsFind(store *configStore, build *core.Build) func(t *testing.T) {
+	return func(t *testing.T) {
+		config, err := store.Find(noContext, build.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := config.Data, "kind: pipeline\nname: default\n"; got != want {
+			t.Errorf("Want config data %q, got %q", want, got)
+		}
+		if got, want := config.Kind, "pipeline"; got != want {
+			t.Errorf("Want config kind %q, got %q", want, got)
+		}
+	}
+}
+
+func testConfigsNotFound(store *configStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		_, err := store.Find(noContext, -1)
+		if got, want := err, sql.ErrNoRows; got != want {
+			t.Errorf("Want sql.ErrNoRows, got %v", got)
+		}
+	}
+}
+
+func testConfigsCascade(store *configStore, builds core.BuildStore, build *core.Build) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := builds.Delete(noContext, build)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = store.Find(noContext, build.ID)
+		if got, want := err, sql.ErrNoRows; got != want {
+			t.Errorf("Want config deleted with build, got %v", got)
+		}
+	}
+}
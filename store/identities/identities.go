@@ -0,0 +1,82 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identities
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new IdentityStore.
+func New(db *db.DB) core.IdentityStore {
+	return &identityStore{db}
+}
+
+type identityStore struct {
+	db *db.DB
+}
+
+func (s *identityStore) Find(ctx context.Context, issuer, subject string) (*core.Identity, error) {
+	out := &core.Identity{Issuer: issuer, Subject: subject}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *identityStore) Create(ctx context.Context, identity *core.Identity) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(identity)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryKey = `
+SELECT
+ identity_user_id
+,identity_issuer
+,identity_subject
+,identity_created
+FROM identities
+WHERE identity_issuer = :identity_issuer
+  AND identity_subject = :identity_subject
+`
+
+const stmtInsert = `
+INSERT INTO identities (
+ identity_user_id
+,identity_issuer
+,identity_subject
+,identity_created
+) VALUES (
+ :identity_user_id
+,:identity_issuer
+,:identity_subject
+,:identity_created
+)
+`
@@ -0,0 +1,94 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package identities
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/drone/drone/store/user"
+)
+
+var noContext = context.TODO()
+
+func TestIdentities(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seed with a dummy user
+	auser := &core.User{Login: "octocat"}
+	users := user.New(conn)
+	users.Create(noContext, auser)
+
+	store := New(conn).(*identityStore)
+	t.Run("Create", testIdentityCreate(store, auser))
+	t.Run("Find", testIdentityFind(store, auser))
+	t.Run("NotFound", testIdentityNotFound(store))
+	t.Run("Duplicate", testIdentityDuplicate(store, auser))
+}
+
+func testIdentityCreate(store *identityStore, user *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Create(noContext, &core.Identity{
+			UserID:  user.ID,
+			Issuer:  "https://accounts.example.com",
+			Subject: "248289761001",
+			Created: 1546300800,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func testIdentityFind(store *identityStore, user *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		identity, err := store.Find(noContext, "https://accounts.example.com", "248289761001")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := identity.UserID, user.ID; got != want {
+			t.Errorf("Want identity user %d, got %d", want, got)
+		}
+		if got, want := identity.Created, int64(1546300800); got != want {
+			t.Errorf("Want identity created %d, got %d", want, got)
+		}
+	}
+}
+
+func testIdentityNotFound(store *identityStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		_, err := store.Find(noContext, "https://accounts.example.com", "unknown")
+		if got, want := err, sql.ErrNoRows; got != want {
+			t.Errorf("Want sql.ErrNoRows, got %v", got)
+		}
+	}
+}
+
+// this test verifies that a user cannot be linked to a
+// second identity from the same issuer.
+func testIdentityDuplicate(store *identityStore, user *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Create(noContext, &core.Identity{
+			UserID:  user.ID,
+			Issuer:  "https://accounts.example.com",
+			Subject: "981727002010",
+		})
+		if err == nil {
+			t.Errorf("Want error linking a second identity from the same issuer")
+		}
+	}
+}
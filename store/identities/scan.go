@@ -0,0 +1,42 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identities
+
+import (
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// helper function converts the Identity structure to a set
+// of named query parameters.
+func toParams(identity *core.Identity) map[string]interface{} {
+	return map[string]interface{}{
+		"identity_user_id": identity.UserID,
+		"identity_issuer":  identity.Issuer,
+		"identity_subject": identity.Subject,
+		"identity_created": identity.Created,
+	}
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dst *core.Identity) error {
+	return scanner.Scan(
+		&dst.UserID,
+		&dst.Issuer,
+		&dst.Subject,
+		&dst.Created,
+	)
+}
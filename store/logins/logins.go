@@ -0,0 +1,129 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logins
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new Login database store.
+func New(db *db.DB) core.LoginStore {
+	return &loginStore{db}
+}
+
+type loginStore struct {
+	db *db.DB
+}
+
+func (s *loginStore) List(ctx context.Context, login string, limit int) ([]*core.LoginEvent, error) {
+	var out []*core.LoginEvent
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"login_login": login,
+			"limit":       limit,
+		}
+		stmt, args, err := binder.BindNamed(queryLogin, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *loginStore) Create(ctx context.Context, event *core.LoginEvent) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, event)
+	}
+	return s.create(ctx, event)
+}
+
+func (s *loginStore) create(ctx context.Context, event *core.LoginEvent) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(event)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		event.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *loginStore) createPostgres(ctx context.Context, event *core.LoginEvent) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(event)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&event.ID)
+	})
+}
+
+const queryLogin = `
+SELECT
+ login_id
+,login_user_id
+,login_login
+,login_provider
+,login_success
+,login_reason
+,login_ip
+,login_agent
+,login_created
+FROM logins
+WHERE login_login = :login_login
+ORDER BY login_id DESC
+LIMIT :limit
+`
+
+const stmtInsert = `
+INSERT INTO logins (
+ login_user_id
+,login_login
+,login_provider
+,login_success
+,login_reason
+,login_ip
+,login_agent
+,login_created
+) VALUES (
+ :login_user_id
+,:login_login
+,:login_provider
+,:login_success
+,:login_reason
+,:login_ip
+,:login_agent
+,:login_created
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING login_id
+`
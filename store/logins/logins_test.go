@@ -0,0 +1,86 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package logins
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db/dbtest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var noContext = context.TODO()
+
+func TestLogins(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	store := New(conn).(*loginStore)
+
+	events := []*core.LoginEvent{
+		{
+			Login:    "octocat",
+			Provider: "scm",
+			Success:  false,
+			Reason:   "Registration is closed",
+			IP:       "192.168.1.1",
+			Agent:    "Mozilla/5.0",
+			Created:  1000000000,
+		},
+		{
+			UserID:   1,
+			Login:    "octocat",
+			Provider: "scm",
+			Success:  true,
+			IP:       "192.168.1.1",
+			Agent:    "Mozilla/5.0",
+			Created:  1000000001,
+		},
+		{
+			UserID:   2,
+			Login:    "spaceghost",
+			Provider: "oidc",
+			Success:  true,
+			Created:  1000000002,
+		},
+	}
+	for _, event := range events {
+		if err := store.Create(noContext, event); err != nil {
+			t.Error(err)
+			return
+		}
+		if event.ID == 0 {
+			t.Errorf("Want login event ID assigned, got %d", event.ID)
+		}
+	}
+
+	list, err := store.List(noContext, "octocat", 10)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if diff := cmp.Diff(list, []*core.LoginEvent{events[1], events[0]}); diff != "" {
+		t.Error(diff)
+	}
+
+	list, err = store.List(noContext, "octocat", 1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want %d login events, got %d", want, got)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logins
+
+import (
+	"database/sql"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// helper function converts the LoginEvent structure to a set
+// of named query parameters.
+func toParams(event *core.LoginEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"login_id":       event.ID,
+		"login_user_id":  event.UserID,
+		"login_login":    event.Login,
+		"login_provider": event.Provider,
+		"login_success":  event.Success,
+		"login_reason":   event.Reason,
+		"login_ip":       event.IP,
+		"login_agent":    event.Agent,
+		"login_created":  event.Created,
+	}
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dst *core.LoginEvent) error {
+	return scanner.Scan(
+		&dst.ID,
+		&dst.UserID,
+		&dst.Login,
+		&dst.Provider,
+		&dst.Success,
+		&dst.Reason,
+		&dst.IP,
+		&dst.Agent,
+		&dst.Created,
+	)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.LoginEvent, error) {
+	defer rows.Close()
+
+	events := []*core.LoginEvent{}
+	for rows.Next() {
+		event := new(core.LoginEvent)
+		err := scanRow(rows, event)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
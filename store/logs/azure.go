@@ -0,0 +1,200 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/core"
+)
+
+// azure blob storage rest api version.
+const azureVersion = "2018-03-28"
+
+// NewAzureEnv returns a new Azure Blob Storage log store. The
+// endpoint may be used to target a local emulator, and defaults
+// to the public blob service endpoint for the account.
+func NewAzureEnv(account, key, container, prefix, endpoint string) (core.LogStore, error) {
+	bucket, err := NewAzure(http.DefaultClient, endpoint, account, key, container)
+	if err != nil {
+		return nil, err
+	}
+	return NewBlob(bucket, prefix), nil
+}
+
+// NewAzure returns a new Bucket backed by Azure Blob Storage,
+// using the REST API with shared key authorization.
+func NewAzure(client *http.Client, endpoint, account, key, container string) (Bucket, error) {
+	secret, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("azure: invalid account key: %s", err)
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", account)
+	}
+	return &azureBucket{
+		client:    client,
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		account:   account,
+		secret:    secret,
+		container: container,
+	}, nil
+}
+
+type azureBucket struct {
+	client    *http.Client
+	endpoint  string
+	account   string
+	secret    []byte
+	container string
+}
+
+func (b *azureBucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", b.blob(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := b.client.Do(b.sign(req).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (b *azureBucket) Put(ctx context.Context, key string, r io.Reader) error {
+	// the put blob operation requires the content length,
+	// and therefore the body is buffered in memory.
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("PUT", b.blob(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	return b.do(ctx, req)
+}
+
+func (b *azureBucket) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequest("DELETE", b.blob(key), nil)
+	if err != nil {
+		return err
+	}
+	return b.do(ctx, req)
+}
+
+func (b *azureBucket) do(ctx context.Context, req *http.Request) error {
+	res, err := b.client.Do(b.sign(req).WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkResponse(res)
+}
+
+func (b *azureBucket) blob(key string) string {
+	return b.endpoint + "/" + url.PathEscape(b.container) + "/" + escapePath(key)
+}
+
+// sign signs the request using the shared key authorization
+// scheme.
+func (b *azureBucket) sign(req *http.Request) *http.Request {
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureVersion)
+
+	length := ""
+	if req.ContentLength > 0 {
+		length = fmt.Sprint(req.ContentLength)
+	}
+	parts := []string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		length,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // date is provided with x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}
+	payload := strings.Join(parts, "\n") + "\n" +
+		canonicalHeaders(req.Header) +
+		canonicalResource(b.account, req.URL)
+
+	h := hmac.New(sha256.New, b.secret)
+	h.Write([]byte(payload))
+	sig := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	req.Header.Set("Authorization", "SharedKey "+b.account+":"+sig)
+	return req
+}
+
+// helper function returns the canonicalized x-ms headers.
+func canonicalHeaders(header http.Header) string {
+	var keys []string
+	for key := range header {
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, "x-ms-") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var buf strings.Builder
+	for _, key := range keys {
+		buf.WriteString(key)
+		buf.WriteString(":")
+		buf.WriteString(strings.TrimSpace(header.Get(key)))
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// helper function returns the canonicalized resource.
+func canonicalResource(account string, u *url.URL) string {
+	res := "/" + account + u.EscapedPath()
+	query := u.Query()
+	var keys []string
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		res += "\n" + strings.ToLower(key) + ":" + strings.Join(values, ",")
+	}
+	return res
+}
+
+// helper function escapes each segment of the path.
+func escapePath(key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
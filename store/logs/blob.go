@@ -0,0 +1,81 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/drone/drone/core"
+)
+
+// Bucket provides access to a generic object storage bucket.
+type Bucket interface {
+	// Get returns the object from the bucket.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Put writes the object to the bucket.
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Delete removes the object from the bucket.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlob returns a new LogStore that persists logs to the
+// object storage bucket.
+func NewBlob(bucket Bucket, prefix string) core.LogStore {
+	return &blobStore{
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+type blobStore struct {
+	bucket Bucket
+	prefix string
+}
+
+func (s *blobStore) Find(ctx context.Context, step int64) (io.ReadCloser, error) {
+	return s.bucket.Get(ctx, s.key(step))
+}
+
+func (s *blobStore) Create(ctx context.Context, step int64, r io.Reader) error {
+	return s.bucket.Put(ctx, s.key(step), r)
+}
+
+func (s *blobStore) Update(ctx context.Context, step int64, r io.Reader) error {
+	return s.Create(ctx, step, r)
+}
+
+func (s *blobStore) Delete(ctx context.Context, step int64) error {
+	err := s.bucket.Delete(ctx, s.key(step))
+	if err == errBlobNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *blobStore) key(step int64) string {
+	return strings.TrimPrefix(path.Join("/", s.prefix, fmt.Sprint(step)), "/")
+}
+
+// helper function returns an error if the http response
+// does not have a successful status code.
+func checkResponse(res *http.Response) error {
+	switch {
+	case res.StatusCode == 404:
+		return errBlobNotFound
+	case res.StatusCode > 299:
+		return fmt.Errorf("blob: unexpected status code %d", res.StatusCode)
+	default:
+		return nil
+	}
+}
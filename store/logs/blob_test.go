@@ -0,0 +1,179 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package logs
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBlob_Key(t *testing.T) {
+	tests := []struct {
+		prefix string
+		result string
+	}{
+		{prefix: "drone/logs", result: "drone/logs/1"},
+		{prefix: "/drone/logs/", result: "drone/logs/1"},
+		{prefix: "", result: "1"},
+	}
+	for _, test := range tests {
+		s := NewBlob(nil, test.prefix).(*blobStore)
+		if got, want := s.key(1), test.result; got != want {
+			t.Errorf("Want key %s, got %s", want, got)
+		}
+	}
+}
+
+func TestGCS(t *testing.T) {
+	fake := newFakeStorage()
+	router := http.NewServeMux()
+	router.HandleFunc("/upload/storage/v1/b/drone/o", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.FormValue("uploadType") != "media" {
+			w.WriteHeader(400)
+			return
+		}
+		fake.put(r.FormValue("name"), r)
+	})
+	router.HandleFunc("/storage/v1/b/drone/o/", func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/drone/o/")
+		switch r.Method {
+		case "GET":
+			if r.FormValue("alt") != "media" {
+				w.WriteHeader(400)
+				return
+			}
+			fake.get(key, w)
+		case "DELETE":
+			fake.delete(key, w)
+		}
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	store, err := NewGCSEnv("drone", "drone/logs", server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLogStore(t, store)
+
+	if got := fake.keys["drone/logs/123456"]; got != nil {
+		t.Errorf("Want object deleted from bucket")
+	}
+	if err := store.Delete(noContext, 1); err != nil {
+		t.Errorf("Want nil error deleting missing object, got %s", err)
+	}
+}
+
+func TestAzure(t *testing.T) {
+	fake := newFakeStorage()
+	router := http.NewServeMux()
+	router.HandleFunc("/devstoreaccount1/drone/", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey devstoreaccount1:") {
+			w.WriteHeader(403)
+			return
+		}
+		if r.Header.Get("x-ms-date") == "" || r.Header.Get("x-ms-version") == "" {
+			w.WriteHeader(400)
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/devstoreaccount1/drone/")
+		switch r.Method {
+		case "PUT":
+			if r.Header.Get("x-ms-blob-type") != "BlockBlob" || r.ContentLength <= 0 {
+				w.WriteHeader(400)
+				return
+			}
+			fake.put(key, r)
+			w.WriteHeader(201)
+		case "GET":
+			fake.get(key, w)
+		case "DELETE":
+			fake.delete(key, w)
+		}
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// the account name and key are the well-known credentials
+	// used by the local storage emulator.
+	store, err := NewAzureEnv(
+		"devstoreaccount1",
+		"Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==",
+		"drone",
+		"drone/logs",
+		server.URL+"/devstoreaccount1",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLogStore(t, store)
+
+	if got := fake.keys["drone/logs/123456"]; got != nil {
+		t.Errorf("Want blob deleted from container")
+	}
+}
+
+func TestAzure_InvalidKey(t *testing.T) {
+	_, err := NewAzureEnv("devstoreaccount1", "not-base64!", "drone", "", "")
+	if err == nil {
+		t.Errorf("Want error with invalid account key")
+	}
+}
+
+func TestCanonicalResource(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://127.0.0.1:10000/devstoreaccount1/drone/logs/1?restype=container&comp=list", nil)
+	got := canonicalResource("devstoreaccount1", req.URL)
+	want := "/devstoreaccount1/devstoreaccount1/drone/logs/1\ncomp:list\nrestype:container"
+	if got != want {
+		t.Errorf("Want canonical resource %q, got %q", want, got)
+	}
+}
+
+// fakeStorage is an in-memory object storage used to emulate
+// the remote storage service in tests.
+type fakeStorage struct {
+	sync.Mutex
+	keys map[string][]byte
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{keys: map[string][]byte{}}
+}
+
+func (s *fakeStorage) put(key string, r *http.Request) {
+	data, _ := ioutil.ReadAll(r.Body)
+	s.Lock()
+	s.keys[key] = data
+	s.Unlock()
+}
+
+func (s *fakeStorage) get(key string, w http.ResponseWriter) {
+	s.Lock()
+	data, ok := s.keys[key]
+	s.Unlock()
+	if !ok {
+		w.WriteHeader(404)
+		return
+	}
+	w.Write(data)
+}
+
+func (s *fakeStorage) delete(key string, w http.ResponseWriter) {
+	s.Lock()
+	_, ok := s.keys[key]
+	delete(s.keys, key)
+	s.Unlock()
+	if !ok {
+		w.WriteHeader(404)
+		return
+	}
+	w.WriteHeader(204)
+}
@@ -0,0 +1,45 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+)
+
+// errBlobNotFound is returned when the object does not exist
+// in the object storage bucket.
+var errBlobNotFound = errors.New("blob: not found")
+
+// IsNotFound reports whether the error returned by the log
+// store indicates the logs do not exist, as opposed to a
+// transient failure reading the logs.
+func IsNotFound(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case err == sql.ErrNoRows,
+		err == errBlobNotFound,
+		os.IsNotExist(err):
+		return true
+	}
+	// the s3 client returns errors that expose an error code,
+	// which is checked without importing the aws packages.
+	if coder, ok := err.(interface{ Code() string }); ok {
+		return coder.Code() == "NoSuchKey"
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package logs
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+)
+
+type codeError string
+
+func (e codeError) Error() string { return string(e) }
+func (e codeError) Code() string  { return string(e) }
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{sql.ErrNoRows, true},
+		{errBlobNotFound, true},
+		{&os.PathError{Op: "open", Path: "/tmp/1", Err: os.ErrNotExist}, true},
+		{codeError("NoSuchKey"), true},
+		{codeError("AccessDenied"), false},
+		{errors.New("connection reset"), false},
+	}
+	for _, test := range tests {
+		if got := IsNotFound(test.err); got != test.want {
+			t.Errorf("Want IsNotFound(%v) %v, got %v", test.err, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/drone/drone/core"
+)
+
+// NewFile returns a new LogStore that persists logs to the
+// local filesystem. The log files are sharded into nested
+// directories by step identifier, to avoid storing a large
+// number of files in a single directory.
+func NewFile(root string) core.LogStore {
+	return &fileStore{root: root}
+}
+
+type fileStore struct {
+	root string
+}
+
+func (s *fileStore) Find(ctx context.Context, step int64) (io.ReadCloser, error) {
+	return os.Open(s.path(step))
+}
+
+func (s *fileStore) Create(ctx context.Context, step int64, r io.Reader) error {
+	path := s.path(step)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// the logs are written to a temporary file and renamed
+	// once complete, so that readers never observe a
+	// partially written file.
+	f, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (s *fileStore) Update(ctx context.Context, step int64, r io.Reader) error {
+	return s.Create(ctx, step, r)
+}
+
+func (s *fileStore) Delete(ctx context.Context, step int64) error {
+	err := os.Remove(s.path(step))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// path returns the file path for the step logs. Steps are
+// sharded by the last four digits of the identifier, for
+// example step 123456 is stored at 56/34/123456.
+func (s *fileStore) path(step int64) string {
+	return filepath.Join(
+		s.root,
+		fmt.Sprintf("%02d", step%100),
+		fmt.Sprintf("%02d", step/100%100),
+		fmt.Sprint(step),
+	)
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drone/drone/core"
+)
+
+func TestFile(t *testing.T) {
+	root, err := ioutil.TempDir("", "drone-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	store := NewFile(root)
+	testLogStore(t, store)
+
+	if _, err := os.Stat(filepath.Join(root, "56", "34", "123456")); err == nil {
+		t.Errorf("Want log file removed")
+	}
+}
+
+func TestFile_Path(t *testing.T) {
+	store := NewFile("/var/lib/drone/logs").(*fileStore)
+	tests := []struct {
+		step int64
+		path string
+	}{
+		{1, "/var/lib/drone/logs/01/00/1"},
+		{123456, "/var/lib/drone/logs/56/34/123456"},
+		{9876543210, "/var/lib/drone/logs/10/32/9876543210"},
+	}
+	for _, test := range tests {
+		if got, want := store.path(test.step), test.path; got != want {
+			t.Errorf("Want path %s, got %s", want, got)
+		}
+	}
+}
+
+func TestFile_DeleteNotFound(t *testing.T) {
+	root, err := ioutil.TempDir("", "drone-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	if err := NewFile(root).Delete(noContext, 1); err != nil {
+		t.Errorf("Want nil error deleting missing logs, got %s", err)
+	}
+}
+
+// helper function that runs the create, find, update and
+// delete lifecycle against the log store.
+func testLogStore(t *testing.T, store core.LogStore) {
+	const step = 123456
+
+	if err := store.Create(noContext, step, bytes.NewBufferString("hello world")); err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := readLogs(t, store.Find, step), "hello world"; got != want {
+		t.Errorf("Want logs %q, got %q", want, got)
+	}
+	if err := store.Update(noContext, step, bytes.NewBufferString("hola mundo")); err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := readLogs(t, store.Find, step), "hola mundo"; got != want {
+		t.Errorf("Want updated logs %q, got %q", want, got)
+	}
+	if err := store.Delete(noContext, step); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := store.Find(noContext, step); err == nil {
+		t.Errorf("Want error finding deleted logs")
+	}
+}
+
+func readLogs(t *testing.T, find func(context.Context, int64) (io.ReadCloser, error), step int64) string {
+	r, err := find(noContext, step)
+	if err != nil {
+		t.Error(err)
+		return ""
+	}
+	defer r.Close()
+	data, _ := ioutil.ReadAll(r)
+	return string(data)
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/drone/drone/core"
+
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	gcsEndpoint = "https://storage.googleapis.com"
+	gcsTokenURL = "https://oauth2.googleapis.com/token"
+	gcsScope    = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+// NewGCSEnv returns a new Google Cloud Storage log store. If
+// the credentials path is provided, requests are authorized
+// using the service account key file. The endpoint may be
+// used to target a local emulator.
+func NewGCSEnv(bucket, prefix, endpoint, credentials string) (core.LogStore, error) {
+	client := http.DefaultClient
+	if credentials != "" {
+		var err error
+		client, err = gcsClient(credentials)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if endpoint == "" {
+		endpoint = gcsEndpoint
+	}
+	return NewBlob(NewGCS(client, endpoint, bucket), prefix), nil
+}
+
+// NewGCS returns a new Bucket backed by Google Cloud Storage,
+// using the JSON API.
+func NewGCS(client *http.Client, endpoint, bucket string) Bucket {
+	return &gcsBucket{
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		bucket:   bucket,
+	}
+}
+
+type gcsBucket struct {
+	client   *http.Client
+	endpoint string
+	bucket   string
+}
+
+func (b *gcsBucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", b.object(key)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := b.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (b *gcsBucket) Put(ctx context.Context, key string, r io.Reader) error {
+	endpoint := b.endpoint + "/upload/storage/v1/b/" +
+		url.PathEscape(b.bucket) + "/o?uploadType=media&name=" +
+		url.QueryEscape(key)
+	req, err := http.NewRequest("POST", endpoint, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return b.do(ctx, req)
+}
+
+func (b *gcsBucket) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequest("DELETE", b.object(key), nil)
+	if err != nil {
+		return err
+	}
+	return b.do(ctx, req)
+}
+
+func (b *gcsBucket) do(ctx context.Context, req *http.Request) error {
+	res, err := b.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkResponse(res)
+}
+
+func (b *gcsBucket) object(key string) string {
+	return b.endpoint + "/storage/v1/b/" +
+		url.PathEscape(b.bucket) + "/o/" +
+		url.PathEscape(key)
+}
+
+// helper function returns an http.Client that authorizes
+// requests using the service account key file.
+func gcsClient(path string) (*http.Client, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key := struct {
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}{}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+	config := &jwt.Config{
+		Email:        key.ClientEmail,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Scopes:       []string{gcsScope},
+		TokenURL:     key.TokenURI,
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = gcsTokenURL
+	}
+	return config.Client(context.Background()), nil
+}
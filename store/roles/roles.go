@@ -0,0 +1,354 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roles
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new Role database store.
+func New(db *db.DB) core.RoleStore {
+	return &roleStore{db}
+}
+
+type roleStore struct {
+	db *db.DB
+}
+
+func (s *roleStore) List(ctx context.Context) ([]*core.Role, error) {
+	var out []*core.Role
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		rows, err := queryer.Query(queryAll)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *roleStore) Find(ctx context.Context, id int64) (*core.Role, error) {
+	out := &core.Role{ID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *roleStore) FindName(ctx context.Context, name string) (*core.Role, error) {
+	out := &core.Role{Name: name}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryName, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *roleStore) Create(ctx context.Context, role *core.Role) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, role)
+	}
+	return s.create(ctx, role)
+}
+
+func (s *roleStore) create(ctx context.Context, role *core.Role) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(role)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		role.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *roleStore) createPostgres(ctx context.Context, role *core.Role) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(role)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&role.ID)
+	})
+}
+
+func (s *roleStore) Update(ctx context.Context, role *core.Role) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(role)
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *roleStore) Delete(ctx context.Context, role *core.Role) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(role)
+		stmt, args, err := binder.BindNamed(stmtDeleteBindings, params)
+		if err != nil {
+			return err
+		}
+		if _, err := execer.Exec(stmt, args...); err != nil {
+			return err
+		}
+		stmt, args, err = binder.BindNamed(stmtDelete, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *roleStore) ListBindings(ctx context.Context) ([]*core.RoleBinding, error) {
+	var out []*core.RoleBinding
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		rows, err := queryer.Query(queryBindings)
+		if err != nil {
+			return err
+		}
+		out, err = scanBindingRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *roleStore) ListBindingRoles(ctx context.Context) ([]*core.RoleBinding, error) {
+	var out []*core.RoleBinding
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		rows, err := queryer.Query(queryBindingRoles)
+		if err != nil {
+			return err
+		}
+		out, err = scanBindingRoleRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *roleStore) FindBinding(ctx context.Context, id int64) (*core.RoleBinding, error) {
+	out := &core.RoleBinding{ID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toBindingParams(out)
+		query, args, err := binder.BindNamed(queryBindingKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanBindingRow(row, out)
+	})
+	return out, err
+}
+
+func (s *roleStore) CreateBinding(ctx context.Context, binding *core.RoleBinding) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createBindingPostgres(ctx, binding)
+	}
+	return s.createBinding(ctx, binding)
+}
+
+func (s *roleStore) createBinding(ctx context.Context, binding *core.RoleBinding) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toBindingParams(binding)
+		stmt, args, err := binder.BindNamed(stmtInsertBinding, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		binding.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *roleStore) createBindingPostgres(ctx context.Context, binding *core.RoleBinding) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toBindingParams(binding)
+		stmt, args, err := binder.BindNamed(stmtInsertBindingPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&binding.ID)
+	})
+}
+
+func (s *roleStore) DeleteBinding(ctx context.Context, binding *core.RoleBinding) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toBindingParams(binding)
+		stmt, args, err := binder.BindNamed(stmtDeleteBinding, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryBase = `
+SELECT
+ role_id
+,role_name
+,role_description
+,role_permissions
+,role_created
+,role_updated
+`
+
+const queryKey = queryBase + `
+FROM roles
+WHERE role_id = :role_id
+LIMIT 1
+`
+
+const queryName = queryBase + `
+FROM roles
+WHERE role_name = :role_name
+LIMIT 1
+`
+
+const queryAll = queryBase + `
+FROM roles
+ORDER BY role_name
+`
+
+const stmtUpdate = `
+UPDATE roles SET
+ role_name = :role_name
+,role_description = :role_description
+,role_permissions = :role_permissions
+,role_updated = :role_updated
+WHERE role_id = :role_id
+`
+
+const stmtDelete = `
+DELETE FROM roles
+WHERE role_id = :role_id
+`
+
+const stmtDeleteBindings = `
+DELETE FROM role_bindings
+WHERE binding_role_id = :role_id
+`
+
+const stmtInsert = `
+INSERT INTO roles (
+ role_name
+,role_description
+,role_permissions
+,role_created
+,role_updated
+) VALUES (
+ :role_name
+,:role_description
+,:role_permissions
+,:role_created
+,:role_updated
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING role_id
+`
+
+const queryBindingBase = `
+SELECT
+ binding_id
+,binding_role_id
+,binding_user
+,binding_org
+,binding_namespace
+,binding_created
+`
+
+const queryBindingKey = queryBindingBase + `
+FROM role_bindings
+WHERE binding_id = :binding_id
+LIMIT 1
+`
+
+const queryBindings = queryBindingBase + `
+FROM role_bindings
+ORDER BY binding_id
+`
+
+const queryBindingRoles = `
+SELECT
+ binding_id
+,binding_role_id
+,binding_user
+,binding_org
+,binding_namespace
+,binding_created
+,role_id
+,role_name
+,role_description
+,role_permissions
+,role_created
+,role_updated
+FROM role_bindings
+INNER JOIN roles ON role_id = binding_role_id
+ORDER BY binding_id
+`
+
+const stmtDeleteBinding = `
+DELETE FROM role_bindings
+WHERE binding_id = :binding_id
+`
+
+const stmtInsertBinding = `
+INSERT INTO role_bindings (
+ binding_role_id
+,binding_user
+,binding_org
+,binding_namespace
+,binding_created
+) VALUES (
+ :binding_role_id
+,:binding_user
+,:binding_org
+,:binding_namespace
+,:binding_created
+)
+`
+
+const stmtInsertBindingPg = stmtInsertBinding + `
+RETURNING binding_id
+`
@@ -0,0 +1,221 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package roles
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db/dbtest"
+)
+
+var noContext = context.TODO()
+
+func TestRole(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	store := New(conn).(*roleStore)
+	t.Run("Create", testRoleCreate(store))
+}
+
+func testRoleCreate(store *roleStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Role{
+			Name:        "release-managers",
+			Description: "approves production deployments",
+			Permissions: []string{"deploy:approve:prod", "queue:pause"},
+			Created:     1000000000,
+			Updated:     1000000000,
+		}
+		err := store.Create(noContext, item)
+		if err != nil {
+			t.Error(err)
+		}
+		if item.ID == 0 {
+			t.Errorf("Want role ID assigned, got %d", item.ID)
+		}
+
+		t.Run("Find", testRoleFind(store, item))
+		t.Run("FindName", testRoleFindName(store))
+		t.Run("List", testRoleList(store))
+		t.Run("Update", testRoleUpdate(store, item))
+		t.Run("Bindings", testRoleBindings(store, item))
+		t.Run("Delete", testRoleDelete(store, item))
+	}
+}
+
+func testRoleFind(store *roleStore, role *core.Role) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.Find(noContext, role.ID)
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testRoleFields(item))
+		}
+	}
+}
+
+func testRoleFindName(store *roleStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.FindName(noContext, "release-managers")
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testRoleFields(item))
+		}
+	}
+}
+
+func testRoleList(store *roleStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		} else {
+			t.Run("Fields", testRoleFields(list[0]))
+		}
+	}
+}
+
+func testRoleUpdate(store *roleStore, role *core.Role) func(t *testing.T) {
+	return func(t *testing.T) {
+		before := *role
+		before.Permissions = []string{"deploy:approve"}
+		before.Updated = 1000000001
+		err := store.Update(noContext, &before)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, err := store.Find(noContext, role.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(after.Permissions), 1; got != want {
+			t.Errorf("Want %d permissions, got %d", want, got)
+		}
+		if got, want := after.Updated, before.Updated; got != want {
+			t.Errorf("Want updated %d, got %d", want, got)
+		}
+	}
+}
+
+func testRoleBindings(store *roleStore, role *core.Role) func(t *testing.T) {
+	return func(t *testing.T) {
+		binding := &core.RoleBinding{
+			RoleID:    role.ID,
+			Org:       "octocat",
+			Namespace: "octocat",
+			Created:   1000000000,
+		}
+		if err := store.CreateBinding(noContext, binding); err != nil {
+			t.Error(err)
+			return
+		}
+		if binding.ID == 0 {
+			t.Errorf("Want binding ID assigned, got %d", binding.ID)
+		}
+		if err := store.CreateBinding(noContext, &core.RoleBinding{RoleID: role.ID, User: "spaceghost"}); err != nil {
+			t.Error(err)
+			return
+		}
+
+		item, err := store.FindBinding(noContext, binding.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := item.RoleID, role.ID; got != want {
+			t.Errorf("Want binding role %d, got %d", want, got)
+		}
+		if got, want := item.Org, "octocat"; got != want {
+			t.Errorf("Want binding org %q, got %q", want, got)
+		}
+		if got, want := item.Namespace, "octocat"; got != want {
+			t.Errorf("Want binding namespace %q, got %q", want, got)
+		}
+
+		list, err := store.ListBindings(noContext)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 2; got != want {
+			t.Errorf("Want %d bindings, got %d", want, got)
+		}
+
+		list, err = store.ListBindingRoles(noContext)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 2; got != want {
+			t.Errorf("Want %d bindings, got %d", want, got)
+			return
+		}
+		if list[0].Role == nil || list[0].Role.ID != role.ID {
+			t.Errorf("Want bound role %d, got %+v", role.ID, list[0].Role)
+		} else if got, want := len(list[0].Role.Permissions), 1; got != want {
+			t.Errorf("Want %d bound role permissions, got %d", want, got)
+		}
+
+		if err := store.DeleteBinding(noContext, binding); err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err := store.FindBinding(noContext, binding.ID); err != sql.ErrNoRows {
+			t.Errorf("Want sql.ErrNoRows, got %v", err)
+		}
+	}
+}
+
+func testRoleDelete(store *roleStore, role *core.Role) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Delete(noContext, role)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err := store.Find(noContext, role.ID); err != sql.ErrNoRows {
+			t.Errorf("Want sql.ErrNoRows, got %v", err)
+		}
+		list, _ := store.ListBindings(noContext)
+		if got, want := len(list), 0; got != want {
+			t.Errorf("Want role bindings deleted with the role, got %d", got)
+		}
+	}
+}
+
+func testRoleFields(role *core.Role) func(t *testing.T) {
+	return func(t *testing.T) {
+		if got, want := role.Name, "release-managers"; got != want {
+			t.Errorf("Want role name %q, got %q", want, got)
+		}
+		if got, want := role.Description, "approves production deployments"; got != want {
+			t.Errorf("Want role description %q, got %q", want, got)
+		}
+		if got, want := len(role.Permissions), 2; got != want {
+			t.Errorf("Want %d permissions, got %d", want, got)
+		}
+		if got, want := role.Created, int64(1000000000); got != want {
+			t.Errorf("Want role created %d, got %d", want, got)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roles
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// helper function converts the Role structure to a set
+// of named query parameters.
+func toParams(role *core.Role) map[string]interface{} {
+	return map[string]interface{}{
+		"role_id":          role.ID,
+		"role_name":        role.Name,
+		"role_description": role.Description,
+		"role_permissions": encodeSlice(role.Permissions),
+		"role_created":     role.Created,
+		"role_updated":     role.Updated,
+	}
+}
+
+// helper function converts the RoleBinding structure to a
+// set of named query parameters.
+func toBindingParams(binding *core.RoleBinding) map[string]interface{} {
+	return map[string]interface{}{
+		"binding_id":        binding.ID,
+		"binding_role_id":   binding.RoleID,
+		"binding_user":      binding.User,
+		"binding_org":       binding.Org,
+		"binding_namespace": binding.Namespace,
+		"binding_created":   binding.Created,
+	}
+}
+
+func encodeSlice(v []string) types.JSONText {
+	raw, _ := json.Marshal(v)
+	return types.JSONText(raw)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dst *core.Role) error {
+	permJSON := types.JSONText{}
+	err := scanner.Scan(
+		&dst.ID,
+		&dst.Name,
+		&dst.Description,
+		&permJSON,
+		&dst.Created,
+		&dst.Updated,
+	)
+	json.Unmarshal(permJSON, &dst.Permissions)
+	return err
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.Role, error) {
+	defer rows.Close()
+
+	roles := []*core.Role{}
+	for rows.Next() {
+		role := new(core.Role)
+		err := scanRow(rows, role)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanBindingRow(scanner db.Scanner, dst *core.RoleBinding) error {
+	return scanner.Scan(
+		&dst.ID,
+		&dst.RoleID,
+		&dst.User,
+		&dst.Org,
+		&dst.Namespace,
+		&dst.Created,
+	)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanBindingRows(rows *sql.Rows) ([]*core.RoleBinding, error) {
+	defer rows.Close()
+
+	bindings := []*core.RoleBinding{}
+	for rows.Next() {
+		binding := new(core.RoleBinding)
+		err := scanBindingRow(rows, binding)
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, binding)
+	}
+	return bindings, nil
+}
+
+// helper function scans the sql.Row and copies the column
+// values of the binding, and the joined role, to the
+// destination objects.
+func scanBindingRoleRows(rows *sql.Rows) ([]*core.RoleBinding, error) {
+	defer rows.Close()
+
+	bindings := []*core.RoleBinding{}
+	for rows.Next() {
+		binding := new(core.RoleBinding)
+		binding.Role = new(core.Role)
+		permJSON := types.JSONText{}
+		err := rows.Scan(
+			&binding.ID,
+			&binding.RoleID,
+			&binding.User,
+			&binding.Org,
+			&binding.Namespace,
+			&binding.Created,
+			&binding.Role.ID,
+			&binding.Role.Name,
+			&binding.Role.Description,
+			&permJSON,
+			&binding.Role.Created,
+			&binding.Role.Updated,
+		)
+		if err != nil {
+			return nil, err
+		}
+		json.Unmarshal(permJSON, &binding.Role.Permissions)
+		bindings = append(bindings, binding)
+	}
+	return bindings, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessions
+
+import (
+	"database/sql"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// helper function converts the Session structure to a set
+// of named query parameters.
+func toParams(session *core.UserSession) map[string]interface{} {
+	return map[string]interface{}{
+		"session_id":        session.ID,
+		"session_user_id":   session.UserID,
+		"session_hash":      session.Hash,
+		"session_device":    session.Device,
+		"session_ip":        session.IP,
+		"session_expires":   session.Expires,
+		"session_last_seen": session.LastSeen,
+		"session_created":   session.Created,
+	}
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRow(scanner db.Scanner, dst *core.UserSession) error {
+	return scanner.Scan(
+		&dst.ID,
+		&dst.UserID,
+		&dst.Hash,
+		&dst.Device,
+		&dst.IP,
+		&dst.Expires,
+		&dst.LastSeen,
+		&dst.Created,
+	)
+}
+
+// helper function scans the sql.Row and copies the column
+// values to the destination object.
+func scanRows(rows *sql.Rows) ([]*core.UserSession, error) {
+	defer rows.Close()
+
+	sessions := []*core.UserSession{}
+	for rows.Next() {
+		session := new(core.UserSession)
+		err := scanRow(rows, session)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
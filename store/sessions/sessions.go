@@ -0,0 +1,235 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessions
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new Session database store.
+func New(db *db.DB) core.SessionStore {
+	return &sessionStore{db}
+}
+
+type sessionStore struct {
+	db *db.DB
+}
+
+func (s *sessionStore) List(ctx context.Context, id int64) ([]*core.UserSession, error) {
+	var out []*core.UserSession
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"session_user_id": id}
+		stmt, args, err := binder.BindNamed(queryUser, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *sessionStore) Find(ctx context.Context, id int64) (*core.UserSession, error) {
+	out := &core.UserSession{ID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *sessionStore) FindHash(ctx context.Context, hash string) (*core.UserSession, error) {
+	out := &core.UserSession{Hash: hash}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryHash, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *sessionStore) Create(ctx context.Context, session *core.UserSession) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, session)
+	}
+	return s.create(ctx, session)
+}
+
+func (s *sessionStore) create(ctx context.Context, session *core.UserSession) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(session)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		session.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *sessionStore) createPostgres(ctx context.Context, session *core.UserSession) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(session)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&session.ID)
+	})
+}
+
+func (s *sessionStore) Update(ctx context.Context, session *core.UserSession) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(session)
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *sessionStore) Delete(ctx context.Context, session *core.UserSession) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(session)
+		stmt, args, err := binder.BindNamed(stmtDelete, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *sessionStore) DeleteUser(ctx context.Context, id int64) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{"session_user_id": id}
+		stmt, args, err := binder.BindNamed(stmtDeleteUser, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *sessionStore) DeleteExpired(ctx context.Context, before int64) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := map[string]interface{}{"session_expires": before}
+		stmt, args, err := binder.BindNamed(stmtDeleteExpired, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryBase = `
+SELECT
+ session_id
+,session_user_id
+,session_hash
+,session_device
+,session_ip
+,session_expires
+,session_last_seen
+,session_created
+`
+
+const queryKey = queryBase + `
+FROM sessions
+WHERE session_id = :session_id
+LIMIT 1
+`
+
+const queryHash = queryBase + `
+FROM sessions
+WHERE session_hash = :session_hash
+LIMIT 1
+`
+
+const queryUser = queryBase + `
+FROM sessions
+WHERE session_user_id = :session_user_id
+ORDER BY session_last_seen DESC
+`
+
+const stmtUpdate = `
+UPDATE sessions SET
+ session_ip = :session_ip
+,session_last_seen = :session_last_seen
+WHERE session_id = :session_id
+`
+
+const stmtDelete = `
+DELETE FROM sessions
+WHERE session_id = :session_id
+`
+
+const stmtDeleteUser = `
+DELETE FROM sessions
+WHERE session_user_id = :session_user_id
+`
+
+const stmtDeleteExpired = `
+DELETE FROM sessions
+WHERE session_expires < :session_expires
+`
+
+const stmtInsert = `
+INSERT INTO sessions (
+ session_user_id
+,session_hash
+,session_device
+,session_ip
+,session_expires
+,session_last_seen
+,session_created
+) VALUES (
+ :session_user_id
+,:session_hash
+,:session_device
+,:session_ip
+,:session_expires
+,:session_last_seen
+,:session_created
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING session_id
+`
@@ -0,0 +1,194 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/drone/drone/store/user"
+)
+
+var noContext = context.TODO()
+
+func TestSession(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seeds the database with a dummy user.
+	account := &core.User{Login: "octocat", Hash: "ulSxuA0FKjNiOFIchk18NNvC6ygSxdtKjiOAS"}
+	users := user.New(conn)
+	if err := users.Create(noContext, account); err != nil {
+		t.Error(err)
+	}
+
+	store := New(conn).(*sessionStore)
+	t.Run("Create", testSessionCreate(store, users, account))
+}
+
+func testSessionCreate(store *sessionStore, users core.UserStore, account *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.UserSession{
+			UserID:   account.ID,
+			Hash:     core.HashToken("correct-horse-battery-staple"),
+			Device:   "Mozilla/5.0",
+			IP:       "192.168.1.1",
+			Expires:  2000000000,
+			LastSeen: 1000000000,
+			Created:  1000000000,
+		}
+		err := store.Create(noContext, item)
+		if err != nil {
+			t.Error(err)
+		}
+		if item.ID == 0 {
+			t.Errorf("Want session ID assigned, got %d", item.ID)
+		}
+
+		t.Run("Find", testSessionFind(store, item))
+		t.Run("FindHash", testSessionFindHash(store))
+		t.Run("List", testSessionList(store, account))
+		t.Run("Update", testSessionUpdate(store, item))
+		t.Run("Delete", testSessionDelete(store, item))
+		t.Run("DeleteExpired", testSessionDeleteExpired(store, account))
+		t.Run("DeleteUser", testSessionDeleteUser(store, account))
+	}
+}
+
+func testSessionFind(store *sessionStore, session *core.UserSession) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.Find(noContext, session.ID)
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testUserSession(item))
+		}
+	}
+}
+
+func testSessionFindHash(store *sessionStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.FindHash(noContext, core.HashToken("correct-horse-battery-staple"))
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testUserSession(item))
+		}
+	}
+}
+
+func testSessionList(store *sessionStore, account *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, account.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		} else {
+			t.Run("Fields", testUserSession(list[0]))
+		}
+	}
+}
+
+func testSessionUpdate(store *sessionStore, session *core.UserSession) func(t *testing.T) {
+	return func(t *testing.T) {
+		before, err := store.Find(noContext, session.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		before.LastSeen = 1000000001
+		err = store.Update(noContext, before)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, err := store.Find(noContext, before.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := after.LastSeen, before.LastSeen; got != want {
+			t.Errorf("Want session LastSeen %d, got %d", want, got)
+		}
+	}
+}
+
+func testSessionDelete(store *sessionStore, session *core.UserSession) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Delete(noContext, session)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = store.Find(noContext, session.ID)
+		if got, want := sql.ErrNoRows, err; got != want {
+			t.Errorf("Want sql.ErrNoRows, got %v", got)
+			return
+		}
+	}
+}
+
+func testSessionDeleteExpired(store *sessionStore, account *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		store.Create(noContext, &core.UserSession{UserID: account.ID, Hash: "a", Expires: 1000000000})
+		store.Create(noContext, &core.UserSession{UserID: account.ID, Hash: "b", Expires: 2000000000})
+
+		err := store.DeleteExpired(noContext, 1500000000)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, _ := store.List(noContext, account.ID)
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		}
+	}
+}
+
+func testSessionDeleteUser(store *sessionStore, account *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		store.Create(noContext, &core.UserSession{UserID: account.ID, Hash: "c", Expires: 2000000000})
+
+		err := store.DeleteUser(noContext, account.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, _ := store.List(noContext, account.ID)
+		if len(list) != 0 {
+			t.Errorf("Want empty session list")
+		}
+	}
+}
+
+func testUserSession(item *core.UserSession) func(t *testing.T) {
+	return func(t *testing.T) {
+		if got, want := item.Hash, core.HashToken("correct-horse-battery-staple"); got != want {
+			t.Errorf("Want session hash %q, got %q", want, got)
+		}
+		if got, want := item.Device, "Mozilla/5.0"; got != want {
+			t.Errorf("Want session device %q, got %q", want, got)
+		}
+		if got, want := item.IP, "192.168.1.1"; got != want {
+			t.Errorf("Want session ip %q, got %q", want, got)
+		}
+		if got, want := item.Expires, int64(2000000000); got != want {
+			t.Errorf("Want session expires %d, got %d", want, got)
+		}
+	}
+}
@@ -44,6 +44,16 @@ func Connect() (*db.DB, error) {
 func Reset(d *db.DB) {
 	d.Lock(func(tx db.Execer, _ db.Binder) error {
 		tx.Exec("DELETE FROM cron")
+		tx.Exec("DELETE FROM tokens")
+		tx.Exec("DELETE FROM sessions")
+		tx.Exec("DELETE FROM role_bindings")
+		tx.Exec("DELETE FROM roles")
+		tx.Exec("DELETE FROM audit")
+		tx.Exec("DELETE FROM logins")
+		tx.Exec("DELETE FROM approvals")
+		tx.Exec("DELETE FROM approval_rules")
+		tx.Exec("DELETE FROM configs")
+		tx.Exec("DELETE FROM identities")
 		tx.Exec("DELETE FROM logs")
 		tx.Exec("DELETE FROM steps")
 		tx.Exec("DELETE FROM stages")
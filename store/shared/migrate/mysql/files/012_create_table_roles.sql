@@ -0,0 +1,24 @@
+-- name: create-table-roles
+
+CREATE TABLE IF NOT EXISTS roles (
+ role_id          INTEGER PRIMARY KEY AUTO_INCREMENT
+,role_name        VARCHAR(50)
+,role_description VARCHAR(500)
+,role_permissions TEXT
+,role_created     INTEGER
+,role_updated     INTEGER
+,UNIQUE(role_name)
+);
+
+-- name: create-table-role-bindings
+
+CREATE TABLE IF NOT EXISTS role_bindings (
+ binding_id        INTEGER PRIMARY KEY AUTO_INCREMENT
+,binding_role_id   INTEGER
+,binding_user      VARCHAR(250)
+,binding_org       VARCHAR(250)
+,binding_namespace VARCHAR(250)
+,binding_created   INTEGER
+,UNIQUE(binding_role_id, binding_user, binding_org, binding_namespace)
+,FOREIGN KEY(binding_role_id) REFERENCES roles(role_id) ON DELETE CASCADE
+);
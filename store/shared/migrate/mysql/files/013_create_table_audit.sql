@@ -0,0 +1,24 @@
+-- name: create-table-audit
+
+CREATE TABLE IF NOT EXISTS audit (
+ audit_id      INTEGER PRIMARY KEY AUTO_INCREMENT
+,audit_actor   VARCHAR(250)
+,audit_action  VARCHAR(50)
+,audit_target  VARCHAR(250)
+,audit_before  TEXT
+,audit_after   TEXT
+,audit_ip      VARCHAR(50)
+,audit_created INTEGER
+);
+
+-- name: create-index-audit-created
+
+CREATE INDEX ix_audit_created ON audit (audit_created);
+
+-- name: create-index-audit-actor
+
+CREATE INDEX ix_audit_actor ON audit (audit_actor);
+
+-- name: create-index-audit-target
+
+CREATE INDEX ix_audit_target ON audit (audit_target);
@@ -0,0 +1,22 @@
+-- name: create-table-sessions
+
+CREATE TABLE IF NOT EXISTS sessions (
+ session_id        INTEGER PRIMARY KEY AUTO_INCREMENT
+,session_user_id   INTEGER
+,session_hash      VARCHAR(250)
+,session_device    VARCHAR(500)
+,session_ip        VARCHAR(250)
+,session_expires   INTEGER
+,session_last_seen INTEGER
+,session_created   INTEGER
+,UNIQUE(session_hash)
+,FOREIGN KEY(session_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- name: create-index-sessions-user
+
+CREATE INDEX ix_sessions_user ON sessions (session_user_id);
+
+-- name: create-index-sessions-expires
+
+CREATE INDEX ix_sessions_expires ON sessions (session_expires);
@@ -0,0 +1,17 @@
+-- name: create-table-logins
+
+CREATE TABLE IF NOT EXISTS logins (
+ login_id       INTEGER PRIMARY KEY AUTO_INCREMENT
+,login_user_id  INTEGER
+,login_login    VARCHAR(250)
+,login_provider VARCHAR(50)
+,login_success  BOOLEAN
+,login_reason   VARCHAR(2000)
+,login_ip       VARCHAR(250)
+,login_agent    VARCHAR(500)
+,login_created  INTEGER
+);
+
+-- name: create-index-logins-login
+
+CREATE INDEX ix_logins_login ON logins (login_login);
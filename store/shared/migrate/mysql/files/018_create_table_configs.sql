@@ -0,0 +1,9 @@
+-- name: create-table-configs
+
+CREATE TABLE IF NOT EXISTS configs (
+ config_build_id INTEGER PRIMARY KEY
+,config_kind     VARCHAR(50)
+,config_data     MEDIUMBLOB
+,config_created  INTEGER
+,FOREIGN KEY(config_build_id) REFERENCES builds(build_id) ON DELETE CASCADE
+);
@@ -0,0 +1,11 @@
+-- name: create-table-identities
+
+CREATE TABLE IF NOT EXISTS identities (
+ identity_user_id INTEGER
+,identity_issuer  VARCHAR(250)
+,identity_subject VARCHAR(250)
+,identity_created INTEGER
+,PRIMARY KEY(identity_issuer, identity_subject)
+,UNIQUE(identity_user_id, identity_issuer)
+,FOREIGN KEY(identity_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
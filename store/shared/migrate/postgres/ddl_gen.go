@@ -80,6 +80,10 @@ var migrations = []struct {
 		name: "create-index-steps-stage",
 		stmt: createIndexStepsStage,
 	},
+	{
+		name: "alter-table-steps-add-column-log-truncated",
+		stmt: alterTableStepsAddColumnLogTruncated,
+	},
 	{
 		name: "create-table-logs",
 		stmt: createTableLogs,
@@ -112,6 +116,78 @@ var migrations = []struct {
 		name: "create-table-nodes",
 		stmt: createTableNodes,
 	},
+	{
+		name: "create-table-tokens",
+		stmt: createTableTokens,
+	},
+	{
+		name: "create-index-tokens-user",
+		stmt: createIndexTokensUser,
+	},
+	{
+		name: "create-table-roles",
+		stmt: createTableRoles,
+	},
+	{
+		name: "create-table-role-bindings",
+		stmt: createTableRoleBindings,
+	},
+	{
+		name: "create-table-audit",
+		stmt: createTableAudit,
+	},
+	{
+		name: "create-index-audit-created",
+		stmt: createIndexAuditCreated,
+	},
+	{
+		name: "create-index-audit-actor",
+		stmt: createIndexAuditActor,
+	},
+	{
+		name: "create-index-audit-target",
+		stmt: createIndexAuditTarget,
+	},
+	{
+		name: "create-table-sessions",
+		stmt: createTableSessions,
+	},
+	{
+		name: "create-index-sessions-user",
+		stmt: createIndexSessionsUser,
+	},
+	{
+		name: "create-index-sessions-expires",
+		stmt: createIndexSessionsExpires,
+	},
+	{
+		name: "alter-table-users-add-column-namespace",
+		stmt: alterTableUsersAddColumnNamespace,
+	},
+	{
+		name: "create-table-approvals",
+		stmt: createTableApprovals,
+	},
+	{
+		name: "create-table-approval-rules",
+		stmt: createTableApprovalRules,
+	},
+	{
+		name: "create-table-logins",
+		stmt: createTableLogins,
+	},
+	{
+		name: "create-index-logins-login",
+		stmt: createIndexLoginsLogin,
+	},
+	{
+		name: "create-table-configs",
+		stmt: createTableConfigs,
+	},
+	{
+		name: "create-table-identities",
+		stmt: createTableIdentities,
+	},
 }
 
 // Migrate performs the database migration. If the migration fails
@@ -417,6 +493,10 @@ var createIndexStepsStage = `
 CREATE INDEX IF NOT EXISTS ix_steps_stage ON steps (step_stage_id);
 `
 
+var alterTableStepsAddColumnLogTruncated = `
+ALTER TABLE steps ADD COLUMN step_log_truncated BOOLEAN NOT NULL DEFAULT false;
+`
+
 //
 // 007_create_table_logs.sql
 //
@@ -522,3 +602,204 @@ CREATE TABLE IF NOT EXISTS nodes (
 ,UNIQUE(node_name)
 );
 `
+
+//
+// 011_create_table_tokens.sql
+//
+
+var createTableTokens = `
+CREATE TABLE IF NOT EXISTS tokens (
+ token_id        SERIAL PRIMARY KEY
+,token_user_id   INTEGER
+,token_name      VARCHAR(50)
+,token_hash      VARCHAR(250)
+,token_scope     VARCHAR(50)
+,token_repo      VARCHAR(250)
+,token_expires   INTEGER
+,token_last_used INTEGER
+,token_created   INTEGER
+,UNIQUE(token_user_id, token_name)
+,UNIQUE(token_hash)
+,FOREIGN KEY(token_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+`
+
+var createIndexTokensUser = `
+CREATE INDEX IF NOT EXISTS ix_tokens_user ON tokens (token_user_id);
+`
+
+//
+// 012_create_table_roles.sql
+//
+
+var createTableRoles = `
+CREATE TABLE IF NOT EXISTS roles (
+ role_id          SERIAL PRIMARY KEY
+,role_name        VARCHAR(50)
+,role_description VARCHAR(500)
+,role_permissions TEXT
+,role_created     INTEGER
+,role_updated     INTEGER
+,UNIQUE(role_name)
+);
+`
+
+var createTableRoleBindings = `
+CREATE TABLE IF NOT EXISTS role_bindings (
+ binding_id        SERIAL PRIMARY KEY
+,binding_role_id   INTEGER
+,binding_user      VARCHAR(250)
+,binding_org       VARCHAR(250)
+,binding_namespace VARCHAR(250)
+,binding_created   INTEGER
+,UNIQUE(binding_role_id, binding_user, binding_org, binding_namespace)
+,FOREIGN KEY(binding_role_id) REFERENCES roles(role_id) ON DELETE CASCADE
+);
+`
+
+//
+// 013_create_table_audit.sql
+//
+
+var createTableAudit = `
+CREATE TABLE IF NOT EXISTS audit (
+ audit_id      SERIAL PRIMARY KEY
+,audit_actor   VARCHAR(250)
+,audit_action  VARCHAR(50)
+,audit_target  VARCHAR(250)
+,audit_before  TEXT
+,audit_after   TEXT
+,audit_ip      VARCHAR(50)
+,audit_created INTEGER
+);
+`
+
+var createIndexAuditCreated = `
+CREATE INDEX IF NOT EXISTS ix_audit_created ON audit (audit_created);
+`
+
+var createIndexAuditActor = `
+CREATE INDEX IF NOT EXISTS ix_audit_actor ON audit (audit_actor);
+`
+
+var createIndexAuditTarget = `
+CREATE INDEX IF NOT EXISTS ix_audit_target ON audit (audit_target);
+`
+
+//
+// 014_create_table_sessions.sql
+//
+
+var createTableSessions = `
+CREATE TABLE IF NOT EXISTS sessions (
+ session_id        SERIAL PRIMARY KEY
+,session_user_id   INTEGER
+,session_hash      VARCHAR(250)
+,session_device    VARCHAR(500)
+,session_ip        VARCHAR(250)
+,session_expires   INTEGER
+,session_last_seen INTEGER
+,session_created   INTEGER
+,UNIQUE(session_hash)
+,FOREIGN KEY(session_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+`
+
+var createIndexSessionsUser = `
+CREATE INDEX IF NOT EXISTS ix_sessions_user ON sessions (session_user_id);
+`
+
+var createIndexSessionsExpires = `
+CREATE INDEX IF NOT EXISTS ix_sessions_expires ON sessions (session_expires);
+`
+
+//
+// 015_alter_table_users_add_namespace.sql
+//
+
+var alterTableUsersAddColumnNamespace = `
+ALTER TABLE users ADD COLUMN user_namespace VARCHAR(250) NOT NULL DEFAULT '';
+`
+
+//
+// 016_create_table_approvals.sql
+//
+
+var createTableApprovals = `
+CREATE TABLE IF NOT EXISTS approvals (
+ approval_id       SERIAL PRIMARY KEY
+,approval_stage_id INTEGER
+,approval_approver VARCHAR(250)
+,approval_approved BOOLEAN
+,approval_comment  VARCHAR(2000)
+,approval_created  INTEGER
+,UNIQUE(approval_stage_id, approval_approver)
+,FOREIGN KEY(approval_stage_id) REFERENCES stages(stage_id) ON DELETE CASCADE
+);
+`
+
+var createTableApprovalRules = `
+CREATE TABLE IF NOT EXISTS approval_rules (
+ rule_id         SERIAL PRIMARY KEY
+,rule_repo       VARCHAR(250)
+,rule_target     VARCHAR(250)
+,rule_protected  BOOLEAN
+,rule_approvals  INTEGER
+,rule_allow_self BOOLEAN
+,rule_groups     TEXT
+,rule_created    INTEGER
+,rule_updated    INTEGER
+);
+`
+
+//
+// 017_create_table_logins.sql
+//
+
+var createTableLogins = `
+CREATE TABLE IF NOT EXISTS logins (
+ login_id       SERIAL PRIMARY KEY
+,login_user_id  INTEGER
+,login_login    VARCHAR(250)
+,login_provider VARCHAR(50)
+,login_success  BOOLEAN
+,login_reason   VARCHAR(2000)
+,login_ip       VARCHAR(250)
+,login_agent    VARCHAR(500)
+,login_created  INTEGER
+);
+`
+
+var createIndexLoginsLogin = `
+CREATE INDEX IF NOT EXISTS ix_logins_login ON logins (login_login);
+`
+
+//
+// 018_create_table_configs.sql
+//
+
+var createTableConfigs = `
+CREATE TABLE IF NOT EXISTS configs (
+ config_build_id INTEGER PRIMARY KEY
+,config_kind     VARCHAR(50)
+,config_data     BYTEA
+,config_created  INTEGER
+,FOREIGN KEY(config_build_id) REFERENCES builds(build_id) ON DELETE CASCADE
+);
+`
+
+//
+// 019_create_table_identities.sql
+//
+
+var createTableIdentities = `
+CREATE TABLE IF NOT EXISTS identities (
+ identity_user_id INTEGER
+,identity_issuer  VARCHAR(250)
+,identity_subject VARCHAR(250)
+,identity_created INTEGER
+,PRIMARY KEY(identity_issuer, identity_subject)
+,UNIQUE(identity_user_id, identity_issuer)
+,FOREIGN KEY(identity_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+`
@@ -0,0 +1,26 @@
+-- name: create-table-approvals
+
+CREATE TABLE IF NOT EXISTS approvals (
+ approval_id       SERIAL PRIMARY KEY
+,approval_stage_id INTEGER
+,approval_approver VARCHAR(250)
+,approval_approved BOOLEAN
+,approval_comment  VARCHAR(2000)
+,approval_created  INTEGER
+,UNIQUE(approval_stage_id, approval_approver)
+,FOREIGN KEY(approval_stage_id) REFERENCES stages(stage_id) ON DELETE CASCADE
+);
+
+-- name: create-table-approval-rules
+
+CREATE TABLE IF NOT EXISTS approval_rules (
+ rule_id         SERIAL PRIMARY KEY
+,rule_repo       VARCHAR(250)
+,rule_target     VARCHAR(250)
+,rule_protected  BOOLEAN
+,rule_approvals  INTEGER
+,rule_allow_self BOOLEAN
+,rule_groups     TEXT
+,rule_created    INTEGER
+,rule_updated    INTEGER
+);
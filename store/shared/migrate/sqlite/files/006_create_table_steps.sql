@@ -19,3 +19,7 @@ CREATE TABLE IF NOT EXISTS steps (
 -- name: create-index-steps-stage
 
 CREATE INDEX IF NOT EXISTS ix_steps_stage ON steps (step_stage_id);
+
+-- name: alter-table-steps-add-column-log-truncated
+
+ALTER TABLE steps ADD COLUMN step_log_truncated BOOLEAN NOT NULL DEFAULT 0;
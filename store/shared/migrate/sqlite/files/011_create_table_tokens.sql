@@ -0,0 +1,20 @@
+-- name: create-table-tokens
+
+CREATE TABLE IF NOT EXISTS tokens (
+ token_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,token_user_id   INTEGER
+,token_name      TEXT
+,token_hash      TEXT
+,token_scope     TEXT
+,token_repo      TEXT
+,token_expires   INTEGER
+,token_last_used INTEGER
+,token_created   INTEGER
+,UNIQUE(token_user_id, token_name)
+,UNIQUE(token_hash)
+,FOREIGN KEY(token_user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- name: create-index-tokens-user
+
+CREATE INDEX IF NOT EXISTS ix_tokens_user ON tokens (token_user_id);
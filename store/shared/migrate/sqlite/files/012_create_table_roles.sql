@@ -0,0 +1,24 @@
+-- name: create-table-roles
+
+CREATE TABLE IF NOT EXISTS roles (
+ role_id          INTEGER PRIMARY KEY AUTOINCREMENT
+,role_name        TEXT
+,role_description TEXT
+,role_permissions TEXT
+,role_created     INTEGER
+,role_updated     INTEGER
+,UNIQUE(role_name)
+);
+
+-- name: create-table-role-bindings
+
+CREATE TABLE IF NOT EXISTS role_bindings (
+ binding_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,binding_role_id   INTEGER
+,binding_user      TEXT
+,binding_org       TEXT
+,binding_namespace TEXT
+,binding_created   INTEGER
+,UNIQUE(binding_role_id, binding_user, binding_org, binding_namespace)
+,FOREIGN KEY(binding_role_id) REFERENCES roles(role_id) ON DELETE CASCADE
+);
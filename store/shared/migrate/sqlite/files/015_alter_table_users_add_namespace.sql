@@ -0,0 +1,3 @@
+-- name: alter-table-users-add-column-namespace
+
+ALTER TABLE users ADD COLUMN user_namespace TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,26 @@
+-- name: create-table-approvals
+
+CREATE TABLE IF NOT EXISTS approvals (
+ approval_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,approval_stage_id INTEGER
+,approval_approver TEXT
+,approval_approved BOOLEAN
+,approval_comment  TEXT
+,approval_created  INTEGER
+,UNIQUE(approval_stage_id, approval_approver)
+,FOREIGN KEY(approval_stage_id) REFERENCES stages(stage_id) ON DELETE CASCADE
+);
+
+-- name: create-table-approval-rules
+
+CREATE TABLE IF NOT EXISTS approval_rules (
+ rule_id         INTEGER PRIMARY KEY AUTOINCREMENT
+,rule_repo       TEXT
+,rule_target     TEXT
+,rule_protected  BOOLEAN
+,rule_approvals  INTEGER
+,rule_allow_self BOOLEAN
+,rule_groups     TEXT
+,rule_created    INTEGER
+,rule_updated    INTEGER
+);
@@ -0,0 +1,17 @@
+-- name: create-table-logins
+
+CREATE TABLE IF NOT EXISTS logins (
+ login_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,login_user_id  INTEGER
+,login_login    TEXT
+,login_provider TEXT
+,login_success  BOOLEAN
+,login_reason   TEXT
+,login_ip       TEXT
+,login_agent    TEXT
+,login_created  INTEGER
+);
+
+-- name: create-index-logins-login
+
+CREATE INDEX IF NOT EXISTS ix_logins_login ON logins (login_login);
@@ -148,6 +148,7 @@ func scanRowStep(scanner db.Scanner, stage *core.Stage, step *nullStep) error {
 		&step.Started,
 		&step.Stopped,
 		&step.Version,
+		&step.Truncated,
 	)
 	json.Unmarshal(depJSON, &stage.DependsOn)
 	json.Unmarshal(labJSON, &stage.Labels)
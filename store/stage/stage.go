@@ -321,6 +321,7 @@ SELECT
 ,step_started
 ,step_stopped
 ,step_version
+,step_log_truncated
 FROM stages
   LEFT JOIN steps
 	ON stages.stage_id=steps.step_stage_id
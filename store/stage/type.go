@@ -32,20 +32,22 @@ type nullStep struct {
 	Started   sql.NullInt64
 	Stopped   sql.NullInt64
 	Version   sql.NullInt64
+	Truncated sql.NullBool
 }
 
 func (s *nullStep) value() *core.Step {
 	return &core.Step{
-		ID:        s.ID.Int64,
-		StageID:   s.StageID.Int64,
-		Number:    int(s.Number.Int64),
-		Name:      s.Name.String,
-		Status:    s.Status.String,
-		Error:     s.Error.String,
-		ErrIgnore: s.ErrIgnore.Bool,
-		ExitCode:  int(s.ExitCode.Int64),
-		Started:   s.Started.Int64,
-		Stopped:   s.Stopped.Int64,
-		Version:   s.Version.Int64,
+		ID:           s.ID.Int64,
+		StageID:      s.StageID.Int64,
+		Number:       int(s.Number.Int64),
+		Name:         s.Name.String,
+		Status:       s.Status.String,
+		Error:        s.Error.String,
+		ErrIgnore:    s.ErrIgnore.Bool,
+		ExitCode:     int(s.ExitCode.Int64),
+		Started:      s.Started.Int64,
+		Stopped:      s.Stopped.Int64,
+		Version:      s.Version.Int64,
+		LogTruncated: s.Truncated.Bool,
 	}
 }
@@ -25,17 +25,18 @@ import (
 // of named query parameters.
 func toParams(from *core.Step) map[string]interface{} {
 	return map[string]interface{}{
-		"step_id":        from.ID,
-		"step_stage_id":  from.StageID,
-		"step_number":    from.Number,
-		"step_name":      from.Name,
-		"step_status":    from.Status,
-		"step_error":     from.Error,
-		"step_errignore": from.ErrIgnore,
-		"step_exit_code": from.ExitCode,
-		"step_started":   from.Started,
-		"step_stopped":   from.Stopped,
-		"step_version":   from.Version,
+		"step_id":            from.ID,
+		"step_stage_id":      from.StageID,
+		"step_number":        from.Number,
+		"step_name":          from.Name,
+		"step_status":        from.Status,
+		"step_error":         from.Error,
+		"step_errignore":     from.ErrIgnore,
+		"step_exit_code":     from.ExitCode,
+		"step_started":       from.Started,
+		"step_stopped":       from.Stopped,
+		"step_version":       from.Version,
+		"step_log_truncated": from.LogTruncated,
 	}
 }
 
@@ -54,6 +55,7 @@ func scanRow(scanner db.Scanner, dest *core.Step) error {
 		&dest.Started,
 		&dest.Stopped,
 		&dest.Version,
+		&dest.LogTruncated,
 	)
 }
 
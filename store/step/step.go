@@ -48,6 +48,27 @@ func (s *stepStore) List(ctx context.Context, id int64) ([]*core.Step, error) {
 	return out, err
 }
 
+func (s *stepStore) ListAfter(ctx context.Context, id int64, limit int) ([]*core.Step, error) {
+	var out []*core.Step
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{
+			"step_id": id,
+			"limit":   limit,
+		}
+		stmt, args, err := binder.BindNamed(queryAfter, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
 func (s *stepStore) Find(ctx context.Context, id int64) (*core.Step, error) {
 	out := &core.Step{ID: id}
 	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
@@ -156,6 +177,7 @@ SELECT
 ,step_started
 ,step_stopped
 ,step_version
+,step_log_truncated
 `
 
 const queryKey = queryBase + `
@@ -174,6 +196,13 @@ FROM steps
 WHERE step_stage_id = :step_stage_id
 `
 
+const queryAfter = queryBase + `
+FROM steps
+WHERE step_id > :step_id
+ORDER BY step_id ASC
+LIMIT :limit
+`
+
 const stmtUpdate = `
 UPDATE steps
 SET
@@ -184,6 +213,7 @@ SET
 ,step_exit_code = :step_exit_code
 ,step_started = :step_started
 ,step_stopped = :step_stopped
+,step_log_truncated = :step_log_truncated
 ,step_version = :step_version_new
 WHERE step_id = :step_id
   AND step_version = :step_version_old
@@ -201,6 +231,7 @@ INSERT INTO steps (
 ,step_started
 ,step_stopped
 ,step_version
+,step_log_truncated
 ) VALUES (
  :step_stage_id
 ,:step_number
@@ -212,6 +243,7 @@ INSERT INTO steps (
 ,:step_started
 ,:step_stopped
 ,:step_version
+,:step_log_truncated
 )
 `
 
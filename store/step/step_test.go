@@ -73,6 +73,7 @@ func testStepCreate(store *stepStore, stage *core.Stage) func(t *testing.T) {
 		t.Run("Find", testStepFind(store, item))
 		t.Run("FindNumber", testStepFindNumber(store, item))
 		t.Run("List", testStepList(store, stage))
+		t.Run("ListAfter", testStepListAfter(store, item))
 		t.Run("Update", testStepUpdate(store, item))
 		t.Run("Locking", testStepLocking(store, item))
 	}
@@ -115,6 +116,30 @@ func testStepList(store *stepStore, stage *core.Stage) func(t *testing.T) {
 	}
 }
 
+func testStepListAfter(store *stepStore, step *core.Step) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.ListAfter(noContext, 0, 10)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		} else {
+			t.Run("Fields", testStep(list[0]))
+		}
+
+		list, err = store.ListAfter(noContext, step.ID, 10)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 0; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		}
+	}
+}
+
 func testStepUpdate(store *stepStore, step *core.Step) func(t *testing.T) {
 	return func(t *testing.T) {
 		before := &core.Step{
@@ -127,6 +152,8 @@ func testStepUpdate(store *stepStore, step *core.Step) func(t *testing.T) {
 			Stopped:  1522878690,
 			Status:   core.StatusFailing,
 			Version:  step.Version,
+
+			LogTruncated: true,
 		}
 		err := store.Update(noContext, before)
 		if err != nil {
@@ -147,6 +174,9 @@ func testStepUpdate(store *stepStore, step *core.Step) func(t *testing.T) {
 		if got, want := after.ExitCode, before.ExitCode; got != want {
 			t.Errorf("Want updated ExitCode %v, got %v", want, got)
 		}
+		if got, want := after.LogTruncated, before.LogTruncated; got != want {
+			t.Errorf("Want updated LogTruncated %v, got %v", want, got)
+		}
 		if got, want := after.Status, before.Status; got != want {
 			t.Errorf("Want updated Status %v, got %v", want, got)
 		}
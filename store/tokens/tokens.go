@@ -0,0 +1,207 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokens
+
+import (
+	"context"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db"
+)
+
+// New returns a new Token database store.
+func New(db *db.DB) core.TokenStore {
+	return &tokenStore{db}
+}
+
+type tokenStore struct {
+	db *db.DB
+}
+
+func (s *tokenStore) List(ctx context.Context, id int64) ([]*core.Token, error) {
+	var out []*core.Token
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := map[string]interface{}{"token_user_id": id}
+		stmt, args, err := binder.BindNamed(queryUser, params)
+		if err != nil {
+			return err
+		}
+		rows, err := queryer.Query(stmt, args...)
+		if err != nil {
+			return err
+		}
+		out, err = scanRows(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *tokenStore) Find(ctx context.Context, id int64) (*core.Token, error) {
+	out := &core.Token{ID: id}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryKey, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *tokenStore) FindHash(ctx context.Context, hash string) (*core.Token, error) {
+	out := &core.Token{Hash: hash}
+	err := s.db.View(func(queryer db.Queryer, binder db.Binder) error {
+		params := toParams(out)
+		query, args, err := binder.BindNamed(queryHash, params)
+		if err != nil {
+			return err
+		}
+		row := queryer.QueryRow(query, args...)
+		return scanRow(row, out)
+	})
+	return out, err
+}
+
+func (s *tokenStore) Create(ctx context.Context, token *core.Token) error {
+	if s.db.Driver() == db.Postgres {
+		return s.createPostgres(ctx, token)
+	}
+	return s.create(ctx, token)
+}
+
+func (s *tokenStore) create(ctx context.Context, token *core.Token) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(token)
+		stmt, args, err := binder.BindNamed(stmtInsert, params)
+		if err != nil {
+			return err
+		}
+		res, err := execer.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		token.ID, err = res.LastInsertId()
+		return err
+	})
+}
+
+func (s *tokenStore) createPostgres(ctx context.Context, token *core.Token) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(token)
+		stmt, args, err := binder.BindNamed(stmtInsertPg, params)
+		if err != nil {
+			return err
+		}
+		return execer.QueryRow(stmt, args...).Scan(&token.ID)
+	})
+}
+
+func (s *tokenStore) Update(ctx context.Context, token *core.Token) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(token)
+		stmt, args, err := binder.BindNamed(stmtUpdate, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+func (s *tokenStore) Delete(ctx context.Context, token *core.Token) error {
+	return s.db.Lock(func(execer db.Execer, binder db.Binder) error {
+		params := toParams(token)
+		stmt, args, err := binder.BindNamed(stmtDelete, params)
+		if err != nil {
+			return err
+		}
+		_, err = execer.Exec(stmt, args...)
+		return err
+	})
+}
+
+const queryBase = `
+SELECT
+ token_id
+,token_user_id
+,token_name
+,token_hash
+,token_scope
+,token_repo
+,token_expires
+,token_last_used
+,token_created
+`
+
+const queryKey = queryBase + `
+FROM tokens
+WHERE token_id = :token_id
+LIMIT 1
+`
+
+const queryHash = queryBase + `
+FROM tokens
+WHERE token_hash = :token_hash
+LIMIT 1
+`
+
+const queryUser = queryBase + `
+FROM tokens
+WHERE token_user_id = :token_user_id
+ORDER BY token_name
+`
+
+const stmtUpdate = `
+UPDATE tokens SET
+ token_name = :token_name
+,token_scope = :token_scope
+,token_repo = :token_repo
+,token_expires = :token_expires
+,token_last_used = :token_last_used
+WHERE token_id = :token_id
+`
+
+const stmtDelete = `
+DELETE FROM tokens
+WHERE token_id = :token_id
+`
+
+const stmtInsert = `
+INSERT INTO tokens (
+ token_user_id
+,token_name
+,token_hash
+,token_scope
+,token_repo
+,token_expires
+,token_last_used
+,token_created
+) VALUES (
+ :token_user_id
+,:token_name
+,:token_hash
+,:token_scope
+,:token_repo
+,:token_expires
+,:token_last_used
+,:token_created
+)
+`
+
+const stmtInsertPg = stmtInsert + `
+RETURNING token_id
+`
@@ -0,0 +1,190 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+package tokens
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/store/shared/db/dbtest"
+	"github.com/drone/drone/store/user"
+)
+
+var noContext = context.TODO()
+
+func TestToken(t *testing.T) {
+	conn, err := dbtest.Connect()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer func() {
+		dbtest.Reset(conn)
+		dbtest.Disconnect(conn)
+	}()
+
+	// seeds the database with a dummy user.
+	account := &core.User{Login: "octocat", Hash: "ulSxuA0FKjNiOFIchk18NNvC6ygSxdtKjiOAS"}
+	users := user.New(conn)
+	if err := users.Create(noContext, account); err != nil {
+		t.Error(err)
+	}
+
+	store := New(conn).(*tokenStore)
+	t.Run("Create", testTokenCreate(store, users, account))
+}
+
+func testTokenCreate(store *tokenStore, users core.UserStore, account *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Token{
+			UserID:  account.ID,
+			Name:    "deploy-script",
+			Hash:    core.HashToken("correct-horse-battery-staple"),
+			Scope:   core.TokenScopeRead,
+			Repo:    "octocat/hello-world",
+			Expires: 1000000000,
+			Created: 1000000000,
+		}
+		err := store.Create(noContext, item)
+		if err != nil {
+			t.Error(err)
+		}
+		if item.ID == 0 {
+			t.Errorf("Want token ID assigned, got %d", item.ID)
+		}
+
+		t.Run("Find", testTokenFind(store, item))
+		t.Run("FindHash", testTokenFindHash(store))
+		t.Run("List", testTokenList(store, account))
+		t.Run("Update", testTokenUpdate(store, item))
+		t.Run("Delete", testTokenDelete(store, item))
+		t.Run("Fkey", testTokenForeignKey(store, users, account))
+	}
+}
+
+func testTokenFind(store *tokenStore, token *core.Token) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.Find(noContext, token.ID)
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testToken(item))
+		}
+	}
+}
+
+func testTokenFindHash(store *tokenStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		item, err := store.FindHash(noContext, core.HashToken("correct-horse-battery-staple"))
+		if err != nil {
+			t.Error(err)
+		} else {
+			t.Run("Fields", testToken(item))
+		}
+	}
+}
+
+func testTokenList(store *tokenStore, account *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		list, err := store.List(noContext, account.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := len(list), 1; got != want {
+			t.Errorf("Want count %d, got %d", want, got)
+		} else {
+			t.Run("Fields", testToken(list[0]))
+		}
+	}
+}
+
+func testTokenUpdate(store *tokenStore, token *core.Token) func(t *testing.T) {
+	return func(t *testing.T) {
+		before, err := store.Find(noContext, token.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		before.LastUsed = 1000000001
+		err = store.Update(noContext, before)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, err := store.Find(noContext, before.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := after.LastUsed, before.LastUsed; got != want {
+			t.Errorf("Want token LastUsed %d, got %d", want, got)
+		}
+	}
+}
+
+func testTokenDelete(store *tokenStore, token *core.Token) func(t *testing.T) {
+	return func(t *testing.T) {
+		err := store.Delete(noContext, token)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = store.Find(noContext, token.ID)
+		if got, want := sql.ErrNoRows, err; got != want {
+			t.Errorf("Want sql.ErrNoRows, got %v", got)
+			return
+		}
+	}
+}
+
+func testTokenForeignKey(store *tokenStore, users core.UserStore, account *core.User) func(t *testing.T) {
+	return func(t *testing.T) {
+		item := &core.Token{
+			UserID: account.ID,
+			Name:   "deploy-script",
+			Hash:   core.HashToken("correct-horse-battery-staple"),
+			Scope:  core.TokenScopeWrite,
+		}
+		store.Create(noContext, item)
+		before, _ := store.List(noContext, account.ID)
+		if len(before) == 0 {
+			t.Errorf("Want non-empty token list")
+			return
+		}
+
+		err := users.Delete(noContext, account)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		after, _ := store.List(noContext, account.ID)
+		if len(after) != 0 {
+			t.Errorf("Want empty token list")
+		}
+	}
+}
+
+func testToken(item *core.Token) func(t *testing.T) {
+	return func(t *testing.T) {
+		if got, want := item.Name, "deploy-script"; got != want {
+			t.Errorf("Want token name %q, got %q", want, got)
+		}
+		if got, want := item.Hash, core.HashToken("correct-horse-battery-staple"); got != want {
+			t.Errorf("Want token hash %q, got %q", want, got)
+		}
+		if got, want := item.Scope, core.TokenScopeRead; got != want {
+			t.Errorf("Want token scope %q, got %q", want, got)
+		}
+		if got, want := item.Repo, "octocat/hello-world"; got != want {
+			t.Errorf("Want token repo %q, got %q", want, got)
+		}
+		if got, want := item.Expires, int64(1000000000); got != want {
+			t.Errorf("Want token expires %d, got %d", want, got)
+		}
+	}
+}
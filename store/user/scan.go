@@ -41,6 +41,7 @@ func toParams(u *core.User) map[string]interface{} {
 		"user_oauth_refresh": u.Refresh,
 		"user_oauth_expiry":  u.Expiry,
 		"user_hash":          u.Hash,
+		"user_namespace":     u.Namespace,
 	}
 }
 
@@ -64,6 +65,7 @@ func scanRow(scanner db.Scanner, dest *core.User) error {
 		&dest.Refresh,
 		&dest.Expiry,
 		&dest.Hash,
+		&dest.Namespace,
 	)
 }
 
@@ -182,6 +182,7 @@ SELECT
 ,user_oauth_refresh
 ,user_oauth_expiry
 ,user_hash
+,user_namespace
 `
 
 const queryKey = queryBase + `
@@ -244,6 +245,7 @@ INSERT INTO users (
 ,user_oauth_refresh
 ,user_oauth_expiry
 ,user_hash
+,user_namespace
 ) VALUES (
  :user_login
 ,:user_email
@@ -260,6 +262,7 @@ INSERT INTO users (
 ,:user_oauth_refresh
 ,:user_oauth_expiry
 ,:user_hash
+,:user_namespace
 )
 `
 
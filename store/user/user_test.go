@@ -54,6 +54,31 @@ func testUserCreate(store *userStore) func(t *testing.T) {
 		t.Run("List", testUserList(store))
 		t.Run("Update", testUserUpdate(store, user))
 		t.Run("Delete", testUserDelete(store, user))
+		t.Run("Namespace", testUserNamespace(store))
+	}
+}
+
+func testUserNamespace(users *userStore) func(t *testing.T) {
+	return func(t *testing.T) {
+		user := &core.User{
+			Login:     "release@octocat",
+			Machine:   true,
+			Namespace: "octocat",
+			Hash:      "cmVsZWFzZUBvY3RvY2F0",
+		}
+		err := users.Create(noContext, user)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		found, err := users.FindLogin(noContext, user.Login)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := found.Namespace, "octocat"; got != want {
+			t.Errorf("Want user namespace %q, got %q", want, got)
+		}
 	}
 }
 
@@ -0,0 +1,197 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/drone/drone-yaml/yaml"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// input types.
+const (
+	inputString = "string"
+	inputBool   = "bool"
+	inputChoice = "choice"
+)
+
+// inputPrefix is the prefix of the environment variables
+// that expose the build inputs to the pipeline.
+const inputPrefix = "DRONE_INPUT_"
+
+var inputRE = regexp.MustCompile("[^A-Za-z0-9]+")
+
+// Input defines a typed build parameter declared by a
+// pipeline in the inputs section.
+type Input struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Type        string   `yaml:"type"`
+	Default     string   `yaml:"default"`
+	Required    bool     `yaml:"required"`
+	Options     []string `yaml:"options"`
+}
+
+// InputError is returned when the build parameters do not
+// satisfy the inputs declared by the pipeline.
+type InputError struct {
+	Message string
+}
+
+func (e *InputError) Error() string {
+	return e.Message
+}
+
+// Inputs returns the inputs declared by the pipelines in
+// the configuration. An input declared by more than one
+// pipeline must be declared with the same type.
+func Inputs(data string) ([]*Input, error) {
+	resources, err := yaml.ParseRawString(data)
+	if err != nil {
+		return nil, err
+	}
+	var inputs []*Input
+	declared := map[string]*Input{}
+	for _, resource := range resources {
+		if resource.Kind != "" && resource.Kind != yaml.KindPipeline {
+			continue
+		}
+		out := struct {
+			Inputs []*Input `yaml:"inputs"`
+		}{}
+		if err := yamlv2.Unmarshal(resource.Data, &out); err != nil {
+			return nil, err
+		}
+		for _, input := range out.Inputs {
+			if input.Type == "" {
+				input.Type = inputString
+			}
+			if err := lintInput(input); err != nil {
+				return nil, err
+			}
+			if prev, ok := declared[input.Name]; ok {
+				if prev.Type != input.Type {
+					return nil, fmt.Errorf("yaml: input %s declared with conflicting types", input.Name)
+				}
+				continue
+			}
+			declared[input.Name] = input
+			inputs = append(inputs, input)
+		}
+	}
+	return inputs, nil
+}
+
+// helper function returns an error if the input declaration
+// is invalid.
+func lintInput(input *Input) error {
+	if input.Name == "" {
+		return fmt.Errorf("yaml: input name cannot be empty")
+	}
+	switch input.Type {
+	case inputString:
+	case inputBool:
+		if input.Default != "" {
+			if _, err := strconv.ParseBool(input.Default); err != nil {
+				return fmt.Errorf("yaml: input %s default must be true or false", input.Name)
+			}
+		}
+	case inputChoice:
+		if len(input.Options) == 0 {
+			return fmt.Errorf("yaml: input %s requires options", input.Name)
+		}
+		if input.Default != "" && !contains(input.Options, input.Default) {
+			return fmt.Errorf("yaml: input %s default is not a valid option", input.Name)
+		}
+	default:
+		return fmt.Errorf("yaml: input %s has unsupported type %s", input.Name, input.Type)
+	}
+	return nil
+}
+
+// resolveInputs validates the parameters against the declared
+// inputs and returns the build parameters, with defaults
+// applied and each input exposed as a DRONE_INPUT_ variable.
+//
+// Required inputs, and unknown parameters, are only enforced
+// for builds that are triggered manually, since builds that
+// are triggered by a hook or cron job have no parameters. If
+// no inputs are declared the parameters are returned as-is.
+func resolveInputs(inputs []*Input, params map[string]string, manual bool) (map[string]string, error) {
+	if len(inputs) == 0 {
+		return params, nil
+	}
+	declared := map[string]struct{}{}
+	for _, input := range inputs {
+		declared[input.Name] = struct{}{}
+	}
+	out := map[string]string{}
+	for key, value := range params {
+		if _, ok := declared[key]; !ok && manual {
+			return nil, &InputError{fmt.Sprintf("Unknown input %s", key)}
+		}
+		out[key] = value
+	}
+	for _, input := range inputs {
+		value := out[input.Name]
+		if value == "" {
+			value = input.Default
+		}
+		if value == "" {
+			if input.Required && manual {
+				return nil, &InputError{fmt.Sprintf("Missing required input %s", input.Name)}
+			}
+			continue
+		}
+		switch input.Type {
+		case inputBool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, &InputError{fmt.Sprintf("Input %s must be true or false", input.Name)}
+			}
+			value = strconv.FormatBool(b)
+		case inputChoice:
+			if !contains(input.Options, value) {
+				return nil, &InputError{fmt.Sprintf("Input %s must be one of %s", input.Name, strings.Join(input.Options, ", "))}
+			}
+		}
+		out[input.Name] = value
+		out[inputEnv(input.Name)] = value
+	}
+	return out, nil
+}
+
+// helper function returns the environment variable name
+// for the input.
+func inputEnv(name string) string {
+	name = inputRE.ReplaceAllString(name, "_")
+	return inputPrefix + strings.ToUpper(name)
+}
+
+// helper function returns true if the list contains the
+// string.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
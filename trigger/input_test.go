@@ -0,0 +1,166 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package trigger
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var mockInputs = `
+kind: pipeline
+name: deploy
+
+inputs:
+- name: environment
+  type: choice
+  options: [ staging, production ]
+  default: staging
+- name: dry-run
+  type: bool
+  default: true
+- name: version
+  required: true
+
+steps: []
+---
+kind: pipeline
+name: notify
+
+inputs:
+- name: version
+
+steps: []
+---
+kind: secret
+name: token
+`
+
+func TestInputs(t *testing.T) {
+	got, err := Inputs(mockInputs)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := []*Input{
+		{Name: "environment", Type: "choice", Options: []string{"staging", "production"}, Default: "staging"},
+		{Name: "dry-run", Type: "bool", Default: "true"},
+		{Name: "version", Type: "string", Required: true},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestInputs_Invalid(t *testing.T) {
+	tests := []string{
+		"kind: pipeline\ninputs:\n- type: string\n",
+		"kind: pipeline\ninputs:\n- name: foo\n  type: number\n",
+		"kind: pipeline\ninputs:\n- name: foo\n  type: choice\n",
+		"kind: pipeline\ninputs:\n- name: foo\n  type: choice\n  options: [ a ]\n  default: b\n",
+		"kind: pipeline\ninputs:\n- name: foo\n  type: bool\n  default: maybe\n",
+		"kind: pipeline\ninputs:\n- name: foo\n---\nkind: pipeline\ninputs:\n- name: foo\n  type: bool\n",
+	}
+	for i, test := range tests {
+		if _, err := Inputs(test); err == nil {
+			t.Errorf("Want error at index %d", i)
+		}
+	}
+}
+
+func TestResolveInputs(t *testing.T) {
+	inputs, _ := Inputs(mockInputs)
+	params := map[string]string{
+		"environment": "production",
+		"dry-run":     "0",
+		"version":     "1.0.0",
+	}
+	got, err := resolveInputs(inputs, params, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := map[string]string{
+		"environment":             "production",
+		"dry-run":                 "false",
+		"version":                 "1.0.0",
+		"DRONE_INPUT_ENVIRONMENT": "production",
+		"DRONE_INPUT_DRY_RUN":     "false",
+		"DRONE_INPUT_VERSION":     "1.0.0",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestResolveInputs_Defaults(t *testing.T) {
+	inputs, _ := Inputs(mockInputs)
+	got, err := resolveInputs(inputs, nil, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := map[string]string{
+		"environment":             "staging",
+		"dry-run":                 "true",
+		"DRONE_INPUT_ENVIRONMENT": "staging",
+		"DRONE_INPUT_DRY_RUN":     "true",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestResolveInputs_NoInputs(t *testing.T) {
+	params := map[string]string{"foo": "bar"}
+	got, err := resolveInputs(nil, params, true)
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := cmp.Diff(got, params); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestResolveInputs_Invalid(t *testing.T) {
+	inputs, _ := Inputs(mockInputs)
+	tests := []struct {
+		params  map[string]string
+		message string
+	}{
+		{
+			params:  map[string]string{},
+			message: "Missing required input version",
+		},
+		{
+			params:  map[string]string{"version": "1", "foo": "bar"},
+			message: "Unknown input foo",
+		},
+		{
+			params:  map[string]string{"version": "1", "environment": "qa"},
+			message: "Input environment must be one of staging, production",
+		},
+		{
+			params:  map[string]string{"version": "1", "dry-run": "maybe"},
+			message: "Input dry-run must be true or false",
+		},
+	}
+	for i, test := range tests {
+		_, err := resolveInputs(inputs, test.params, true)
+		if err == nil {
+			t.Errorf("Want error at index %d", i)
+			continue
+		}
+		if _, ok := err.(*InputError); !ok {
+			t.Errorf("Want input error at index %d", i)
+		}
+		if got, want := err.Error(), test.message; got != want {
+			t.Errorf("Want error %q at index %d, got %q", want, i, got)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright 2019 Drone IO, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/drone/drone-yaml/yaml"
+	"github.com/drone/drone-yaml/yaml/converter"
+	"github.com/drone/drone-yaml/yaml/linter"
+
+	"github.com/drone/drone/core"
+)
+
+// regular expression to extract the line number from
+// yaml parsing errors.
+var lineRE = regexp.MustCompile(`line (\d+)`)
+
+type (
+	// Report represents the result of a configuration dry
+	// run, listing the pipelines that would execute for a
+	// hypothetical hook.
+	Report struct {
+		Verified   bool               `json:"verified"`
+		Pipelines  []*ReportResult    `json:"pipelines"`
+		Skipped    []*ReportResult    `json:"skipped"`
+		Errors     []*ReportError     `json:"errors"`
+		Violations []*ReportViolation `json:"violations,omitempty"`
+	}
+
+	// ReportResult represents a pipeline in the report.
+	ReportResult struct {
+		Name    string   `json:"name"`
+		Line    int      `json:"line"`
+		Depends []string `json:"depends_on,omitempty"`
+		Reason  string   `json:"reason,omitempty"`
+		Blocked bool     `json:"blocked,omitempty"`
+	}
+
+	// ReportError represents a configuration error in the
+	// report. The line number is zero if the error cannot
+	// be attributed to a line in the configuration file.
+	ReportError struct {
+		Message  string `json:"message"`
+		Line     int    `json:"line,omitempty"`
+		Pipeline string `json:"pipeline,omitempty"`
+	}
+
+	// ReportViolation represents a policy violation in the
+	// report. Violations with the error action fail the
+	// build, and violations with the block action block the
+	// build pending approval.
+	ReportViolation struct {
+		Policy  string `json:"policy"`
+		Message string `json:"message"`
+		Action  string `json:"action"`
+	}
+)
+
+// Valid returns true if the report contains no errors.
+func (r *Report) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Lint runs the configuration through the same conversion,
+// parsing, linting, input validation, policy evaluation,
+// signature verification and matching steps as the
+// triggerer, without creating a build.
+func Lint(ctx context.Context, policy core.PolicyService, repo *core.Repository, base *core.Hook, data string) *Report {
+	report := &Report{
+		Pipelines: []*ReportResult{},
+		Skipped:   []*ReportResult{},
+		Errors:    []*ReportError{},
+	}
+
+	data, err := converter.ConvertString(data, converter.Metadata{
+		Filename: repo.Config,
+		Ref:      base.Ref,
+	})
+	if err != nil {
+		report.Errors = append(report.Errors, newReportError(err, 1))
+		return report
+	}
+
+	// each document is parsed individually so that errors
+	// can be reported with the absolute line number.
+	documents, err := split(data)
+	if err != nil {
+		report.Errors = append(report.Errors, &ReportError{
+			Message: err.Error(),
+		})
+		return report
+	}
+	manifest := new(yaml.Manifest)
+	var lines []int
+	for _, document := range documents {
+		parsed, err := yaml.ParseString(document.data)
+		if err != nil {
+			report.Errors = append(report.Errors, newReportError(err, document.line))
+			continue
+		}
+		for _, resource := range parsed.Resources {
+			manifest.Resources = append(manifest.Resources, resource)
+			lines = append(lines, document.line)
+		}
+	}
+	if !report.Valid() {
+		return report
+	}
+
+	for i, resource := range manifest.Resources {
+		pipeline, ok := resource.(*yaml.Pipeline)
+		if !ok {
+			continue
+		}
+		err := linter.Lint(pipeline, repo.Trusted)
+		if err != nil {
+			report.Errors = append(report.Errors, &ReportError{
+				Message:  err.Error(),
+				Line:     lines[i],
+				Pipeline: pipeline.Name,
+			})
+		}
+	}
+	if !report.Valid() {
+		return report
+	}
+
+	// the manifest linter verifies pipeline names and
+	// dependencies across documents.
+	err = linter.Manifest(manifest, repo.Trusted)
+	if err != nil {
+		report.Errors = append(report.Errors, &ReportError{
+			Message: err.Error(),
+		})
+		return report
+	}
+
+	inputs, err := Inputs(data)
+	if err != nil {
+		report.Errors = append(report.Errors, &ReportError{
+			Message: err.Error(),
+		})
+		return report
+	}
+	manual := base.Trigger != core.TriggerHook && base.Trigger != core.TriggerCron
+	params, err := resolveInputs(inputs, base.Params, manual)
+	if err != nil {
+		report.Errors = append(report.Errors, &ReportError{
+			Message: err.Error(),
+		})
+		return report
+	}
+
+	violations, err := policy.Evaluate(ctx, &core.PolicyArgs{
+		Repo: repo,
+		Build: &core.Build{
+			RepoID:  repo.ID,
+			Trigger: base.Trigger,
+			Event:   base.Event,
+			Action:  base.Action,
+			Before:  base.Before,
+			After:   base.After,
+			Ref:     base.Ref,
+			Fork:    base.Fork,
+			Source:  base.Source,
+			Target:  base.Target,
+			Params:  params,
+			Deploy:  base.Deployment,
+			Sender:  base.Sender,
+		},
+		Config: &core.Config{Data: data},
+	})
+	if err != nil {
+		report.Errors = append(report.Errors, &ReportError{
+			Message: err.Error(),
+		})
+		return report
+	}
+	for _, violation := range violations {
+		report.Violations = append(report.Violations, &ReportViolation{
+			Policy:  violation.Policy,
+			Message: violation.Message,
+			Action:  violation.Action,
+		})
+	}
+	action, _ := enforce(violations)
+	if action == core.PolicyError {
+		for _, violation := range violations {
+			if violation.Action != core.PolicyError {
+				continue
+			}
+			report.Errors = append(report.Errors, &ReportError{
+				Message: fmt.Sprintf("policy %s: %s", violation.Policy, violation.Message),
+			})
+		}
+		return report
+	}
+
+	report.Verified = verify(repo, base, data)
+
+	for i, resource := range manifest.Resources {
+		pipeline, ok := resource.(*yaml.Pipeline)
+		if !ok {
+			continue
+		}
+		result := &ReportResult{
+			Name:    pipeline.Name,
+			Line:    lines[i],
+			Depends: pipeline.DependsOn,
+		}
+		if result.Name == "" {
+			result.Name = "default"
+		}
+		if reason := skipPipeline(pipeline, repo, base); reason != "" {
+			result.Reason = "does not match " + reason
+			report.Skipped = append(report.Skipped, result)
+			continue
+		}
+		result.Blocked = !report.Verified || action == core.PolicyBlock
+		report.Pipelines = append(report.Pipelines, result)
+	}
+	return report
+}
+
+// document represents a single yaml document and the line
+// number at which it starts.
+type document struct {
+	line int
+	data string
+}
+
+// split splits the multi-document yaml into individual
+// documents, using the same rules as the yaml parser.
+func split(data string) ([]*document, error) {
+	var documents []*document
+	var current *document
+	var buf strings.Builder
+
+	// the maximum line length is the length of the file, to
+	// ensure lines longer than the default limit are split.
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for i := 1; scanner.Scan(); i++ {
+		line := scanner.Text()
+		separator := strings.HasPrefix(line, "---")
+		if separator && current != nil {
+			current.data = buf.String()
+			buf.Reset()
+			current = nil
+		}
+		if current == nil {
+			current = &document{line: i}
+			documents = append(documents, current)
+		}
+		if separator {
+			current.line = i + 1
+			continue
+		}
+		if strings.HasPrefix(line, "...") {
+			break
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if current != nil {
+		current.data = buf.String()
+	}
+	return documents, nil
+}
+
+// newReportError returns a report error, offsetting the
+// line number in the error message by the line number at
+// which the document starts.
+func newReportError(err error, offset int) *ReportError {
+	out := &ReportError{Message: err.Error()}
+	match := lineRE.FindStringSubmatch(out.Message)
+	if len(match) == 2 {
+		line, _ := strconv.Atoi(match[1])
+		out.Line = line + offset - 1
+	} else {
+		out.Line = offset
+	}
+	return out
+}
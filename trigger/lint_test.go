@@ -0,0 +1,235 @@
+// Copyright 2019 Drone.IO Inc. All rights reserved.
+// Use of this source code is governed by the Drone Non-Commercial License
+// that can be found in the LICENSE file.
+
+// +build !oss
+
+package trigger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/core"
+	"github.com/drone/drone/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	policy := mock.NewMockPolicyService(controller)
+	policy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	repo := &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml"}
+	hook := &core.Hook{Event: core.EventPush, Target: "master", Ref: "refs/heads/master"}
+	data := `---
+kind: pipeline
+name: test
+
+steps:
+- name: test
+  image: golang
+
+---
+kind: pipeline
+name: deploy
+
+trigger:
+  branch: [ production ]
+
+steps:
+- name: deploy
+  image: alpine
+`
+	got := Lint(noContext, policy, repo, hook, data)
+	want := &Report{
+		Verified: true,
+		Pipelines: []*ReportResult{
+			{Name: "test", Line: 2},
+		},
+		Skipped: []*ReportResult{
+			{Name: "deploy", Line: 10, Reason: "does not match branch"},
+		},
+		Errors: []*ReportError{},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestLint_ParseError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	policy := mock.NewMockPolicyService(controller)
+	repo := &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml"}
+	hook := &core.Hook{Event: core.EventPush, Target: "master"}
+	data := `---
+kind: pipeline
+name: test
+---
+kind: pipeline
+name: deploy
+steps:
+- name: deploy
+  image: [ alpine
+`
+	got := Lint(noContext, policy, repo, hook, data)
+	if got.Valid() {
+		t.Errorf("Expect parse error")
+		return
+	}
+	if got, want := got.Errors[0].Line, 9; got != want {
+		t.Errorf("Want error on line %d, got %d", want, got)
+	}
+}
+
+func TestLint_Untrusted(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	policy := mock.NewMockPolicyService(controller)
+	repo := &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml"}
+	hook := &core.Hook{Event: core.EventPush, Target: "master"}
+	data := `---
+kind: pipeline
+name: test
+
+steps:
+- name: test
+  image: docker
+  privileged: true
+`
+	got := Lint(noContext, policy, repo, hook, data)
+	want := []*ReportError{
+		{Message: "linter: untrusted repositories cannot enable privileged mode", Line: 2, Pipeline: "test"},
+	}
+	if diff := cmp.Diff(got.Errors, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestLint_Unverified(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	policy := mock.NewMockPolicyService(controller)
+	policy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	repo := &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml", Protected: true, Secret: "correct-horse-battery-staple"}
+	hook := &core.Hook{Event: core.EventPush, Target: "master", Trigger: core.TriggerHook}
+	data := "kind: pipeline\nname: test\nsteps:\n- name: test\n  image: golang\n"
+
+	got := Lint(noContext, policy, repo, hook, data)
+	if got.Verified {
+		t.Errorf("Expect unsigned configuration is not verified")
+	}
+	if len(got.Pipelines) != 1 || !got.Pipelines[0].Blocked {
+		t.Errorf("Expect pipeline is blocked")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	got, err := split("kind: pipeline\n---\n\nkind: secret\n...\nkind: ignored\n")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := []*document{
+		{line: 1, data: "kind: pipeline\n"},
+		{line: 3, data: "\nkind: secret\n"},
+	}
+	if diff := cmp.Diff(got, want, cmp.AllowUnexported(document{})); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestLint_PolicyError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	violations := []*core.PolicyViolation{
+		{Policy: "trusted", Message: "privileged mode requires a trusted repository", Action: core.PolicyError},
+		{Policy: "latest", Message: "images must be pinned", Action: core.PolicyBlock},
+	}
+	policy := mock.NewMockPolicyService(controller)
+	policy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(violations, nil)
+
+	repo := &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml"}
+	hook := &core.Hook{Event: core.EventPush, Target: "master", Trigger: core.TriggerHook}
+	data := "kind: pipeline\nname: test\nsteps:\n- name: test\n  image: golang\n"
+
+	got := Lint(noContext, policy, repo, hook, data)
+	want := []*ReportError{
+		{Message: "policy trusted: privileged mode requires a trusted repository"},
+	}
+	if diff := cmp.Diff(got.Errors, want); diff != "" {
+		t.Error(diff)
+	}
+	if got, want := len(got.Violations), 2; got != want {
+		t.Errorf("Want %d violations, got %d", want, got)
+	}
+}
+
+func TestLint_PolicyBlock(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	violations := []*core.PolicyViolation{
+		{Policy: "latest", Message: "images must be pinned", Action: core.PolicyBlock},
+	}
+	policy := mock.NewMockPolicyService(controller)
+	policy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(violations, nil)
+
+	repo := &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml"}
+	hook := &core.Hook{Event: core.EventPush, Target: "master", Trigger: core.TriggerHook}
+	data := "kind: pipeline\nname: test\nsteps:\n- name: test\n  image: golang\n"
+
+	got := Lint(noContext, policy, repo, hook, data)
+	if !got.Valid() {
+		t.Errorf("Expect blocking violation does not invalidate the report")
+	}
+	if len(got.Pipelines) != 1 || !got.Pipelines[0].Blocked {
+		t.Errorf("Expect pipeline is blocked")
+	}
+	want := []*ReportViolation{
+		{Policy: "latest", Message: "images must be pinned", Action: core.PolicyBlock},
+	}
+	if diff := cmp.Diff(got.Violations, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestLint_InvalidInputs(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	policy := mock.NewMockPolicyService(controller)
+	repo := &core.Repository{Slug: "octocat/hello-world", Config: ".drone.yml"}
+	hook := &core.Hook{Event: core.EventPush, Target: "master", Trigger: core.TriggerHook}
+	data := "kind: pipeline\nname: test\ninputs:\n- name: debug\n  type: number\nsteps:\n- name: test\n  image: golang\n"
+
+	got := Lint(noContext, policy, repo, hook, data)
+	want := []*ReportError{
+		{Message: "yaml: input debug has unsupported type number"},
+	}
+	if diff := cmp.Diff(got.Errors, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSplit_LongLine(t *testing.T) {
+	line := "# " + strings.Repeat("x", 100000) + "\n"
+	got, err := split("kind: pipeline\n" + line)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(got) != 1 || got[0].data != "kind: pipeline\n"+line {
+		t.Errorf("Expect long line included in the document")
+	}
+}
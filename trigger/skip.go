@@ -21,6 +21,25 @@ import (
 	"github.com/drone/drone/core"
 )
 
+// skipPipeline returns the reason the pipeline is skipped
+// for the hook, or an empty string if the pipeline matches.
+func skipPipeline(pipeline *yaml.Pipeline, repo *core.Repository, base *core.Hook) string {
+	switch {
+	case skipBranch(pipeline, base.Target):
+		return "branch"
+	case skipEvent(pipeline, base.Event):
+		return "event"
+	case skipRef(pipeline, base.Ref):
+		return "ref"
+	case skipRepo(pipeline, repo.Slug):
+		return "repo"
+	case skipTarget(pipeline, base.Deployment):
+		return "deploy target"
+	default:
+		return ""
+	}
+}
+
 func skipBranch(document *yaml.Pipeline, branch string) bool {
 	return !document.Trigger.Branch.Match(branch)
 }
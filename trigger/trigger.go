@@ -16,6 +16,7 @@ package trigger
 
 import (
 	"context"
+	"fmt"
 	"runtime/debug"
 	"strings"
 	"time"
@@ -32,6 +33,8 @@ import (
 
 type triggerer struct {
 	config  core.ConfigService
+	convert core.ConvertService
+	policy  core.PolicyService
 	commits core.CommitService
 	status  core.StatusService
 	builds  core.BuildStore
@@ -44,6 +47,8 @@ type triggerer struct {
 // New returns a new build triggerer.
 func New(
 	config core.ConfigService,
+	convert core.ConvertService,
+	policy core.PolicyService,
 	commits core.CommitService,
 	status core.StatusService,
 	builds core.BuildStore,
@@ -54,6 +59,8 @@ func New(
 ) core.Triggerer {
 	return &triggerer{
 		config:  config,
+		convert: convert,
+		policy:  policy,
 		commits: commits,
 		status:  status,
 		builds:  builds,
@@ -192,6 +199,18 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		return nil, err
 	}
 
+	raw, err = t.convert.Convert(ctx, &core.ConvertArgs{
+		User:   user,
+		Repo:   repo,
+		Build:  req.Build,
+		Config: raw,
+	})
+	if err != nil {
+		logger = logger.WithError(err)
+		logger.Warnln("trigger: cannot convert yaml")
+		return t.createBuildError(ctx, repo, base, err.Error())
+	}
+
 	// this code is temporarily in place to detect and convert
 	// the legacy yaml configuration file to the new format.
 	raw.Data, err = converter.ConvertString(raw.Data, converter.Metadata{
@@ -218,13 +237,50 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		return t.createBuildError(ctx, repo, base, err.Error())
 	}
 
-	verified := true
-	if repo.Protected && base.Trigger == core.TriggerHook {
-		key := signer.KeyString(repo.Secret)
-		val := []byte(raw.Data)
-		verified, _ = signer.Verify(val, key)
+	inputs, err := Inputs(raw.Data)
+	if err != nil {
+		logger = logger.WithError(err)
+		logger.Warnln("trigger: cannot parse inputs")
+		return t.createBuildError(ctx, repo, base, err.Error())
+	}
+
+	// the build parameters are validated against the inputs
+	// declared in the yaml. Invalid parameters provided by
+	// the user are rejected without creating a build.
+	manual := base.Trigger != core.TriggerHook && base.Trigger != core.TriggerCron
+	base.Params, err = resolveInputs(inputs, base.Params, manual)
+	if err != nil && manual {
+		logger = logger.WithError(err)
+		logger.Infoln("trigger: invalid build inputs")
+		return nil, err
+	} else if err != nil {
+		logger = logger.WithError(err)
+		logger.Warnln("trigger: invalid build inputs")
+		return t.createBuildError(ctx, repo, base, err.Error())
 	}
 
+	violations, err := t.policy.Evaluate(ctx, &core.PolicyArgs{
+		Repo:   repo,
+		Build:  req.Build,
+		Config: raw,
+	})
+	if err != nil {
+		logger = logger.WithError(err)
+		logger.Warnln("trigger: cannot evaluate policy")
+		return t.createBuildError(ctx, repo, base, err.Error())
+	}
+
+	// policy violations either fail the build, or block the
+	// build pending approval. In both cases the violation
+	// messages are recorded in the build error.
+	action, message := enforce(violations)
+	if action == core.PolicyError {
+		logger.Infoln("trigger: policy violation")
+		return t.createBuildError(ctx, repo, base, message)
+	}
+
+	verified := verify(repo, base, raw.Data)
+
 	// var paths []string
 	// paths, err := listChanges(t.client, repo, base)
 	// if err != nil {
@@ -238,39 +294,14 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		if !ok {
 			continue
 		}
-		// TODO add repo
 		// TODO add instance
-		// TODO add target
-		// TODO add ref
-		if skipBranch(pipeline, base.Target) {
-			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match branch")
-			continue
-		} else if skipEvent(pipeline, base.Event) {
-			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match event")
-			continue
-			// } else if skipPaths(pipeline, paths) {
-			// 	logger.Debug().
-			// 		Str("branch", base.Target).
-			// 		Str("pipeline", pipeline.Name).
-			// 		Msg("skipping pipeline. does not match changed paths")
-			// 	continue
-		} else if skipRef(pipeline, base.Ref) {
-			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match ref")
-			continue
-		} else if skipRepo(pipeline, repo.Slug) {
+		// TODO add paths
+		if reason := skipPipeline(pipeline, repo, base); reason != "" {
 			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match repo")
+			logger.Infof("trigger: skipping pipeline, does not match %s", reason)
 			continue
-		} else if skipTarget(pipeline, base.Deployment) {
-			logger = logger.WithField("pipeline", pipeline.Name)
-			logger.Infoln("trigger: skipping pipeline, does not match deploy target")
-			continue
-		} else {
-			matched = append(matched, pipeline)
 		}
+		matched = append(matched, pipeline)
 	}
 
 	if len(matched) == 0 {
@@ -310,6 +341,7 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		Params:       base.Params,
 		Deploy:       base.Deployment,
 		Sender:       base.Sender,
+		Error:        message,
 		Created:      time.Now().Unix(),
 		Updated:      time.Now().Unix(),
 	}
@@ -352,7 +384,7 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		if stage.Name == "" {
 			stage.Name = "default"
 		}
-		if verified == false {
+		if verified == false || action == core.PolicyBlock {
 			stage.Status = core.StatusBlocked
 		} else if len(stage.DependsOn) == 0 {
 			stage.Status = core.StatusPending
@@ -360,7 +392,11 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 		stages[i] = stage
 	}
 
-	err = t.builds.Create(ctx, build, stages)
+	// the resolved configuration file is persisted with the
+	// build, in the same transaction, so that the runner
+	// executes the same configuration that was used to plan
+	// the build.
+	err = t.builds.CreateWithConfig(ctx, build, stages, raw)
 	if err != nil {
 		logger = logger.WithError(err)
 		logger.Errorln("trigger: cannot create build")
@@ -424,6 +460,31 @@ func (t *triggerer) Trigger(ctx context.Context, repo *core.Repository, base *co
 	return build, nil
 }
 
+// verify returns true if the configuration signature is
+// valid, or if the repository does not require a signature.
+func verify(repo *core.Repository, base *core.Hook, data string) bool {
+	if repo.Protected && base.Trigger == core.TriggerHook {
+		key := signer.KeyString(repo.Secret)
+		verified, _ := signer.Verify([]byte(data), key)
+		return verified
+	}
+	return true
+}
+
+// enforce returns the enforcement action for the policy
+// violations, and the combined violation messages. The
+// error action takes precedence over the block action.
+func enforce(violations []*core.PolicyViolation) (action, message string) {
+	var messages []string
+	for _, violation := range violations {
+		if action != core.PolicyError {
+			action = violation.Action
+		}
+		messages = append(messages, fmt.Sprintf("policy %s: %s", violation.Policy, violation.Message))
+	}
+	return action, strings.Join(messages, "\n")
+}
+
 func trunc(s string, i int) string {
 	runes := []rune(s)
 	if len(runes) > i {
@@ -9,6 +9,7 @@ package trigger
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"io"
 	"io/ioutil"
 	"testing"
@@ -28,11 +29,16 @@ func init() {
 	logrus.SetOutput(ioutil.Discard)
 }
 
+// convertPassthrough returns the configuration unmodified.
+func convertPassthrough(_ context.Context, args *core.ConvertArgs) (*core.Config, error) {
+	return args.Config, nil
+}
+
 func TestTrigger(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 
-	checkBuild := func(_ context.Context, build *core.Build, stages []*core.Stage) {
+	checkBuild := func(_ context.Context, build *core.Build, stages []*core.Stage, _ *core.Config) {
 		if diff := cmp.Diff(build, dummyBuild, ignoreBuildFileds); diff != "" {
 			t.Errorf(diff)
 		}
@@ -60,6 +66,12 @@ func TestTrigger(t *testing.T) {
 	mockConfigService := mock.NewMockConfigService(controller)
 	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYaml, nil)
 
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
+
+	mockPolicy := mock.NewMockPolicyService(controller)
+	mockPolicy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(nil, nil)
+
 	mockStatus := mock.NewMockStatusService(controller)
 	mockStatus.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Do(checkStatus)
 
@@ -67,13 +79,15 @@ func TestTrigger(t *testing.T) {
 	mockQueue.EXPECT().Schedule(gomock.Any(), gomock.Any()).Return(nil)
 
 	mockBuilds := mock.NewMockBuildStore(controller)
-	mockBuilds.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Do(checkBuild).Return(nil)
+	mockBuilds.EXPECT().CreateWithConfig(gomock.Any(), gomock.Any(), gomock.Any(), dummyYaml).Do(checkBuild).Return(nil)
 
 	mockWebhooks := mock.NewMockWebhookSender(controller)
 	mockWebhooks.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
 
 	triggerer := New(
 		mockConfigService,
+		mockConvertService,
+		mockPolicy,
 		nil,
 		mockStatus,
 		mockBuilds,
@@ -105,6 +119,8 @@ func TestTrigger_SkipCI(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 	)
 	dummyHookSkip := *dummyHook
 	dummyHookSkip.Message = "foo [CI SKIP] bar"
@@ -129,6 +145,8 @@ func TestTrigger_NoOwner(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 		mockUsers,
 		nil,
 	)
@@ -158,6 +176,8 @@ func TestTrigger_MissingYaml(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 		mockUsers,
 		nil,
 	)
@@ -180,6 +200,9 @@ func TestTrigger_ErrorYaml(t *testing.T) {
 	mockConfigService := mock.NewMockConfigService(controller)
 	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYamlInvalid, nil)
 
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
+
 	mockRepos := mock.NewMockRepositoryStore(controller)
 	mockRepos.EXPECT().Increment(gomock.Any(), dummyRepo).Return(dummyRepo, nil)
 
@@ -188,6 +211,8 @@ func TestTrigger_ErrorYaml(t *testing.T) {
 
 	triggerer := New(
 		mockConfigService,
+		mockConvertService,
+		nil,
 		nil,
 		nil,
 		mockBuilds,
@@ -213,6 +238,207 @@ func TestTrigger_ErrorYaml(t *testing.T) {
 	}
 }
 
+// this test verifies that a build should be created with an
+// error status if the configuration cannot be converted.
+func TestTrigger_ErrorConvert(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := mock.NewMockUserStore(controller)
+	mockUsers.EXPECT().Find(noContext, dummyRepo.UserID).Return(dummyUser, nil)
+
+	mockConfigService := mock.NewMockConfigService(controller)
+	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYaml, nil)
+
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).Return(nil, errors.New("jsonnet: syntax error"))
+
+	mockRepos := mock.NewMockRepositoryStore(controller)
+	mockRepos.EXPECT().Increment(gomock.Any(), dummyRepo).Return(dummyRepo, nil)
+
+	mockBuilds := mock.NewMockBuildStore(controller)
+	mockBuilds.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	triggerer := New(
+		mockConfigService,
+		mockConvertService,
+		nil,
+		nil,
+		nil,
+		mockBuilds,
+		nil,
+		mockRepos,
+		mockUsers,
+		nil,
+	)
+
+	build, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := build.Status, core.StatusError; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	if got, want := build.Error, "jsonnet: syntax error"; got != want {
+		t.Errorf("Want error %s, got %s", want, got)
+	}
+}
+
+// this test verifies that a build is not created if the
+// parameters of a manual build do not satisfy the inputs.
+func TestTrigger_ErrorInput(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := mock.NewMockUserStore(controller)
+	mockUsers.EXPECT().Find(noContext, dummyRepo.UserID).Return(dummyUser, nil)
+
+	mockConfigService := mock.NewMockConfigService(controller)
+	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(&core.Config{
+		Data: "kind: pipeline\ninputs:\n- name: version\n  required: true\nsteps: [ ]",
+	}, nil)
+
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
+
+	triggerer := New(
+		mockConfigService,
+		mockConvertService,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		mockUsers,
+		nil,
+	)
+
+	hook := *dummyHook
+	hook.Trigger = "octocat"
+	_, err := triggerer.Trigger(noContext, dummyRepo, &hook)
+	if _, ok := err.(*InputError); !ok {
+		t.Errorf("Want input error, got %v", err)
+	}
+}
+
+// this test verifies that a build should be created with an
+// error status if the configuration violates a policy.
+func TestTrigger_ErrorPolicy(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	mockUsers := mock.NewMockUserStore(controller)
+	mockUsers.EXPECT().Find(noContext, dummyRepo.UserID).Return(dummyUser, nil)
+
+	mockConfigService := mock.NewMockConfigService(controller)
+	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYaml, nil)
+
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
+
+	mockPolicy := mock.NewMockPolicyService(controller)
+	mockPolicy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return([]*core.PolicyViolation{
+		{Policy: "images", Message: "image golang uses the latest tag", Action: core.PolicyError},
+		{Policy: "promote", Message: "deploy requires the promote event", Action: core.PolicyBlock},
+	}, nil)
+
+	mockRepos := mock.NewMockRepositoryStore(controller)
+	mockRepos.EXPECT().Increment(gomock.Any(), dummyRepo).Return(dummyRepo, nil)
+
+	mockBuilds := mock.NewMockBuildStore(controller)
+	mockBuilds.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	triggerer := New(
+		mockConfigService,
+		mockConvertService,
+		mockPolicy,
+		nil,
+		nil,
+		mockBuilds,
+		nil,
+		mockRepos,
+		mockUsers,
+		nil,
+	)
+
+	build, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := build.Status, core.StatusError; got != want {
+		t.Errorf("Want status %s, got %s", want, got)
+	}
+	want := "policy images: image golang uses the latest tag\npolicy promote: deploy requires the promote event"
+	if got := build.Error; got != want {
+		t.Errorf("Want error %q, got %q", want, got)
+	}
+}
+
+// this test verifies that a build should be created with all
+// stages blocked if the configuration violates a blocking policy.
+func TestTrigger_BlockPolicy(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	checkBuild := func(_ context.Context, build *core.Build, stages []*core.Stage, _ *core.Config) {
+		if got, want := build.Error, "policy promote: deploy requires the promote event"; got != want {
+			t.Errorf("Want error %q, got %q", want, got)
+		}
+		for _, stage := range stages {
+			if got, want := stage.Status, core.StatusBlocked; got != want {
+				t.Errorf("Want stage status %s, got %s", want, got)
+			}
+		}
+	}
+
+	mockUsers := mock.NewMockUserStore(controller)
+	mockUsers.EXPECT().Find(gomock.Any(), dummyRepo.UserID).Return(dummyUser, nil)
+
+	mockRepos := mock.NewMockRepositoryStore(controller)
+	mockRepos.EXPECT().Increment(gomock.Any(), dummyRepo).Return(dummyRepo, nil)
+
+	mockConfigService := mock.NewMockConfigService(controller)
+	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYaml, nil)
+
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
+
+	mockPolicy := mock.NewMockPolicyService(controller)
+	mockPolicy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return([]*core.PolicyViolation{
+		{Policy: "promote", Message: "deploy requires the promote event", Action: core.PolicyBlock},
+	}, nil)
+
+	mockStatus := mock.NewMockStatusService(controller)
+	mockStatus.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	mockBuilds := mock.NewMockBuildStore(controller)
+	mockBuilds.EXPECT().CreateWithConfig(gomock.Any(), gomock.Any(), gomock.Any(), dummyYaml).Do(checkBuild).Return(nil)
+
+	mockWebhooks := mock.NewMockWebhookSender(controller)
+	mockWebhooks.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+	triggerer := New(
+		mockConfigService,
+		mockConvertService,
+		mockPolicy,
+		nil,
+		mockStatus,
+		mockBuilds,
+		nil,
+		mockRepos,
+		mockUsers,
+		mockWebhooks,
+	)
+
+	_, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
 // this test verifies that no build should be scheduled if the
 // hook branch does not match the branches defined in the yaml.
 func TestTrigger_SkipBranch(t *testing.T) {
@@ -225,8 +451,16 @@ func TestTrigger_SkipBranch(t *testing.T) {
 	mockConfigService := mock.NewMockConfigService(controller)
 	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYamlSkipBranch, nil)
 
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
+
+	mockPolicy := mock.NewMockPolicyService(controller)
+	mockPolicy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(nil, nil)
+
 	triggerer := New(
 		mockConfigService,
+		mockConvertService,
+		mockPolicy,
 		nil,
 		nil,
 		nil,
@@ -254,8 +488,16 @@ func TestTrigger_SkipEvent(t *testing.T) {
 	mockConfigService := mock.NewMockConfigService(controller)
 	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYamlSkipEvent, nil)
 
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
+
+	mockPolicy := mock.NewMockPolicyService(controller)
+	mockPolicy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(nil, nil)
+
 	triggerer := New(
 		mockConfigService,
+		mockConvertService,
+		mockPolicy,
 		nil,
 		nil,
 		nil,
@@ -287,8 +529,16 @@ func TestTrigger_ErrorIncrement(t *testing.T) {
 	mockConfigService := mock.NewMockConfigService(controller)
 	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYaml, nil)
 
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
+
+	mockPolicy := mock.NewMockPolicyService(controller)
+	mockPolicy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(nil, nil)
+
 	triggerer := New(
 		mockConfigService,
+		mockConvertService,
+		mockPolicy,
 		nil,
 		nil,
 		nil,
@@ -304,46 +554,47 @@ func TestTrigger_ErrorIncrement(t *testing.T) {
 	}
 }
 
+// this test verifies that the build is not scheduled if the
+// build, and the configuration file, cannot be persisted.
 func TestTrigger_ErrorCreate(t *testing.T) {
-	t.Skip()
-	// 	controller := gomock.NewController(t)
-	// 	defer controller.Finish()
+	controller := gomock.NewController(t)
+	defer controller.Finish()
 
-	// 	mockUsers := mock.NewMockUserStore(controller)
-	// 	mockUsers.EXPECT().Find(noContext, dummyRepo.UserID).Return(dummyUser, nil)
+	mockUsers := mock.NewMockUserStore(controller)
+	mockUsers.EXPECT().Find(gomock.Any(), dummyRepo.UserID).Return(dummyUser, nil)
 
-	// 	mockTriggers := mock.NewMockTriggerStore(controller)
-	// 	mockTriggers.EXPECT().List(noContext, dummyRepo.ID).Return([]*core.Trigger{dummyTrigger}, nil)
+	mockRepos := mock.NewMockRepositoryStore(controller)
+	mockRepos.EXPECT().Increment(gomock.Any(), dummyRepo).Return(dummyRepo, nil)
 
-	// 	mockRepos := mock.NewMockRepositoryStore(controller)
-	// 	mockRepos.EXPECT().Increment(gomock.Any(), dummyRepo).Return(dummyRepo, nil)
+	mockConfigService := mock.NewMockConfigService(controller)
+	mockConfigService.EXPECT().Find(gomock.Any(), gomock.Any()).Return(dummyYaml, nil)
 
-	// 	mockContents := mock.NewMockContentService(controller)
-	// 	mockContents.EXPECT().Find(gomock.Any(), dummyRepo.Slug, dummyTrigger.Path, dummyHook.After).Return(dummyYaml, nil, nil)
-	// 	mockContents.EXPECT().Find(gomock.Any(), dummyRepo.Slug, dummySignature.Path, dummyHook.After).Return(dummySignature, nil, nil)
+	mockConvertService := mock.NewMockConvertService(controller)
+	mockConvertService.EXPECT().Convert(gomock.Any(), gomock.Any()).DoAndReturn(convertPassthrough)
 
-	// 	mockClient := new(scm.Client)
-	// 	mockClient.Contents = mockContents
+	mockPolicy := mock.NewMockPolicyService(controller)
+	mockPolicy.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(nil, nil)
 
-	// 	mockBuilds := mock.NewMockBuildStore(controller)
-	// 	mockBuilds.EXPECT().Create(gomock.Any(), gomock.Any()).Return(sql.ErrNoRows)
+	mockBuilds := mock.NewMockBuildStore(controller)
+	mockBuilds.EXPECT().CreateWithConfig(gomock.Any(), gomock.Any(), gomock.Any(), dummyYaml).Return(sql.ErrConnDone)
 
-	// 	triggerer := New(
-	// 		mockClient,
-	// 		mockBuilds,
-	// 		nil,
-	// 		mockRepos,
-	// 		mockTriggers,
-	// 		mockUsers,
-	// 	)
+	triggerer := New(
+		mockConfigService,
+		mockConvertService,
+		mockPolicy,
+		nil,
+		nil,
+		mockBuilds,
+		nil,
+		mockRepos,
+		mockUsers,
+		nil,
+	)
 
-	// 	builds, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
-	// 	if err != sql.ErrNoRows {
-	// 		t.Error("Expect error when persisting the build fails")
-	// 	}
-	// 	if got, want := len(builds), 0; got != want {
-	// 		t.Errorf("Got build count %d, want %d", got, want)
-	// 	}
+	_, err := triggerer.Trigger(noContext, dummyRepo, dummyHook)
+	if got, want := err, sql.ErrConnDone; got != want {
+		t.Errorf("Want error %v, got %v", want, got)
+	}
 }
 
 func TestTrigger_ErrorEnqueue(t *testing.T) {